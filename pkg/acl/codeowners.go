@@ -0,0 +1,203 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// CodeOwnersPaths are the locations of the CODEOWNERS file, in the order they
+// are looked up.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// a GitLab section header like "[Docs]", "^[Docs][2]" with its default owners
+var codeOwnersSectionRegexp = regexp.MustCompile(`^\^?\[[^\]]+\](\[\d+\])?`)
+
+// MemberChecker returns true if the sender is a member of the team or group
+// mentioned in a CODEOWNERS file, without the leading @.
+type MemberChecker func(ctx context.Context, team string) (bool, error)
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeOwnersSection is a GitLab section of a CODEOWNERS file, the rules
+// before the first section are in a section of their own.
+type codeOwnersSection []codeOwnersRule
+
+// UserInCodeOwnersFile returns true if the sender is a code owner of all the
+// changed files in the first CODEOWNERS file found, mentioned directly or as a
+// member of a team checked with isMember. The owners of a file are the owners
+// of the last pattern matching it, in each GitLab section. A file without
+// owners is not owned by the sender, and neither is an event without changed
+// files. Email addresses are ignored.
+func UserInCodeOwnersFile(ctx context.Context, getFile FileGetter, changedFiles []string, sender string, isMember MemberChecker) (bool, error) {
+	var content string
+	for _, path := range CodeOwnersPaths {
+		var err error
+		if content, err = getFile(ctx, path); err != nil {
+			return false, err
+		}
+		if content != "" {
+			break
+		}
+	}
+	if content == "" || len(changedFiles) == 0 {
+		return false, nil
+	}
+	sections, err := parseCodeOwners(content)
+	if err != nil {
+		return false, err
+	}
+
+	memberships := map[string]bool{}
+	isOwner := func(owner string) (bool, error) {
+		if strings.EqualFold(owner, sender) {
+			return true, nil
+		}
+		if member, ok := memberships[owner]; ok {
+			return member, nil
+		}
+		member, err := isMember(ctx, owner)
+		if err != nil {
+			return false, err
+		}
+		memberships[owner] = member
+		return member, nil
+	}
+	for _, file := range changedFiles {
+		owned := false
+		for _, owner := range fileCodeOwners(sections, file) {
+			if owned, err = isOwner(owner); err != nil {
+				return false, err
+			}
+			if owned {
+				break
+			}
+		}
+		if !owned {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// UserInEventCodeOwnersFile returns true if the sender is a code owner of all
+// the files changed by the event, see UserInCodeOwnersFile.
+func UserInEventCodeOwnersFile(ctx context.Context, getFile FileGetter, getChangedFiles ChangedFilesGetter, event *info.Event, sender string, isMember MemberChecker) (bool, error) {
+	changedFiles, err := getChangedFiles(ctx, event)
+	if err != nil {
+		return false, fmt.Errorf("cannot list the changed files to check the CODEOWNERS file: %w", err)
+	}
+	return UserInCodeOwnersFile(ctx, getFile, changedFiles.All, sender, isMember)
+}
+
+// fileCodeOwners returns the owners of the last rule matching the file in
+// each section.
+func fileCodeOwners(sections []codeOwnersSection, file string) []string {
+	owners := []string{}
+	for _, section := range sections {
+		for i := len(section) - 1; i >= 0; i-- {
+			if section[i].pattern.MatchString(file) {
+				owners = append(owners, section[i].owners...)
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// parseCodeOwners parses the rules of a CODEOWNERS file. A rule without owners
+// takes the default owners of its GitLab section.
+func parseCodeOwners(content string) ([]codeOwnersSection, error) {
+	sections := []codeOwnersSection{{}}
+	defaults := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if loc := codeOwnersSectionRegexp.FindStringIndex(line); loc != nil {
+			sections = append(sections, codeOwnersSection{})
+			defaults = mentions(strings.Fields(line[loc[1]:]))
+			continue
+		}
+		// escaped spaces are part of the pattern
+		fields := strings.Fields(strings.ReplaceAll(line, `\ `, "\x00"))
+		pattern, err := codeOwnersPattern(strings.ReplaceAll(fields[0], "\x00", " "))
+		if err != nil {
+			return nil, err
+		}
+		owners := mentions(fields[1:])
+		if len(fields) == 1 && len(sections) > 1 {
+			owners = defaults
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], codeOwnersRule{pattern: pattern, owners: owners})
+	}
+	return sections, nil
+}
+
+// mentions returns the users and teams mentioned in the fields of a rule,
+// without the leading @.
+func mentions(fields []string) []string {
+	owners := []string{}
+	for _, field := range fields {
+		if owner, ok := strings.CutPrefix(field, "@"); ok && owner != "" {
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// codeOwnersPattern compiles a gitignore like CODEOWNERS pattern matching the
+// paths relative to the root of the repository. A pattern with a leading or
+// a middle slash is anchored to the root, a pattern matching a directory
+// matches all the files under it.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(.*/)?")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch c := trimmed[i]; {
+		case c == '*' && i+1 < len(trimmed) && trimmed[i+1] == '*':
+			i++
+			if i+1 < len(trimmed) && trimmed[i+1] == '/' {
+				i++
+				expr.WriteString("(.*/)?")
+			} else {
+				expr.WriteString(".*")
+			}
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		expr.WriteString("/.*$")
+	} else {
+		expr.WriteString("(/.*)?$")
+	}
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile the CODEOWNERS pattern %s: %w", pattern, err)
+	}
+	return re, nil
+}
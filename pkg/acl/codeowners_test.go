@@ -0,0 +1,168 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	content := `# global owners
+*       @global-owner1 @org/global-team
+*.js    @js-owner # inline comment
+/docs/  docs@example.com @docs-owner
+path\ with\ spaces/ @space-owner
+/apps/**/test/ @test-owner
+/generated/
+
+[Database][2] @database-team
+model/db/
+^[Optional] @group/subgroup
+db/schema.sql @dba
+`
+	sections, err := parseCodeOwners(content)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sections), 3)
+
+	tests := []struct {
+		file   string
+		owners []string
+	}{
+		{file: "README.md", owners: []string{"global-owner1", "org/global-team"}},
+		{file: "src/app.js", owners: []string{"js-owner"}},
+		{file: "docs/index.md", owners: []string{"docs-owner"}},
+		{file: "src/docs/index.md", owners: []string{"global-owner1", "org/global-team"}},
+		{file: "path with spaces/file.txt", owners: []string{"space-owner"}},
+		{file: "sub/path with spaces/file.txt", owners: []string{"space-owner"}},
+		{file: "apps/test/main_test.go", owners: []string{"test-owner"}},
+		{file: "apps/web/test/main_test.go", owners: []string{"test-owner"}},
+		{file: "generated/zz_generated.go", owners: []string{}},
+		{file: "model/db/user.go", owners: []string{"global-owner1", "org/global-team", "database-team"}},
+		{file: "db/schema.sql", owners: []string{"global-owner1", "org/global-team", "dba"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			assert.DeepEqual(t, fileCodeOwners(sections, tt.file), tt.owners)
+		})
+	}
+}
+
+func TestUserInCodeOwnersFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		changedFiles []string
+		sender       string
+		members      map[string][]string
+		want         bool
+		wantErr      string
+	}{
+		{
+			name:   "user listed directly",
+			files:  map[string]string{".github/CODEOWNERS": "* @maintainer\n"},
+			sender: "maintainer",
+			want:   true,
+		},
+		{
+			name:    "member of a team",
+			files:   map[string]string{"CODEOWNERS": "* @org/maintainers\n"},
+			sender:  "contributor",
+			members: map[string][]string{"org/maintainers": {"contributor"}},
+			want:    true,
+		},
+		{
+			name:    "not an owner",
+			files:   map[string]string{"docs/CODEOWNERS": "* @maintainer @org/maintainers\n"},
+			sender:  "contributor",
+			members: map[string][]string{"org/maintainers": {"someone"}},
+			want:    false,
+		},
+		{
+			name: "first file found",
+			files: map[string]string{
+				".github/CODEOWNERS": "* @maintainer\n",
+				"CODEOWNERS":         "* @contributor\n",
+			},
+			sender: "contributor",
+			want:   false,
+		},
+		{
+			name:   "no CODEOWNERS file",
+			files:  map[string]string{},
+			sender: "contributor",
+			want:   false,
+		},
+		{
+			name:         "owner of the changed files only",
+			files:        map[string]string{"CODEOWNERS": "* @maintainer\n/docs/ @docs-writer\n"},
+			changedFiles: []string{"docs/index.md", "main.go"},
+			sender:       "docs-writer",
+			want:         false,
+		},
+		{
+			name:         "owner of all the changed files",
+			files:        map[string]string{"CODEOWNERS": "* @maintainer\n/docs/ @docs-writer\n*.md @docs-writer\n"},
+			changedFiles: []string{"docs/index.md", "README.md"},
+			sender:       "docs-writer",
+			want:         true,
+		},
+		{
+			name:         "last matching pattern wins",
+			files:        map[string]string{"CODEOWNERS": "/docs/ @docs-writer\n/docs/api/ @api-owner\n"},
+			changedFiles: []string{"docs/api/index.md"},
+			sender:       "docs-writer",
+			want:         false,
+		},
+		{
+			name:         "changed file without owners",
+			files:        map[string]string{"CODEOWNERS": "* @maintainer\n/vendor/\n"},
+			changedFiles: []string{"vendor/modules.txt"},
+			sender:       "maintainer",
+			want:         false,
+		},
+		{
+			name:         "no changed files",
+			files:        map[string]string{"CODEOWNERS": "* @maintainer\n"},
+			changedFiles: []string{},
+			sender:       "maintainer",
+			want:         false,
+		},
+		{
+			name:    "membership error",
+			files:   map[string]string{"CODEOWNERS": "* @org/error\n"},
+			sender:  "contributor",
+			wantErr: "cannot check membership",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getFile := func(_ context.Context, path string) (string, error) {
+				return tt.files[path], nil
+			}
+			isMember := func(_ context.Context, team string) (bool, error) {
+				if team == "org/error" {
+					return false, fmt.Errorf("cannot check membership")
+				}
+				for _, member := range tt.members[team] {
+					if member == tt.sender {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+			changedFiles := tt.changedFiles
+			if changedFiles == nil {
+				changedFiles = []string{"main.go"}
+			}
+			got, err := UserInCodeOwnersFile(context.Background(), getFile, changedFiles, tt.sender, isMember)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
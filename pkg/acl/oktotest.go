@@ -0,0 +1,102 @@
+package acl
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// OkToTestApproval is the record of the /ok-to-test approval of a pull
+// request, stored as JSON in an annotation of the Repository CR.
+type OkToTestApproval struct {
+	Approver   string     `json:"approver,omitempty"`
+	SHA        string     `json:"sha,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedBy  string     `json:"revoked_by,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// OkToTestApprovalAnnotation returns the Repository annotation recording the
+// /ok-to-test approval of the pull request.
+func OkToTestApprovalAnnotation(pullRequestNumber int) string {
+	return fmt.Sprintf("%s-%d", keys.OkToTestApproval, pullRequestNumber)
+}
+
+// NewOkToTestApproval returns the approval given by approver on the sha at
+// now, expiring after ttl when it is set.
+func NewOkToTestApproval(approver, sha, ttl string, now time.Time) (*OkToTestApproval, error) {
+	approvedAt := now.UTC()
+	approval := &OkToTestApproval{Approver: approver, SHA: sha, ApprovedAt: &approvedAt}
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ok-to-test-ttl %q: %w", ttl, err)
+		}
+		expiresAt := approvedAt.Add(d)
+		approval.ExpiresAt = &expiresAt
+	}
+	return approval, nil
+}
+
+// GetOkToTestApproval returns the approval recorded for the pull request on
+// the Repository CR, or nil when there is none.
+func GetOkToTestApproval(repo *v1alpha1.Repository, pullRequestNumber int) (*OkToTestApproval, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	value, ok := repo.GetAnnotations()[OkToTestApprovalAnnotation(pullRequestNumber)]
+	if !ok {
+		return nil, nil
+	}
+	approval := &OkToTestApproval{}
+	if err := json.Unmarshal([]byte(value), approval); err != nil {
+		return nil, fmt.Errorf("cannot parse the /ok-to-test approval of pull request %d: %w", pullRequestNumber, err)
+	}
+	return approval, nil
+}
+
+// Revoke marks the approval as revoked by user at now.
+func (a *OkToTestApproval) Revoke(user string, now time.Time) {
+	revokedAt := now.UTC()
+	a.RevokedBy = user
+	a.RevokedAt = &revokedAt
+}
+
+// Active returns true if the approval has been given, has not been revoked
+// and has not expired at now.
+func (a *OkToTestApproval) Active(now time.Time) bool {
+	if a == nil || a.Approver == "" || a.RevokedAt != nil {
+		return false
+	}
+	return a.ExpiresAt == nil || now.Before(*a.ExpiresAt)
+}
+
+// RememberOkToTest returns true if the /ok-to-test comments previously posted
+// on the pull request of the event can still allow it to run.
+//
+// A revoked or expired approval is never remembered, nor an approval given on
+// another commit when require-ok-to-test-sha is enabled. When a ttl is set the
+// approval has to be recorded. A new /ok-to-test or a /revoke-ok-to-test
+// always has to come from an allowed user, not by way of the previous
+// approvals, the approval is then recorded with its sender.
+func RememberOkToTest(repo *v1alpha1.Repository, event *info.Event, opts *info.PacOpts, now time.Time) bool {
+	if MatchRegexp(RevokeOkToTestCommentRegexp, event.TriggerComment) || MatchRegexp(OKToTestCommentRegexp, event.TriggerComment) {
+		return false
+	}
+	approval, err := GetOkToTestApproval(repo, event.PullRequestNumber)
+	if err != nil {
+		return false
+	}
+	if approval == nil {
+		return opts.OkToTestTTL == ""
+	}
+	if opts.RequireOkToTestSHA && approval.SHA != event.SHA {
+		return false
+	}
+	return approval.Active(now)
+}
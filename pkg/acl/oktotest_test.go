@@ -0,0 +1,125 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRememberOkToTest(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := `{"approver":"owner","sha":"abc","approved_at":"2025-01-01T10:00:00Z","expires_at":"2025-01-01T14:00:00Z"}`
+	tests := []struct {
+		name           string
+		annotation     string
+		triggerComment string
+		ttl            string
+		requireSHA     bool
+		sha            string
+		want           bool
+	}{
+		{
+			name: "no record without ttl",
+			want: true,
+		},
+		{
+			name: "no record with ttl",
+			ttl:  "1h",
+		},
+		{
+			name:       "active",
+			annotation: active,
+			ttl:        "4h",
+			want:       true,
+		},
+		{
+			name:       "expired",
+			annotation: `{"approver":"owner","approved_at":"2025-01-01T10:00:00Z","expires_at":"2025-01-01T11:00:00Z"}`,
+			ttl:        "1h",
+		},
+		{
+			name:       "revoked",
+			annotation: `{"approver":"owner","approved_at":"2025-01-01T10:00:00Z","revoked_by":"security","revoked_at":"2025-01-01T11:00:00Z"}`,
+		},
+		{
+			name:       "revoked without approval",
+			annotation: `{"revoked_by":"security","revoked_at":"2025-01-01T11:00:00Z"}`,
+		},
+		{
+			name:       "invalid record",
+			annotation: `not json`,
+		},
+		{
+			name:           "new ok-to-test with ttl is not renewed by the previous approvals",
+			annotation:     active,
+			triggerComment: "/ok-to-test",
+			ttl:            "4h",
+		},
+		{
+			name:           "new ok-to-test without ttl is not allowed by the previous approvals",
+			annotation:     active,
+			triggerComment: "/ok-to-test",
+		},
+		{
+			name:           "revoke-ok-to-test is not allowed by the previous approvals",
+			annotation:     active,
+			triggerComment: "/revoke-ok-to-test",
+		},
+		{
+			name:       "approved commit with require-ok-to-test-sha",
+			annotation: active,
+			requireSHA: true,
+			sha:        "abc",
+			want:       true,
+		},
+		{
+			name:       "another commit with require-ok-to-test-sha",
+			annotation: active,
+			requireSHA: true,
+			sha:        "def",
+		},
+		{
+			name:       "another commit without require-ok-to-test-sha",
+			annotation: active,
+			sha:        "def",
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+			if tt.annotation != "" {
+				repo.Annotations[OkToTestApprovalAnnotation(42)] = tt.annotation
+			}
+			event := &info.Event{PullRequestNumber: 42, SHA: tt.sha, TriggerComment: tt.triggerComment}
+			opts := &info.PacOpts{}
+			opts.OkToTestTTL = tt.ttl
+			opts.RequireOkToTestSHA = tt.requireSHA
+			assert.Equal(t, RememberOkToTest(repo, event, opts, now), tt.want)
+		})
+	}
+}
+
+func TestNewOkToTestApproval(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	approval, err := NewOkToTestApproval("owner", "abc", "", now)
+	assert.NilError(t, err)
+	assert.Assert(t, approval.ExpiresAt == nil)
+	assert.Assert(t, approval.Active(now.Add(24*time.Hour)))
+
+	approval, err = NewOkToTestApproval("owner", "abc", "2h", now)
+	assert.NilError(t, err)
+	assert.Equal(t, *approval.ExpiresAt, now.Add(2*time.Hour))
+	assert.Assert(t, approval.Active(now.Add(time.Hour)))
+	assert.Assert(t, !approval.Active(now.Add(2*time.Hour)))
+
+	approval.Revoke("security", now)
+	assert.Assert(t, !approval.Active(now))
+
+	_, err = NewOkToTestApproval("owner", "abc", "forever", now)
+	assert.ErrorContains(t, err, "invalid ok-to-test-ttl")
+}
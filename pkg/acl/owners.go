@@ -1,63 +1,171 @@
 package acl
 
 import (
+	"context"
 	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"sigs.k8s.io/yaml"
 )
 
+const (
+	ownersFile        = "OWNERS"
+	ownersAliasesFile = "OWNERS_ALIASES"
+)
+
 type aliases = map[string][]string
 
 type simpleConfig struct {
-	Approvers []string `json:"approvers,omitempty"`
-	Reviewers []string `json:"reviewers,omitempty"`
-}
-
-type filtersConfig struct {
-	Filters map[string]simpleConfig `json:"filters,omitempty"`
+	Approvers         []string `json:"approvers,omitempty"`
+	Reviewers         []string `json:"reviewers,omitempty"`
+	EmeritusApprovers []string `json:"emeritus_approvers,omitempty"`
 }
 
 type aliasesConfig struct {
 	Aliases aliases `json:"aliases,omitempty"`
 }
 
-// UserInOwnerFile Parse OWNERS and OWNERS_ALIASES files and return true if the sender is in
-// there. Support OWNERS simple configs (approvers, reviewers) and filters. When filters are used,
-// only match against the ".*" filter.
-func UserInOwnerFile(ownersContent, ownersAliasesContent, sender string) (bool, error) {
-	sc := simpleConfig{}
-	fc := filtersConfig{}
-	ac := aliasesConfig{}
-	err := yaml.Unmarshal([]byte(ownersContent), &sc)
-	if err != nil {
-		return false, fmt.Errorf("cannot parse OWNERS file Approvers and Reviewers: %w", err)
-	}
-	err = yaml.Unmarshal([]byte(ownersContent), &fc)
+// FileGetter returns the content of a file of the repository, or an empty
+// string when the file does not exist.
+type FileGetter func(ctx context.Context, path string) (string, error)
+
+type ownersOptions struct {
+	NoParentOwners bool `json:"no_parent_owners,omitempty"`
+}
+
+type ownersConfig struct {
+	Approvers         []string                `json:"approvers,omitempty"`
+	Reviewers         []string                `json:"reviewers,omitempty"`
+	EmeritusApprovers []string                `json:"emeritus_approvers,omitempty"`
+	Options           ownersOptions           `json:"options,omitempty"`
+	Filters           map[string]simpleConfig `json:"filters,omitempty"`
+}
+
+// UserInOwnersFiles resolves the owners of the changed files like Prow does
+// and returns true if the sender is an owner of all of them.
+//
+// The owners of a file are the approvers and reviewers of the closest OWNERS
+// file and of the OWNERS files of the parent directories, up to the root of
+// the repository or to an OWNERS file setting no_parent_owners. The filters
+// of an OWNERS file apply when their regexp matches the path of the file
+// relative to the directory of the OWNERS file, and the emeritus approvers are
+// not owners. The aliases are read from the root OWNERS_ALIASES file. Without
+// changed files only the root OWNERS file is checked.
+func UserInOwnersFiles(ctx context.Context, getFile FileGetter, changedFiles []string, sender string) (bool, error) {
+	aliasesContent, err := getFile(ctx, ownersAliasesFile)
 	if err != nil {
-		return false, fmt.Errorf("cannot parse OWNERS file Filters: %w", err)
+		return false, err
 	}
-	err = yaml.Unmarshal([]byte(ownersAliasesContent), &ac)
-	if err != nil {
+	ac := aliasesConfig{}
+	if err := yaml.Unmarshal([]byte(aliasesContent), &ac); err != nil {
 		return false, fmt.Errorf("cannot parse OWNERS_ALIASES: %w", err)
 	}
 
-	var approvers, reviewers []string
-	if len(sc.Approvers) > 0 || len(sc.Reviewers) > 0 {
-		approvers, reviewers = sc.Approvers, sc.Reviewers
-		// Simple config (approvers/reviewers) and filters can't exist together.
-		// We only check for the ".*" filter (matching all files in the repo).
-	} else if filter, ok := fc.Filters[".*"]; ok {
-		if len(filter.Approvers) > 0 || len(filter.Reviewers) > 0 {
-			approvers, reviewers = filter.Approvers, filter.Reviewers
+	configs := map[string]*ownersConfig{}
+	getConfig := func(dir string) (*ownersConfig, error) {
+		if config, ok := configs[dir]; ok {
+			return config, nil
+		}
+		filePath := path.Join(dir, ownersFile)
+		content, err := getFile(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		var config *ownersConfig
+		if content != "" {
+			config = &ownersConfig{}
+			if err := yaml.Unmarshal([]byte(content), config); err != nil {
+				return nil, fmt.Errorf("cannot parse %s file: %w", filePath, err)
+			}
 		}
+		configs[dir] = config
+		return config, nil
 	}
-	owners := expandAliases(append(approvers, reviewers...), ac.Aliases)
-	for _, owner := range owners {
-		if owner == sender {
-			return true, nil
+
+	if len(changedFiles) == 0 {
+		config, err := getConfig(".")
+		if err != nil || config == nil {
+			return false, err
 		}
+		return config.hasOwner("", sender, ac.Aliases)
+	}
+
+	for _, file := range changedFiles {
+		owned := false
+		for dir := path.Dir(file); ; dir = path.Dir(dir) {
+			config, err := getConfig(dir)
+			if err != nil {
+				return false, err
+			}
+			if config != nil {
+				relative := strings.TrimPrefix(file, dir+"/")
+				if owned, err = config.hasOwner(relative, sender, ac.Aliases); err != nil {
+					return false, err
+				}
+				if owned || config.Options.NoParentOwners {
+					break
+				}
+			}
+			if dir == "." {
+				break
+			}
+		}
+		if !owned {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ChangedFilesGetter lists the files changed by an event.
+type ChangedFilesGetter func(context.Context, *info.Event) (changedfiles.ChangedFiles, error)
+
+// UserInEventOwnersFiles returns true if the sender is an owner of all the
+// files changed by the event, see UserInOwnersFiles. The changed files have to
+// be listed: checking only the root OWNERS file instead could give another
+// answer. The ACL of an event is evaluated several times, the check is cached
+// on the event for the sender so the OWNERS files are fetched once.
+func UserInEventOwnersFiles(ctx context.Context, getFile FileGetter, getChangedFiles ChangedFilesGetter, event *info.Event, sender string) (bool, error) {
+	if check, ok := event.OwnersFilesChecks[sender]; ok {
+		return check.Allowed, check.Err
+	}
+	changedFiles, err := getChangedFiles(ctx, event)
+	if err != nil {
+		return false, fmt.Errorf("cannot list the changed files to check the OWNERS files: %w", err)
+	}
+	allowed, err := UserInOwnersFiles(ctx, getFile, changedFiles.All, sender)
+	if event.OwnersFilesChecks == nil {
+		event.OwnersFilesChecks = map[string]info.PolicyCheck{}
+	}
+	event.OwnersFilesChecks[sender] = info.PolicyCheck{Allowed: allowed, Err: err}
+	return allowed, err
+}
+
+// hasOwner returns true if the sender is an approver or a reviewer of the
+// file in this OWNERS file, and not an emeritus approver.
+func (c *ownersConfig) hasOwner(file, sender string, aliases aliases) (bool, error) {
+	owners := append(slices.Clone(c.Approvers), c.Reviewers...)
+	emeritus := slices.Clone(c.EmeritusApprovers)
+	for expr, filter := range c.Filters {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return false, fmt.Errorf("cannot compile the OWNERS filter %s: %w", expr, err)
+		}
+		if re.MatchString(file) {
+			owners = append(owners, filter.Approvers...)
+			owners = append(owners, filter.Reviewers...)
+			emeritus = append(emeritus, filter.EmeritusApprovers...)
+		}
+	}
+	if slices.Contains(expandAliases(emeritus, aliases), sender) {
+		return false, nil
 	}
-	return false, nil
+	return slices.Contains(expandAliases(owners, aliases), sender), nil
 }
 
 // Expand aliases into the list of owners removing the duplicates.
@@ -1,12 +1,17 @@
 package acl
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"golang.org/x/exp/slices"
 )
 
-func TestUserInOwnerFile(t *testing.T) {
+func TestUserInRootOwnersFile(t *testing.T) {
 	type args struct {
 		ownersContent        string
 		ownersAliasesContent string
@@ -99,6 +104,15 @@ func TestUserInOwnerFile(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "emeritus approver",
+			args: args{
+				ownersContent:        "---\n approvers:\n  - allowed-alias\n emeritus_approvers:\n  - retired\n",
+				ownersAliasesContent: "---\n aliases:\n  allowed-alias:\n  - retired",
+				sender:               "retired",
+			},
+			want: false,
+		},
 		{
 			name: "no owners file",
 			args: args{
@@ -126,13 +140,19 @@ func TestUserInOwnerFile(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UserInOwnerFile(tt.args.ownersContent, tt.args.ownersAliasesContent, tt.args.sender)
+			getFile := func(_ context.Context, path string) (string, error) {
+				if path == ownersAliasesFile {
+					return tt.args.ownersAliasesContent, nil
+				}
+				return tt.args.ownersContent, nil
+			}
+			got, err := UserInOwnersFiles(context.Background(), getFile, nil, tt.args.sender)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("UserInOwnerFile() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("UserInOwnersFiles() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("UserInOwnerFile() = %v, want %v", got, tt.want)
+				t.Errorf("UserInOwnersFiles() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -206,3 +226,218 @@ func TestExpandAliases(t *testing.T) {
 		})
 	}
 }
+
+func TestUserInOwnersFiles(t *testing.T) {
+	files := map[string]string{
+		"OWNERS":              "approvers:\n- root-approver\nreviewers:\n- root-reviewer\n",
+		"OWNERS_ALIASES":      "aliases:\n  docs-team:\n  - docs-writer\n  legacy-team:\n  - retired\n  - maintainer\n",
+		"docs/OWNERS":         "approvers:\n- docs-team\n",
+		"pkg/OWNERS":          "filters:\n  \\.go$:\n    approvers:\n    - go-approver\n  ^api/:\n    approvers:\n    - api-approver\n",
+		"vendor/OWNERS":       "options:\n  no_parent_owners: true\napprovers:\n- vendor-approver\n",
+		"pkg/legacy/OWNERS":   "approvers:\n- legacy-team\nemeritus_approvers:\n- retired\n",
+		"broken/OWNERS":       "filters:\n  \"[\":\n    approvers:\n    - someone\n",
+		"badyaml/deep/OWNERS": "bad",
+	}
+	getFile := func(_ context.Context, path string) (string, error) {
+		return files[path], nil
+	}
+	tests := []struct {
+		name         string
+		changedFiles []string
+		sender       string
+		want         bool
+		wantErr      string
+	}{
+		{
+			name:   "root owner without changed files",
+			sender: "root-reviewer",
+			want:   true,
+		},
+		{
+			name:   "not an owner without changed files",
+			sender: "docs-writer",
+			want:   false,
+		},
+		{
+			name:         "owner of the closest OWNERS file through an alias",
+			changedFiles: []string{"docs/index.md", "docs/guides/install.md"},
+			sender:       "docs-writer",
+			want:         true,
+		},
+		{
+			name:         "not an owner of every changed file",
+			changedFiles: []string{"docs/index.md", "README.md"},
+			sender:       "docs-writer",
+			want:         false,
+		},
+		{
+			name:         "root owner of files in subdirectories",
+			changedFiles: []string{"docs/index.md", "pkg/api/types.go", "README.md"},
+			sender:       "root-approver",
+			want:         true,
+		},
+		{
+			name:         "filter matching the file",
+			changedFiles: []string{"pkg/main.go", "pkg/api/types.go"},
+			sender:       "go-approver",
+			want:         true,
+		},
+		{
+			name:         "filter not matching the file",
+			changedFiles: []string{"pkg/README.md"},
+			sender:       "go-approver",
+			want:         false,
+		},
+		{
+			name:         "filter matched relative to the OWNERS file",
+			changedFiles: []string{"pkg/api/README.md"},
+			sender:       "api-approver",
+			want:         true,
+		},
+		{
+			name:         "no parent owners",
+			changedFiles: []string{"vendor/lib/lib.go"},
+			sender:       "root-approver",
+			want:         false,
+		},
+		{
+			name:         "owner with no parent owners",
+			changedFiles: []string{"vendor/lib/lib.go"},
+			sender:       "vendor-approver",
+			want:         true,
+		},
+		{
+			name:         "emeritus approver",
+			changedFiles: []string{"pkg/legacy/old.go"},
+			sender:       "retired",
+			want:         false,
+		},
+		{
+			name:         "approver next to an emeritus approver",
+			changedFiles: []string{"pkg/legacy/old.go"},
+			sender:       "maintainer",
+			want:         true,
+		},
+		{
+			name:         "invalid filter",
+			changedFiles: []string{"broken/file"},
+			sender:       "root-approver",
+			wantErr:      "cannot compile the OWNERS filter",
+		},
+		{
+			name:         "invalid OWNERS file",
+			changedFiles: []string{"badyaml/deep/file"},
+			sender:       "root-approver",
+			wantErr:      "cannot parse badyaml/deep/OWNERS file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UserInOwnersFiles(context.Background(), getFile, tt.changedFiles, tt.sender)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("UserInOwnersFiles() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("UserInOwnersFiles() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("UserInOwnersFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserInEventOwnersFiles(t *testing.T) {
+	getFile := func(_ context.Context, path string) (string, error) {
+		if path == "docs/OWNERS" {
+			return "approvers:\n- docs-writer\n", nil
+		}
+		return "approvers:\n- root-approver\n", nil
+	}
+	tests := []struct {
+		name            string
+		changedFiles    []string
+		changedFilesErr error
+		sender          string
+		want            bool
+		wantErr         string
+	}{
+		{
+			name:         "owner of the changed files",
+			changedFiles: []string{"docs/index.md"},
+			sender:       "docs-writer",
+			want:         true,
+		},
+		{
+			name:         "not an owner of the changed files",
+			changedFiles: []string{"README.md"},
+			sender:       "docs-writer",
+			want:         false,
+		},
+		{
+			name:            "changed files cannot be listed",
+			changedFilesErr: fmt.Errorf("api down"),
+			sender:          "root-approver",
+			wantErr:         "cannot list the changed files to check the OWNERS files: api down",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getChangedFiles := func(_ context.Context, _ *info.Event) (changedfiles.ChangedFiles, error) {
+				return changedfiles.ChangedFiles{All: tt.changedFiles}, tt.changedFilesErr
+			}
+			got, err := UserInEventOwnersFiles(context.Background(), getFile, getChangedFiles, info.NewEvent(), tt.sender)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("UserInEventOwnersFiles() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got {
+					t.Errorf("UserInEventOwnersFiles() = %v on error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("UserInEventOwnersFiles() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("UserInEventOwnersFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserInEventOwnersFilesCached(t *testing.T) {
+	fetched := 0
+	getFile := func(_ context.Context, _ string) (string, error) {
+		fetched++
+		return "approvers:\n- root-approver\n", nil
+	}
+	listed := 0
+	getChangedFiles := func(_ context.Context, _ *info.Event) (changedfiles.ChangedFiles, error) {
+		listed++
+		return changedfiles.ChangedFiles{All: []string{"docs/index.md", "README.md"}}, nil
+	}
+	event := info.NewEvent()
+	for range 3 {
+		got, err := UserInEventOwnersFiles(context.Background(), getFile, getChangedFiles, event, "root-approver")
+		if err != nil || !got {
+			t.Fatalf("UserInEventOwnersFiles() = %v, %v, want true", got, err)
+		}
+	}
+	if listed != 1 || fetched != 3 {
+		t.Errorf("changed files listed %d times and OWNERS files fetched %d times, want 1 and 3", listed, fetched)
+	}
+
+	// another user is checked on their own
+	if got, _ := UserInEventOwnersFiles(context.Background(), getFile, getChangedFiles, event, "someone"); got {
+		t.Errorf("UserInEventOwnersFiles() = %v for someone, want false", got)
+	}
+	if listed != 2 {
+		t.Errorf("changed files listed %d times, want 2", listed)
+	}
+}
@@ -92,6 +92,7 @@ func (l *listener) Start(ctx context.Context) error {
 		_, _ = fmt.Fprint(w, "ok")
 	})
 
+	mux.HandleFunc(incomingStatusPath, l.handleIncomingStatus(ctx))
 	mux.HandleFunc("/", l.handleEvent(ctx))
 
 	srv := &http.Server{
@@ -171,15 +172,26 @@ func (l listener) handleEvent(ctx context.Context) http.HandlerFunc {
 		}
 
 		isIncoming, targettedRepo, err := l.detectIncoming(ctx, request, payload)
+		if errors.Is(err, errIncomingSkipped) {
+			l.writeResponse(response, http.StatusOK, "skipped event")
+			return
+		}
 		if err != nil {
-			if errors.Is(err, errMissingFields) {
+			if errors.Is(err, errMissingFields) || errors.Is(err, errInvalidIncomingParams) {
 				l.writeResponse(response, http.StatusBadRequest, err.Error())
 			}
 			l.logger.Errorf("error processing incoming webhook: %v", err)
 			return
 		}
 
+		var incomingID string
 		if isIncoming {
+			if incomingID, err = newIncomingID(); err != nil {
+				l.logger.Error(err)
+				response.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			l.event.IncomingID = incomingID
 			gitProvider, logger, err = l.processIncoming(targettedRepo)
 		} else {
 			gitProvider, logger, err = l.detectProvider(request, string(payload))
@@ -206,13 +218,31 @@ func (l listener) handleEvent(ctx context.Context) http.HandlerFunc {
 		// clone the request to use it further
 		localRequest := request.Clone(request.Context())
 
+		processed := make(chan struct{})
 		go func() {
+			defer close(processed)
 			err := s.processEvent(ctx, localRequest)
 			if err != nil {
 				logger.Errorf("an error occurred: %v", err)
 			}
+			if isIncoming {
+				l.recordIncomingOutcome(ctx, incomingID, targettedRepo, err)
+			}
 		}()
 
+		// the reply to an incoming webhook request has its PipelineRuns when
+		// they have been created in time, they are otherwise followed with
+		// the id on the status endpoint.
+		if isIncoming {
+			select {
+			case <-processed:
+				l.writeJSON(response, http.StatusAccepted, l.processedIncoming(ctx, incomingID, targettedRepo))
+			case <-time.After(incomingReplyTimeout):
+				l.writeJSON(response, http.StatusAccepted, acceptedIncoming(incomingID, targettedRepo))
+			}
+			return
+		}
+
 		l.writeResponse(response, http.StatusAccepted, "accepted")
 	}
 }
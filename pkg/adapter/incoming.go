@@ -2,18 +2,25 @@ package adapter
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketdatacenter"
@@ -27,8 +34,24 @@ import (
 
 const (
 	defaultIncomingWebhookSecretKey = "secret"
+
+	incomingAuthHMACSHA256         = "hmac-sha256"
+	incomingAuthSecret             = "secret"
+	defaultIncomingSignatureHeader = apincoming.SignatureHeader
+	defaultIncomingTimestampHeader = apincoming.TimestampHeader
+	defaultIncomingReplayWindow    = 5 * time.Minute
+	incomingSignaturePrefix        = apincoming.SignaturePrefix
+
+	incomingTypeWebhookURL  = "webhook-url"
+	incomingTypePullRequest = "pull-request"
+	incomingTypeSHA         = "sha"
+
+	incomingCommandRetest = apincoming.CommandRetest
+	incomingCommandCancel = apincoming.CommandCancel
 )
 
+var shaRegex = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
 var errMissingFields = errors.New("missing required fields")
 
 func errMissingSpecificFields(fields []string) error {
@@ -44,17 +67,28 @@ type incomingPayload struct {
 	PipelineRun string         `json:"pipelinerun"`
 	Secret      string         `json:"secret"`
 	Params      map[string]any `json:"params"`
+	PullRequest int            `json:"pull_request,omitempty"` // Only for the pull-request incoming webhooks
+	SHA         string         `json:"sha,omitempty"`          // Only for the sha incoming webhooks
+	Command     string         `json:"command,omitempty"`      // Only for the pull-request incoming webhooks
 }
 
-func (payload *incomingPayload) validate() error {
+// validate checks the required fields are set, the secret is only required
+// when the webhook is authenticated with a plain shared secret and the
+// PipelineRun is optional with a command, which then targets all of them.
+func (payload *incomingPayload) validate(requireSecret bool) error {
 	missingFields := []string{}
 
-	for field, value := range map[string]string{
-		"repository":  payload.RepoName,
-		"branch":      payload.Branch,
-		"pipelinerun": payload.PipelineRun,
-		"secret":      payload.Secret,
-	} {
+	fields := map[string]string{
+		"repository": payload.RepoName,
+		"branch":     payload.Branch,
+	}
+	if payload.Command == "" {
+		fields["pipelinerun"] = payload.PipelineRun
+	}
+	if requireSecret {
+		fields["secret"] = payload.Secret
+	}
+	for field, value := range fields {
 		if value == "" {
 			missingFields = append(missingFields, field)
 		}
@@ -77,7 +111,7 @@ func parseIncomingPayload(request *http.Request, payloadBody []byte) (incomingPa
 		legacyMode:  true,
 	}
 
-	if parsedPayload.validate() != nil {
+	if parsedPayload.validate(true) != nil {
 		if request.Method == http.MethodPost && request.Header.Get("Content-Type") == "application/json" && len(payloadBody) > 0 {
 			parsedPayload = incomingPayload{legacyMode: false}
 			if err := json.Unmarshal(payloadBody, &parsedPayload); err != nil {
@@ -86,13 +120,82 @@ func parseIncomingPayload(request *http.Request, payloadBody []byte) (incomingPa
 		}
 	}
 
-	return parsedPayload, parsedPayload.validate()
+	return parsedPayload, parsedPayload.validate(false)
 }
 
 func compareSecret(incomingSecret, secretValue string) bool {
 	return subtle.ConstantTimeCompare([]byte(incomingSecret), []byte(secretValue)) != 0
 }
 
+// verifyIncomingSignature checks the request has been signed with the secret
+// within the replay window. The signature is the hex encoded HMAC-SHA256 of
+// the timestamp, a dot and the body.
+func verifyIncomingSignature(header http.Header, payloadBody []byte, secretValue string, auth *v1alpha1.IncomingAuth, now time.Time) error {
+	signatureHeader := auth.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = defaultIncomingSignatureHeader
+	}
+	timestampHeader := auth.TimestampHeader
+	if timestampHeader == "" {
+		timestampHeader = defaultIncomingTimestampHeader
+	}
+	replayWindow := defaultIncomingReplayWindow
+	if auth.ReplayWindow != nil && auth.ReplayWindow.Duration > 0 {
+		replayWindow = auth.ReplayWindow.Duration
+	}
+
+	timestamp := header.Get(timestampHeader)
+	if timestamp == "" {
+		return fmt.Errorf("missing %s header on the signed incoming webhook request", timestampHeader)
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header %q: %w", timestampHeader, timestamp, err)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > replayWindow || age < -replayWindow {
+		return fmt.Errorf("incoming webhook request signed at %s is outside of the replay window of %s", timestamp, replayWindow)
+	}
+
+	signature := header.Get(signatureHeader)
+	if signature == "" {
+		return fmt.Errorf("missing %s header on the signed incoming webhook request", signatureHeader)
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, incomingSignaturePrefix))
+	if err != nil {
+		return fmt.Errorf("invalid %s header: %w", signatureHeader, err)
+	}
+	if !hmac.Equal(received, apincoming.Signature(secretValue, timestamp, payloadBody)) {
+		return fmt.Errorf("signature in the %s header does not match the incoming webhook request", signatureHeader)
+	}
+	return nil
+}
+
+// authenticateIncoming authenticates the request with the secret, depending
+// of the authentication type of the incoming webhook.
+func authenticateIncoming(req *http.Request, payloadBody []byte, payload incomingPayload, hook *v1alpha1.Incoming, secretValue string) error {
+	// the plain shared secret ends up in the URLs and the logs of the systems
+	// calling the webhook, it has to be chosen explicitly.
+	if hook.Auth == nil {
+		return fmt.Errorf("the incoming webhook with secret %s has no auth type, set auth.type to %s to sign the requests or to %s to pass the plain secret",
+			hook.Secret.Name, incomingAuthHMACSHA256, incomingAuthSecret)
+	}
+	if hook.Auth.Type == incomingAuthHMACSHA256 {
+		if payload.Secret != "" {
+			return fmt.Errorf("the incoming webhook with secret %s uses %s authentication, the secret must not be passed in the request", hook.Secret.Name, incomingAuthHMACSHA256)
+		}
+		return verifyIncomingSignature(req.Header, payloadBody, secretValue, hook.Auth, time.Now())
+	}
+
+	if payload.Secret == "" {
+		return errMissingSpecificFields([]string{"secret"})
+	}
+	// TODO: move to somewhere common to share between gitlab and here
+	if !compareSecret(payload.Secret, secretValue) {
+		return fmt.Errorf("secret passed to the webhook does not match the incoming webhook secret set on repository CR in secret %s", hook.Secret.Name)
+	}
+	return nil
+}
+
 func applyIncomingParams(req *http.Request, payloadBody []byte, params []string) (apincoming.Payload, error) {
 	if req.Header.Get("Content-Type") != "application/json" {
 		return apincoming.Payload{}, fmt.Errorf("invalid content type, only application/json is accepted when posting a body")
@@ -109,6 +212,16 @@ func applyIncomingParams(req *http.Request, payloadBody []byte, params []string)
 	return payload, nil
 }
 
+// incomingParamNames returns the names of the params allowed by the incoming
+// webhook, listed or declared in its schema.
+func incomingParamNames(hook *v1alpha1.Incoming) []string {
+	names := slices.Clone(hook.Params)
+	for _, param := range hook.ParamsSchema {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
 // detectIncoming checks if the request is for an "incoming" webhook request.
 // If the request is for an "incoming" webhook request the request is parsed and matched to the expected
 // repository.
@@ -119,12 +232,23 @@ func (l *listener) detectIncoming(ctx context.Context, req *http.Request, payloa
 
 	l.logger.Infof("incoming request has been requested: %v", req.URL)
 	payload, err := parseIncomingPayload(req, payloadBody)
-	if payload.legacyMode {
+	if payload.legacyMode && payload.Secret != "" {
 		// Log this, even if the request is invalid
 		l.logger.Warnf("[SECURITY] Incoming webhook used legacy URL-based secret passing. This is insecure and will be deprecated. Please use POST body instead.")
 	}
+	// a payload from a third-party system can't be parsed, the repository is
+	// then given in the URL and the rest is mapped from the payload by the
+	// incoming webhook rules.
+	mapped := false
 	if err != nil {
-		return false, nil, err
+		if req.URL.Query().Get("repository") == "" {
+			return false, nil, err
+		}
+		mapped = true
+		payload = incomingPayload{
+			RepoName:  req.URL.Query().Get("repository"),
+			Namespace: req.URL.Query().Get("namespace"),
+		}
 	}
 
 	repo, err := matcher.GetRepoByName(ctx, l.run, payload.RepoName, payload.Namespace)
@@ -142,35 +266,32 @@ func (l *listener) detectIncoming(ctx context.Context, req *http.Request, payloa
 		return false, nil, fmt.Errorf("you need to have incoming webhooks rules in your repo spec, repo: %s", payload.RepoName)
 	}
 
-	hook := matcher.IncomingWebhookRule(payload.Branch, *repo.Spec.Incomings)
-	if hook == nil {
-		return false, nil, fmt.Errorf("branch '%s' has not matched any rules in repo incoming webhooks spec: %+v", payload.Branch, *repo.Spec.Incomings)
+	var hook *v1alpha1.Incoming
+	var mappedParams map[string]string
+	if mapped {
+		secretOf := func(hook *v1alpha1.Incoming) (string, error) { return l.incomingSecret(ctx, repo, hook) }
+		if hook, payload, mappedParams, err = matchIncomingMapping(req, payloadBody, payload, *repo.Spec.Incomings, secretOf); err != nil {
+			return false, nil, err
+		}
+	} else {
+		hook = matcher.IncomingWebhookRule(payload.Branch, *repo.Spec.Incomings)
+		if hook == nil {
+			return false, nil, fmt.Errorf("branch '%s' has not matched any rules in repo incoming webhooks spec: %+v", payload.Branch, *repo.Spec.Incomings)
+		}
+		secretValue, err := l.incomingSecret(ctx, repo, hook)
+		if err != nil {
+			return false, nil, err
+		}
+		if err := authenticateIncoming(req, payloadBody, payload, hook, secretValue); err != nil {
+			return false, nil, err
+		}
 	}
 
 	// log incoming request
 	l.logger.Infof("incoming request targeting pipelinerun %s on branch %s for repository %s has been accepted", payload.PipelineRun, payload.Branch, payload.RepoName)
 
-	secretOpts := ktypes.GetSecretOpt{
-		Namespace: repo.Namespace,
-		Name:      hook.Secret.Name,
-		Key:       hook.Secret.Key,
-	}
-
-	if secretOpts.Key == "" {
-		secretOpts.Key = defaultIncomingWebhookSecretKey
-	}
-
-	secretValue, err := l.kint.GetSecret(ctx, secretOpts)
-	if err != nil {
-		return false, nil, fmt.Errorf("error getting secret referenced in incoming-webhook: %w", err)
-	}
-	if secretValue == "" {
-		return false, nil, fmt.Errorf("secret referenced in incoming-webhook %s is empty or key %s is not existent", hook.Secret.Name, hook.Secret.Key)
-	}
-
-	// TODO: move to somewhere common to share between gitlab and here
-	if !compareSecret(payload.Secret, secretValue) {
-		return false, nil, fmt.Errorf("secret passed to the webhook does not match the incoming webhook secret set on repository CR in secret %s", hook.Secret.Name)
+	if err := validateIncomingType(payload, hook); err != nil {
+		return false, nil, err
 	}
 
 	if repo.Spec.GitProvider == nil || repo.Spec.GitProvider.Type == "" {
@@ -191,9 +312,28 @@ func (l *listener) detectIncoming(ctx context.Context, req *http.Request, payloa
 		}
 	}
 
-	// make sure accepted is json
-	if string(payloadBody) != "" {
-		if l.event.Event, err = applyIncomingParams(req, payloadBody, hook.Params); err != nil {
+	var params map[string]any
+	switch {
+	case mapped:
+		// expose the third-party payload as the body of the event
+		var body map[string]any
+		if err := json.Unmarshal(payloadBody, &body); err != nil {
+			return false, nil, fmt.Errorf("invalid JSON body for incoming webhook: %w", err)
+		}
+		l.event.Event = body
+		l.event.IncomingParams = mappedParams
+		params = mappedIncomingParams(hook.ParamsSchema, mappedParams)
+	case string(payloadBody) != "":
+		// make sure accepted is json
+		applied, err := applyIncomingParams(req, payloadBody, incomingParamNames(hook))
+		if err != nil {
+			return false, nil, err
+		}
+		l.event.Event = applied
+		params = applied.Params
+	}
+	if len(hook.ParamsSchema) > 0 {
+		if l.event.IncomingParams, err = validateIncomingParams(hook.ParamsSchema, params); err != nil {
 			return false, nil, err
 		}
 	}
@@ -205,6 +345,23 @@ func (l *listener) detectIncoming(ctx context.Context, req *http.Request, payloa
 	l.event.TargetPipelineRun = payload.PipelineRun
 	l.event.HeadBranch = payload.Branch
 	l.event.BaseBranch = payload.Branch
+	switch hook.Type {
+	case incomingTypePullRequest:
+		// the head of the pull request is fetched from the provider API, its
+		// base branch is then checked against the branch of the request.
+		l.event.TriggerTarget = triggertype.PullRequest
+		l.event.PullRequestNumber = payload.PullRequest
+		l.event.HeadBranch = ""
+		l.event.IncomingCommand = payload.Command
+		// a cancel command acts like a /cancel comment on the pull request
+		if payload.Command == incomingCommandCancel {
+			l.event.CancelPipelineRuns = true
+			l.event.TargetCancelPipelineRun = payload.PipelineRun
+			l.event.TargetPipelineRun = ""
+		}
+	case incomingTypeSHA:
+		l.event.SHA = payload.SHA
+	}
 	l.event.Request.Header = req.Header
 	l.event.Request.Payload = payloadBody
 	l.event.URL = repo.Spec.URL
@@ -213,6 +370,59 @@ func (l *listener) detectIncoming(ctx context.Context, req *http.Request, payloa
 	return true, repo, err
 }
 
+// validateIncomingType checks the payload has the fields required by the type
+// of the incoming webhook.
+func validateIncomingType(payload incomingPayload, hook *v1alpha1.Incoming) error {
+	if payload.Command != "" {
+		if hook.Type != incomingTypePullRequest {
+			return fmt.Errorf("command %s can only be passed to the incoming webhooks of type %s", payload.Command, incomingTypePullRequest)
+		}
+		if payload.Command != incomingCommandRetest && payload.Command != incomingCommandCancel {
+			return fmt.Errorf("unsupported incoming webhook command %s, it can be %s or %s", payload.Command, incomingCommandRetest, incomingCommandCancel)
+		}
+	}
+	switch hook.Type {
+	case "", incomingTypeWebhookURL:
+	case incomingTypePullRequest:
+		if payload.PullRequest <= 0 {
+			return errMissingSpecificFields([]string{"pull_request"})
+		}
+	case incomingTypeSHA:
+		if payload.SHA == "" {
+			return errMissingSpecificFields([]string{"sha"})
+		}
+		if !shaRegex.MatchString(payload.SHA) {
+			return fmt.Errorf("sha %q passed to the incoming webhook is not a commit SHA", payload.SHA)
+		}
+	default:
+		return fmt.Errorf("unsupported incoming webhook type %s", hook.Type)
+	}
+	return nil
+}
+
+// incomingSecret returns the value of the secret referenced by the incoming
+// webhook rule.
+func (l *listener) incomingSecret(ctx context.Context, repo *v1alpha1.Repository, hook *v1alpha1.Incoming) (string, error) {
+	secretOpts := ktypes.GetSecretOpt{
+		Namespace: repo.Namespace,
+		Name:      hook.Secret.Name,
+		Key:       hook.Secret.Key,
+	}
+
+	if secretOpts.Key == "" {
+		secretOpts.Key = defaultIncomingWebhookSecretKey
+	}
+
+	secretValue, err := l.kint.GetSecret(ctx, secretOpts)
+	if err != nil {
+		return "", fmt.Errorf("error getting secret referenced in incoming-webhook: %w", err)
+	}
+	if secretValue == "" {
+		return "", fmt.Errorf("secret referenced in incoming-webhook %s is empty or key %s is not existent", hook.Secret.Name, hook.Secret.Key)
+	}
+	return secretValue, nil
+}
+
 func (l *listener) processIncoming(targetRepo *v1alpha1.Repository) (provider.Interface, *zap.SugaredLogger, error) {
 	// can a git ssh URL be a Repo URL? I don't think this will even ever work
 	org, repo, err := formatting.GetRepoOwnerSplitted(targetRepo.Spec.URL)
@@ -0,0 +1,163 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cel"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+)
+
+var errIncomingSkipped = errors.New("incoming webhook request has been skipped by the mapping filters")
+
+// matchIncomingMapping finds the first incoming webhook rule whose mapping
+// accepts the third-party payload and targets the mapped branch. The request
+// is authenticated with the secret of the rule before its mapping is
+// evaluated, the body of an unauthenticated request is never read. It returns
+// the rule with the mapped payload and params.
+func matchIncomingMapping(req *http.Request, payloadBody []byte, payload incomingPayload, incomings []v1alpha1.Incoming, secretOf func(*v1alpha1.Incoming) (string, error)) (*v1alpha1.Incoming, incomingPayload, map[string]string, error) {
+	headers := map[string]string{}
+	for k, v := range req.Header {
+		headers[k] = v[0]
+	}
+
+	var body map[string]any
+	var lastErr error
+	hasMapping := false
+	for i := range incomings {
+		hook := &incomings[i]
+		if hook.Mapping == nil {
+			continue
+		}
+		hasMapping = true
+
+		if err := authenticateIncomingMapping(req, payloadBody, headers, hook, secretOf); err != nil {
+			// the request may be signed with the secret of a later rule
+			lastErr = err
+			continue
+		}
+		if body == nil {
+			if err := json.Unmarshal(payloadBody, &body); err != nil {
+				return nil, payload, nil, fmt.Errorf("invalid JSON body for incoming webhook: %w", err)
+			}
+		}
+
+		mapped, params, err := evaluateIncomingMapping(hook.Mapping, body, headers)
+		if errors.Is(err, errIncomingSkipped) {
+			continue
+		}
+		if err != nil {
+			// the payload may come from another system mapped by a later rule
+			lastErr = err
+			continue
+		}
+		if matcher.IncomingWebhookRule(mapped.Branch, []v1alpha1.Incoming{*hook}) == nil {
+			lastErr = fmt.Errorf("mapped branch '%s' has not matched the targets %v of the incoming webhook", mapped.Branch, hook.Targets)
+			continue
+		}
+		mapped.RepoName = payload.RepoName
+		mapped.Namespace = payload.Namespace
+		return hook, mapped, params, nil
+	}
+
+	switch {
+	case !hasMapping:
+		return nil, payload, nil, fmt.Errorf("the payload is not a Pipelines-as-Code incoming payload and no incoming webhook of repository %s declares a mapping: %w",
+			payload.RepoName, errMissingSpecificFields([]string{"branch", "pipelinerun"}))
+	case lastErr != nil:
+		return nil, payload, nil, lastErr
+	default:
+		return nil, payload, nil, errIncomingSkipped
+	}
+}
+
+// authenticateIncomingMapping authenticates the request with the secret of
+// the incoming webhook rule. The plain secret is read from the secret query
+// parameter, or from the headers by the secret expression of the mapping.
+func authenticateIncomingMapping(req *http.Request, payloadBody []byte, headers map[string]string, hook *v1alpha1.Incoming, secretOf func(*v1alpha1.Incoming) (string, error)) error {
+	secretValue, err := secretOf(hook)
+	if err != nil {
+		return err
+	}
+	payload := incomingPayload{Secret: req.URL.Query().Get("secret")}
+	if hook.Auth != nil && hook.Auth.Type == incomingAuthHMACSHA256 {
+		payload.Secret = ""
+	} else if hook.Mapping.Secret != "" {
+		if payload.Secret, err = incomingMappingValue(hook.Mapping.Secret, map[string]any{}, headers); err != nil {
+			return fmt.Errorf("cannot map incoming webhook secret: %w", err)
+		}
+	}
+	return authenticateIncoming(req, payloadBody, payload, hook, secretValue)
+}
+
+// evaluateIncomingMapping evaluates the CEL expressions of the mapping against
+// the payload and headers. It returns errIncomingSkipped when the filter
+// doesn't evaluate to true.
+func evaluateIncomingMapping(mapping *v1alpha1.IncomingMapping, body map[string]any, headers map[string]string) (incomingPayload, map[string]string, error) {
+	if mapping.Filter != "" {
+		val, err := cel.Value(mapping.Filter, body, headers, map[string]string{}, map[string]any{})
+		if err != nil {
+			return incomingPayload{}, nil, fmt.Errorf("cannot evaluate incoming webhook mapping filter: %w", err)
+		}
+		if matched, ok := val.Value().(bool); !ok || !matched {
+			return incomingPayload{}, nil, errIncomingSkipped
+		}
+	}
+
+	var err error
+	var pullRequest string
+	mapped := incomingPayload{}
+	for _, field := range []struct {
+		name  string
+		expr  string
+		value *string
+	}{
+		{"branch", mapping.Branch, &mapped.Branch},
+		{"pipelinerun", mapping.PipelineRun, &mapped.PipelineRun},
+		{"sha", mapping.SHA, &mapped.SHA},
+		{"pull_request", mapping.PullRequest, &pullRequest},
+	} {
+		if field.expr == "" {
+			continue
+		}
+		if *field.value, err = incomingMappingValue(field.expr, body, headers); err != nil {
+			return incomingPayload{}, nil, fmt.Errorf("cannot map incoming webhook %s: %w", field.name, err)
+		}
+	}
+	if mapped.Branch == "" || mapped.PipelineRun == "" {
+		return incomingPayload{}, nil, fmt.Errorf("incoming webhook mapping has returned an empty branch or pipelinerun")
+	}
+	if pullRequest != "" {
+		if mapped.PullRequest, err = strconv.Atoi(pullRequest); err != nil {
+			return incomingPayload{}, nil, fmt.Errorf("incoming webhook mapping has returned an invalid pull_request %q: %w", pullRequest, err)
+		}
+	}
+
+	params := map[string]string{}
+	for name, expr := range mapping.Params {
+		if params[name], err = incomingMappingValue(expr, body, headers); err != nil {
+			return incomingPayload{}, nil, fmt.Errorf("cannot map incoming webhook param %s: %w", name, err)
+		}
+	}
+	return mapped, params, nil
+}
+
+// incomingMappingValue evaluates a CEL expression of a mapping and returns its
+// result as a string.
+func incomingMappingValue(expr string, body map[string]any, headers map[string]string) (string, error) {
+	val, err := cel.Value(expr, body, headers, map[string]string{}, map[string]any{})
+	if err != nil {
+		return "", err
+	}
+	switch val.Type() {
+	case types.StringType, types.IntType, types.UintType, types.DoubleType, types.BoolType:
+		return fmt.Sprint(val.Value()), nil
+	default:
+		return "", fmt.Errorf("expression %#v has returned a %s instead of a string", expr, val.Type().TypeName())
+	}
+}
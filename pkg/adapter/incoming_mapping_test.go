@@ -0,0 +1,262 @@
+package adapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+const harborPayload = `{
+	"type": "PUSH_ARTIFACT",
+	"event_data": {
+		"resources": [{"tag": "v1.2.0", "resource_url": "harbor.example.com/library/app:v1.2.0"}],
+		"repository": {"name": "app", "namespace": "library"}
+	}
+}`
+
+func TestEvaluateIncomingMapping(t *testing.T) {
+	headers := map[string]string{"Authorization": "harbor-secret"}
+	tests := []struct {
+		name       string
+		mapping    v1alpha1.IncomingMapping
+		wantBranch string
+		wantPR     string
+		wantParams map[string]string
+		wantErr    string
+		wantSkip   bool
+	}{
+		{
+			name: "mapped",
+			mapping: v1alpha1.IncomingMapping{
+				Filter:      `body.type == "PUSH_ARTIFACT"`,
+				Branch:      `"main"`,
+				PipelineRun: `"deploy-" + body.event_data.repository.name`,
+				Secret:      `headers["Authorization"]`,
+				Params: map[string]string{
+					"image": `body.event_data.resources[0].resource_url`,
+					"count": `size(body.event_data.resources)`,
+				},
+			},
+			wantBranch: "main",
+			wantPR:     "deploy-app",
+			wantParams: map[string]string{"image": "harbor.example.com/library/app:v1.2.0", "count": "1"},
+		},
+		{
+			name: "filtered out",
+			mapping: v1alpha1.IncomingMapping{
+				Filter:      `body.type == "DELETE_ARTIFACT"`,
+				Branch:      `"main"`,
+				PipelineRun: `"deploy"`,
+			},
+			wantSkip: true,
+		},
+		{
+			name: "missing field",
+			mapping: v1alpha1.IncomingMapping{
+				Branch:      `body.ref`,
+				PipelineRun: `"deploy"`,
+			},
+			wantErr: "cannot map incoming webhook branch",
+		},
+		{
+			name: "not a string",
+			mapping: v1alpha1.IncomingMapping{
+				Branch:      `"main"`,
+				PipelineRun: `"deploy"`,
+				Params:      map[string]string{"resources": `body.event_data.resources`},
+			},
+			wantErr: "instead of a string",
+		},
+		{
+			name: "empty pipelinerun",
+			mapping: v1alpha1.IncomingMapping{
+				Branch:      `"main"`,
+				PipelineRun: `""`,
+			},
+			wantErr: "empty branch or pipelinerun",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]any{}
+			assert.NilError(t, json.Unmarshal([]byte(harborPayload), &body))
+			mapped, params, err := evaluateIncomingMapping(&tt.mapping, body, headers)
+			if tt.wantSkip {
+				assert.ErrorIs(t, err, errIncomingSkipped)
+				return
+			}
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, mapped.Branch, tt.wantBranch)
+			assert.Equal(t, mapped.PipelineRun, tt.wantPR)
+			assert.DeepEqual(t, params, tt.wantParams)
+		})
+	}
+}
+
+func TestDetectIncomingMapped(t *testing.T) {
+	mapping := &v1alpha1.IncomingMapping{
+		Filter:      `body.type == "PUSH_ARTIFACT"`,
+		Branch:      `"main"`,
+		PipelineRun: `"deploy"`,
+		Secret:      `headers["Authorization"]`,
+		Params:      map[string]string{"image": `body.event_data.resources[0].resource_url`},
+	}
+	repositories := map[string]*v1alpha1.Repository{
+		"test-good": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-good", Namespace: "ns"},
+			Spec: v1alpha1.RepositorySpec{
+				URL: "https://matched/by/incoming",
+				Incomings: &[]v1alpha1.Incoming{
+					{
+						Targets: []string{"main"},
+						Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
+						Secret:  v1alpha1.Secret{Name: "plain-secret"},
+						Params:  []string{"image"},
+					},
+					{
+						Targets: []string{"main"},
+						Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
+						Secret:  v1alpha1.Secret{Name: "harbor-secret"},
+						Mapping: mapping,
+					},
+				},
+				GitProvider: &v1alpha1.GitProvider{Type: "github"},
+			},
+		},
+		"no-mapping": {
+			ObjectMeta: metav1.ObjectMeta{Name: "no-mapping", Namespace: "ns"},
+			Spec: v1alpha1.RepositorySpec{
+				URL: "https://matched/by/incoming",
+				Incomings: &[]v1alpha1.Incoming{{
+					Targets: []string{"main"},
+					Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
+					Secret:  v1alpha1.Secret{Name: "plain-secret"},
+				}},
+				GitProvider: &v1alpha1.GitProvider{Type: "github"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		repository    string
+		url           string
+		body          string
+		authorization string
+		want          bool
+		wantErr       string
+		wantSkip      bool
+	}{
+		{
+			name:          "mapped payload",
+			repository:    "test-good",
+			url:           "/incoming?repository=test-good&namespace=ns",
+			body:          harborPayload,
+			authorization: "verysecrete",
+			want:          true,
+		},
+		{
+			name:          "bad secret",
+			repository:    "test-good",
+			url:           "/incoming?repository=test-good&namespace=ns",
+			body:          harborPayload,
+			authorization: "wrong",
+			wantErr:       "does not match the incoming webhook secret",
+		},
+		{
+			name:          "bad secret is rejected before the filter",
+			repository:    "test-good",
+			url:           "/incoming?repository=test-good&namespace=ns",
+			body:          `{"type": "DELETE_ARTIFACT"}`,
+			authorization: "wrong",
+			wantErr:       "does not match the incoming webhook secret",
+		},
+		{
+			name:          "filtered out",
+			repository:    "test-good",
+			url:           "/incoming?repository=test-good&namespace=ns",
+			body:          `{"type": "DELETE_ARTIFACT"}`,
+			authorization: "verysecrete",
+			wantSkip:      true,
+		},
+		{
+			name:       "no mapping on the repository",
+			repository: "no-mapping",
+			url:        "/incoming?repository=no-mapping&namespace=ns",
+			body:       harborPayload,
+			wantErr:    "no incoming webhook of repository no-mapping declares a mapping",
+		},
+		{
+			name:       "no repository in the url",
+			repository: "test-good",
+			url:        "/incoming",
+			body:       harborPayload,
+			wantErr:    "missing required fields",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			ctx = info.StoreCurrentControllerName(ctx, "default")
+			ctx = info.StoreNS(ctx, "pipelinesascode")
+			cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{repositories[tt.repository]},
+			})
+			client := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: cs.PipelineAsCode,
+					Kube:           cs.Kube,
+				},
+				Info: info.Info{
+					Controller: &info.ControllerInfo{Secret: info.DefaultPipelinesAscodeSecretName},
+				},
+			}
+			req := httptest.NewRequestWithContext(ctx, http.MethodPost, "http://localhost"+tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", tt.authorization)
+			l := &listener{
+				run:    client,
+				logger: zap.NewNop().Sugar(),
+				kint: &kubernetestint.KinterfaceTest{GetSecretResult: map[string]string{
+					"harbor-secret": "verysecrete",
+					"plain-secret":  "othersecret",
+				}},
+				event: info.NewEvent(),
+			}
+			got, _, err := l.detectIncoming(ctx, req, []byte(tt.body))
+			if tt.wantSkip {
+				assert.ErrorIs(t, err, errIncomingSkipped)
+				return
+			}
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+			assert.Equal(t, l.event.TargetPipelineRun, "deploy")
+			assert.Equal(t, l.event.HeadBranch, "main")
+			assert.DeepEqual(t, l.event.IncomingParams, map[string]string{"image": "harbor.example.com/library/app:v1.2.0"})
+			body, ok := l.event.Event.(map[string]any)
+			assert.Assert(t, ok)
+			assert.Equal(t, body["type"], "PUSH_ARTIFACT")
+		})
+	}
+}
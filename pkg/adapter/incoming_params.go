@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+const (
+	incomingParamString = "string"
+	incomingParamInt    = "int"
+	incomingParamBool   = "bool"
+	incomingParamEnum   = "enum"
+)
+
+var errInvalidIncomingParams = errors.New("invalid incoming webhook params")
+
+// validateIncomingParams checks the params of the request against the schema
+// of the incoming webhook and returns them as strings, with the defaults of
+// the params not passed in the request. All the violations are reported at
+// once.
+func validateIncomingParams(schema []v1alpha1.IncomingParam, params map[string]any) (map[string]string, error) {
+	ret := map[string]string{}
+	violations := []string{}
+	for k, v := range params {
+		if vs, ok := v.(string); ok {
+			ret[k] = vs
+		}
+	}
+	for _, param := range schema {
+		value, ok := params[param.Name]
+		if !ok || value == nil {
+			switch {
+			case param.Required:
+				violations = append(violations, fmt.Sprintf("param %s is required", param.Name))
+			case param.Default != "":
+				ret[param.Name] = param.Default
+			}
+			continue
+		}
+		str, err := incomingParamValue(param, value)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("param %s %s", param.Name, err.Error()))
+			continue
+		}
+		ret[param.Name] = str
+	}
+	if len(violations) > 0 {
+		slices.Sort(violations)
+		return nil, fmt.Errorf("%w: %s", errInvalidIncomingParams, strings.Join(violations, ", "))
+	}
+	return ret, nil
+}
+
+// incomingParamValue checks the value has the type of the param and matches
+// its pattern, and returns it as a string.
+func incomingParamValue(param v1alpha1.IncomingParam, value any) (string, error) {
+	var str string
+	switch param.Type {
+	case "", incomingParamString, incomingParamEnum:
+		vs, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("must be a string")
+		}
+		if param.Type == incomingParamEnum && !slices.Contains(param.Enum, vs) {
+			return "", fmt.Errorf("must be one of %s", strings.Join(param.Enum, ", "))
+		}
+		str = vs
+	case incomingParamInt:
+		vf, ok := value.(float64)
+		if !ok || vf != math.Trunc(vf) {
+			return "", fmt.Errorf("must be an integer")
+		}
+		str = strconv.FormatInt(int64(vf), 10)
+	case incomingParamBool:
+		vb, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("must be a boolean")
+		}
+		str = strconv.FormatBool(vb)
+	default:
+		return "", fmt.Errorf("has the unsupported type %s", param.Type)
+	}
+
+	if param.Pattern != "" {
+		re, err := regexp.Compile(param.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("has an invalid pattern %s: %w", param.Pattern, err)
+		}
+		if !re.MatchString(str) {
+			return "", fmt.Errorf("must match the pattern %s", param.Pattern)
+		}
+	}
+	return str, nil
+}
+
+// mappedIncomingParams converts the params mapped from a third-party payload
+// to the types of the schema, so they are validated like the params passed
+// in the Pipelines-as-Code payload.
+func mappedIncomingParams(schema []v1alpha1.IncomingParam, mapped map[string]string) map[string]any {
+	params := map[string]any{}
+	for k, v := range mapped {
+		params[k] = v
+	}
+	for _, param := range schema {
+		value, ok := mapped[param.Name]
+		if !ok {
+			continue
+		}
+		switch param.Type {
+		case incomingParamInt:
+			if vi, err := strconv.ParseInt(value, 10, 64); err == nil {
+				params[param.Name] = float64(vi)
+			}
+		case incomingParamBool:
+			if vb, err := strconv.ParseBool(value); err == nil {
+				params[param.Name] = vb
+			}
+		}
+	}
+	return params
+}
@@ -0,0 +1,153 @@
+package adapter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestValidateIncomingParams(t *testing.T) {
+	schema := []v1alpha1.IncomingParam{
+		{Name: "env", Type: "enum", Enum: []string{"staging", "production"}, Required: true},
+		{Name: "replicas", Type: "int", Default: "1"},
+		{Name: "dry_run", Type: "bool"},
+		{Name: "version", Pattern: `^v[0-9]+\.[0-9]+\.[0-9]+$`},
+	}
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			params: map[string]any{"env": "staging", "replicas": float64(3), "dry_run": true, "version": "v1.2.3", "other": "value"},
+			want:   map[string]string{"env": "staging", "replicas": "3", "dry_run": "true", "version": "v1.2.3", "other": "value"},
+		},
+		{
+			name:   "defaults",
+			params: map[string]any{"env": "production"},
+			want:   map[string]string{"env": "production", "replicas": "1"},
+		},
+		{
+			name:    "required",
+			params:  map[string]any{},
+			wantErr: "invalid incoming webhook params: param env is required",
+		},
+		{
+			name:    "all violations",
+			params:  map[string]any{"env": "dev", "replicas": 1.5, "dry_run": "yes", "version": "1.2.3; rm -rf /"},
+			wantErr: `invalid incoming webhook params: param dry_run must be a boolean, param env must be one of staging, production, param replicas must be an integer, param version must match the pattern ^v[0-9]+\.[0-9]+\.[0-9]+$`,
+		},
+		{
+			name:    "string expected",
+			params:  map[string]any{"env": "staging", "version": float64(1)},
+			wantErr: "param version must be a string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateIncomingParams(schema, tt.params)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Assert(t, errors.Is(err, errInvalidIncomingParams))
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestMappedIncomingParams(t *testing.T) {
+	schema := []v1alpha1.IncomingParam{
+		{Name: "replicas", Type: "int"},
+		{Name: "dry_run", Type: "bool"},
+	}
+	got, err := validateIncomingParams(schema, mappedIncomingParams(schema, map[string]string{"replicas": "3", "dry_run": "false", "tag": "v1"}))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, map[string]string{"replicas": "3", "dry_run": "false", "tag": "v1"})
+
+	_, err = validateIncomingParams(schema, mappedIncomingParams(schema, map[string]string{"replicas": "three"}))
+	assert.ErrorContains(t, err, "param replicas must be an integer")
+}
+
+func TestDetectIncomingParamsSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "valid params",
+			body: `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","params":{"env":"staging","replicas":2}}`,
+			want: map[string]string{"env": "staging", "replicas": "2"},
+		},
+		{
+			name:    "invalid params",
+			body:    `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","params":{"env":"dev"}}`,
+			wantErr: "param env must be one of staging, production",
+		},
+		{
+			name:    "unknown param",
+			body:    `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","params":{"env":"staging","image":"evil"}}`,
+			wantErr: "param image is not allowed in incoming webhook CR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-good", Namespace: "ns"},
+						Spec: v1alpha1.RepositorySpec{
+							URL: "https://matched/by/incoming",
+							Incomings: &[]v1alpha1.Incoming{{
+								Targets: []string{"main"},
+								Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
+								Secret:  v1alpha1.Secret{Name: "good-secret"},
+								ParamsSchema: []v1alpha1.IncomingParam{
+									{Name: "env", Type: "enum", Enum: []string{"staging", "production"}},
+									{Name: "replicas", Type: "int"},
+								},
+							}},
+							GitProvider: &v1alpha1.GitProvider{Type: "github"},
+						},
+					},
+				},
+			})
+			l := &listener{
+				run: &params.Run{
+					Clients: clients.Clients{PipelineAsCode: cs.PipelineAsCode, Kube: cs.Kube},
+				},
+				logger: zap.NewNop().Sugar(),
+				kint:   &kubernetestint.KinterfaceTest{GetSecretResult: map[string]string{"good-secret": "verysecrete"}},
+				event:  info.NewEvent(),
+			}
+			req := httptest.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/incoming", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			_, _, err := l.detectIncoming(ctx, req, []byte(tt.body))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, l.event.IncomingParams, tt.want)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package adapter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	incomingStatusPath = "/incoming/status/"
+
+	// incomingReplyTimeout is how long the reply to an incoming webhook
+	// request waits for its PipelineRuns, below the 30s timeout of the
+	// OpenShift routes.
+	incomingReplyTimeout = 20 * time.Second
+
+	incomingStatePending   = "pending"
+	incomingStateQueued    = "queued"
+	incomingStateRunning   = "running"
+	incomingStateSucceeded = "succeeded"
+	incomingStateFailed    = "failed"
+	incomingStateNoMatch   = "no-match"
+
+	// the reasons of the Repository events recording the outcome of the
+	// requests that have not created any PipelineRun.
+	incomingReasonFailed  = "IncomingWebhookFailed"
+	incomingReasonNoMatch = "IncomingWebhookNoMatch"
+)
+
+var incomingIDRegex = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// incomingResponse is the response to an accepted incoming webhook request,
+// its PipelineRuns can be followed on the status URL.
+type incomingResponse struct {
+	Response
+	incomingStatus
+	StatusURL string `json:"status_url"`
+}
+
+// incomingStatus is the response of the incoming webhook status endpoint.
+type incomingStatus struct {
+	ID           string                   `json:"id"`
+	Repository   string                   `json:"repository"`
+	Namespace    string                   `json:"namespace"`
+	State        string                   `json:"state"`
+	Reason       string                   `json:"reason,omitempty"`
+	PipelineRuns []incomingPipelineRunRef `json:"pipelineruns"`
+}
+
+type incomingPipelineRunRef struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	ConsoleURL string `json:"console_url"`
+	State      string `json:"state"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// newIncomingID returns a random identifier for the PipelineRuns created by
+// an incoming webhook request, usable as a label value.
+func newIncomingID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate incoming webhook request id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// incomingStatusURL returns the path of the status endpoint for the request.
+func incomingStatusURL(id string, repo *v1alpha1.Repository) string {
+	query := url.Values{"repository": {repo.GetName()}, "namespace": {repo.GetNamespace()}}
+	return incomingStatusPath + id + "?" + query.Encode()
+}
+
+// handleIncomingStatus reports the state of the PipelineRuns created by an
+// incoming webhook request. The request is authenticated like the incoming
+// webhooks of the repository: the secret is passed as a bearer token, or the
+// request is signed with an empty body for the hmac-sha256 webhooks.
+func (l *listener) handleIncomingStatus(ctx context.Context) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			l.writeResponse(response, http.StatusMethodNotAllowed, "only GET is supported on the incoming webhook status endpoint")
+			return
+		}
+		id := strings.TrimPrefix(request.URL.Path, incomingStatusPath)
+		if !incomingIDRegex.MatchString(id) {
+			l.writeResponse(response, http.StatusBadRequest, fmt.Sprintf("invalid incoming webhook request id %q", id))
+			return
+		}
+		repoName, namespace := request.URL.Query().Get("repository"), request.URL.Query().Get("namespace")
+		if repoName == "" {
+			l.writeResponse(response, http.StatusBadRequest, errMissingSpecificFields([]string{"repository"}).Error())
+			return
+		}
+
+		repo, err := matcher.GetRepoByName(ctx, l.run, repoName, namespace)
+		if err != nil {
+			if errors.Is(err, matcher.ErrRepositoryNameConflict) {
+				l.writeResponse(response, http.StatusBadRequest, errMissingSpecificFields([]string{"namespace"}).Error())
+				return
+			}
+			l.logger.Errorf("error getting repo for incoming webhook status: %v", err)
+			l.writeResponse(response, http.StatusInternalServerError, "cannot get repository")
+			return
+		}
+		if repo == nil || repo.Spec.Incomings == nil {
+			// don't tell apart a missing repository and a failed authentication
+			l.writeResponse(response, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if err := l.authenticateIncomingStatus(ctx, request, repo); err != nil {
+			l.logger.Warnf("incoming webhook status request for %s on repository %s/%s has been refused: %v", id, repo.Namespace, repo.Name, err)
+			l.writeResponse(response, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		status, err := l.incomingStatus(ctx, id, repo)
+		if err != nil {
+			l.logger.Errorf("error getting incoming webhook status: %v", err)
+			l.writeResponse(response, http.StatusInternalServerError, "cannot get the PipelineRuns of the incoming webhook request")
+			return
+		}
+		l.writeJSON(response, http.StatusOK, status)
+	}
+}
+
+// authenticateIncomingStatus accepts the request if it authenticates with
+// any of the incoming webhooks of the repository.
+func (l *listener) authenticateIncomingStatus(ctx context.Context, request *http.Request, repo *v1alpha1.Repository) error {
+	payload := incomingPayload{Secret: strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")}
+	var lastErr error
+	for i := range *repo.Spec.Incomings {
+		hook := &(*repo.Spec.Incomings)[i]
+		secretValue, err := l.incomingSecret(ctx, repo, hook)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if lastErr = authenticateIncoming(request, []byte{}, payload, hook, secretValue); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// incomingStatus returns the state of the PipelineRuns labelled with the id
+// of the incoming webhook request.
+func (l *listener) incomingStatus(ctx context.Context, id string, repo *v1alpha1.Repository) (*incomingStatus, error) {
+	prs, err := l.incomingPipelineRuns(ctx, id, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &incomingStatus{
+		ID:           id,
+		Repository:   repo.GetName(),
+		Namespace:    repo.GetNamespace(),
+		PipelineRuns: []incomingPipelineRunRef{},
+	}
+	states := map[string]bool{}
+	for i := range prs.Items {
+		pr := &prs.Items[i]
+		state, reason := incomingPipelineRunState(pr)
+		states[state] = true
+		status.PipelineRuns = append(status.PipelineRuns, incomingPipelineRunRef{
+			Name:       pr.GetName(),
+			Namespace:  pr.GetNamespace(),
+			ConsoleURL: l.run.Clients.ConsoleUI().DetailURL(pr),
+			State:      state,
+			Reason:     reason,
+		})
+	}
+
+	switch {
+	case len(status.PipelineRuns) == 0:
+		return status, l.incomingOutcome(ctx, status, repo)
+	case states[incomingStateRunning] || states[incomingStateQueued]:
+		status.State = incomingStateRunning
+	case states[incomingStateFailed]:
+		status.State = incomingStateFailed
+	default:
+		status.State = incomingStateSucceeded
+	}
+	return status, nil
+}
+
+// incomingPipelineRuns lists the PipelineRuns labelled with the id of the
+// incoming webhook request.
+func (l *listener) incomingPipelineRuns(ctx context.Context, id string, repo *v1alpha1.Repository) (*tektonv1.PipelineRunList, error) {
+	labelSelector := fmt.Sprintf("%s=%s,%s=%s", keys.IncomingID, id, keys.Repository, repo.GetName())
+	return l.run.Clients.Tekton.TektonV1().PipelineRuns(repo.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+}
+
+// incomingOutcome sets the state of a request without PipelineRuns from the
+// Repository event recording its outcome, it is pending until it has been
+// processed.
+func (l *listener) incomingOutcome(ctx context.Context, status *incomingStatus, repo *v1alpha1.Repository) error {
+	status.State = incomingStatePending
+	labelSelector := fmt.Sprintf("%s=%s,%s=%s", keys.IncomingID, status.ID, keys.Repository, formatting.CleanValueKubernetes(repo.GetName()))
+	evs, err := l.run.Clients.Kube.CoreV1().Events(repo.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs.Items {
+		switch ev.Reason {
+		case incomingReasonFailed:
+			status.State, status.Reason = incomingStateFailed, ev.Message
+		case incomingReasonNoMatch:
+			status.State, status.Reason = incomingStateNoMatch, ev.Message
+		}
+	}
+	return nil
+}
+
+// acceptedIncoming returns the response to an accepted incoming webhook
+// request, its PipelineRuns are created asynchronously and can be followed on
+// the status URL.
+func acceptedIncoming(id string, repo *v1alpha1.Repository) incomingResponse {
+	return incomingResponse{
+		Response: Response{Status: http.StatusAccepted, Message: "accepted"},
+		incomingStatus: incomingStatus{
+			ID:           id,
+			Repository:   repo.GetName(),
+			Namespace:    repo.GetNamespace(),
+			State:        incomingStatePending,
+			PipelineRuns: []incomingPipelineRunRef{},
+		},
+		StatusURL: incomingStatusURL(id, repo),
+	}
+}
+
+// processedIncoming returns the response to a processed incoming webhook
+// request, with the PipelineRuns it has created.
+func (l *listener) processedIncoming(ctx context.Context, id string, repo *v1alpha1.Repository) incomingResponse {
+	response := acceptedIncoming(id, repo)
+	status, err := l.incomingStatus(ctx, id, repo)
+	if err != nil {
+		l.logger.Errorf("error getting incoming webhook status: %v", err)
+		return response
+	}
+	response.incomingStatus = *status
+	return response
+}
+
+// recordIncomingOutcome records the outcome of a processed incoming webhook
+// request which has not created any PipelineRun as an event on the
+// Repository, for the status endpoint.
+func (l *listener) recordIncomingOutcome(ctx context.Context, id string, repo *v1alpha1.Repository, processErr error) {
+	prs, err := l.incomingPipelineRuns(ctx, id, repo)
+	if err != nil {
+		l.logger.Errorf("error getting the PipelineRuns of the incoming webhook request: %v", err)
+		return
+	}
+	if len(prs.Items) > 0 {
+		return
+	}
+	reason, message := incomingReasonNoMatch, "no PipelineRun matched the incoming webhook request"
+	if processErr != nil {
+		reason, message = incomingReasonFailed, fmt.Sprintf("the incoming webhook request has failed: %v", processErr)
+	}
+	events.NewEventEmitter(l.run.Clients.Kube, l.logger).EmitLabelledMessage(repo, zap.WarnLevel, reason, message,
+		map[string]string{keys.IncomingID: id})
+}
+
+// incomingPipelineRunState returns the state of the PipelineRun and the
+// reason of its Succeeded condition.
+func incomingPipelineRunState(pr *tektonv1.PipelineRun) (string, string) {
+	condition := pr.Status.GetCondition(apis.ConditionSucceeded)
+	reason := ""
+	if condition != nil {
+		reason = condition.Reason
+	}
+	switch {
+	case condition != nil && condition.Status == corev1.ConditionTrue:
+		return incomingStateSucceeded, reason
+	case condition != nil && condition.Status == corev1.ConditionFalse:
+		return incomingStateFailed, reason
+	case pr.GetLabels()[keys.State] == kubeinteraction.StateQueued:
+		return incomingStateQueued, reason
+	default:
+		return incomingStateRunning, reason
+	}
+}
+
+func (l listener) writeJSON(response http.ResponseWriter, statusCode int, body any) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(statusCode)
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		l.logger.Errorf("failed to write back sink response: %v", err)
+	}
+}
@@ -0,0 +1,362 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+const incomingTestID = "0123456789abcdef"
+
+func incomingTestPipelineRun(name, state string, condition *apis.Condition) *tektonv1.PipelineRun {
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels: map[string]string{
+				keys.IncomingID: incomingTestID,
+				keys.Repository: "repo",
+				keys.State:      state,
+			},
+		},
+	}
+	if condition != nil {
+		pr.Status.Status = duckv1.Status{Conditions: duckv1.Conditions{*condition}}
+	}
+	return pr
+}
+
+func TestHandleIncomingStatus(t *testing.T) {
+	succeeded := &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}
+	failed := &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"}
+	tests := []struct {
+		name         string
+		method       string
+		id           string
+		query        string
+		auth         *v1alpha1.IncomingAuth
+		token        string
+		sign         bool
+		pipelineRuns []*tektonv1.PipelineRun
+		outcome      string
+		wantCode     int
+		wantState    string
+		wantReason   string
+		wantRuns     map[string]string
+	}{
+		{
+			name:      "no pipelinerun created yet",
+			id:        incomingTestID,
+			query:     "?repository=repo",
+			token:     "Bearer verysecrete",
+			wantCode:  http.StatusOK,
+			wantState: incomingStatePending,
+			wantRuns:  map[string]string{},
+		},
+		{
+			name:       "no pipelinerun matched",
+			id:         incomingTestID,
+			query:      "?repository=repo",
+			token:      "Bearer verysecrete",
+			outcome:    incomingReasonNoMatch,
+			wantCode:   http.StatusOK,
+			wantState:  incomingStateNoMatch,
+			wantReason: "outcome of the request",
+			wantRuns:   map[string]string{},
+		},
+		{
+			name:       "request failed before creating a pipelinerun",
+			id:         incomingTestID,
+			query:      "?repository=repo",
+			token:      "Bearer verysecrete",
+			outcome:    incomingReasonFailed,
+			wantCode:   http.StatusOK,
+			wantState:  incomingStateFailed,
+			wantReason: "outcome of the request",
+			wantRuns:   map[string]string{},
+		},
+		{
+			name:  "running",
+			id:    incomingTestID,
+			query: "?repository=repo&namespace=ns",
+			token: "Bearer verysecrete",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				incomingTestPipelineRun("deploy-1", kubeinteraction.StateStarted, nil),
+				incomingTestPipelineRun("deploy-2", kubeinteraction.StateCompleted, succeeded),
+			},
+			wantCode:  http.StatusOK,
+			wantState: incomingStateRunning,
+			wantRuns:  map[string]string{"deploy-1": incomingStateRunning, "deploy-2": incomingStateSucceeded},
+		},
+		{
+			name:  "failed",
+			id:    incomingTestID,
+			query: "?repository=repo",
+			token: "Bearer verysecrete",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				incomingTestPipelineRun("deploy-1", kubeinteraction.StateFailed, failed),
+				incomingTestPipelineRun("deploy-2", kubeinteraction.StateCompleted, succeeded),
+			},
+			wantCode:  http.StatusOK,
+			wantState: incomingStateFailed,
+			wantRuns:  map[string]string{"deploy-1": incomingStateFailed, "deploy-2": incomingStateSucceeded},
+		},
+		{
+			name:  "queued",
+			id:    incomingTestID,
+			query: "?repository=repo",
+			token: "Bearer verysecrete",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				incomingTestPipelineRun("deploy-1", kubeinteraction.StateQueued, nil),
+			},
+			wantCode:  http.StatusOK,
+			wantState: incomingStateRunning,
+			wantRuns:  map[string]string{"deploy-1": incomingStateQueued},
+		},
+		{
+			name:      "signed request",
+			id:        incomingTestID,
+			query:     "?repository=repo",
+			auth:      &v1alpha1.IncomingAuth{Type: incomingAuthHMACSHA256},
+			sign:      true,
+			wantCode:  http.StatusOK,
+			wantState: incomingStatePending,
+			wantRuns:  map[string]string{},
+		},
+		{
+			name:     "bad secret",
+			id:       incomingTestID,
+			query:    "?repository=repo",
+			token:    "Bearer othersecret",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "no secret",
+			id:       incomingTestID,
+			query:    "?repository=repo",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "unknown repository",
+			id:       incomingTestID,
+			query:    "?repository=repo&namespace=other",
+			token:    "Bearer verysecrete",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "missing repository",
+			id:       incomingTestID,
+			token:    "Bearer verysecrete",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid id",
+			id:       "not-an-id",
+			query:    "?repository=repo",
+			token:    "Bearer verysecrete",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "only get",
+			method:   http.MethodPost,
+			id:       incomingTestID,
+			query:    "?repository=repo",
+			token:    "Bearer verysecrete",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.auth == nil {
+				tt.auth = &v1alpha1.IncomingAuth{Type: incomingAuthSecret}
+			}
+			ctx, _ := rtesting.SetupFakeContext(t)
+			cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+						Spec: v1alpha1.RepositorySpec{
+							URL: "https://matched/by/incoming",
+							Incomings: &[]v1alpha1.Incoming{{
+								Targets: []string{"main"},
+								Secret:  v1alpha1.Secret{Name: "good-secret"},
+								Auth:    tt.auth,
+							}},
+						},
+					},
+				},
+				PipelineRuns: tt.pipelineRuns,
+			})
+			run := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: cs.PipelineAsCode,
+					Tekton:         cs.Pipeline,
+					Kube:           cs.Kube,
+				},
+			}
+			run.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+			l := &listener{
+				run:    run,
+				logger: zap.NewNop().Sugar(),
+				kint:   &kubernetestint.KinterfaceTest{GetSecretResult: map[string]string{"good-secret": "verysecrete"}},
+			}
+			if tt.outcome != "" {
+				repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+				events.NewEventEmitter(cs.Kube, nil).EmitLabelledMessage(repo, zap.WarnLevel, tt.outcome, "outcome of the request",
+					map[string]string{keys.IncomingID: tt.id})
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequestWithContext(ctx, method, "http://localhost"+incomingStatusPath+tt.id+tt.query, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			if tt.sign {
+				timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+				req.Header.Set("X-PaC-Timestamp", timestamp)
+				req.Header.Set("X-PaC-Signature-256", signIncoming("verysecrete", timestamp, []byte{}))
+			}
+			rec := httptest.NewRecorder()
+			l.handleIncomingStatus(ctx)(rec, req)
+			assert.Equal(t, rec.Code, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			status := incomingStatus{}
+			assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+			assert.Equal(t, status.ID, tt.id)
+			assert.Equal(t, status.Namespace, "ns")
+			assert.Equal(t, status.State, tt.wantState)
+			assert.Equal(t, status.Reason, tt.wantReason)
+			runs := map[string]string{}
+			for _, pr := range status.PipelineRuns {
+				runs[pr.Name] = pr.State
+				assert.Equal(t, pr.Namespace, "ns")
+				assert.Assert(t, pr.ConsoleURL != "")
+			}
+			assert.DeepEqual(t, runs, tt.wantRuns)
+		})
+	}
+}
+
+func TestIncomingStatusURL(t *testing.T) {
+	id, err := newIncomingID()
+	assert.NilError(t, err)
+	assert.Assert(t, incomingIDRegex.MatchString(id))
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	assert.Equal(t, incomingStatusURL(id, repo), "/incoming/status/"+id+"?namespace=ns&repository=repo")
+}
+
+func TestAcceptedIncoming(t *testing.T) {
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	got := acceptedIncoming(incomingTestID, repo)
+	assert.Equal(t, got.Status, http.StatusAccepted)
+	assert.Equal(t, got.ID, incomingTestID)
+	assert.Equal(t, got.State, incomingStatePending)
+	assert.Equal(t, got.StatusURL, incomingStatusURL(incomingTestID, repo))
+
+	b, err := json.Marshal(got)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(b), `"message":"accepted"`), string(b))
+	assert.Assert(t, strings.Contains(string(b), `"pipelineruns":[]`), string(b))
+}
+
+func TestProcessedIncoming(t *testing.T) {
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	ctx, _ := rtesting.SetupFakeContext(t)
+	cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{
+		incomingTestPipelineRun("deploy-1", kubeinteraction.StateStarted, nil),
+	}})
+	run := &params.Run{Clients: clients.Clients{Tekton: cs.Pipeline, Kube: cs.Kube}}
+	run.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+	l := &listener{run: run, logger: zap.NewNop().Sugar()}
+
+	got := l.processedIncoming(ctx, incomingTestID, repo)
+	assert.Equal(t, got.Status, http.StatusAccepted)
+	assert.Equal(t, got.ID, incomingTestID)
+	assert.Equal(t, got.State, incomingStateRunning)
+	assert.Equal(t, got.StatusURL, incomingStatusURL(incomingTestID, repo))
+	assert.Equal(t, len(got.PipelineRuns), 1)
+	assert.Equal(t, got.PipelineRuns[0].Name, "deploy-1")
+	assert.Equal(t, got.PipelineRuns[0].Namespace, "ns")
+	assert.Assert(t, got.PipelineRuns[0].ConsoleURL != "")
+}
+
+func TestRecordIncomingOutcome(t *testing.T) {
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	tests := []struct {
+		name         string
+		pipelineRuns []*tektonv1.PipelineRun
+		processErr   error
+		wantState    string
+		wantReason   string
+	}{
+		{
+			name: "pipelineruns created",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				incomingTestPipelineRun("deploy-1", kubeinteraction.StateStarted, nil),
+			},
+			wantState: incomingStateRunning,
+		},
+		{
+			name:       "no pipelinerun matched",
+			wantState:  incomingStateNoMatch,
+			wantReason: "no PipelineRun matched the incoming webhook request",
+		},
+		{
+			name:       "failed",
+			processErr: fmt.Errorf("cannot get the PipelineRuns of the repository"),
+			wantState:  incomingStateFailed,
+			wantReason: "the incoming webhook request has failed: cannot get the PipelineRuns of the repository",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: tt.pipelineRuns})
+			run := &params.Run{Clients: clients.Clients{Tekton: cs.Pipeline, Kube: cs.Kube}}
+			run.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+			l := &listener{run: run, logger: zap.NewNop().Sugar()}
+
+			// the request is pending until it has been processed
+			status, err := l.incomingStatus(ctx, incomingTestID, repo)
+			assert.NilError(t, err)
+			if len(tt.pipelineRuns) == 0 {
+				assert.Equal(t, status.State, incomingStatePending)
+			}
+
+			l.recordIncomingOutcome(ctx, incomingTestID, repo, tt.processErr)
+			status, err = l.incomingStatus(ctx, incomingTestID, repo)
+			assert.NilError(t, err)
+			assert.Equal(t, status.State, tt.wantState)
+			assert.Equal(t, status.Reason, tt.wantReason)
+		})
+	}
+}
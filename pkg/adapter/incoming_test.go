@@ -2,13 +2,18 @@ package adapter
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
 
@@ -25,6 +30,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketdatacenter"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea"
@@ -132,6 +138,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -169,6 +176,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -189,6 +197,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -229,6 +238,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -270,6 +280,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -308,6 +319,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "incoming-secret",
 											// Key is not specified, should default to "secret"
@@ -435,6 +447,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -455,6 +468,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -489,6 +503,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"notmain"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -550,6 +565,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "secret",
 										},
@@ -593,6 +609,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "secret",
 										},
@@ -628,6 +645,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "secret",
 										},
@@ -662,6 +680,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "secret",
 										},
@@ -703,6 +722,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -740,6 +760,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "good-secret",
 										},
@@ -780,6 +801,7 @@ func Test_listener_detectIncoming(t *testing.T) {
 								Incomings: &[]v1alpha1.Incoming{
 									{
 										Targets: []string{"main"},
+										Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 										Secret: v1alpha1.Secret{
 											Name: "empty-secret",
 										},
@@ -802,6 +824,42 @@ func Test_listener_detectIncoming(t *testing.T) {
 				incomingBody:     `{"params":{"the_best_superhero_is":"you"}}`,
 			},
 		},
+		{
+			name:          "bad/incoming without auth type",
+			wantSubstrErr: "the incoming webhook with secret good-secret has no auth type, set auth.type to hmac-sha256 to sign the requests or to secret to pass the plain secret",
+			args: args{
+				secretResult: map[string]string{"good-secret": "verysecrete"},
+				data: testclient.Data{
+					Repositories: []*v1alpha1.Repository{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "test-good",
+							},
+							Spec: v1alpha1.RepositorySpec{
+								URL: goodURL,
+								Incomings: &[]v1alpha1.Incoming{
+									{
+										Targets: []string{"main"},
+										Secret: v1alpha1.Secret{
+											Name: "good-secret",
+										},
+									},
+								},
+								GitProvider: &v1alpha1.GitProvider{
+									Type: "github",
+								},
+							},
+						},
+					},
+				},
+				method:           "GET",
+				queryURL:         "/incoming",
+				queryRepository:  "test-good",
+				querySecret:      "verysecrete",
+				queryPipelineRun: "pipelinerun1",
+				queryBranch:      "main",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1097,6 +1155,7 @@ func Test_detectIncoming_legacy_warning(t *testing.T) {
 					URL: "https://matched/by/incoming",
 					Incomings: &[]v1alpha1.Incoming{{
 						Targets: []string{"main"},
+						Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 						Secret:  v1alpha1.Secret{Name: "good-secret"},
 						Params:  []string{"foo", "bar"},
 					}},
@@ -1196,6 +1255,7 @@ func Test_detectIncoming_body_params_are_parsed(t *testing.T) {
 					URL: "https://matched/by/incoming",
 					Incomings: &[]v1alpha1.Incoming{{
 						Targets: []string{"main"},
+						Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
 						Secret:  v1alpha1.Secret{Name: "good-secret"},
 						Params:  []string{"foo", "bar"},
 					}},
@@ -1303,6 +1363,22 @@ func Test_parseIncomingPayload(t *testing.T) {
 			wantErr:       true,
 			wantErrSubstr: "missing required fields",
 		},
+		{
+			name:   "JSON body without secret for signed requests",
+			method: http.MethodPost,
+			url:    "http://localhost/incoming",
+			headers: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			body: `{"repository":"test-repo","branch":"main","pipelinerun":"pr-789"}`,
+			wantPayload: incomingPayload{
+				legacyMode:  false,
+				RepoName:    "test-repo",
+				Branch:      "main",
+				PipelineRun: "pr-789",
+			},
+			wantErr: false,
+		},
 		{
 			name:   "fallback from invalid query params to JSON body",
 			method: http.MethodPost,
@@ -1353,3 +1429,343 @@ func Test_parseIncomingPayload(t *testing.T) {
 		})
 	}
 }
+
+func signIncoming(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func Test_verifyIncomingSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"repository":"repo","branch":"main","pipelinerun":"pr"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	oldTimestamp := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		auth    *v1alpha1.IncomingAuth
+		headers map[string]string
+		wantErr string
+	}{
+		{
+			name: "valid signature",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Timestamp":     timestamp,
+				"X-PaC-Signature-256": signIncoming("verysecrete", timestamp, body),
+			},
+		},
+		{
+			name: "custom headers",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256", SignatureHeader: "X-Signature", TimestampHeader: "X-Timestamp"},
+			headers: map[string]string{
+				"X-Timestamp": timestamp,
+				"X-Signature": signIncoming("verysecrete", timestamp, body),
+			},
+		},
+		{
+			name: "signed with another secret",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Timestamp":     timestamp,
+				"X-PaC-Signature-256": signIncoming("othersecret", timestamp, body),
+			},
+			wantErr: "signature in the X-PaC-Signature-256 header does not match",
+		},
+		{
+			name: "timestamp is not the signed one",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Timestamp":     strconv.FormatInt(now.Unix()-1, 10),
+				"X-PaC-Signature-256": signIncoming("verysecrete", timestamp, body),
+			},
+			wantErr: "does not match",
+		},
+		{
+			name: "outside of the replay window",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Timestamp":     oldTimestamp,
+				"X-PaC-Signature-256": signIncoming("verysecrete", oldTimestamp, body),
+			},
+			wantErr: "outside of the replay window of 5m0s",
+		},
+		{
+			name: "within a custom replay window",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256", ReplayWindow: &metav1.Duration{Duration: 15 * time.Minute}},
+			headers: map[string]string{
+				"X-PaC-Timestamp":     oldTimestamp,
+				"X-PaC-Signature-256": signIncoming("verysecrete", oldTimestamp, body),
+			},
+		},
+		{
+			name: "missing timestamp",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Signature-256": signIncoming("verysecrete", timestamp, body),
+			},
+			wantErr: "missing X-PaC-Timestamp header",
+		},
+		{
+			name: "missing signature",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Timestamp": timestamp,
+			},
+			wantErr: "missing X-PaC-Signature-256 header",
+		},
+		{
+			name: "invalid signature encoding",
+			auth: &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			headers: map[string]string{
+				"X-PaC-Timestamp":     timestamp,
+				"X-PaC-Signature-256": "sha256=nothex",
+			},
+			wantErr: "invalid X-PaC-Signature-256 header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.headers {
+				header.Set(k, v)
+			}
+			err := verifyIncomingSignature(header, body, "verysecrete", tt.auth, now)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func Test_detectIncoming_hmac(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	ctx = info.StoreCurrentControllerName(ctx, "default")
+	ctx = info.StoreNS(ctx, "pipelinesascode")
+	cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+		Repositories: []*v1alpha1.Repository{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-good"},
+				Spec: v1alpha1.RepositorySpec{
+					URL: "https://matched/by/incoming",
+					Incomings: &[]v1alpha1.Incoming{{
+						Targets: []string{"main"},
+						Secret:  v1alpha1.Secret{Name: "good-secret"},
+						Auth:    &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+					}},
+					GitProvider: &v1alpha1.GitProvider{Type: "github"},
+				},
+			},
+		},
+	})
+	client := &params.Run{
+		Clients: clients.Clients{
+			PipelineAsCode: cs.PipelineAsCode,
+			Kube:           cs.Kube,
+		},
+		Info: info.Info{
+			Controller: &info.ControllerInfo{Secret: info.DefaultPipelinesAscodeSecretName},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		secret  string
+		wantErr string
+	}{
+		{
+			name:   "signed request",
+			body:   `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1"}`,
+			secret: "verysecrete",
+		},
+		{
+			name:    "badly signed request",
+			body:    `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1"}`,
+			secret:  "othersecret",
+			wantErr: "does not match the incoming webhook request",
+		},
+		{
+			name:    "plain secret is refused",
+			body:    `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete"}`,
+			secret:  "verysecrete",
+			wantErr: "the secret must not be passed in the request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req := httptest.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/incoming", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-PaC-Timestamp", timestamp)
+			req.Header.Set("X-PaC-Signature-256", signIncoming(tt.secret, timestamp, []byte(tt.body)))
+			l := &listener{
+				run:    client,
+				logger: zap.NewNop().Sugar(),
+				kint:   &kubernetestint.KinterfaceTest{GetSecretResult: map[string]string{"good-secret": "verysecrete"}},
+				event:  info.NewEvent(),
+			}
+			got, _, err := l.detectIncoming(ctx, req, []byte(tt.body))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, got)
+			assert.Equal(t, l.event.TargetPipelineRun, "pipelinerun1")
+		})
+	}
+}
+
+func Test_detectIncoming_types(t *testing.T) {
+	tests := []struct {
+		name            string
+		hookType        string
+		body            string
+		wantErr         string
+		wantTarget      triggertype.Trigger
+		wantPullRequest int
+		wantSHA         string
+		wantHeadBranch  string
+		wantTargetPR    string
+		wantCancel      string
+	}{
+		{
+			name:           "webhook-url",
+			hookType:       "webhook-url",
+			body:           `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete"}`,
+			wantTarget:     triggertype.Push,
+			wantHeadBranch: "main",
+		},
+		{
+			name:            "pull-request",
+			hookType:        "pull-request",
+			body:            `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","pull_request":42}`,
+			wantTarget:      triggertype.PullRequest,
+			wantPullRequest: 42,
+			wantTargetPR:    "pipelinerun1",
+		},
+		{
+			name:     "pull-request without pull request number",
+			hookType: "pull-request",
+			body:     `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete"}`,
+			wantErr:  "missing required fields: [pull_request]",
+		},
+		{
+			name:            "pull-request retest of all the pipelineruns",
+			hookType:        "pull-request",
+			body:            `{"repository":"test-good","branch":"main","secret":"verysecrete","pull_request":42,"command":"retest"}`,
+			wantTarget:      triggertype.PullRequest,
+			wantPullRequest: 42,
+		},
+		{
+			name:            "pull-request retest of a pipelinerun",
+			hookType:        "pull-request",
+			body:            `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","pull_request":42,"command":"retest"}`,
+			wantTarget:      triggertype.PullRequest,
+			wantPullRequest: 42,
+			wantTargetPR:    "pipelinerun1",
+		},
+		{
+			name:            "pull-request cancel",
+			hookType:        "pull-request",
+			body:            `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","pull_request":42,"command":"cancel"}`,
+			wantTarget:      triggertype.PullRequest,
+			wantPullRequest: 42,
+			wantCancel:      "pipelinerun1",
+		},
+		{
+			name:     "pull-request unknown command",
+			hookType: "pull-request",
+			body:     `{"repository":"test-good","branch":"main","secret":"verysecrete","pull_request":42,"command":"merge"}`,
+			wantErr:  "unsupported incoming webhook command merge",
+		},
+		{
+			name:     "command on a webhook-url webhook",
+			hookType: "webhook-url",
+			body:     `{"repository":"test-good","branch":"main","secret":"verysecrete","command":"retest"}`,
+			wantErr:  "command retest can only be passed to the incoming webhooks of type pull-request",
+		},
+		{
+			name:           "sha",
+			hookType:       "sha",
+			body:           `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","sha":"6d1f8e3a"}`,
+			wantTarget:     triggertype.Push,
+			wantSHA:        "6d1f8e3a",
+			wantHeadBranch: "main",
+		},
+		{
+			name:     "sha without sha",
+			hookType: "sha",
+			body:     `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete"}`,
+			wantErr:  "missing required fields: [sha]",
+		},
+		{
+			name:     "sha is not a commit",
+			hookType: "sha",
+			body:     `{"repository":"test-good","branch":"main","pipelinerun":"pipelinerun1","secret":"verysecrete","sha":"main;rm"}`,
+			wantErr:  "is not a commit SHA",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			ctx = info.StoreCurrentControllerName(ctx, "default")
+			ctx = info.StoreNS(ctx, "pipelinesascode")
+			cs, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-good"},
+						Spec: v1alpha1.RepositorySpec{
+							URL: "https://matched/by/incoming",
+							Incomings: &[]v1alpha1.Incoming{{
+								Type:    tt.hookType,
+								Targets: []string{"main"},
+								Auth:    &v1alpha1.IncomingAuth{Type: "secret"},
+								Secret:  v1alpha1.Secret{Name: "good-secret"},
+							}},
+							GitProvider: &v1alpha1.GitProvider{Type: "github"},
+						},
+					},
+				},
+			})
+			l := &listener{
+				run: &params.Run{
+					Clients: clients.Clients{
+						PipelineAsCode: cs.PipelineAsCode,
+						Kube:           cs.Kube,
+					},
+					Info: info.Info{
+						Controller: &info.ControllerInfo{Secret: info.DefaultPipelinesAscodeSecretName},
+					},
+				},
+				logger: zap.NewNop().Sugar(),
+				kint:   &kubernetestint.KinterfaceTest{GetSecretResult: map[string]string{"good-secret": "verysecrete"}},
+				event:  info.NewEvent(),
+			}
+			req := httptest.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/incoming", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			got, _, err := l.detectIncoming(ctx, req, []byte(tt.body))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, got)
+			assert.Equal(t, l.event.TriggerTarget, tt.wantTarget)
+			assert.Equal(t, l.event.PullRequestNumber, tt.wantPullRequest)
+			assert.Equal(t, l.event.SHA, tt.wantSHA)
+			assert.Equal(t, l.event.HeadBranch, tt.wantHeadBranch)
+			assert.Equal(t, l.event.BaseBranch, "main")
+			if tt.wantTarget == triggertype.PullRequest {
+				assert.Equal(t, l.event.TargetPipelineRun, tt.wantTargetPR)
+			}
+			assert.Equal(t, l.event.CancelPipelineRuns, tt.wantCancel != "")
+			assert.Equal(t, l.event.TargetCancelPipelineRun, tt.wantCancel)
+		})
+	}
+}
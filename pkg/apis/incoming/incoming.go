@@ -1,6 +1,25 @@
 package incoming
 
-import "encoding/json"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+const (
+	// SignatureHeader is the default header of the signature of a signed request.
+	SignatureHeader = "X-PaC-Signature-256"
+	// TimestampHeader is the default header of the time a request has been signed at.
+	TimestampHeader = "X-PaC-Timestamp"
+	// SignaturePrefix prefixes the hex encoded signature in the header.
+	SignaturePrefix = "sha256="
+
+	// CommandRetest runs the PipelineRuns of a pull request again, like a /retest comment.
+	CommandRetest = "retest"
+	// CommandCancel cancels the PipelineRuns of a pull request, like a /cancel comment.
+	CommandCancel = "cancel"
+)
 
 type (
 	Params  map[string]any
@@ -18,3 +37,17 @@ func ParseIncomingPayload(payload []byte) (Payload, error) {
 	}
 	return incomingPayload, nil
 }
+
+// Signature returns the HMAC-SHA256 of the timestamp, a dot and the body of a
+// request computed with the secret of the incoming webhook.
+func Signature(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// SignatureHeaderValue returns the value of the signature header of a request.
+func SignatureHeaderValue(secret, timestamp string, body []byte) string {
+	return SignaturePrefix + hex.EncodeToString(Signature(secret, timestamp, body))
+}
@@ -58,6 +58,13 @@ const (
 	TargetNamespace        = pipelinesascode.GroupName + "/target-namespace"
 	MaxKeepRuns            = pipelinesascode.GroupName + "/max-keep-runs"
 	CancelInProgress       = pipelinesascode.GroupName + "/cancel-in-progress"
+	CancelInProgressKey    = pipelinesascode.GroupName + "/cancel-in-progress-key"
+	QueueTimeout           = pipelinesascode.GroupName + "/queue-timeout"
+	Hold                   = pipelinesascode.GroupName + "/hold"
+	PolicyTeams            = pipelinesascode.GroupName + "/policy-teams"
+	OkToTestApproval       = pipelinesascode.GroupName + "/ok-to-test-approval"
+	IncomingID             = pipelinesascode.GroupName + "/incoming-id"
+	LocalRun               = pipelinesascode.GroupName + "/local-run"
 	LogURL                 = pipelinesascode.GroupName + "/log-url"
 	ExecutionOrder         = pipelinesascode.GroupName + "/execution-order"
 	SCMReportingPLRStarted = pipelinesascode.GroupName + "/scm-reporting-plr-started"
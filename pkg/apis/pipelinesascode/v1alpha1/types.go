@@ -168,6 +168,16 @@ type Settings struct {
 	// AIAnalysis contains AI/LLM analysis configuration for automated CI/CD pipeline analysis.
 	// +optional
 	AIAnalysis *AIAnalysisConfig `json:"ai,omitempty"`
+
+	// Commands declares custom GitOps commands which can be used in pull request
+	// comments to launch a PipelineRun, for example '/deploy staging'.
+	// +optional
+	Commands []Command `json:"commands,omitempty"`
+
+	// CodeOwners trusts the users and the members of the teams of the CODEOWNERS file of
+	// the default branch like the users of the OWNERS file. Only supported on GitHub and GitLab.
+	// +optional
+	CodeOwners bool `json:"codeowners,omitempty"`
 }
 
 type GitlabSettings struct {
@@ -223,6 +233,64 @@ func (s *Settings) Merge(newSettings *Settings) {
 	if newSettings.AIAnalysis != nil && s.AIAnalysis == nil {
 		s.AIAnalysis = newSettings.AIAnalysis
 	}
+	if newSettings.Commands != nil && s.Commands == nil {
+		s.Commands = newSettings.Commands
+	}
+}
+
+type Command struct {
+	// Name of the command without the leading slash, a comment starting with
+	// '/deploy' runs the command named 'deploy'.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][a-z0-9_-]*$`
+	Name string `json:"name"`
+
+	// Description of the command, shown in the '/help' output.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// PipelineRun is the name of the PipelineRun from the .tekton directory
+	// launched when the command is used.
+	// +kubebuilder:validation:Required
+	PipelineRun string `json:"pipelinerun"`
+
+	// Users is a list of usernames allowed to run this command.
+	// +optional
+	Users []string `json:"users,omitempty"`
+
+	// PolicyGroups is a list of teams or groups on the git provider allowed to
+	// run this command. When neither Users nor PolicyGroups are set, the same
+	// permissions as the '/test' command apply.
+	// +optional
+	PolicyGroups []string `json:"policy_groups,omitempty"`
+
+	// Args defines the arguments accepted by the command, in the order they can
+	// be passed positionally. Arguments can also be passed as 'name=value' and
+	// are available as '{{ name }}' in the PipelineRun.
+	// +optional
+	Args []CommandArg `json:"args,omitempty"`
+}
+
+type CommandArg struct {
+	// Name of the argument.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Description of the argument, shown in the '/help' output.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Required makes the command fail when the argument is not provided.
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// Default value of the argument when it is not provided.
+	// +optional
+	Default string `json:"default,omitempty"`
+
+	// Enum restricts the argument to a list of allowed values.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
 }
 
 type Policy struct {
@@ -237,6 +305,28 @@ type Policy struct {
 	// This is useful for allowing specific external contributors to trigger pipeline runs.
 	// +optional
 	PullRequest []string `json:"pull_request,omitempty"`
+
+	// Push defines who is allowed to trigger PipelineRuns by pushing to the branches
+	// matched by its rules. A push to a branch not matched by any rule is not restricted.
+	// +optional
+	Push []PushPolicy `json:"push,omitempty"`
+
+	// Cancel defines a list of teams or usernames allowed to cancel PipelineRuns with the
+	// /cancel GitOps command. When not set, the users allowed to run the CI can cancel.
+	// +optional
+	Cancel []string `json:"cancel,omitempty"`
+}
+
+// PushPolicy restricts who can trigger PipelineRuns by pushing to some branches.
+type PushPolicy struct {
+	// Branches are the glob patterns of the branches the rule applies to.
+	// +kubebuilder:validation:Required
+	Branches []string `json:"branches"`
+
+	// Allowed defines a list of teams or usernames allowed to trigger PipelineRuns by
+	// pushing to the branches.
+	// +optional
+	Allowed []string `json:"allowed,omitempty"`
 }
 
 type Params struct {
@@ -263,10 +353,11 @@ type Params struct {
 }
 
 type Incoming struct {
-	// Type of the incoming webhook. Currently only 'webhook-url' is supported, which allows
-	// external systems to trigger PipelineRuns via generic webhook requests.
+	// Type of the incoming webhook. 'webhook-url' runs the PipelineRun on the head of the
+	// branch, 'pull-request' runs it on the pull request given in the request and reports
+	// the status there, 'sha' runs it on the commit given in the request.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=webhook-url
+	// +kubebuilder:validation:Enum=webhook-url;pull-request;sha
 	Type string `json:"type"`
 
 	// Secret for the incoming webhook authentication. This secret is used to validate
@@ -279,10 +370,116 @@ type Incoming struct {
 	// +optional
 	Params []string `json:"params,omitempty"`
 
+	// ParamsSchema declares the type and the validation of the params. A param
+	// declared here is allowed even when it is not listed in Params.
+	// +optional
+	ParamsSchema []IncomingParam `json:"params_schema,omitempty"`
+
 	// Targets defines target branches for this webhook. When specified, only webhook
 	// events targeting these branches will trigger PipelineRuns.
 	// +optional
 	Targets []string `json:"targets,omitempty"`
+
+	// Auth configures how the requests to this webhook are authenticated. The requests
+	// to a webhook without it are rejected.
+	// +optional
+	Auth *IncomingAuth `json:"auth,omitempty"`
+
+	// Mapping extracts the branch, PipelineRun and params from an arbitrary JSON
+	// payload with CEL expressions, for systems that cannot send the Pipelines-as-Code
+	// payload. The repository is then passed in the URL query.
+	// +optional
+	Mapping *IncomingMapping `json:"mapping,omitempty"`
+}
+
+// IncomingParam declares the type and the validation of a param passed to an
+// incoming webhook.
+type IncomingParam struct {
+	// Name of the param.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Type of the param value, string when not set. The enum params are strings
+	// limited to the values listed in Enum.
+	// +kubebuilder:validation:Enum=string;int;bool;enum
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Enum lists the values allowed for the enum params.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+
+	// Pattern is a regular expression the value must match.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Required rejects the requests not passing the param.
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// Default is the value of the param when the request doesn't pass it.
+	// +optional
+	Default string `json:"default,omitempty"`
+}
+
+type IncomingMapping struct {
+	// Filter is a CEL expression evaluated against the body and headers of the request,
+	// the request is skipped when it doesn't evaluate to true.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// Branch is a CEL expression returning the branch to run the PipelineRun on.
+	// +kubebuilder:validation:Required
+	Branch string `json:"branch"`
+
+	// PipelineRun is a CEL expression returning the name (or generateName) of the
+	// PipelineRun to run.
+	// +kubebuilder:validation:Required
+	PipelineRun string `json:"pipelinerun"`
+
+	// Secret is a CEL expression returning the shared secret sent by the system from the
+	// headers, for example headers['Authorization']. It is evaluated before the request is
+	// authenticated and cannot read the body. It is not used with the hmac-sha256 authentication.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// PullRequest is a CEL expression returning the pull request number, for the
+	// pull-request incoming webhooks.
+	// +optional
+	PullRequest string `json:"pull_request,omitempty"`
+
+	// SHA is a CEL expression returning the commit SHA, for the sha incoming webhooks.
+	// +optional
+	SHA string `json:"sha,omitempty"`
+
+	// Params maps parameter names to the CEL expressions returning their values.
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type IncomingAuth struct {
+	// Type of authentication for the incoming webhook. Options:
+	// - 'hmac-sha256': the request carries a HMAC-SHA256 signature of the timestamp and
+	//   body computed with the secret, the secret itself is never sent
+	// - 'secret': the legacy plain shared secret passed in the payload or the URL
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=hmac-sha256;secret
+	Type string `json:"type"`
+
+	// SignatureHeader is the header holding the 'sha256=<hex>' signature of the request.
+	// Defaults to 'X-PaC-Signature-256'.
+	// +optional
+	SignatureHeader string `json:"signature_header,omitempty"`
+
+	// TimestampHeader is the header holding the Unix timestamp, in seconds, at which the
+	// request has been signed. Defaults to 'X-PaC-Timestamp'.
+	// +optional
+	TimestampHeader string `json:"timestamp_header,omitempty"`
+
+	// ReplayWindow is how far the signing timestamp can be from the time the request is
+	// received, older or future requests are rejected. Defaults to 5m.
+	// +optional
+	ReplayWindow *metav1.Duration `json:"replay_window,omitempty"`
 }
 
 type GitProvider struct {
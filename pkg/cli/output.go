@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputFlag = "output"
+
+	outputJSON       = "json"
+	outputYAML       = "yaml"
+	outputGoTemplate = "go-template="
+	outputJSONPath   = "jsonpath="
+)
+
+// AddOutputFlag adds the --output flag to select a machine-readable output
+// instead of the default human-readable one.
+func AddOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, OutputFlag, "o", "",
+		"Output format. One of: json|yaml|go-template=...|jsonpath=...")
+	_ = cmd.RegisterFlagCompletionFunc(OutputFlag,
+		func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{outputJSON, outputYAML, outputGoTemplate, outputJSONPath}, cobra.ShellCompDirectiveNoSpace
+		},
+	)
+}
+
+// ValidateOutputFormat checks the value of the --output flag, an empty value
+// is the human-readable output.
+func ValidateOutputFormat(output string) error {
+	switch {
+	case output == "", output == outputJSON, output == outputYAML:
+		return nil
+	case strings.HasPrefix(output, outputGoTemplate), strings.HasPrefix(output, outputJSONPath):
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, supported formats are: json, yaml, go-template=..., jsonpath=", output)
+}
+
+// PrintOutput prints the object in the format of the --output flag. The
+// go-template and jsonpath expressions are evaluated on the JSON
+// representation of the object, so they use the same field names as the json
+// and yaml outputs.
+func PrintOutput(w io.Writer, output string, obj any) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case output == outputJSON:
+		var out []byte
+		if out, err = json.MarshalIndent(obj, "", "  "); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case output == outputYAML:
+		var out []byte
+		if out, err = yaml.JSONToYAML(b); err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	}
+
+	var data any
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	switch {
+	case strings.HasPrefix(output, outputGoTemplate):
+		t, err := template.New("output").Parse(strings.TrimPrefix(output, outputGoTemplate))
+		if err != nil {
+			return fmt.Errorf("cannot parse go-template: %w", err)
+		}
+		return t.Execute(w, data)
+	case strings.HasPrefix(output, outputJSONPath):
+		jp := jsonpath.New("output").AllowMissingKeys(true)
+		if err := jp.Parse(strings.TrimPrefix(output, outputJSONPath)); err != nil {
+			return fmt.Errorf("cannot parse jsonpath: %w", err)
+		}
+		return jp.Execute(w, data)
+	}
+	return ValidateOutputFormat(output)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPrintOutput(t *testing.T) {
+	obj := struct {
+		Name  string   `json:"name"`
+		Items []string `json:"items"`
+	}{
+		Name:  "repo",
+		Items: []string{"one", "two"},
+	}
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "json",
+			output: "json",
+			want:   "{\n  \"name\": \"repo\",\n  \"items\": [\n    \"one\",\n    \"two\"\n  ]\n}\n",
+		},
+		{
+			name:   "yaml",
+			output: "yaml",
+			want:   "items:\n- one\n- two\nname: repo\n",
+		},
+		{
+			name:   "go-template",
+			output: "go-template={{.name}}: {{range .items}}{{.}} {{end}}",
+			want:   "repo: one two ",
+		},
+		{
+			name:   "jsonpath",
+			output: "jsonpath={.items[1]}",
+			want:   "two",
+		},
+		{
+			name:   "jsonpath missing key",
+			output: "jsonpath={.nothere}",
+			want:   "",
+		},
+		{
+			name:    "bad go-template",
+			output:  "go-template={{.name",
+			wantErr: "cannot parse go-template",
+		},
+		{
+			name:    "bad jsonpath",
+			output:  "jsonpath={.items[",
+			wantErr: "cannot parse jsonpath",
+		},
+		{
+			name:    "unknown format",
+			output:  "xml",
+			wantErr: `unknown output format "xml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := PrintOutput(out, tt.output, obj)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, out.String(), tt.want)
+		})
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, output := range []string{"", "json", "yaml", "go-template={{.name}}", "jsonpath={.name}"} {
+		assert.NilError(t, ValidateOutputFormat(output))
+	}
+	assert.ErrorContains(t, ValidateOutputFormat("table"), "unknown output format")
+}
@@ -3,6 +3,7 @@ package status
 import (
 	"context"
 	"regexp"
+	"slices"
 
 	"github.com/google/go-github/v81/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
@@ -72,3 +73,41 @@ func MixLivePRandRepoStatus(ctx context.Context, cs *params.Run, repository pacv
 	}
 	return sortrepostatus.RepositorySortRunStatus(repositorystatus)
 }
+
+// QueueStatus is the state of the concurrency queue of a Repository, the
+// PipelineRuns are listed in the order they have been started or queued.
+type QueueStatus struct {
+	ConcurrencyLimit *int     `json:"concurrencyLimit,omitempty"`
+	Running          []string `json:"running"`
+	Pending          []string `json:"pending"`
+}
+
+// RepositoryQueueStatus returns the PipelineRuns of the repository currently
+// running and the ones waiting in the queue for their turn.
+func RepositoryQueueStatus(ctx context.Context, cs *params.Run, repository pacv1alpha1.Repository) (*QueueStatus, error) {
+	queue := &QueueStatus{
+		ConcurrencyLimit: repository.Spec.ConcurrencyLimit,
+		Running:          []string{},
+		Pending:          []string{},
+	}
+	prs, err := cs.Clients.Tekton.TektonV1().PipelineRuns(repository.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: keys.Repository + "=" + repository.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(prs.Items, func(a, b tektonv1.PipelineRun) int {
+		return a.GetCreationTimestamp().Compare(b.GetCreationTimestamp().Time)
+	})
+	for _, pr := range prs.Items {
+		switch pr.GetLabels()[keys.State] {
+		case kubeinteraction.StateQueued:
+			queue.Pending = append(queue.Pending, pr.GetName())
+		case kubeinteraction.StateStarted:
+			if !pr.IsDone() {
+				queue.Running = append(queue.Running, pr.GetName())
+			}
+		}
+	}
+	return queue, nil
+}
@@ -17,6 +17,7 @@ import (
 type bitbucketCloudConfig struct {
 	Client              *bitbucket.Client
 	IOStream            *cli.IOStreams
+	nonInteractive      bool
 	controllerURL       string
 	repoOwner           string
 	repoName            string
@@ -26,10 +27,15 @@ type bitbucketCloudConfig struct {
 }
 
 func (bb *bitbucketCloudConfig) Run(_ context.Context, opts *Options) (*response, error) {
+	bb.nonInteractive = opts.NonInteractive
+	bb.username = opts.UserName
 	err := bb.askBBWebhookConfig(opts.RepositoryURL, opts.ControllerURL, opts.ProviderAPIURL, opts.PersonalAccessToken)
 	if err != nil {
 		return nil, err
 	}
+	if err := bb.validateToken(); err != nil {
+		return nil, err
+	}
 
 	return &response{
 		ControllerURL:       bb.controllerURL,
@@ -63,13 +69,21 @@ func (bb *bitbucketCloudConfig) askBBWebhookConfig(repositoryURL, controllerURL,
 	bb.repoOwner = repoArr[0]
 	bb.repoName = repoArr[1]
 
-	if err := prompt.SurveyAskOne(&survey.Input{
-		Message: "Please enter your bitbucket cloud username: ",
-	}, &bb.username, survey.WithValidator(survey.Required)); err != nil {
-		return err
+	if bb.username == "" {
+		if bb.nonInteractive {
+			return missingValueError("the Bitbucket Cloud username")
+		}
+		if err := prompt.SurveyAskOne(&survey.Input{
+			Message: "Please enter your bitbucket cloud username: ",
+		}, &bb.username, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
 	}
 
 	if personalAccessToken == "" {
+		if bb.nonInteractive {
+			return missingValueError("the Bitbucket Cloud app password")
+		}
 		fmt.Fprintln(bb.IOStream.Out, "ℹ ️You now need to create a Bitbucket Cloud app password, please checkout the docs at https://is.gd/fqMHiJ for the required permissions")
 		if err := prompt.SurveyAskOne(&survey.Password{
 			Message: "Please enter the Bitbucket Cloud app password: ",
@@ -80,33 +94,16 @@ func (bb *bitbucketCloudConfig) askBBWebhookConfig(repositoryURL, controllerURL,
 		bb.personalAccessToken = personalAccessToken
 	}
 
-	bb.controllerURL = controllerURL
-
 	// confirm whether to use the detected url
-	if bb.controllerURL != "" {
-		var answer bool
-		fmt.Fprintf(bb.IOStream.Out, "👀 I have detected a controller url: %s\n", bb.controllerURL)
-		err := prompt.SurveyAskOne(&survey.Confirm{
-			Message: "Do you want me to use it?",
-			Default: true,
-		}, &answer)
-		if err != nil {
-			return err
-		}
-		if !answer {
-			bb.controllerURL = ""
-		}
-	}
-
-	if bb.controllerURL == "" {
-		if err := prompt.SurveyAskOne(&survey.Input{
-			Message: "Please enter your controller public route URL: ",
-		}, &bb.controllerURL, survey.WithValidator(survey.Required)); err != nil {
-			return err
-		}
+	bb.controllerURL = controllerURL
+	if err := askControllerURL(bb.IOStream, bb.nonInteractive, &bb.controllerURL); err != nil {
+		return err
 	}
 
 	if apiURL == "" && !strings.HasPrefix(repositoryURL, "https://bitbucket.org") {
+		if bb.nonInteractive {
+			return missingValueError("the Bitbucket enterprise API URL")
+		}
 		if err := prompt.SurveyAskOne(&survey.Input{
 			Message: "Please enter your Bitbucket enterprise API URL:: ",
 		}, &bb.APIURL, survey.WithValidator(survey.Required)); err != nil {
@@ -119,10 +116,24 @@ func (bb *bitbucketCloudConfig) askBBWebhookConfig(repositoryURL, controllerURL,
 	return nil
 }
 
-func (bb *bitbucketCloudConfig) create() error {
+// validateToken checks the app password against the API before it gets used
+// or stored.
+func (bb *bitbucketCloudConfig) validateToken() error {
+	if err := bb.newClient(); err != nil {
+		return err
+	}
+	user, err := bb.Client.User.Profile()
+	if err != nil {
+		return fmt.Errorf("cannot validate the Bitbucket Cloud app password: %w", err)
+	}
+	fmt.Fprintf(bb.IOStream.Out, "✓ Bitbucket Cloud app password validated for user %s\n", user.Username)
+	return nil
+}
+
+func (bb *bitbucketCloudConfig) newClient() error {
 	if bb.Client == nil {
 		var err error
-		bb.Client, err = bitbucket.NewBasicAuth(bb.repoOwner, bb.personalAccessToken)
+		bb.Client, err = bitbucket.NewBasicAuth(bb.username, bb.personalAccessToken)
 		if err != nil {
 			return err
 		}
@@ -134,6 +145,13 @@ func (bb *bitbucketCloudConfig) create() error {
 		}
 		bb.Client.SetApiBaseURL(*parsedURL)
 	}
+	return nil
+}
+
+func (bb *bitbucketCloudConfig) create() error {
+	if err := bb.newClient(); err != nil {
+		return err
+	}
 
 	opts := &bitbucket.WebhooksOptions{
 		Owner:    bb.repoOwner,
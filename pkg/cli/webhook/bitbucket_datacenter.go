@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/stash"
+	"github.com/jenkins-x/go-scm/scm/transport/oauth2"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+)
+
+// bitbucketDataCenterHookEvents are the events the controller processes from a
+// Bitbucket Data Center webhook.
+var bitbucketDataCenterHookEvents = []string{
+	"repo:refs_changed",
+	"repo:modified",
+	"pr:opened",
+	"pr:from_ref_updated",
+	"pr:comment:added",
+}
+
+type bitbucketDataCenterConfig struct {
+	Client              *scm.Client
+	IOStream            *cli.IOStreams
+	nonInteractive      bool
+	controllerURL       string
+	projectKey          string
+	repoSlug            string
+	webhookSecret       string
+	personalAccessToken string
+	username            string
+	APIURL              string
+}
+
+func (bd *bitbucketDataCenterConfig) Run(ctx context.Context, opts *Options) (*response, error) {
+	bd.nonInteractive = opts.NonInteractive
+	bd.webhookSecret = opts.WebhookSecret
+	bd.username = opts.UserName
+	err := bd.askBBDCWebhookConfig(opts.RepositoryURL, opts.ControllerURL, opts.ProviderAPIURL, opts.PersonalAccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := bd.validateToken(ctx); err != nil {
+		return nil, err
+	}
+
+	return &response{
+		ControllerURL:       bd.controllerURL,
+		PersonalAccessToken: bd.personalAccessToken,
+		WebhookSecret:       bd.webhookSecret,
+		APIURL:              bd.APIURL,
+		UserName:            bd.username,
+		ProviderType:        "bitbucket-datacenter",
+	}, bd.create(ctx)
+}
+
+func (bd *bitbucketDataCenterConfig) askBBDCWebhookConfig(repoURL, controllerURL, apiURL, personalAccessToken string) error {
+	if repoURL == "" {
+		msg := "Please enter the git repository url you want to be configured: "
+		if err := prompt.SurveyAskOne(&survey.Input{Message: msg}, &repoURL,
+			survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(bd.IOStream.Out, "✓ Setting up Bitbucket Data Center Webhook for Repository %s\n", repoURL)
+	}
+
+	instanceURL, projectKey, repoSlug, err := parseBitbucketDataCenterURL(repoURL)
+	if err != nil {
+		return err
+	}
+	bd.projectKey = projectKey
+	bd.repoSlug = repoSlug
+
+	if bd.username == "" {
+		if bd.nonInteractive {
+			return missingValueError("the Bitbucket Data Center username")
+		}
+		if err := prompt.SurveyAskOne(&survey.Input{
+			Message: "Please enter your Bitbucket Data Center username: ",
+		}, &bd.username, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	}
+
+	if personalAccessToken == "" {
+		if bd.nonInteractive {
+			return missingValueError("the Bitbucket Data Center access token")
+		}
+		fmt.Fprintln(bd.IOStream.Out, "ℹ ️You now need to create a Bitbucket Data Center HTTP access token with the PROJECT_ADMIN and REPOSITORY_ADMIN permissions")
+		if err := prompt.SurveyAskOne(&survey.Password{
+			Message: "Please enter the Bitbucket Data Center access token: ",
+		}, &bd.personalAccessToken, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	} else {
+		bd.personalAccessToken = personalAccessToken
+	}
+
+	bd.controllerURL = controllerURL
+	if err := askControllerURL(bd.IOStream, bd.nonInteractive, &bd.controllerURL); err != nil {
+		return err
+	}
+
+	if err := askWebhookSecret(bd.nonInteractive, &bd.webhookSecret); err != nil {
+		return err
+	}
+
+	bd.APIURL = apiURL
+	if bd.APIURL == "" {
+		if !bd.nonInteractive {
+			if err := prompt.SurveyAskOne(&survey.Input{
+				Message: fmt.Sprintf("Please enter the URL of your Bitbucket Data Center instance (default: %s): ", instanceURL),
+				Default: instanceURL,
+			}, &bd.APIURL); err != nil {
+				return err
+			}
+		}
+		if bd.APIURL == "" {
+			bd.APIURL = instanceURL
+		}
+	}
+	// the Repository CR and the client want the url of the instance without
+	// the /rest suffix.
+	bd.APIURL = strings.TrimSuffix(strings.TrimSuffix(bd.APIURL, "/"), "/rest")
+
+	return nil
+}
+
+// parseBitbucketDataCenterURL splits the url of a repository as shown in the
+// Bitbucket Data Center UI, i.e:
+// https://bitbucket.example.com/context/projects/KEY/repos/slug/browse
+// into the url of the instance, the key of the project and the repository slug.
+func parseBitbucketDataCenterURL(repoURL string) (string, string, string, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i+3 < len(parts); i++ {
+		if parts[i] == "projects" && parts[i+2] == "repos" {
+			instance := fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+			if i > 0 {
+				instance += "/" + strings.Join(parts[:i], "/")
+			}
+			return instance, parts[i+1], parts[i+3], nil
+		}
+	}
+	return "", "", "", fmt.Errorf("invalid Bitbucket Data Center repository url, needs to be of format 'https://host/projects/KEY/repos/slug': %s", repoURL)
+}
+
+// validateToken checks the token against the API before it gets used or stored.
+func (bd *bitbucketDataCenterConfig) validateToken(ctx context.Context) error {
+	client, err := bd.newClient()
+	if err != nil {
+		return err
+	}
+	user, resp, err := client.Users.FindLogin(ctx, bd.username)
+	if resp != nil && resp.Status == http.StatusUnauthorized {
+		return fmt.Errorf("cannot validate the Bitbucket Data Center access token of user %s: unauthorized", bd.username)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot validate the Bitbucket Data Center access token of user %s: %w", bd.username, err)
+	}
+	fmt.Fprintf(bd.IOStream.Out, "✓ Bitbucket Data Center access token validated for user %s\n", user.Login)
+	return nil
+}
+
+func (bd *bitbucketDataCenterConfig) create(ctx context.Context) error {
+	client, err := bd.newClient()
+	if err != nil {
+		return err
+	}
+
+	_, resp, err := client.Repositories.CreateHook(ctx, bd.projectKey+"/"+bd.repoSlug, &scm.HookInput{
+		Name:         "Pipelines-as-Code",
+		Target:       bd.controllerURL,
+		Secret:       bd.webhookSecret,
+		NativeEvents: bitbucketDataCenterHookEvents,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook on repository %v/%v: %w", bd.projectKey, bd.repoSlug, err)
+	}
+	if resp.Status != http.StatusCreated {
+		return fmt.Errorf("failed to create webhook on repository %v/%v, status code: %v",
+			bd.projectKey, bd.repoSlug, resp.Status)
+	}
+
+	fmt.Fprintf(bd.IOStream.Out, "✓ Webhook has been created on repository %v/%v\n", bd.projectKey, bd.repoSlug)
+	return nil
+}
+
+func (bd *bitbucketDataCenterConfig) newClient() (*scm.Client, error) {
+	if bd.Client != nil {
+		return bd.Client, nil
+	}
+	client, err := stash.New(bd.APIURL)
+	if err != nil {
+		return nil, err
+	}
+	client.Client = &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&scm.Token{Token: bd.personalAccessToken}),
+		},
+	}
+	bd.Client = client
+	return client, nil
+}
@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	bbdctest "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketdatacenter/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestParseBitbucketDataCenterURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		repoURL      string
+		wantInstance string
+		wantProject  string
+		wantRepo     string
+		wantErr      bool
+	}{
+		{
+			name:         "repository url",
+			repoURL:      "https://bitbucket.example.com/projects/PAC/repos/demo",
+			wantInstance: "https://bitbucket.example.com",
+			wantProject:  "PAC",
+			wantRepo:     "demo",
+		},
+		{
+			name:         "browse url with a context path",
+			repoURL:      "https://example.com/bitbucket/projects/PAC/repos/demo/browse",
+			wantInstance: "https://example.com/bitbucket",
+			wantProject:  "PAC",
+			wantRepo:     "demo",
+		},
+		{
+			name:    "not a repository url",
+			repoURL: "https://bitbucket.example.com/scm/pac/demo.git",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance, project, repo, err := parseBitbucketDataCenterURL(tt.repoURL)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, instance, tt.wantInstance)
+			assert.Equal(t, project, tt.wantProject)
+			assert.Equal(t, repo, tt.wantRepo)
+		})
+	}
+}
+
+func TestAskBBDCWebhookConfig(t *testing.T) {
+	//nolint
+	io, _, _, _ := cli.IOTest()
+	tests := []struct {
+		name                string
+		wantErrStr          string
+		askStubs            func(*prompt.AskStubber)
+		repoURL             string
+		controllerURL       string
+		apiURL              string
+		personalaccesstoken string
+		username            string
+		nonInteractive      bool
+		wantAPIURL          string
+	}{
+		{
+			name: "ask all details no defaults",
+			askStubs: func(as *prompt.AskStubber) {
+				as.StubOne("https://bitbucket.example.com/projects/PAC/repos/test")
+				as.StubOne("user")
+				as.StubOne("token")
+				as.StubOne("https://controller.url")
+				as.StubOne("webhook-secret")
+				as.StubOne("https://api.bitbucket.example.com/rest")
+			},
+			wantAPIURL: "https://api.bitbucket.example.com",
+		},
+		{
+			name: "with defaults",
+			askStubs: func(as *prompt.AskStubber) {
+				as.StubOne(true)
+				as.StubOne("webhook-secret")
+				as.StubOne("")
+			},
+			repoURL:             "https://bitbucket.example.com/projects/PAC/repos/demo",
+			controllerURL:       "https://test",
+			personalaccesstoken: "token",
+			username:            "user",
+			wantAPIURL:          "https://bitbucket.example.com",
+		},
+		{
+			name:                "non interactive",
+			repoURL:             "https://bitbucket.example.com/projects/PAC/repos/demo",
+			controllerURL:       "https://test",
+			personalaccesstoken: "token",
+			username:            "user",
+			nonInteractive:      true,
+			wantAPIURL:          "https://bitbucket.example.com",
+		},
+		{
+			name:                "non interactive without username",
+			repoURL:             "https://bitbucket.example.com/projects/PAC/repos/demo",
+			controllerURL:       "https://test",
+			personalaccesstoken: "token",
+			nonInteractive:      true,
+			wantErrStr:          "the Bitbucket Data Center username is required in non-interactive mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as, teardown := prompt.InitAskStubber()
+			defer teardown()
+			if tt.askStubs != nil {
+				tt.askStubs(as)
+			}
+			bd := bitbucketDataCenterConfig{IOStream: io, nonInteractive: tt.nonInteractive, username: tt.username}
+			err := bd.askBBDCWebhookConfig(tt.repoURL, tt.controllerURL, tt.apiURL, tt.personalaccesstoken)
+			if tt.wantErrStr != "" {
+				assert.Error(t, err, tt.wantErrStr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, bd.APIURL, tt.wantAPIURL)
+		})
+	}
+}
+
+func TestBBDCValidateToken(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, mux, teardown, _ := bbdctest.SetupBBDataCenterClient()
+	defer teardown()
+
+	mux.HandleFunc("/users/user", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"name": "user", "slug": "user"}`)
+	})
+	mux.HandleFunc("/users/unknown", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	tests := []struct {
+		name     string
+		username string
+		wantOut  string
+		wantErr  string
+	}{
+		{
+			name:     "valid token",
+			username: "user",
+			wantOut:  "✓ Bitbucket Data Center access token validated for user user\n",
+		},
+		{
+			name:     "unauthorized",
+			username: "unknown",
+			wantErr:  "cannot validate the Bitbucket Data Center access token of user unknown: unauthorized",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//nolint
+			io, _, out, _ := cli.IOTest()
+			bd := bitbucketDataCenterConfig{IOStream: io, Client: client, username: tt.username}
+			err := bd.validateToken(ctx)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, out.String(), tt.wantOut)
+		})
+	}
+}
+
+func TestBBDCCreate(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, mux, teardown, _ := bbdctest.SetupBBDataCenterClient()
+	defer teardown()
+	//nolint
+	io, _, _, _ := cli.IOTest()
+
+	mux.HandleFunc("/projects/PAC/repos/created/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		hook := struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+			Config struct {
+				Secret string `json:"secret"`
+			} `json:"configuration"`
+		}{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&hook))
+		assert.Equal(t, hook.URL, "https://controller.url")
+		assert.Equal(t, hook.Config.Secret, "webhook-secret")
+		assert.DeepEqual(t, hook.Events, bitbucketDataCenterHookEvents)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"id": 1}`)
+	})
+	mux.HandleFunc("/projects/PAC/repos/forbidden/webhooks", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	tests := []struct {
+		name     string
+		repoSlug string
+		wantErr  bool
+	}{
+		{
+			name:     "webhook created",
+			repoSlug: "created",
+		},
+		{
+			name:     "webhook failed",
+			repoSlug: "forbidden",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bd := bitbucketDataCenterConfig{
+				IOStream:      io,
+				Client:        client,
+				projectKey:    "PAC",
+				repoSlug:      tt.repoSlug,
+				controllerURL: "https://controller.url",
+				webhookSecret: "webhook-secret",
+			}
+			err := bd.create(ctx)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v3"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+)
+
+// giteaHookEvents are the events the controller processes from a Forgejo or
+// Gitea webhook.
+var giteaHookEvents = []string{
+	"push",
+	"pull_request",
+	"pull_request_sync",
+	"pull_request_label",
+	"issue_comment",
+	"pull_request_comment",
+}
+
+type giteaConfig struct {
+	Client              *forgejo.Client
+	IOStream            *cli.IOStreams
+	nonInteractive      bool
+	providerType        string
+	controllerURL       string
+	repoOwner           string
+	repoName            string
+	webhookSecret       string
+	personalAccessToken string
+	APIURL              string
+}
+
+func (gt *giteaConfig) Run(ctx context.Context, opts *Options) (*response, error) {
+	gt.nonInteractive = opts.NonInteractive
+	gt.webhookSecret = opts.WebhookSecret
+	err := gt.askGiteaWebhookConfig(opts.RepositoryURL, opts.ControllerURL, opts.ProviderAPIURL, opts.PersonalAccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := gt.validateToken(ctx); err != nil {
+		return nil, err
+	}
+
+	return &response{
+		ControllerURL:       gt.controllerURL,
+		PersonalAccessToken: gt.personalAccessToken,
+		WebhookSecret:       gt.webhookSecret,
+		APIURL:              gt.APIURL,
+		ProviderType:        gt.providerType,
+	}, gt.create(ctx)
+}
+
+func (gt *giteaConfig) askGiteaWebhookConfig(repoURL, controllerURL, apiURL, personalAccessToken string) error {
+	if repoURL == "" {
+		msg := "Please enter the git repository url you want to be configured: "
+		if err := prompt.SurveyAskOne(&survey.Input{Message: msg}, &repoURL,
+			survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(gt.IOStream.Out, "✓ Setting up Forgejo Webhook for Repository %s\n", repoURL)
+	}
+
+	owner, repo, err := formatting.GetRepoOwnerSplitted(repoURL)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(owner, "/") {
+		return fmt.Errorf("invalid repository, needs to be of format 'org-name/repo-name'")
+	}
+	gt.repoOwner = owner
+	gt.repoName = strings.TrimSuffix(repo, ".git")
+
+	gt.controllerURL = controllerURL
+	if err := askControllerURL(gt.IOStream, gt.nonInteractive, &gt.controllerURL); err != nil {
+		return err
+	}
+
+	if err := askWebhookSecret(gt.nonInteractive, &gt.webhookSecret); err != nil {
+		return err
+	}
+
+	if personalAccessToken == "" {
+		if gt.nonInteractive {
+			return missingValueError("the Forgejo access token")
+		}
+		fmt.Fprintln(gt.IOStream.Out, "ℹ ️You now need to create a Forgejo access token with the write:repository, write:issue and read:user scopes")
+		if err := prompt.SurveyAskOne(&survey.Password{
+			Message: "Please enter the Forgejo access token: ",
+		}, &gt.personalAccessToken, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	} else {
+		gt.personalAccessToken = personalAccessToken
+	}
+
+	gt.APIURL = apiURL
+	if gt.APIURL == "" {
+		// the instance is most of the time served at the root of the host
+		defaultURL, err := hostURL(repoURL)
+		if err != nil {
+			return err
+		}
+		if !gt.nonInteractive {
+			if err := prompt.SurveyAskOne(&survey.Input{
+				Message: fmt.Sprintf("Please enter the URL of your Forgejo instance (default: %s): ", defaultURL),
+				Default: defaultURL,
+			}, &gt.APIURL); err != nil {
+				return err
+			}
+		}
+		if gt.APIURL == "" {
+			gt.APIURL = defaultURL
+		}
+	}
+
+	return nil
+}
+
+// validateToken checks the token against the API before it gets used or stored.
+func (gt *giteaConfig) validateToken(ctx context.Context) error {
+	client, err := gt.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	user, _, err := client.GetMyUserInfo()
+	if err != nil {
+		return fmt.Errorf("cannot validate the Forgejo access token: %w", err)
+	}
+	fmt.Fprintf(gt.IOStream.Out, "✓ Forgejo access token validated for user %s\n", user.UserName)
+	return nil
+}
+
+func (gt *giteaConfig) create(ctx context.Context) error {
+	client, err := gt.newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, resp, err := client.CreateRepoHook(gt.repoOwner, gt.repoName, forgejo.CreateHookOption{
+		// Forgejo keeps sending the Gitea headers the controller detects
+		Type: forgejo.HookTypeGitea,
+		Config: map[string]string{
+			"url":          gt.controllerURL,
+			"content_type": "json",
+			"http_method":  "post",
+			"secret":       gt.webhookSecret,
+		},
+		Events: giteaHookEvents,
+		Active: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook on repository %v/%v: %w", gt.repoOwner, gt.repoName, err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to create webhook on repository %v/%v, status code: %v",
+			gt.repoOwner, gt.repoName, resp.StatusCode)
+	}
+
+	fmt.Fprintf(gt.IOStream.Out, "✓ Webhook has been created on repository %v/%v\n", gt.repoOwner, gt.repoName)
+	return nil
+}
+
+func (gt *giteaConfig) newClient(ctx context.Context) (*forgejo.Client, error) {
+	if gt.Client != nil {
+		return gt.Client, nil
+	}
+	client, err := forgejo.NewClient(gt.APIURL, forgejo.SetToken(gt.personalAccessToken), forgejo.SetContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	gt.Client = client
+	return client, nil
+}
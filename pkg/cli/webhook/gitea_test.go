@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v3"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	giteatest "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestAskGiteaWebhookConfig(t *testing.T) {
+	//nolint
+	io, _, _, _ := cli.IOTest()
+	tests := []struct {
+		name                string
+		wantErrStr          string
+		askStubs            func(*prompt.AskStubber)
+		repoURL             string
+		controllerURL       string
+		apiURL              string
+		personalaccesstoken string
+		nonInteractive      bool
+		wantAPIURL          string
+	}{
+		{
+			name: "ask all details no defaults",
+			askStubs: func(as *prompt.AskStubber) {
+				as.StubOne("https://forgejo.example.com/pac/test")
+				as.StubOne("https://controller.url")
+				as.StubOne("webhook-secret")
+				as.StubOne("token")
+				as.StubOne("https://forgejo.example.com/forge")
+			},
+			wantAPIURL: "https://forgejo.example.com/forge",
+		},
+		{
+			name: "with defaults",
+			askStubs: func(as *prompt.AskStubber) {
+				as.StubOne(true)
+				as.StubOne("webhook-secret")
+				as.StubOne("")
+			},
+			repoURL:             "https://forgejo.example.com/pac/demo",
+			controllerURL:       "https://test",
+			personalaccesstoken: "token",
+			wantAPIURL:          "https://forgejo.example.com",
+		},
+		{
+			name:                "non interactive",
+			repoURL:             "https://forgejo.example.com/pac/demo",
+			controllerURL:       "https://test",
+			personalaccesstoken: "token",
+			nonInteractive:      true,
+			wantAPIURL:          "https://forgejo.example.com",
+		},
+		{
+			name:           "non interactive without token",
+			repoURL:        "https://forgejo.example.com/pac/demo",
+			controllerURL:  "https://test",
+			nonInteractive: true,
+			wantErrStr:     "the Forgejo access token is required in non-interactive mode",
+		},
+		{
+			name:                "non interactive without controller url",
+			repoURL:             "https://forgejo.example.com/pac/demo",
+			personalaccesstoken: "token",
+			nonInteractive:      true,
+			wantErrStr:          "the controller url is required in non-interactive mode",
+		},
+		{
+			name:       "repository in a sub group",
+			repoURL:    "https://forgejo.example.com/pac/group/demo",
+			wantErrStr: "invalid repository, needs to be of format 'org-name/repo-name'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as, teardown := prompt.InitAskStubber()
+			defer teardown()
+			if tt.askStubs != nil {
+				tt.askStubs(as)
+			}
+			gt := giteaConfig{IOStream: io, nonInteractive: tt.nonInteractive}
+			err := gt.askGiteaWebhookConfig(tt.repoURL, tt.controllerURL, tt.apiURL, tt.personalaccesstoken)
+			if tt.wantErrStr != "" {
+				assert.Error(t, err, tt.wantErrStr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, gt.APIURL, tt.wantAPIURL)
+			assert.Assert(t, gt.webhookSecret != "")
+		})
+	}
+}
+
+func TestGiteaValidateToken(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, teardown := giteatest.Setup(t)
+	defer teardown()
+	//nolint
+	io, _, out, _ := cli.IOTest()
+
+	mux.HandleFunc("/user", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"login": "pac"}`)
+	})
+
+	gt := giteaConfig{IOStream: io, Client: fakeclient}
+	assert.NilError(t, gt.validateToken(ctx))
+	assert.Equal(t, out.String(), "✓ Forgejo access token validated for user pac\n")
+}
+
+func TestGiteaCreate(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, teardown := giteatest.Setup(t)
+	defer teardown()
+	//nolint
+	io, _, _, _ := cli.IOTest()
+
+	mux.HandleFunc("/repos/pac/created/hooks", func(w http.ResponseWriter, r *http.Request) {
+		hook := forgejo.CreateHookOption{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&hook))
+		assert.Equal(t, hook.Type, forgejo.HookTypeGitea)
+		assert.Equal(t, hook.Config["url"], "https://controller.url")
+		assert.Equal(t, hook.Config["secret"], "webhook-secret")
+		assert.DeepEqual(t, hook.Events, giteaHookEvents)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"id": 1}`)
+	})
+	mux.HandleFunc("/repos/pac/forbidden/hooks", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprint(w, `{"message": "forbidden"}`)
+	})
+
+	tests := []struct {
+		name     string
+		repoName string
+		wantErr  bool
+	}{
+		{
+			name:     "webhook created",
+			repoName: "created",
+		},
+		{
+			name:     "webhook failed",
+			repoName: "forbidden",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gt := giteaConfig{
+				IOStream:      io,
+				Client:        fakeclient,
+				repoOwner:     "pac",
+				repoName:      tt.repoName,
+				controllerURL: "https://controller.url",
+				webhookSecret: "webhook-secret",
+			}
+			err := gt.create(ctx)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -14,13 +14,13 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
-	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
 	"golang.org/x/oauth2"
 )
 
 type gitHubConfig struct {
 	Client              *github.Client
 	IOStream            *cli.IOStreams
+	nonInteractive      bool
 	controllerURL       string
 	repoOwner           string
 	repoName            string
@@ -30,10 +30,15 @@ type gitHubConfig struct {
 }
 
 func (gh *gitHubConfig) Run(ctx context.Context, opts *Options) (*response, error) {
+	gh.nonInteractive = opts.NonInteractive
+	gh.webhookSecret = opts.WebhookSecret
 	err := gh.askGHWebhookConfig(opts.RepositoryURL, opts.ControllerURL, opts.ProviderAPIURL, opts.PersonalAccessToken)
 	if err != nil {
 		return nil, err
 	}
+	if err := gh.validateToken(ctx); err != nil {
+		return nil, err
+	}
 
 	return &response{
 		ControllerURL:       gh.controllerURL,
@@ -68,43 +73,20 @@ func (gh *gitHubConfig) askGHWebhookConfig(repoURL, controllerURL, apiURL, perso
 	gh.repoOwner = repoArr[0]
 	gh.repoName = repoArr[1]
 
-	// set controller url
+	// set controller url and confirm whether to use the detected one
 	gh.controllerURL = controllerURL
-
-	// confirm whether to use the detected url
-	if gh.controllerURL != "" {
-		var answer bool
-		fmt.Fprintf(gh.IOStream.Out, "👀 I have detected a controller url: %s\n", gh.controllerURL)
-		err := prompt.SurveyAskOne(&survey.Confirm{
-			Message: "Do you want me to use it?",
-			Default: true,
-		}, &answer)
-		if err != nil {
-			return err
-		}
-		if !answer {
-			gh.controllerURL = ""
-		}
-	}
-
-	if gh.controllerURL == "" {
-		if err := prompt.SurveyAskOne(&survey.Input{
-			Message: "Please enter your controller public route URL: ",
-		}, &gh.controllerURL, survey.WithValidator(survey.Required)); err != nil {
-			return err
-		}
+	if err := askControllerURL(gh.IOStream, gh.nonInteractive, &gh.controllerURL); err != nil {
+		return err
 	}
 
-	data := random.AlphaString(12)
-	msg := fmt.Sprintf("Please enter the secret to configure the webhook for payload validation (default: %s): ", data)
-	var webhookSecret string
-	if err := prompt.SurveyAskOne(&survey.Input{Message: msg, Default: data}, &webhookSecret); err != nil {
+	if err := askWebhookSecret(gh.nonInteractive, &gh.webhookSecret); err != nil {
 		return err
 	}
 
-	gh.webhookSecret = webhookSecret
-
 	if personalAccessToken == "" {
+		if gh.nonInteractive {
+			return missingValueError("the GitHub access token")
+		}
 		fmt.Fprintln(gh.IOStream.Out, "ℹ ️You now need to create a GitHub personal access token, please checkout the docs at https://is.gd/KJ1dDH for the required scopes")
 		if err := prompt.SurveyAskOne(&survey.Password{
 			Message: "Please enter the GitHub access token: ",
@@ -116,6 +98,9 @@ func (gh *gitHubConfig) askGHWebhookConfig(repoURL, controllerURL, apiURL, perso
 	}
 
 	if apiURL == "" && !strings.HasPrefix(repoURL, "https://github.com") {
+		if gh.nonInteractive {
+			return missingValueError("the GitHub enterprise API URL")
+		}
 		if err := prompt.SurveyAskOne(&survey.Input{
 			Message: "Please enter your GitHub enterprise API URL: ",
 		}, &gh.APIURL, survey.WithValidator(survey.Required)); err != nil {
@@ -169,6 +154,20 @@ func (gh *gitHubConfig) create(ctx context.Context) error {
 	return nil
 }
 
+// validateToken checks the token against the API before it gets used or stored.
+func (gh *gitHubConfig) validateToken(ctx context.Context) error {
+	ghClient, err := gh.newGHClientByToken(ctx)
+	if err != nil {
+		return err
+	}
+	user, _, err := ghClient.Users.Get(ctx, "")
+	if err != nil {
+		return fmt.Errorf("cannot validate the GitHub access token: %w", err)
+	}
+	fmt.Fprintf(gh.IOStream.Out, "✓ GitHub access token validated for user %s\n", user.GetLogin())
+	return nil
+}
+
 func (gh *gitHubConfig) newGHClientByToken(ctx context.Context) (*github.Client, error) {
 	if gh.Client != nil {
 		return gh.Client, nil
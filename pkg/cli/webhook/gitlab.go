@@ -9,13 +9,14 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
-	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
 type gitLabConfig struct {
 	Client              *gitlab.Client
 	IOStream            *cli.IOStreams
+	nonInteractive      bool
 	controllerURL       string
 	projectID           string
 	webhookSecret       string
@@ -24,10 +25,16 @@ type gitLabConfig struct {
 }
 
 func (gl *gitLabConfig) Run(_ context.Context, opts *Options) (*response, error) {
+	gl.nonInteractive = opts.NonInteractive
+	gl.projectID = opts.ProjectID
+	gl.webhookSecret = opts.WebhookSecret
 	err := gl.askGLWebhookConfig(opts.RepositoryURL, opts.ControllerURL, opts.ProviderAPIURL, opts.PersonalAccessToken)
 	if err != nil {
 		return nil, err
 	}
+	if err := gl.validateToken(); err != nil {
+		return nil, err
+	}
 
 	return &response{
 		ControllerURL:       gl.controllerURL,
@@ -49,47 +56,36 @@ func (gl *gitLabConfig) askGLWebhookConfig(repoURL, controllerURL, apiURL, perso
 		fmt.Fprintf(gl.IOStream.Out, "✓ Setting up GitLab Webhook for Repository %s\n", repoURL)
 	}
 
-	msg := "Enter the project ID of your GitLab repository.\nThe project ID is a unique number (e.g. 34405323) shown at the top of your GitLab project page: "
-	if err := prompt.SurveyAskOne(&survey.Input{Message: msg}, &gl.projectID,
-		survey.WithValidator(survey.Required)); err != nil {
-		return err
-	}
-
-	gl.controllerURL = controllerURL
-
-	if gl.controllerURL != "" {
-		var answer bool
-		fmt.Fprintf(gl.IOStream.Out, "👀 Controller URL detected: %s\n", gl.controllerURL)
-		err := prompt.SurveyAskOne(&survey.Confirm{
-			Message: "Do you want me to use it?",
-			Default: true,
-		}, &answer)
+	switch {
+	case gl.projectID != "":
+	case gl.nonInteractive:
+		// the API accepts the path of the project as well as its ID
+		projectPath, err := formatting.GetRepoOwnerFromURL(repoURL)
 		if err != nil {
 			return err
 		}
-		if !answer {
-			gl.controllerURL = ""
-		}
-	}
-
-	if gl.controllerURL == "" {
-		if err := prompt.SurveyAskOne(&survey.Input{
-			Message: "Enter your controller's public route URL: ",
-		}, &gl.controllerURL, survey.WithValidator(survey.Required)); err != nil {
+		gl.projectID = projectPath
+	default:
+		msg := "Enter the project ID of your GitLab repository.\nThe project ID is a unique number (e.g. 34405323) shown at the top of your GitLab project page: "
+		if err := prompt.SurveyAskOne(&survey.Input{Message: msg}, &gl.projectID,
+			survey.WithValidator(survey.Required)); err != nil {
 			return err
 		}
 	}
 
-	data := random.AlphaString(12)
-	msg = fmt.Sprintf("Enter a secret for webhook payload validation (default: %s): ", data)
-	var webhookSecret string
-	if err := prompt.SurveyAskOne(&survey.Input{Message: msg, Default: data}, &webhookSecret); err != nil {
+	gl.controllerURL = controllerURL
+	if err := askControllerURL(gl.IOStream, gl.nonInteractive, &gl.controllerURL); err != nil {
 		return err
 	}
 
-	gl.webhookSecret = webhookSecret
+	if err := askWebhookSecret(gl.nonInteractive, &gl.webhookSecret); err != nil {
+		return err
+	}
 
 	if personalAccessToken == "" {
+		if gl.nonInteractive {
+			return missingValueError("the GitLab access token")
+		}
 		fmt.Fprintln(gl.IOStream.Out, "ℹ ️You need to create a GitLab personal access token with 'api' scope")
 		fmt.Fprintln(gl.IOStream.Out, "ℹ ️Generate one at https://gitlab.com/-/profile/personal_access_tokens (see documentation: https://is.gd/rOEo9B)")
 		if err := prompt.SurveyAskOne(&survey.Password{
@@ -101,13 +97,20 @@ func (gl *gitLabConfig) askGLWebhookConfig(repoURL, controllerURL, apiURL, perso
 		gl.personalAccessToken = personalAccessToken
 	}
 
-	if apiURL == "" {
+	switch {
+	case apiURL == "" && gl.nonInteractive:
+		baseURL, err := hostURL(repoURL)
+		if err != nil {
+			return err
+		}
+		gl.APIURL = baseURL
+	case apiURL == "":
 		if err := prompt.SurveyAskOne(&survey.Input{
 			Message: "Enter your GitLab API URL: ",
 		}, &gl.APIURL, survey.WithValidator(survey.Required)); err != nil {
 			return err
 		}
-	} else {
+	default:
 		gl.APIURL = apiURL
 	}
 
@@ -149,6 +152,20 @@ func (gl *gitLabConfig) create() error {
 	return nil
 }
 
+// validateToken checks the token against the API before it gets used or stored.
+func (gl *gitLabConfig) validateToken() error {
+	glClient, err := gl.newClient()
+	if err != nil {
+		return err
+	}
+	user, _, err := glClient.Users.CurrentUser()
+	if err != nil {
+		return fmt.Errorf("cannot validate the GitLab access token: %w", err)
+	}
+	fmt.Fprintf(gl.IOStream.Out, "✓ GitLab access token validated for user %s\n", user.Username)
+	return nil
+}
+
 func (gl *gitLabConfig) newClient() (*gitlab.Client, error) {
 	if gl.Client != nil {
 		return gl.Client, nil
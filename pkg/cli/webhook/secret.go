@@ -72,6 +72,10 @@ func (w *Options) updateRepositoryCR(ctx context.Context, res *response) error {
 		repo.Spec.GitProvider.URL = res.APIURL
 	}
 
+	if res.ProviderType != "" {
+		repo.Spec.GitProvider.Type = res.ProviderType
+	}
+
 	_, err = w.Run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(w.RepositoryNamespace).
 		Update(ctx, repo, metav1.UpdateOptions{})
 	if err != nil {
@@ -3,6 +3,7 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -11,6 +12,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/bootstrap"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
 )
 
 type Interface interface {
@@ -30,6 +32,11 @@ type Options struct {
 	RepositoryCreateORUpdate bool
 	SecretName               string
 	ProviderSecretKey        string
+	// NonInteractive fails on the missing values instead of asking for them.
+	NonInteractive bool
+	WebhookSecret  string
+	UserName       string
+	ProjectID      string
 }
 
 type response struct {
@@ -38,6 +45,7 @@ type response struct {
 	WebhookSecret       string
 	PersonalAccessToken string
 	APIURL              string
+	ProviderType        string
 }
 
 func (w *Options) Install(ctx context.Context, providerType string) error {
@@ -57,13 +65,18 @@ func (w *Options) Install(ctx context.Context, providerType string) error {
 	}
 
 	// check if info configmap has url then use that otherwise try to detect
-	if pacInfo.ControllerURL != "" && w.ControllerURL == "" {
-		w.ControllerURL = pacInfo.ControllerURL
-	} else {
-		w.ControllerURL, _ = bootstrap.DetectOpenShiftRoute(ctx, w.Run, w.PACNamespace)
+	if w.ControllerURL == "" {
+		if pacInfo.ControllerURL != "" {
+			w.ControllerURL = pacInfo.ControllerURL
+		} else {
+			w.ControllerURL, _ = bootstrap.DetectOpenShiftRoute(ctx, w.Run, installationNS)
+		}
 	}
 
 	if w.RepositoryURL == "" {
+		if w.NonInteractive {
+			return missingValueError("the git repository url")
+		}
 		q := "Please enter the Git repository url: "
 		if err := prompt.SurveyAskOne(&survey.Input{Message: q}, &w.RepositoryURL,
 			survey.WithValidator(survey.Required)); err != nil {
@@ -79,6 +92,10 @@ func (w *Options) Install(ctx context.Context, providerType string) error {
 		webhookProvider = &gitLabConfig{IOStream: w.IOStreams}
 	case "bitbucket-cloud":
 		webhookProvider = &bitbucketCloudConfig{IOStream: w.IOStreams}
+	case "bitbucket-datacenter":
+		webhookProvider = &bitbucketDataCenterConfig{IOStream: w.IOStreams}
+	case "gitea", "forgejo":
+		webhookProvider = &giteaConfig{IOStream: w.IOStreams, providerType: providerType}
 	default:
 		return fmt.Errorf("invalid webhook provider")
 	}
@@ -102,28 +119,105 @@ func (w *Options) Install(ctx context.Context, providerType string) error {
 	return w.updateRepositoryCR(ctx, response)
 }
 
-func GetProviderName(url string) (string, error) {
-	var (
-		err          error
-		providerName string
-	)
+// DetectProviderName guesses the git platform from the repository url, it
+// returns an empty string when it cannot be guessed.
+func DetectProviderName(url string) string {
 	switch {
 	case strings.Contains(url, "github"):
-		providerName = "github"
+		return "github"
 	case strings.Contains(url, "gitlab"):
-		providerName = "gitlab"
+		return "gitlab"
 	case strings.Contains(url, "bitbucket-cloud"):
-		providerName = "bitbucket-cloud"
-	default:
-		msg := "Please select the type of the git platform to setup webhook:"
-		if err = prompt.SurveyAskOne(
-			&survey.Select{
-				Message: msg,
-				Options: []string{"github", "gitlab", "bitbucket-cloud"},
-				Default: 0,
-			}, &providerName); err != nil {
-			return "", err
-		}
+		return "bitbucket-cloud"
+	case strings.Contains(url, "forgejo"), strings.Contains(url, "codeberg.org"):
+		return "forgejo"
+	case strings.Contains(url, "gitea"):
+		return "gitea"
+	case strings.Contains(url, "/projects/") && strings.Contains(url, "/repos/"):
+		return "bitbucket-datacenter"
+	}
+	return ""
+}
+
+func GetProviderName(url string) (string, error) {
+	providerName := DetectProviderName(url)
+	if providerName != "" {
+		return providerName, nil
+	}
+	msg := "Please select the type of the git platform to setup webhook:"
+	if err := prompt.SurveyAskOne(
+		&survey.Select{
+			Message: msg,
+			Options: []string{"github", "gitlab", "bitbucket-cloud", "bitbucket-datacenter", "forgejo"},
+			Default: 0,
+		}, &providerName); err != nil {
+		return "", err
 	}
 	return providerName, nil
 }
+
+// missingValueError is returned when a value that would have been asked is
+// missing in non-interactive mode.
+func missingValueError(what string) error {
+	return fmt.Errorf("%s is required in non-interactive mode", what)
+}
+
+// askControllerURL confirms the detected controller url or asks for it.
+func askControllerURL(ioStreams *cli.IOStreams, nonInteractive bool, controllerURL *string) error {
+	if *controllerURL != "" && !nonInteractive {
+		var answer bool
+		fmt.Fprintf(ioStreams.Out, "👀 I have detected a controller url: %s\n", *controllerURL)
+		if err := prompt.SurveyAskOne(&survey.Confirm{
+			Message: "Do you want me to use it?",
+			Default: true,
+		}, &answer); err != nil {
+			return err
+		}
+		if !answer {
+			*controllerURL = ""
+		}
+	}
+
+	if *controllerURL != "" {
+		return nil
+	}
+	if nonInteractive {
+		return missingValueError("the controller url")
+	}
+	return prompt.SurveyAskOne(&survey.Input{
+		Message: "Please enter your controller public route URL: ",
+	}, controllerURL, survey.WithValidator(survey.Required))
+}
+
+// askWebhookSecret asks for the secret of the webhook proposing a random one,
+// which is used as is in non-interactive mode.
+func askWebhookSecret(nonInteractive bool, webhookSecret *string) error {
+	if *webhookSecret != "" {
+		return nil
+	}
+	data := random.AlphaString(12)
+	if nonInteractive {
+		*webhookSecret = data
+		return nil
+	}
+	msg := fmt.Sprintf("Please enter the secret to configure the webhook for payload validation (default: %s): ", data)
+	if err := prompt.SurveyAskOne(&survey.Input{Message: msg, Default: data}, webhookSecret); err != nil {
+		return err
+	}
+	if *webhookSecret == "" {
+		*webhookSecret = data
+	}
+	return nil
+}
+
+// hostURL returns the scheme and the host of a repository url.
+func hostURL(repoURL string) (string, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid repository url: %s", repoURL)
+	}
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host), nil
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"gotest.tools/v3/assert"
+)
+
+func TestDetectProviderName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://github.com/owner/repo", want: "github"},
+		{url: "https://gitlab.com/group/sub/repo", want: "gitlab"},
+		{url: "https://codeberg.org/owner/repo", want: "forgejo"},
+		{url: "https://forgejo.example.com/owner/repo", want: "forgejo"},
+		{url: "https://gitea.example.com/owner/repo", want: "gitea"},
+		{url: "https://bitbucket.example.com/projects/PAC/repos/repo", want: "bitbucket-datacenter"},
+		{url: "https://git.example.com/owner/repo", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, DetectProviderName(tt.url), tt.want)
+		})
+	}
+}
+
+func TestNonInteractiveGLWebhookConfig(t *testing.T) {
+	//nolint
+	io, _, _, _ := cli.IOTest()
+	gl := gitLabConfig{IOStream: io, nonInteractive: true}
+	err := gl.askGLWebhookConfig("https://gitlab.example.com/group/sub/repo", "https://controller.url", "", "token")
+	assert.NilError(t, err)
+	assert.Equal(t, gl.projectID, "group/sub/repo")
+	assert.Equal(t, gl.APIURL, "https://gitlab.example.com")
+	assert.Assert(t, gl.webhookSecret != "")
+
+	gl = gitLabConfig{IOStream: io, nonInteractive: true}
+	err = gl.askGLWebhookConfig("https://gitlab.example.com/group/repo", "https://controller.url", "", "")
+	assert.Error(t, err, "the GitLab access token is required in non-interactive mode")
+}
@@ -537,6 +537,66 @@ func detectProvider(headers map[string]string, body []byte) (string, error) {
 	return "", fmt.Errorf("unable to detect provider from headers or payload")
 }
 
+// ParseHeaders parses the headers of a webhook request, as JSON, plain HTTP
+// headers or a gosmee-generated shell script.
+func ParseHeaders(b []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	bs := bytes.TrimSpace(b)
+	switch {
+	case len(bs) > 0 && (bs[0] == '{' || bs[0] == '['):
+		// JSON format headers
+		if err := json.Unmarshal(bs, &headers); err != nil {
+			return nil, err
+		}
+		return headers, nil
+	case isGosmeeScript(string(bs)):
+		// Gosmee-generated shell script with curl commands
+		return parseGosmeeScript(string(bs))
+	default:
+		// Plain HTTP headers format
+		return parseHTTPHeaders(string(bs))
+	}
+}
+
+// EventFromPayload parses the webhook payload of the provider into an event,
+// the provider is detected from the headers and the payload when it is
+// "auto". It returns the event and the provider of the payload, the GitHub
+// token is optional and used to enrich the GitHub events.
+func EventFromPayload(provider string, bodyBytes []byte, headers map[string]string, githubToken string) (*info.Event, string, error) {
+	if provider == "auto" {
+		detectedProvider, err := detectProvider(headers, bodyBytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("auto-detection failed: %w", err)
+		}
+		provider = detectedProvider
+	}
+
+	var event *info.Event
+	var err error
+	switch provider {
+	case "github":
+		if githubToken != "" {
+			event, err = eventFromGitHubWithToken(bodyBytes, headers, githubToken)
+		} else {
+			event, err = eventFromGitHub(bodyBytes, headers)
+		}
+	case "gitlab":
+		event, err = eventFromGitLab(bodyBytes, headers)
+	case "bitbucket-cloud":
+		event, err = eventFromBitbucketCloud(bodyBytes, headers)
+	case "bitbucket-datacenter":
+		event, err = eventFromBitbucketDataCenter(bodyBytes, headers)
+	case "gitea", "forgejo":
+		event, err = eventFromGitea(bodyBytes, headers)
+	default:
+		return nil, "", fmt.Errorf("unsupported provider %s", provider)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return event, provider, nil
+}
+
 func Command(ioStreams *cli.IOStreams) *cobra.Command {
 	var bodyFile, headersFile, provider, githubToken string
 
@@ -571,80 +631,17 @@ that would be used in PipelineRun configurations.`,
 				if err != nil {
 					return err
 				}
-				bs := bytes.TrimSpace(b)
-				switch {
-				case len(bs) > 0 && (bs[0] == '{' || bs[0] == '['):
-					// JSON format headers
-					if err := json.Unmarshal(bs, &headers); err != nil {
-						return err
-					}
-				case isGosmeeScript(string(bs)):
-					// Gosmee-generated shell script with curl commands
-					h, err := parseGosmeeScript(string(bs))
-					if err != nil {
-						return err
-					}
-					headers = h
-				default:
-					// Plain HTTP headers format
-					h, err := parseHTTPHeaders(string(bs))
-					if err != nil {
-						return err
-					}
-					headers = h
-				}
-			}
-			// nolint:ineffassign,staticcheck
-			pacParams := map[string]string{}
-			// Auto-detect provider if not specified explicitly
-			if provider == "auto" {
-				detectedProvider, err := detectProvider(headers, bodyBytes)
-				if err != nil {
-					return fmt.Errorf("auto-detection failed: %w", err)
+				if headers, err = ParseHeaders(b); err != nil {
+					return err
 				}
-				provider = detectedProvider
 			}
 
-			switch provider {
-			case "github":
-				var event *info.Event
-				var err error
-				if githubToken != "" {
-					event, err = eventFromGitHubWithToken(bodyBytes, headers, githubToken)
-				} else {
-					event, err = eventFromGitHub(bodyBytes, headers)
-				}
-				if err != nil {
-					return err
-				}
-				pacParams = pacParamsFromEvent(event)
-			case "gitlab":
-				event, err := eventFromGitLab(bodyBytes, headers)
-				if err != nil {
-					return err
-				}
-				pacParams = pacParamsFromEvent(event)
-			case "bitbucket-cloud":
-				event, err := eventFromBitbucketCloud(bodyBytes, headers)
-				if err != nil {
-					return err
-				}
-				pacParams = pacParamsFromEvent(event)
-			case "bitbucket-datacenter":
-				event, err := eventFromBitbucketDataCenter(bodyBytes, headers)
-				if err != nil {
-					return err
-				}
-				pacParams = pacParamsFromEvent(event)
-			case "gitea", "forgejo":
-				event, err := eventFromGitea(bodyBytes, headers)
-				if err != nil {
-					return err
-				}
-				pacParams = pacParamsFromEvent(event)
-			default:
-				return fmt.Errorf("unsupported provider %s", provider)
+			event, detectedProvider, err := EventFromPayload(provider, bodyBytes, headers, githubToken)
+			if err != nil {
+				return err
 			}
+			provider = detectedProvider
+			pacParams := pacParamsFromEvent(event)
 
 			// Display warning banner about CLI vs live payload differences
 			printBanner(ioStreams, provider, bodyFile, headersFile)
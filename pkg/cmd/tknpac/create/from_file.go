@@ -0,0 +1,243 @@
+package create
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	pacInfo "github.com/openshift-pipelines/pipelines-as-code/pkg/cli/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/webhook"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/bootstrap"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// manifestRepository is a repository of the manifest passed to --from-file,
+// the empty fields are taken from the defaults of the manifest.
+type manifestRepository struct {
+	Name          string `json:"name,omitempty"`
+	URL           string `json:"url,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	APIURL        string `json:"apiURL,omitempty"`
+	ControllerURL string `json:"controllerURL,omitempty"`
+	User          string `json:"user,omitempty"`
+	Token         string `json:"token,omitempty"`
+	TokenEnv      string `json:"tokenEnv,omitempty"`
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+	ProjectID     string `json:"projectID,omitempty"`
+	Webhook       *bool  `json:"webhook,omitempty"`
+}
+
+type manifest struct {
+	Defaults     manifestRepository   `json:"defaults,omitempty"`
+	Repositories []manifestRepository `json:"repositories"`
+}
+
+// withDefaults returns the repository with its empty fields set from the
+// defaults, the name and the url are specific to each repository.
+func (m manifestRepository) withDefaults(d manifestRepository) manifestRepository {
+	set := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+	set(&m.Namespace, d.Namespace)
+	set(&m.Provider, d.Provider)
+	set(&m.APIURL, d.APIURL)
+	set(&m.ControllerURL, d.ControllerURL)
+	set(&m.User, d.User)
+	set(&m.Token, d.Token)
+	set(&m.TokenEnv, d.TokenEnv)
+	set(&m.WebhookSecret, d.WebhookSecret)
+	if m.Webhook == nil {
+		m.Webhook = d.Webhook
+	}
+	return m
+}
+
+func readManifest(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	if len(m.Repositories) == 0 {
+		return nil, fmt.Errorf("no repositories found in %s", path)
+	}
+	for i, repo := range m.Repositories {
+		if repo.URL == "" {
+			return nil, fmt.Errorf("repository #%d of %s has no url", i+1, path)
+		}
+	}
+	return m, nil
+}
+
+// createFromFile creates the Repository CRs and the webhooks of a manifest
+// without asking anything, a repository failing does not stop the others.
+func (r *RepoOptions) createFromFile(ctx context.Context, path string) error {
+	m, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+
+	installed, installationNS, err := bootstrap.DetectPacInstallation(ctx, r.pacNamespace, r.Run)
+	if !installed {
+		return fmt.Errorf("pipelines-as-code is not installed in the cluster")
+	}
+	if err != nil {
+		return err
+	}
+	githubAppInstalled := pacInfo.IsGithubAppInstalled(ctx, r.Run, installationNS)
+
+	cs := r.IoStreams.ColorScheme()
+	var created, skipped, failed int
+	for _, repo := range m.Repositories {
+		repo = repo.withDefaults(m.Defaults)
+		ok, err := r.createFromManifest(ctx, repo, githubAppInstalled)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(r.IoStreams.ErrOut, "%s Cannot create the repository %s: %s\n", cs.FailureIcon(), repo.URL, err.Error())
+		case !ok:
+			skipped++
+		default:
+			created++
+		}
+	}
+
+	fmt.Fprintf(r.IoStreams.Out, "%d repositories created, %d skipped, %d failed\n", created, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d repositories of %s could not be created", failed, len(m.Repositories), path)
+	}
+	return nil
+}
+
+// createFromManifest creates a repository of the manifest, it returns false
+// when the repository already exists with its webhook. The webhook of an
+// existing repository without one is added, the command can then be run
+// again after a webhook has failed.
+func (r *RepoOptions) createFromManifest(ctx context.Context, repo manifestRepository, githubAppInstalled bool) (bool, error) {
+	cs := r.IoStreams.ColorScheme()
+	namespace := repo.Namespace
+	if namespace == "" {
+		namespace = r.Run.Info.Kube.Namespace
+	}
+	name := repo.Name
+	if name == "" {
+		repoOwner, err := formatting.GetRepoOwnerFromURL(repo.URL)
+		if err != nil {
+			return false, fmt.Errorf("invalid git URL: %s, it should be of format: https://gitprovider/project/repository", repo.URL)
+		}
+		name = formatting.CleanKubernetesName(repoOwner)
+	}
+
+	// check everything needed for the webhook before creating anything
+	wantWebhook := repo.Webhook == nil || *repo.Webhook
+	existing, err := r.Run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil && (!wantWebhook || hasWebhook(existing) || existing.Spec.URL != repo.URL) {
+		fmt.Fprintf(r.IoStreams.Out, "%s Repository %s already exists in %s namespace, skipping\n", cs.WarningIcon(), name, namespace)
+		return false, nil
+	}
+	exists := err == nil
+	var token, provider string
+	if wantWebhook {
+		if provider = repo.Provider; provider == "" {
+			if provider = webhook.DetectProviderName(repo.URL); provider == "" {
+				return false, fmt.Errorf("cannot detect the git provider, set it with the provider field")
+			}
+		}
+		if provider == "github" && githubAppInstalled {
+			if exists {
+				fmt.Fprintf(r.IoStreams.Out, "%s Repository %s already exists in %s namespace, skipping\n", cs.WarningIcon(), name, namespace)
+				return false, nil
+			}
+			wantWebhook = false
+		}
+		token = repo.Token
+		if repo.TokenEnv != "" {
+			if token = os.Getenv(repo.TokenEnv); token == "" {
+				return false, fmt.Errorf("environment variable %s is not set", repo.TokenEnv)
+			}
+		}
+		if wantWebhook && token == "" {
+			return false, fmt.Errorf("no token to create the webhook, set token or tokenEnv, or webhook to false")
+		}
+	}
+
+	if exists {
+		fmt.Fprintf(r.IoStreams.Out, "%s Repository %s already exists in %s namespace without its webhook, adding it\n", cs.InfoIcon(), name, namespace)
+	} else {
+		if err := ensureNamespace(ctx, r, namespace); err != nil {
+			return false, err
+		}
+		opts := &RepoOptions{
+			Event:      info.NewEvent(),
+			Repository: &apipac.Repository{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+			Run:        r.Run,
+			IoStreams:  r.IoStreams,
+		}
+		opts.Event.URL = repo.URL
+		if _, _, err := createRepoCRD(ctx, opts); err != nil {
+			return false, err
+		}
+	}
+	if !wantWebhook {
+		return true, nil
+	}
+
+	config := &webhook.Options{
+		Run:                      r.Run,
+		IOStreams:                r.IoStreams,
+		PACNamespace:             r.pacNamespace,
+		RepositoryURL:            repo.URL,
+		RepositoryName:           name,
+		RepositoryNamespace:      namespace,
+		ProviderAPIURL:           strings.TrimSuffix(repo.APIURL, "/"),
+		ControllerURL:            repo.ControllerURL,
+		PersonalAccessToken:      token,
+		RepositoryCreateORUpdate: true,
+		NonInteractive:           true,
+		WebhookSecret:            repo.WebhookSecret,
+		UserName:                 repo.User,
+		ProjectID:                repo.ProjectID,
+	}
+	if err := config.Install(ctx, provider); err != nil {
+		return true, fmt.Errorf("repository %s is in %s namespace without its webhook, run the command again or add it with \"%s pac webhook add\": %w",
+			name, namespace, settings.TknBinaryName, err)
+	}
+	return true, nil
+}
+
+// hasWebhook returns true if the webhook secret of the repository is set,
+// which is done once its webhook has been created.
+func hasWebhook(repo *apipac.Repository) bool {
+	return repo.Spec.GitProvider != nil && repo.Spec.GitProvider.WebhookSecret != nil
+}
+
+// ensureNamespace creates the namespace when it does not exist yet.
+func ensureNamespace(ctx context.Context, r *RepoOptions, namespace string) error {
+	_, err := r.Run.Clients.Kube.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	if _, err := r.Run.Clients.Kube.CoreV1().Namespaces().Create(ctx,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.IoStreams.Out, "%s Namespace %s has been created\n", r.IoStreams.ColorScheme().SuccessIcon(), namespace)
+	return nil
+}
@@ -0,0 +1,176 @@
+package create
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"gotest.tools/v3/assert"
+	testfs "gotest.tools/v3/fs"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			content: "defaults:\n  namespace: ns\nrepositories:\n- url: https://forge/owner/repo\n",
+		},
+		{
+			name:    "unknown field",
+			content: "repositories:\n- url: https://forge/owner/repo\n  tokn: typo\n",
+			wantErr: `cannot parse {{PATH}}: error unmarshaling JSON: while decoding JSON: json: unknown field "tokn"`,
+		},
+		{
+			name:    "no repositories",
+			content: "defaults:\n  namespace: ns\n",
+			wantErr: "no repositories found in {{PATH}}",
+		},
+		{
+			name:    "repository without url",
+			content: "repositories:\n- name: repo\n",
+			wantErr: "repository #1 of {{PATH}} has no url",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testfs.NewDir(t, "manifest", testfs.WithFile("repositories.yaml", tt.content))
+			defer dir.Remove()
+			path := filepath.Join(dir.Path(), "repositories.yaml")
+			_, err := readManifest(path)
+			if tt.wantErr != "" {
+				assert.Error(t, err, strings.ReplaceAll(tt.wantErr, "{{PATH}}", path))
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestCreateFromFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/version", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"version": "1.17.0"}`)
+	})
+	mux.HandleFunc("/api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Authorization"), "token forgejo-token")
+		_, _ = fmt.Fprint(w, `{"login": "pac"}`)
+	})
+	hooks := []string{}
+	for _, repo := range []string{"repo", "existing"} {
+		mux.HandleFunc("/api/v1/repos/owner/"+repo+"/hooks", func(w http.ResponseWriter, _ *http.Request) {
+			hooks = append(hooks, repo)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprint(w, `{"id": 1}`)
+		})
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("PAC_TEST_FORGEJO_TOKEN", "forgejo-token")
+
+	manifest := fmt.Sprintf(`defaults:
+  namespace: ns
+  provider: forgejo
+  apiURL: %s
+  controllerURL: https://controller.url
+  tokenEnv: PAC_TEST_FORGEJO_TOKEN
+repositories:
+- url: https://forgejo.example.com/owner/repo
+- url: https://forgejo.example.com/owner/existing
+- url: https://forgejo.example.com/owner/configured
+- url: https://forgejo.example.com/owner/other
+  name: cr-only
+  namespace: other
+  webhook: false
+- url: https://forgejo.example.com/owner/unset
+  tokenEnv: PAC_TEST_UNSET_TOKEN
+`, server.URL)
+	dir := testfs.NewDir(t, "manifest", testfs.WithFile("repositories.yaml", manifest))
+	defer dir.Remove()
+	path := filepath.Join(dir.Path(), "repositories.yaml")
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+		Namespaces: []*corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "ns"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "pipelines-as-code"}},
+		},
+		ConfigMap: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipelines-as-code-info", Namespace: "pipelines-as-code"},
+			Data:       map[string]string{"version": "devel"},
+		}},
+		Repositories: []*apipac.Repository{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "owner-existing", Namespace: "ns"},
+				Spec:       apipac.RepositorySpec{URL: "https://forgejo.example.com/owner/existing"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "owner-configured", Namespace: "ns"},
+				Spec: apipac.RepositorySpec{
+					URL:         "https://forgejo.example.com/owner/configured",
+					GitProvider: &apipac.GitProvider{WebhookSecret: &apipac.Secret{Name: "owner-configured"}},
+				},
+			},
+		},
+	})
+	//nolint
+	io, _, out, errOut := cli.IOTest()
+	opts := &RepoOptions{
+		Run: &params.Run{
+			Clients: clients.Clients{
+				PipelineAsCode: stdata.PipelineAsCode,
+				Kube:           stdata.Kube,
+			},
+			Info: info.Info{Kube: &info.KubeOpts{Namespace: "default"}},
+		},
+		IoStreams:    io,
+		pacNamespace: "pipelines-as-code",
+	}
+
+	err := opts.createFromFile(ctx, path)
+	assert.Error(t, err, fmt.Sprintf("1 of the 5 repositories of %s could not be created", path))
+	assert.Assert(t, strings.Contains(out.String(), "3 repositories created, 1 skipped, 1 failed"), out.String())
+	assert.Assert(t, strings.Contains(out.String(), "Repository owner-existing already exists in ns namespace without its webhook, adding it"), out.String())
+	assert.Assert(t, strings.Contains(out.String(), "Repository owner-configured already exists in ns namespace, skipping"), out.String())
+	assert.DeepEqual(t, hooks, []string{"repo", "existing"})
+	assert.Assert(t, strings.Contains(errOut.String(), "Cannot create the repository https://forgejo.example.com/owner/unset: environment variable PAC_TEST_UNSET_TOKEN is not set"), errOut.String())
+
+	repo, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Get(ctx, "owner-repo", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, repo.Spec.GitProvider.Type, "forgejo")
+	assert.Equal(t, repo.Spec.GitProvider.URL, server.URL)
+	assert.Equal(t, repo.Spec.GitProvider.Secret.Name, "owner-repo")
+	secret, err := stdata.Kube.CoreV1().Secrets("ns").Get(ctx, "owner-repo", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, string(secret.Data[pipelineascode.DefaultGitProviderSecretKey]), "forgejo-token")
+	assert.Assert(t, len(secret.Data[pipelineascode.DefaultGitProviderWebhookSecretKey]) > 0)
+
+	existing, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Get(ctx, "owner-existing", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, existing.Spec.GitProvider.WebhookSecret.Name, "owner-existing")
+
+	_, err = stdata.Kube.CoreV1().Namespaces().Get(ctx, "other", metav1.GetOptions{})
+	assert.NilError(t, err)
+	crOnly, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("other").Get(ctx, "cr-only", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, crOnly.Spec.GitProvider == nil)
+
+	_, err = stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Get(ctx, "owner-unset", metav1.GetOptions{})
+	assert.Assert(t, err != nil)
+}
@@ -26,7 +26,8 @@ import (
 )
 
 const (
-	noColorFlag = "no-color"
+	noColorFlag  = "no-color"
+	fromFileFlag = "from-file"
 )
 
 type RepoOptions struct {
@@ -36,6 +37,7 @@ type RepoOptions struct {
 	GitInfo      *git.Info
 	pacNamespace string
 	Provider     string
+	fromFile     string
 
 	IoStreams *cli.IOStreams
 	cliOpts   *cli.PacCliOpts
@@ -57,6 +59,19 @@ func repositoryCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command
 			createOpts.cliOpts = cli.NewCliOptions()
 			createOpts.IoStreams.SetColorEnabled(!createOpts.cliOpts.NoColoring)
 
+			if createOpts.fromFile != "" {
+				if createOpts.Event.URL != "" || createOpts.Repository.Name != "" {
+					return fmt.Errorf("--%s cannot be used with --url or --name", fromFileFlag)
+				}
+				if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+					return err
+				}
+				if createOpts.Repository.Namespace != "" {
+					run.Info.Kube.Namespace = createOpts.Repository.Namespace
+				}
+				return createOpts.createFromFile(ctx, createOpts.fromFile)
+			}
+
 			cwd, err := os.Getwd()
 			if err != nil {
 				return err
@@ -122,6 +137,8 @@ func repositoryCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command
 		"The target namespace where the runs will be created")
 	cmd.PersistentFlags().StringVarP(&createOpts.pacNamespace, "pac-namespace",
 		"", "", "The namespace where pac is installed")
+	cmd.PersistentFlags().StringVar(&createOpts.fromFile, fromFileFlag, "",
+		"Create the repositories and their webhooks listed in a YAML manifest without asking anything")
 	return cmd
 }
 
@@ -253,7 +270,10 @@ func createRepoCRD(ctx context.Context, opts *RepoOptions) (string, string, erro
 	if err != nil {
 		return "", "", fmt.Errorf("invalid git URL: %s, it should be of format: https://gitprovider/project/repository", opts.Event.URL)
 	}
-	repositoryName := formatting.CleanKubernetesName(repoOwner)
+	repositoryName := opts.Repository.Name
+	if repositoryName == "" {
+		repositoryName = formatting.CleanKubernetesName(repoOwner)
+	}
 	opts.Repository, err = opts.Run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(opts.Repository.Namespace).Create(
 		ctx,
 		&apipac.Repository{
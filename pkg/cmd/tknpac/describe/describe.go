@@ -58,6 +58,16 @@ func formatStatus(status v1alpha1.RepositoryRunStatus, cs *cli.ColorScheme, c cl
 		cs.HyperLink(status.PipelineRunName, *status.LogURL))
 }
 
+// describeOutput is the machine-readable output of a repository.
+type describeOutput struct {
+	Name      string                         `json:"name"`
+	Namespace string                         `json:"namespace"`
+	URL       string                         `json:"url"`
+	Queue     *status.QueueStatus            `json:"queue,omitempty"`
+	Statuses  []v1alpha1.RepositoryRunStatus `json:"statuses"`
+	Events    []corev1.Event                 `json:"events,omitempty"`
+}
+
 type describeOpts struct {
 	cli.PacCliOpts
 	TargetPipelineRun string
@@ -72,6 +82,7 @@ func newDescribeOptions(_ *cobra.Command) *describeOpts {
 
 func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	var useRealTime bool
+	var output string
 	cmd := &cobra.Command{
 		Use:     "describe",
 		Aliases: []string{"desc"},
@@ -107,6 +118,11 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 				return err
 			}
 
+			opts.Output = output
+			if err := cli.ValidateOutputFormat(opts.Output); err != nil {
+				return err
+			}
+
 			if len(args) > 0 {
 				repoName = args[0]
 			}
@@ -147,6 +163,7 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 		showEventflag, "", false, "show kubernetes events associated with this repository, useful if you have an error that cannot be reported on the git provider interface")
 	cmd.PersistentFlags().BoolVarP(&useRealTime, useRealTimeFlag, "", false,
 		"display the time as RFC3339 instead of a relative time")
+	cli.AddOutputFlag(cmd, &output)
 	return cmd
 }
 
@@ -246,6 +263,20 @@ func describe(ctx context.Context, cs *params.Run, clock clockwork.Clock, opts *
 		}
 	}
 
+	if opts.Output != "" {
+		out := describeOutput{
+			Name:      repository.GetName(),
+			Namespace: repository.GetNamespace(),
+			URL:       repository.Spec.URL,
+			Statuses:  statuses,
+			Events:    eventList,
+		}
+		if out.Queue, err = status.RepositoryQueueStatus(ctx, cs, *repository); err != nil {
+			return err
+		}
+		return cli.PrintOutput(ioStreams.Out, opts.Output, out)
+	}
+
 	data := struct {
 		Repository  *v1alpha1.Repository
 		Statuses    []v1alpha1.RepositoryRunStatus
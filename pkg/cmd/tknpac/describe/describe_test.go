@@ -446,6 +446,42 @@ func TestDescribe(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "json output",
+			args: args{
+				repoName:         "test-run",
+				currentNamespace: ns,
+				opts:             &describeOpts{PacCliOpts: cli.PacCliOpts{Output: "json"}},
+				pruns: []*tektonv1.PipelineRun{
+					tektontest.MakePRCompletion(cw, "running", ns, running, map[string]string{
+						keys.Branch: "tartanpion",
+					}, map[string]string{
+						keys.Repository: "test-run",
+						keys.State:      "queued",
+					}, 30),
+				},
+				statuses: []v1alpha1.RepositoryRunStatus{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "jsonpath output",
+			args: args{
+				repoName:         "test-run",
+				currentNamespace: ns,
+				opts:             &describeOpts{PacCliOpts: cli.PacCliOpts{Output: `jsonpath={.name}: {.statuses[*].pipelineRunName} queued: {.queue.pending}`}},
+				pruns: []*tektonv1.PipelineRun{
+					tektontest.MakePRCompletion(cw, "running", ns, running, map[string]string{
+						keys.Branch: "tartanpion",
+					}, map[string]string{
+						keys.Repository: "test-run",
+						keys.State:      "queued",
+					}, 30),
+				},
+				statuses: []v1alpha1.RepositoryRunStatus{},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
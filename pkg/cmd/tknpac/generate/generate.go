@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -36,6 +37,7 @@ type Opts struct {
 	FileName                string
 	overwrite               bool
 	language                string
+	cache                   bool
 	generateWithClusterTask bool
 }
 
@@ -86,6 +88,8 @@ func Command(_ *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 		"Whether to overwrite the file if it exists")
 	cmd.PersistentFlags().StringVarP(&gopt.language, "language", "l", "",
 		"Generate template for this programming language")
+	cmd.PersistentFlags().BoolVar(&gopt.cache, "cache", false,
+		"Bind the cache workspace of the template to a PersistentVolumeClaim keeping the dependencies between the runs")
 	cmd.PersistentFlags().BoolVarP(&gopt.generateWithClusterTask, "use-clustertasks", "", false,
 		"Deprecated, not available anymore")
 	_ = cmd.PersistentFlags().MarkDeprecated("use-clustertasks", "This flag will be removed in a future release")
@@ -178,11 +182,19 @@ func generatefileName(eventType string) string {
 }
 
 // samplePipeline will try to create a basic pipeline in tekton
-// directory.
+// directory. When no language nor file name is given and the top level of the
+// repository has no project, a pipeline is created for each project of its
+// directories.
 func (o *Opts) samplePipeline(recreateTemplate bool) error {
-	cs := o.IOStreams.ColorScheme()
-	var relpath, fpath, dirPath string
+	if o.FileName == "" && o.language == "" {
+		if _, ok := languageIn(o.GitInfo.TopLevelPath); !ok {
+			if projects := detectProjects(o.GitInfo.TopLevelPath); len(projects) > 0 {
+				return o.monorepoPipelines(projects, recreateTemplate)
+			}
+		}
+	}
 
+	var relpath, fpath, dirPath string
 	if o.FileName != "" {
 		fpath = o.FileName
 		relpath = fpath
@@ -193,7 +205,36 @@ func (o *Opts) samplePipeline(recreateTemplate bool) error {
 		relpath, _ = filepath.Rel(o.GitInfo.TopLevelPath, fpath)
 		dirPath = filepath.Join(o.GitInfo.TopLevelPath, ".tekton")
 	}
+	return o.writeTemplate(fpath, relpath, dirPath, recreateTemplate, o.genTmpl)
+}
 
+// monorepoPipelines creates a pipeline in the tekton directory for each
+// project of a monorepo.
+func (o *Opts) monorepoPipelines(projects []project, recreateTemplate bool) error {
+	cs := o.IOStreams.ColorScheme()
+	fmt.Fprintf(o.IOStreams.Out, "%s We detected %d projects in your repository, a PipelineRun is generated for each of them:\n",
+		cs.SuccessIcon(), len(projects))
+	for _, p := range projects {
+		fmt.Fprintf(o.IOStreams.Out, "  - %s: %s\n", cs.Bold(p.dir), fmt.Sprintf(p.lang.detected, p.lang.title))
+	}
+
+	dirPath := filepath.Join(o.GitInfo.TopLevelPath, ".tekton")
+	for _, p := range projects {
+		fpath := filepath.Join(dirPath, p.name()+"-"+generatefileName(o.Event.EventType))
+		relpath, _ := filepath.Rel(o.GitInfo.TopLevelPath, fpath)
+		if err := o.writeTemplate(fpath, relpath, dirPath, recreateTemplate, func() (*bytes.Buffer, error) {
+			return o.projectTmpl(p)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTemplate writes the template genTmpl generates to fpath, asking before
+// overwriting an existing file when recreateTemplate is set.
+func (o *Opts) writeTemplate(fpath, relpath, dirPath string, recreateTemplate bool, genTmpl func() (*bytes.Buffer, error)) error {
+	cs := o.IOStreams.ColorScheme()
 	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
 		if err := os.MkdirAll(dirPath, 0o750); err != nil {
 			return err
@@ -220,7 +261,7 @@ func (o *Opts) samplePipeline(recreateTemplate bool) error {
 		}
 		return nil
 	}
-	tmpl, err := o.genTmpl()
+	tmpl, err := genTmpl()
 	if err != nil {
 		return err
 	}
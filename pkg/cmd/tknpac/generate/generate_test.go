@@ -236,3 +236,34 @@ func TestGenerateTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateMonorepo(t *testing.T) {
+	newdir := fs.NewDir(t, "TestGenerateMonorepo",
+		fs.WithDir("web", fs.WithFile("package.json", "{}")),
+		fs.WithDir("charts", fs.WithDir("app", fs.WithFile("Chart.yaml", "name: app"))),
+	)
+	defer newdir.Remove()
+
+	io, _, out, _ := cli.IOTest()
+	err := Generate(&Opts{
+		Event:     &info.Event{EventType: "push", BaseBranch: "main"},
+		GitInfo:   &git.Info{URL: "https://hello/mono", TopLevelPath: newdir.Path()},
+		IOStreams: io,
+		CLIOpts:   &cli.PacCliOpts{},
+	}, false)
+	assert.NilError(t, err)
+	assert.Assert(t, regexp.MustCompile(`We detected 2 projects in your repository`).MatchString(out.String()), out.String())
+
+	for fname, wants := range map[string][]string{
+		".tekton/web-push.yaml":        {"name: mono-web-push", `on-path-change: "[web/**]"`, "- name: PATH_CONTEXT\n            value: web\n"},
+		".tekton/charts-app-push.yaml": {"name: mono-charts-app-push", `on-path-change: "[charts/app/**]"`, "workingDir: $(workspaces.source.path)/charts/app\n"},
+	} {
+		b, err := os.ReadFile(newdir.Join(fname))
+		assert.NilError(t, err)
+		for _, want := range wants {
+			assert.Assert(t, regexp.MustCompile(regexp.QuoteMeta(want)).Match(b), "%q not found in %s:\n%s", want, fname, string(b))
+		}
+	}
+	_, err = os.Stat(newdir.Join(".tekton/push.yaml"))
+	assert.Assert(t, os.IsNotExist(err))
+}
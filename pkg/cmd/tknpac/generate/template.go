@@ -5,74 +5,198 @@ import (
 	"embed"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"gopkg.in/yaml.v3"
 )
 
 type langOpts struct {
+	name string
+	// title names the language in the detection message, detected is how
+	// the message says the repository uses it.
+	title    string
+	detected string
+	// detectionFiles are the glob patterns of the files of the language.
 	detectionFiles []string
+	// contextParam is the parameter of the hub task taking the directory of
+	// the project, the steps of the templates without one run in it.
+	contextParam string
+	// cache tells if the template has a cache workspace to bind.
+	cache bool
 }
 
+const detectedLanguage = "the %s programming language"
+
 // I hate this part of the code so much.. but we are waiting for UBI images
 // having >1.6 golang for integrated templates.
-var languageDetection = map[string]langOpts{
-	"go": {
+//
+// The languages are detected in this order, the container template comes
+// last since many projects have a Containerfile to ship them.
+var languageDetection = []langOpts{
+	{
+		name: "go", title: "Go", detected: detectedLanguage,
 		detectionFiles: []string{"go.mod"},
+		contextParam:   "context",
+	},
+	{
+		name: "rust", title: "Rust", detected: detectedLanguage,
+		detectionFiles: []string{"Cargo.toml"},
+		cache:          true,
 	},
-	"python": {
+	{
+		name: "python", title: "Python", detected: detectedLanguage,
 		detectionFiles: []string{"setup.py", "pyproject.toml", "poetry.lock"},
+		contextParam:   "path",
 	},
-	"nodejs": {
+	{
+		name: "nodejs", title: "Nodejs", detected: detectedLanguage,
 		detectionFiles: []string{"package.json"},
+		contextParam:   "PATH_CONTEXT",
 	},
-	"java": {
+	{
+		name: "java", title: "Java", detected: detectedLanguage,
 		detectionFiles: []string{"pom.xml"},
+		contextParam:   "CONTEXT_DIR",
+		cache:          true,
+	},
+	{
+		name: "gradle", title: "Gradle", detected: "%s",
+		detectionFiles: []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
+		contextParam:   "PROJECT_DIR",
+	},
+	{
+		name: "dotnet", title: ".NET", detected: "%s",
+		detectionFiles: []string{"*.sln", "*.csproj", "*.fsproj", "*.vbproj"},
+		cache:          true,
+	},
+	{
+		name: "ruby", title: "Ruby", detected: detectedLanguage,
+		detectionFiles: []string{"Gemfile"},
+		cache:          true,
+	},
+	{
+		name: "php", title: "PHP", detected: detectedLanguage,
+		detectionFiles: []string{"composer.json"},
+		cache:          true,
+	},
+	{
+		name: "helm", title: "Helm chart", detected: "a %s",
+		detectionFiles: []string{"Chart.yaml"},
+	},
+	{
+		name: "container", title: "Containerfile", detected: "a %s",
+		detectionFiles: []string{"Containerfile", "Dockerfile"},
+		contextParam:   "CONTEXT",
+	},
+	{
+		name: "generic",
 	},
-	"generic": {},
 }
 
+// maxProjectDepth is how deep the projects of a monorepo are looked for.
+const maxProjectDepth = 2
+
+// ignoredProjectDirs are the directories never looked into for projects.
+var ignoredProjectDirs = map[string]bool{"vendor": true, "node_modules": true, "testdata": true, "third_party": true}
+
+var projectNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+
 //go:embed templates
 var resource embed.FS
 
+// project is a directory of the repository and its language, the directory is
+// empty for the top level of the repository.
+type project struct {
+	dir  string
+	lang langOpts
+}
+
+// name returns the name of the project in the PipelineRun and file names.
+func (p project) name() string {
+	return strings.Trim(projectNameRe.ReplaceAllString(strings.ToLower(p.dir), "-"), "-")
+}
+
+func languageOptions(name string) (langOpts, bool) {
+	for _, l := range languageDetection {
+		if l.name == name {
+			return l, true
+		}
+	}
+	return langOpts{}, false
+}
+
+// languageIn returns the language of the project in dir, if it has one.
+func languageIn(dir string) (langOpts, bool) {
+	for _, l := range languageDetection {
+		for _, pattern := range l.detectionFiles {
+			if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+				return l, true
+			}
+		}
+	}
+	return langOpts{}, false
+}
+
+// detectProjects returns the projects of the directories of a repository, a
+// directory with a project is not looked into.
+func detectProjects(topLevel string) []project {
+	projects := []project{}
+	var walk func(rel string, depth int)
+	walk = func(rel string, depth int) {
+		entries, err := os.ReadDir(filepath.Join(topLevel, rel))
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") || ignoredProjectDirs[e.Name()] {
+				continue
+			}
+			dir := filepath.ToSlash(filepath.Join(rel, e.Name()))
+			if lang, ok := languageIn(filepath.Join(topLevel, dir)); ok {
+				projects = append(projects, project{dir: dir, lang: lang})
+				continue
+			}
+			if depth < maxProjectDepth {
+				walk(dir, depth+1)
+			}
+		}
+	}
+	walk("", 1)
+	return projects
+}
+
 // detectLanguage determines the programming language used in the repository.
 // It first checks if a language has been explicitly set in the options. If so,
 // it verifies that a template is available for that language. If not, it returns an error.
-// If no language is set, it iterates over the known languages and checks if a
-// characteristic file for each language exists in the repository (go.mod >
-// go). If it finds a match, it outputs a success message and returns the
-// detected language. If no language can be detected, it defaults to "generic".
+// If no language is set, it iterates over the known languages in order and
+// checks if a characteristic file for each language exists in the repository
+// (go.mod > go). If it finds a match, it outputs a success message and returns
+// the detected language. If no language can be detected, it defaults to
+// "generic".
 // Returns the detected language as a string, or an error if a problem
 // occurred.
 func (o *Opts) detectLanguage() (string, error) {
 	if o.language != "" {
-		if _, ok := languageDetection[o.language]; !ok {
+		if _, ok := languageOptions(o.language); !ok {
 			return "", fmt.Errorf("no template available for %s", o.language)
 		}
 		return o.language, nil
 	}
 
-	cs := o.IOStreams.ColorScheme()
-	for t, v := range languageDetection {
-		if v.detectionFiles == nil {
-			continue
-		}
-		for _, f := range v.detectionFiles {
-			fpath := filepath.Join(o.GitInfo.TopLevelPath, f)
-			if _, err := os.Stat(fpath); !os.IsNotExist(err) {
-				fmt.Fprintf(o.IOStreams.Out, "%s We detected your repository uses the %s programming language.\n",
-					cs.SuccessIcon(),
-					cs.Bold(cases.Title(language.Und, cases.NoLower).String(t)),
-				)
-				return t, nil
-			}
-		}
+	lang, ok := languageIn(o.GitInfo.TopLevelPath)
+	if !ok {
+		return "generic", nil
 	}
-	return "generic", nil
+	cs := o.IOStreams.ColorScheme()
+	fmt.Fprintf(o.IOStreams.Out, "%s We detected your repository uses %s.\n",
+		cs.SuccessIcon(), fmt.Sprintf(lang.detected, cs.Bold(lang.title)))
+	return lang.name, nil
 }
 
 func (o *Opts) genTmpl() (*bytes.Buffer, error) {
@@ -80,18 +204,20 @@ func (o *Opts) genTmpl() (*bytes.Buffer, error) {
 	if err != nil {
 		return nil, err
 	}
+	opts, _ := languageOptions(lang)
+	return o.projectTmpl(project{lang: opts})
+}
 
-	embedfile, err := resource.Open(fmt.Sprintf("templates/%s.yaml", lang))
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer embedfile.Close()
-	tmplB, _ := io.ReadAll(embedfile)
-
+// projectTmpl renders the template of a project, the PipelineRun of a project
+// in a directory runs in it and only when its files change.
+func (o *Opts) projectTmpl(p project) (*bytes.Buffer, error) {
 	prName := filepath.Base(o.GitInfo.URL)
 	if prName == "." {
 		prName = filepath.Base(o.Event.URL)
 	}
+	if p.dir != "" {
+		prName = prName + "-" + p.name()
+	}
 
 	// if eventType has both the events [push, pull_request] then skip
 	// adding it to pipelinerun name
@@ -99,14 +225,43 @@ func (o *Opts) genTmpl() (*bytes.Buffer, error) {
 		prName = prName + "-" + strings.ReplaceAll(o.Event.EventType, "_", "-")
 	}
 
-	tmplB = bytes.ReplaceAll(tmplB, []byte("pipelinesascode.tekton.dev/on-event: \"pull_request\""),
-		[]byte(fmt.Sprintf("pipelinesascode.tekton.dev/on-event: \"[%s]\"", o.Event.EventType)))
-
-	tmplB = bytes.ReplaceAll(tmplB, []byte("pipelinesascode.tekton.dev/on-target-branch: \"main\""),
-		[]byte(fmt.Sprintf("pipelinesascode.tekton.dev/on-target-branch: \"[%s]\"", o.Event.BaseBranch)))
+	tmplB, err := RenderTemplate(p.lang.name, prName, o.Event.EventType, o.Event.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
 
-	tmplB = bytes.ReplaceAll(tmplB, []byte(fmt.Sprintf("name: pipelinerun-%s", lang)),
-		[]byte(fmt.Sprintf("name: %s", prName)))
+	edits := []templateEdit{}
+	if p.lang.name == "container" {
+		projectPath := filepath.Join(o.GitInfo.TopLevelPath, p.dir)
+		containerfile := "Dockerfile"
+		if !fileExists(filepath.Join(projectPath, "Dockerfile")) && fileExists(filepath.Join(projectPath, "Containerfile")) {
+			containerfile = "Containerfile"
+		}
+		if containerfile != "Dockerfile" || p.dir != "" {
+			edits = append(edits, setParam("DOCKERFILE", "./"+path.Join(p.dir, containerfile)))
+		}
+	}
+	if p.dir != "" {
+		if p.lang.contextParam != "" {
+			edits = append(edits, setParam(p.lang.contextParam, p.dir))
+		} else {
+			edits = append(edits, setWorkingDir(p.dir))
+		}
+		edits = append(edits, addPathChange(p.dir))
+	}
+	if o.cache {
+		if p.lang.cache {
+			edits = append(edits, bindCache(p.cacheClaimName()))
+		} else {
+			cs := o.IOStreams.ColorScheme()
+			fmt.Fprintf(o.IOStreams.ErrOut, "%s The %s template has no cache to bind, --cache is ignored.\n", cs.WarningIcon(), p.lang.name)
+		}
+	}
+	if len(edits) > 0 {
+		if tmplB, err = editTemplate(tmplB, edits); err != nil {
+			return nil, fmt.Errorf("cannot generate the %s template: %w", p.lang.name, err)
+		}
+	}
 
 	if o.generateWithClusterTask {
 		tmplB = bytes.ReplaceAll(tmplB, []byte(fmt.Sprintf("name: %s", gitCloneClusterTaskName)),
@@ -115,3 +270,195 @@ func (o *Opts) genTmpl() (*bytes.Buffer, error) {
 
 	return bytes.NewBuffer(tmplB), nil
 }
+
+// cacheClaimName returns the name of the PersistentVolumeClaim bound to the
+// cache workspace, the projects in a directory have their own.
+func (p project) cacheClaimName() string {
+	if p.dir == "" {
+		return "{{ repo_name }}-cache"
+	}
+	return fmt.Sprintf("{{ repo_name }}-%s-cache", p.name())
+}
+
+// templateEdit edits the PipelineRun of a template, it fails when the
+// template has nothing to edit.
+type templateEdit func(pr *yaml.Node) error
+
+// editTemplate applies the edits to the PipelineRun of a template.
+func editTemplate(tmplB []byte, edits []templateEdit) ([]byte, error) {
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(tmplB, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("the template is empty")
+	}
+	for _, edit := range edits {
+		if err := edit(doc.Content[0]); err != nil {
+			return nil, err
+		}
+	}
+	buf := bytes.NewBufferString("---\n")
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// setParam sets the value of the task parameters named name.
+func setParam(name, value string) templateEdit {
+	return func(pr *yaml.Node) error {
+		found := false
+		walkMappings(pr, func(node *yaml.Node) {
+			if v := mappingValue(node, "value"); v != nil && v.Kind == yaml.ScalarNode && scalarValue(node, "name") == name {
+				v.Value, v.Tag, v.Style = value, "!!str", 0
+				found = true
+			}
+		})
+		if !found {
+			return fmt.Errorf("the template has no %s parameter", name)
+		}
+		return nil
+	}
+}
+
+// setWorkingDir runs the steps working in the source workspace in dir.
+func setWorkingDir(dir string) templateEdit {
+	return func(pr *yaml.Node) error {
+		found := false
+		walkMappings(pr, func(node *yaml.Node) {
+			if v := mappingValue(node, "workingDir"); v != nil && v.Value == "$(workspaces.source.path)" {
+				v.Value = "$(workspaces.source.path)/" + dir
+				found = true
+			}
+		})
+		if !found {
+			return fmt.Errorf("the template has no step working in the source workspace")
+		}
+		return nil
+	}
+}
+
+// addPathChange adds the on-path-change annotation running the PipelineRun
+// only when the files of the project in dir change, after the
+// on-target-branch one.
+func addPathChange(dir string) templateEdit {
+	return func(pr *yaml.Node) error {
+		annotations := mappingValue(mappingValue(pr, "metadata"), "annotations")
+		if annotations == nil || annotations.Kind != yaml.MappingNode {
+			return fmt.Errorf("the template has no annotations")
+		}
+		key := &yaml.Node{
+			Kind: yaml.ScalarNode, Tag: "!!str", Value: keys.OnPathChange,
+			HeadComment: fmt.Sprintf("The PipelineRun only runs when the files of the %s project change.", dir),
+		}
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprintf("[%s/**]", dir), Style: yaml.DoubleQuotedStyle}
+		at := len(annotations.Content)
+		for i := 0; i+1 < len(annotations.Content); i += 2 {
+			if annotations.Content[i].Value == keys.OnTargetBranch {
+				at = i + 2
+			}
+		}
+		annotations.Content = slices.Insert(annotations.Content, at, key, value)
+		return nil
+	}
+}
+
+// bindCache binds the cache workspace declared by the pipeline of the
+// template to the PersistentVolumeClaim claimName.
+func bindCache(claimName string) templateEdit {
+	return func(pr *yaml.Node) error {
+		spec := mappingValue(pr, "spec")
+		declared := false
+		if decl := mappingValue(mappingValue(spec, "pipelineSpec"), "workspaces"); decl != nil {
+			for _, ws := range decl.Content {
+				declared = declared || scalarValue(ws, "name") == "cache"
+			}
+		}
+		workspaces := mappingValue(spec, "workspaces")
+		if !declared || workspaces == nil || workspaces.Kind != yaml.SequenceNode {
+			return fmt.Errorf("the template has no cache workspace to bind")
+		}
+		workspaces.Content = append(workspaces.Content, &yaml.Node{
+			Kind: yaml.MappingNode, Tag: "!!map",
+			HeadComment: "This workspace keeps the dependencies between the runs, create the\n" +
+				"PersistentVolumeClaim in the namespace of the Repository CR first.",
+			Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name"},
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: "cache"},
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: "persistentVolumeClaim"},
+				{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "claimName"},
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: claimName, Style: yaml.DoubleQuotedStyle},
+				}},
+			},
+		})
+		return nil
+	}
+}
+
+// walkMappings calls fn on all the mappings under node.
+func walkMappings(node *yaml.Node, fn func(*yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		fn(node)
+	}
+	for _, child := range node.Content {
+		walkMappings(child, fn)
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the value of a scalar of a mapping, an empty string
+// when it is missing.
+func scalarValue(node *yaml.Node, key string) string {
+	if v := mappingValue(node, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// RenderTemplate returns the PipelineRun template of a language named prName
+// and matching the comma separated eventTypes and targetBranches.
+func RenderTemplate(lang, prName, eventTypes, targetBranches string) ([]byte, error) {
+	embedfile, err := resource.Open(fmt.Sprintf("templates/%s.yaml", lang))
+	if err != nil {
+		return nil, fmt.Errorf("no template available for %s", lang)
+	}
+	defer embedfile.Close()
+	tmplB, err := io.ReadAll(embedfile)
+	if err != nil {
+		return nil, err
+	}
+
+	tmplB = bytes.ReplaceAll(tmplB, []byte("pipelinesascode.tekton.dev/on-event: \"pull_request\""),
+		[]byte(fmt.Sprintf("pipelinesascode.tekton.dev/on-event: \"[%s]\"", eventTypes)))
+
+	tmplB = bytes.ReplaceAll(tmplB, []byte("pipelinesascode.tekton.dev/on-target-branch: \"main\""),
+		[]byte(fmt.Sprintf("pipelinesascode.tekton.dev/on-target-branch: \"[%s]\"", targetBranches)))
+
+	tmplB = bytes.ReplaceAll(tmplB, []byte(fmt.Sprintf("name: pipelinerun-%s", lang)),
+		[]byte(fmt.Sprintf("name: %s", prName)))
+
+	return tmplB, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -71,6 +71,87 @@ func TestDetectLanguage(t *testing.T) {
 			addExtraFilesInRepo: map[string]string{},
 			expectedLanguage:    "generic",
 		},
+		{
+			name: "detect rust",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"Cargo.toml": "[package]",
+			},
+			expectedLanguage: "rust",
+		},
+		{
+			name: "detect gradle",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"build.gradle.kts": "plugins {}",
+			},
+			expectedLanguage: "gradle",
+		},
+		{
+			name: "detect dotnet from a project file",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"app.csproj": "<Project></Project>",
+			},
+			expectedLanguage: "dotnet",
+		},
+		{
+			name: "detect ruby",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"Gemfile": "source 'https://rubygems.org'",
+			},
+			expectedLanguage: "ruby",
+		},
+		{
+			name: "detect php",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"composer.json": "{}",
+			},
+			expectedLanguage: "php",
+		},
+		{
+			name: "detect helm",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"Chart.yaml": "name: chart",
+			},
+			expectedLanguage: "helm",
+		},
+		{
+			name: "detect container",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"Containerfile": "FROM scratch",
+			},
+			expectedLanguage: "container",
+		},
+		{
+			name: "language before container",
+			gitinfo: git.Info{
+				TopLevelPath: "/tmp/test-repo",
+			},
+			addExtraFilesInRepo: map[string]string{
+				"go.mod":     "module github.com/test/repo",
+				"Dockerfile": "FROM scratch",
+			},
+			expectedLanguage: "go",
+		},
 		{
 			name: "explicit language set",
 			gitinfo: git.Info{
@@ -263,3 +344,141 @@ func TestGenTmpl(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectTmpl(t *testing.T) {
+	tests := []struct {
+		name                string
+		project             project
+		cache               bool
+		addExtraFilesInRepo map[string]string
+		wantContains        []string
+		wantNotContains     []string
+		wantErrOut          string
+		wantErr             string
+	}{
+		{
+			name:    "project in a directory with a hub task",
+			project: project{dir: "services/api", lang: langOpts{name: "go", contextParam: "context"}},
+			wantContains: []string{
+				"name: repo-services-api-pull-request",
+				"pipelinesascode.tekton.dev/on-path-change: \"[services/api/**]\"",
+				"- name: context\n            value: services/api\n",
+			},
+		},
+		{
+			name:    "project in a directory with steps",
+			project: project{dir: "crates", lang: langOpts{name: "rust", cache: true}},
+			wantContains: []string{
+				"workingDir: $(workspaces.source.path)/crates\n",
+				"pipelinesascode.tekton.dev/on-path-change: \"[crates/**]\"",
+			},
+			wantNotContains: []string{"claimName:"},
+		},
+		{
+			name:    "containerfile in a directory",
+			project: project{dir: "image", lang: langOpts{name: "container", contextParam: "CONTEXT"}},
+			addExtraFilesInRepo: map[string]string{
+				"image/Containerfile": "FROM scratch",
+			},
+			wantContains: []string{
+				"- name: DOCKERFILE\n            value: ./image/Containerfile\n",
+				"- name: CONTEXT\n            value: image\n",
+			},
+		},
+		{
+			name:    "cache",
+			project: project{lang: langOpts{name: "java", contextParam: "CONTEXT_DIR", cache: true}},
+			cache:   true,
+			wantContains: []string{
+				"- name: maven-local-repo\n            workspace: cache\n",
+				"    - name: cache\n      persistentVolumeClaim:\n        claimName: \"{{ repo_name }}-cache\"\n",
+			},
+			wantNotContains: []string{"on-path-change"},
+		},
+		{
+			name:    "cache of a project in a directory",
+			project: project{dir: "services/api", lang: langOpts{name: "java", contextParam: "CONTEXT_DIR", cache: true}},
+			cache:   true,
+			wantContains: []string{
+				"- name: CONTEXT_DIR\n            value: services/api\n",
+				"    - name: cache\n      persistentVolumeClaim:\n        claimName: \"{{ repo_name }}-services-api-cache\"\n",
+			},
+		},
+		{
+			name:    "template without the context parameter",
+			project: project{dir: "services/api", lang: langOpts{name: "go", contextParam: "PATH_CONTEXT"}},
+			wantErr: "cannot generate the go template: the template has no PATH_CONTEXT parameter",
+		},
+		{
+			name:    "template without steps in the source workspace",
+			project: project{dir: "services/api", lang: langOpts{name: "go"}},
+			wantErr: "cannot generate the go template: the template has no step working in the source workspace",
+		},
+		{
+			name:    "template without cache workspace",
+			project: project{lang: langOpts{name: "go", cache: true}},
+			cache:   true,
+			wantErr: "cannot generate the go template: the template has no cache workspace to bind",
+		},
+		{
+			name:            "cache not available",
+			project:         project{lang: langOpts{name: "go", contextParam: "context"}},
+			cache:           true,
+			wantNotContains: []string{"claimName:"},
+			wantErrOut:      "The go template has no cache to bind, --cache is ignored.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newdir := fs.NewDir(t, "TestProjectTmpl")
+			defer newdir.Remove()
+			for key, value := range tt.addExtraFilesInRepo {
+				err := os.MkdirAll(filepath.Dir(newdir.Join(key)), os.ModePerm)
+				assert.NilError(t, err)
+				err = os.WriteFile(newdir.Join(key), []byte(value), 0o600)
+				assert.NilError(t, err)
+			}
+
+			io, _, _, errOut := cli.IOTest()
+			opts := &Opts{
+				GitInfo:   &git.Info{URL: "https://hello/repo", TopLevelPath: newdir.Path()},
+				Event:     &info.Event{EventType: "pull_request", BaseBranch: "main"},
+				IOStreams: io,
+				cache:     tt.cache,
+			}
+			buf, err := opts.projectTmpl(tt.project)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			output := buf.String()
+			for _, want := range tt.wantContains {
+				assert.Assert(t, strings.Contains(output, want), "%q not found in:\n%s", want, output)
+			}
+			for _, notWant := range tt.wantNotContains {
+				assert.Assert(t, !strings.Contains(output, notWant), "%q found in:\n%s", notWant, output)
+			}
+			assert.Assert(t, strings.Contains(errOut.String(), tt.wantErrOut), errOut.String())
+		})
+	}
+}
+
+func TestDetectProjects(t *testing.T) {
+	newdir := fs.NewDir(t, "TestDetectProjects",
+		fs.WithDir("frontend", fs.WithFile("package.json", "{}"), fs.WithDir("server", fs.WithFile("go.mod", "module server"))),
+		fs.WithDir("services", fs.WithDir("api", fs.WithFile("go.mod", "module api")), fs.WithDir("worker", fs.WithFile("Cargo.toml", "[package]"))),
+		fs.WithDir("deep", fs.WithDir("er", fs.WithDir("app", fs.WithFile("go.mod", "module app")))),
+		fs.WithDir("node_modules", fs.WithDir("dep", fs.WithFile("package.json", "{}"))),
+		fs.WithDir(".github", fs.WithDir("tool", fs.WithFile("go.mod", "module tool"))),
+		fs.WithDir("docs", fs.WithFile("index.md", "")),
+	)
+	defer newdir.Remove()
+
+	got := []string{}
+	for _, p := range detectProjects(newdir.Path()) {
+		got = append(got, p.dir+":"+p.lang.name)
+	}
+	assert.DeepEqual(t, got, []string{"frontend:nodejs", "services/api:go", "services/worker:rust"})
+}
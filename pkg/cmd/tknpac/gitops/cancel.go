@@ -0,0 +1,23 @@
+package gitops
+
+import (
+	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/spf13/cobra"
+)
+
+const cancelLonghelp = `cancel - cancel the running PipelineRuns of a pull request
+
+Ask the controller to cancel the running PipelineRuns of a pull request, the
+same way as a /cancel comment, or a /cancel <pipelinerun> comment with
+--pipelinerun, without commenting on the pull request.
+
+The request is sent to the incoming webhook of the Repository CR matching the
+branch targeted by the pull request, it needs to be of type pull-request. It is
+authenticated with the secret of the incoming webhook, read from the cluster.`
+
+// CancelCommand returns the cancel command.
+func CancelCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	return newCommand(run, ioStreams, apincoming.CommandCancel, "Cancel the running PipelineRuns of a pull request", cancelLonghelp)
+}
@@ -0,0 +1,396 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	pacinfo "github.com/openshift-pipelines/pipelines-as-code/pkg/cli/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/bootstrap"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	namespaceFlag     = "namespace"
+	pullRequestFlag   = "pr"
+	pipelineRunFlag   = "pipelinerun"
+	branchFlag        = "branch"
+	controllerURLFlag = "controller-url"
+
+	incomingTypePullRequest  = "pull-request"
+	incomingAuthHMACSHA256   = "hmac-sha256"
+	defaultIncomingSecretKey = "secret"
+	incomingPath             = "/incoming"
+	controllerRequestTimeout = 30 * time.Second
+	statusPollInterval       = 2 * time.Second
+	statusWaitTimeout        = 2 * time.Minute
+
+	incomingStatePending = "pending"
+	incomingStateNoMatch = "no-match"
+)
+
+// incomingRequest is the payload posted to the incoming webhook endpoint of
+// the controller.
+type incomingRequest struct {
+	Repository  string `json:"repository"`
+	Namespace   string `json:"namespace"`
+	Branch      string `json:"branch"`
+	PipelineRun string `json:"pipelinerun,omitempty"`
+	Secret      string `json:"secret,omitempty"`
+	PullRequest int    `json:"pull_request"`
+	Command     string `json:"command"`
+}
+
+// incomingResponse is the response of the controller to an accepted request.
+type incomingResponse struct {
+	Message   string `json:"message"`
+	ID        string `json:"id"`
+	StatusURL string `json:"status_url"`
+}
+
+// incomingStatus is the status of a request returned by the status endpoint
+// of the controller.
+type incomingStatus struct {
+	State        string `json:"state"`
+	Reason       string `json:"reason"`
+	PipelineRuns []struct {
+		Name       string `json:"name"`
+		ConsoleURL string `json:"console_url"`
+	} `json:"pipelineruns"`
+}
+
+type commandOpts struct {
+	cs            *params.Run
+	ioStreams     *cli.IOStreams
+	clock         clockwork.Clock
+	httpClient    *http.Client
+	command       string
+	repoName      string
+	namespace     string
+	pullRequest   int
+	pipelineRun   string
+	branch        string
+	controllerURL string
+}
+
+// newCommand returns the command sending a GitOps command to the incoming
+// webhook of the controller.
+func newCommand(run *params.Run, ioStreams *cli.IOStreams, command, short, long string) *cobra.Command {
+	opts := &commandOpts{
+		cs:        run,
+		ioStreams: ioStreams,
+		command:   command,
+	}
+	cmd := &cobra.Command{
+		Use:   command + " [repository]",
+		Short: short,
+		Long:  long,
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completion.ParentCompletion,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.repoName = args[0]
+			}
+			if opts.pullRequest <= 0 {
+				return fmt.Errorf("the pull request number needs to be passed with --%s", pullRequestFlag)
+			}
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			opts.clock = clockwork.NewRealClock()
+			opts.httpClient = &http.Client{Timeout: controllerRequestTimeout}
+			return send(ctx, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.namespace, namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
+	_ = cmd.RegisterFlagCompletionFunc(namespaceFlag,
+		func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion(namespaceFlag, args)
+		},
+	)
+	cmd.Flags().IntVar(&opts.pullRequest, pullRequestFlag, 0, "Number of the pull request")
+	cmd.Flags().StringVar(&opts.pipelineRun, pipelineRunFlag, "", fmt.Sprintf("Only %s this PipelineRun of the .tekton directory", command))
+	cmd.Flags().StringVar(&opts.branch, branchFlag, "", "Branch targeted by the pull request, detected from its PipelineRuns when not set")
+	cmd.Flags().StringVar(&opts.controllerURL, controllerURLFlag, "", "URL of the Pipelines-as-Code controller, detected from the cluster when not set")
+	return cmd
+}
+
+func send(ctx context.Context, opts *commandOpts) error {
+	if opts.namespace != "" {
+		opts.cs.Info.Kube.Namespace = opts.namespace
+	}
+
+	var repo *v1alpha1.Repository
+	var err error
+	if opts.repoName != "" {
+		repo, err = opts.cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(opts.cs.Info.Kube.Namespace).Get(ctx,
+			opts.repoName, metav1.GetOptions{})
+	} else {
+		repo, err = prompt.SelectRepo(ctx, opts.cs, opts.cs.Info.Kube.Namespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	branch := opts.branch
+	if branch == "" {
+		if branch, err = pullRequestBranch(ctx, opts.cs, repo, opts.pullRequest); err != nil {
+			return err
+		}
+	}
+	hook, err := pullRequestIncoming(repo, branch)
+	if err != nil {
+		return err
+	}
+	secret, err := incomingSecret(ctx, opts.cs, repo, hook)
+	if err != nil {
+		return err
+	}
+
+	controllerURL := opts.controllerURL
+	if controllerURL == "" {
+		if controllerURL, err = detectControllerURL(ctx, opts.cs); err != nil {
+			return err
+		}
+	}
+	controllerURL = strings.TrimSuffix(controllerURL, "/")
+
+	payload := incomingRequest{
+		Repository:  repo.GetName(),
+		Namespace:   repo.GetNamespace(),
+		Branch:      branch,
+		PipelineRun: opts.pipelineRun,
+		PullRequest: opts.pullRequest,
+		Command:     opts.command,
+	}
+	signed := hook.Auth != nil && hook.Auth.Type == incomingAuthHMACSHA256
+	if !signed {
+		payload.Secret = secret
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, controllerURL+incomingPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signed {
+		signRequest(req, hook, secret, body, opts.clock.Now())
+	}
+
+	resp, err := opts.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot send the request to the controller at %s: %w", controllerURL, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	response := incomingResponse{}
+	_ = json.Unmarshal(respBody, &response)
+	if resp.StatusCode != http.StatusAccepted {
+		if response.Message == "" {
+			response.Message = "check the logs of the controller"
+		}
+		return fmt.Errorf("the controller has not accepted the %s request: %s: %s", opts.command, resp.Status, response.Message)
+	}
+
+	cs := opts.ioStreams.ColorScheme()
+	target := fmt.Sprintf("the PipelineRuns of pull request #%d", opts.pullRequest)
+	if opts.pipelineRun != "" {
+		target = fmt.Sprintf("the PipelineRun %s of pull request #%d", opts.pipelineRun, opts.pullRequest)
+	}
+	fmt.Fprintf(opts.ioStreams.Out, "%s %s of %s has been requested on repository %s\n",
+		cs.SuccessIcon(), strings.ToUpper(opts.command[:1])+opts.command[1:], target, cs.Bold(repo.GetNamespace()+"/"+repo.GetName()))
+	if response.StatusURL == "" {
+		return nil
+	}
+	statusURL := controllerURL + response.StatusURL
+	// a cancel does not create any PipelineRun to follow
+	if opts.command != apincoming.CommandRetest {
+		fmt.Fprintf(opts.ioStreams.Out, "Follow the request on %s\n", statusURL)
+		return nil
+	}
+
+	status, err := waitIncomingStatus(ctx, opts, statusURL, hook, secret)
+	if err != nil {
+		return err
+	}
+	switch status.State {
+	case incomingStatePending:
+		fmt.Fprintf(opts.ioStreams.Out, "Follow the request on %s\n", statusURL)
+	case incomingStateNoMatch:
+		fmt.Fprintf(opts.ioStreams.Out, "%s No PipelineRun has been started: %s\n", cs.WarningIcon(), status.Reason)
+	default:
+		if len(status.PipelineRuns) == 0 {
+			return fmt.Errorf("the %s request has not started any PipelineRun: %s", opts.command, status.Reason)
+		}
+		for _, pr := range status.PipelineRuns {
+			fmt.Fprintf(opts.ioStreams.Out, "%s PipelineRun %s has been started\n", cs.SuccessIcon(), cs.Bold(pr.Name))
+			if pr.ConsoleURL != "" {
+				fmt.Fprintf(opts.ioStreams.Out, "  %s\n", pr.ConsoleURL)
+			}
+		}
+	}
+	return nil
+}
+
+// waitIncomingStatus polls the status endpoint of an accepted request until
+// the controller has processed it, or returns the pending status after
+// statusWaitTimeout.
+func waitIncomingStatus(ctx context.Context, opts *commandOpts, statusURL string, hook *v1alpha1.Incoming, secret string) (*incomingStatus, error) {
+	deadline := opts.clock.Now().Add(statusWaitTimeout)
+	for {
+		status, err := getIncomingStatus(ctx, opts, statusURL, hook, secret)
+		if err != nil {
+			return nil, err
+		}
+		if status.State != incomingStatePending || !opts.clock.Now().Before(deadline) {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-opts.clock.After(statusPollInterval):
+		}
+	}
+}
+
+// getIncomingStatus gets the status of a request, authenticated like the
+// request itself: with the secret as a bearer token or signed with an empty
+// body.
+func getIncomingStatus(ctx context.Context, opts *commandOpts, statusURL string, hook *v1alpha1.Incoming, secret string) (*incomingStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if hook.Auth != nil && hook.Auth.Type == incomingAuthHMACSHA256 {
+		signRequest(req, hook, secret, []byte{}, opts.clock.Now())
+	} else {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	resp, err := opts.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the status of the request on %s: %w", statusURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get the status of the request on %s: %s", statusURL, resp.Status)
+	}
+	status := &incomingStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, fmt.Errorf("cannot decode the status of the request: %w", err)
+	}
+	return status, nil
+}
+
+// signRequest sets the timestamp and HMAC-SHA256 signature headers of the
+// incoming webhook on the request.
+func signRequest(req *http.Request, hook *v1alpha1.Incoming, secret string, body []byte, now time.Time) {
+	signatureHeader, timestampHeader := apincoming.SignatureHeader, apincoming.TimestampHeader
+	if hook.Auth.SignatureHeader != "" {
+		signatureHeader = hook.Auth.SignatureHeader
+	}
+	if hook.Auth.TimestampHeader != "" {
+		timestampHeader = hook.Auth.TimestampHeader
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(signatureHeader, apincoming.SignatureHeaderValue(secret, timestamp, body))
+}
+
+// pullRequestBranch returns the base branch of a pull request from the
+// PipelineRuns it already has on the cluster.
+func pullRequestBranch(ctx context.Context, cs *params.Run, repo *v1alpha1.Repository, pullRequest int) (string, error) {
+	prs, err := cs.Clients.Tekton.TektonV1().PipelineRuns(repo.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s=%d", keys.Repository, formatting.CleanValueKubernetes(repo.GetName()), keys.PullRequest, pullRequest),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, pr := range prs.Items {
+		if branch := pr.GetAnnotations()[keys.Branch]; branch != "" {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("cannot detect the branch targeted by pull request #%d, pass it with --%s", pullRequest, branchFlag)
+}
+
+// pullRequestIncoming returns the incoming webhook the controller matches for
+// the branch, it has to be of the pull-request type to accept the commands.
+func pullRequestIncoming(repo *v1alpha1.Repository, branch string) (*v1alpha1.Incoming, error) {
+	if repo.Spec.Incomings == nil {
+		return nil, fmt.Errorf("repository %s has no incoming webhook, add one of type %s to send GitOps commands from the CLI", repo.GetName(), incomingTypePullRequest)
+	}
+	hook := matcher.IncomingWebhookRule(branch, *repo.Spec.Incomings)
+	if hook == nil {
+		return nil, fmt.Errorf("no incoming webhook of repository %s targets the branch %s", repo.GetName(), branch)
+	}
+	if hook.Type != incomingTypePullRequest {
+		return nil, fmt.Errorf("the incoming webhook of repository %s targeting the branch %s is of type %q, it needs to be of type %s",
+			repo.GetName(), branch, hook.Type, incomingTypePullRequest)
+	}
+	return hook, nil
+}
+
+func incomingSecret(ctx context.Context, cs *params.Run, repo *v1alpha1.Repository, hook *v1alpha1.Incoming) (string, error) {
+	key := hook.Secret.Key
+	if key == "" {
+		key = defaultIncomingSecretKey
+	}
+	secret, err := cs.Clients.Kube.CoreV1().Secrets(repo.GetNamespace()).Get(ctx, hook.Secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("cannot get the secret of the incoming webhook: %w", err)
+	}
+	value := string(secret.Data[key])
+	if value == "" {
+		return "", fmt.Errorf("secret %s of the incoming webhook has no key %s", hook.Secret.Name, key)
+	}
+	return value, nil
+}
+
+// detectControllerURL returns the URL of the controller set in the info
+// configmap of the installation or the one of its OpenShift route.
+func detectControllerURL(ctx context.Context, cs *params.Run) (string, error) {
+	installed, ns, err := bootstrap.DetectPacInstallation(ctx, "", cs)
+	if !installed {
+		return "", fmt.Errorf("pipelines as code not installed")
+	}
+	if err != nil {
+		return "", err
+	}
+	if pacInfo, err := pacinfo.GetPACInfo(ctx, cs, ns); err == nil && pacInfo.ControllerURL != "" {
+		return pacInfo.ControllerURL, nil
+	}
+	if url, _ := bootstrap.DetectOpenShiftRoute(ctx, cs, ns); url != "" {
+		return url, nil
+	}
+	return "", fmt.Errorf("cannot detect the URL of the controller, pass it with --%s", controllerURLFlag)
+}
@@ -0,0 +1,284 @@
+package gitops
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tcli "github.com/openshift-pipelines/pipelines-as-code/pkg/test/cli"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+const ns = "namespace"
+
+var now = time.Date(1999, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		hook        v1alpha1.Incoming
+		pipelineRun string
+		branch      string
+		pullRequest int
+		status      int
+		response    string
+		statuses    []string
+		wantErr     string
+		wantRequest incomingRequest
+		wantSigned  bool
+		wantOut     string
+	}{
+		{
+			name:    "signed retest with the branch of the pull request",
+			command: apincoming.CommandRetest,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+				Auth:    &v1alpha1.IncomingAuth{Type: "hmac-sha256"},
+			},
+			pullRequest: 42,
+			status:      http.StatusAccepted,
+			response:    `{"status":202,"message":"accepted","id":"0123456789abcdef","status_url":"/incoming/status/0123456789abcdef?namespace=namespace&repository=repo"}`,
+			statuses: []string{
+				`{"id":"0123456789abcdef","state":"running","pipelineruns":[{"name":"pr-abcde","console_url":"https://console/pr-abcde","state":"running"}]}`,
+			},
+			wantRequest: incomingRequest{Repository: "repo", Namespace: ns, Branch: "main", PullRequest: 42, Command: "retest"},
+			wantSigned:  true,
+			wantOut: "✓ Retest of the PipelineRuns of pull request #42 has been requested on repository namespace/repo\n" +
+				"✓ PipelineRun pr-abcde has been started\n" +
+				"  https://console/pr-abcde\n",
+		},
+		{
+			name:    "retest waits until the request has been processed",
+			command: apincoming.CommandRetest,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			pullRequest: 42,
+			status:      http.StatusAccepted,
+			response:    `{"status":202,"message":"accepted","id":"0123456789abcdef","status_url":"/incoming/status/0123456789abcdef?namespace=namespace&repository=repo"}`,
+			statuses: []string{
+				`{"id":"0123456789abcdef","state":"pending","pipelineruns":[]}`,
+				`{"id":"0123456789abcdef","state":"no-match","reason":"no PipelineRun matched the incoming webhook request","pipelineruns":[]}`,
+			},
+			wantRequest: incomingRequest{Repository: "repo", Namespace: ns, Branch: "main", Secret: "verysecret", PullRequest: 42, Command: "retest"},
+			wantOut: "✓ Retest of the PipelineRuns of pull request #42 has been requested on repository namespace/repo\n" +
+				"! No PipelineRun has been started: no PipelineRun matched the incoming webhook request\n",
+		},
+		{
+			name:    "retest has failed",
+			command: apincoming.CommandRetest,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			pullRequest: 42,
+			status:      http.StatusAccepted,
+			response:    `{"status":202,"message":"accepted","id":"0123456789abcdef","status_url":"/incoming/status/0123456789abcdef?namespace=namespace&repository=repo"}`,
+			statuses: []string{
+				`{"id":"0123456789abcdef","state":"failed","reason":"the incoming webhook request has failed: boom","pipelineruns":[]}`,
+			},
+			wantErr: "the retest request has not started any PipelineRun: the incoming webhook request has failed: boom",
+		},
+		{
+			name:    "cancel does not wait for the request",
+			command: apincoming.CommandCancel,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			pullRequest: 42,
+			status:      http.StatusAccepted,
+			response:    `{"status":202,"message":"accepted","id":"0123456789abcdef","status_url":"/incoming/status/0123456789abcdef?namespace=namespace&repository=repo"}`,
+			wantRequest: incomingRequest{Repository: "repo", Namespace: ns, Branch: "main", Secret: "verysecret", PullRequest: 42, Command: "cancel"},
+			wantOut: "✓ Cancel of the PipelineRuns of pull request #42 has been requested on repository namespace/repo\n" +
+				"Follow the request on {{URL}}/incoming/status/0123456789abcdef?namespace=namespace&repository=repo\n",
+		},
+		{
+			name:    "cancel of a pipelinerun with a shared secret",
+			command: apincoming.CommandCancel,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"release-*"},
+				Secret:  v1alpha1.Secret{Name: "incoming", Key: "other"},
+			},
+			pipelineRun: "e2e",
+			branch:      "release-1.0",
+			pullRequest: 7,
+			status:      http.StatusAccepted,
+			response:    `{"status":202,"message":"accepted"}`,
+			wantRequest: incomingRequest{Repository: "repo", Namespace: ns, Branch: "release-1.0", PipelineRun: "e2e", Secret: "othersecret", PullRequest: 7, Command: "cancel"},
+			wantOut:     "✓ Cancel of the PipelineRun e2e of pull request #7 has been requested on repository namespace/repo\n",
+		},
+		{
+			name:    "controller refuses the request",
+			command: apincoming.CommandRetest,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			pullRequest: 42,
+			status:      http.StatusBadRequest,
+			response:    `{"status":400,"message":"missing required fields: [branch]"}`,
+			wantErr:     "the controller has not accepted the retest request: 400 Bad Request: missing required fields: [branch]",
+		},
+		{
+			name:    "branch of the pull request is unknown",
+			command: apincoming.CommandRetest,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			pullRequest: 1,
+			wantErr:     "cannot detect the branch targeted by pull request #1, pass it with --branch",
+		},
+		{
+			name:    "incoming webhook of another type",
+			command: apincoming.CommandRetest,
+			hook: v1alpha1.Incoming{
+				Type:    "webhook-url",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			pullRequest: 42,
+			wantErr:     `the incoming webhook of repository repo targeting the branch main is of type "webhook-url", it needs to be of type pull-request`,
+		},
+		{
+			name:    "no incoming webhook for the branch",
+			command: apincoming.CommandCancel,
+			hook: v1alpha1.Incoming{
+				Type:    "pull-request",
+				Targets: []string{"main"},
+				Secret:  v1alpha1.Secret{Name: "incoming"},
+			},
+			branch:      "devel",
+			pullRequest: 42,
+			wantErr:     "no incoming webhook of repository repo targets the branch devel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRequest incomingRequest
+			var gotHeader http.Header
+			statusRequests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					assert.Equal(t, r.URL.Path, "/incoming/status/0123456789abcdef")
+					if tt.wantSigned {
+						assert.Equal(t, r.Header.Get(apincoming.SignatureHeader),
+							apincoming.SignatureHeaderValue("verysecret", r.Header.Get(apincoming.TimestampHeader), []byte{}))
+					} else {
+						assert.Equal(t, r.Header.Get("Authorization"), "Bearer verysecret")
+					}
+					assert.Assert(t, statusRequests < len(tt.statuses), "unexpected status request")
+					_, _ = w.Write([]byte(tt.statuses[statusRequests]))
+					statusRequests++
+					return
+				}
+				assert.Equal(t, r.URL.Path, "/incoming")
+				body, err := io.ReadAll(r.Body)
+				assert.NilError(t, err)
+				assert.NilError(t, json.Unmarshal(body, &gotRequest))
+				gotHeader = r.Header.Clone()
+				if tt.wantSigned {
+					assert.Equal(t, r.Header.Get(apincoming.SignatureHeader),
+						apincoming.SignatureHeaderValue("verysecret", r.Header.Get(apincoming.TimestampHeader), body))
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Namespaces: []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: ns}}},
+				Repositories: []*v1alpha1.Repository{{
+					ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: ns},
+					Spec: v1alpha1.RepositorySpec{
+						URL:       "https://forge/owner/repo",
+						Incomings: &[]v1alpha1.Incoming{tt.hook},
+					},
+				}},
+				Secret: []*corev1.Secret{{
+					ObjectMeta: metav1.ObjectMeta{Name: "incoming", Namespace: ns},
+					Data:       map[string][]byte{"secret": []byte("verysecret"), "other": []byte("othersecret")},
+				}},
+				PipelineRuns: []*tektonv1.PipelineRun{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pr-42",
+						Namespace:   ns,
+						Labels:      map[string]string{keys.Repository: "repo", keys.PullRequest: "42"},
+						Annotations: map[string]string{keys.Branch: "main"},
+					},
+				}},
+			})
+			fakeClock := clockwork.NewFakeClockAt(now)
+			go func() {
+				// lets the CLI poll the status again after each pending one
+				for i := 1; i < len(tt.statuses); i++ {
+					fakeClock.BlockUntil(1)
+					fakeClock.Advance(statusPollInterval)
+				}
+			}()
+			ios, out := tcli.NewIOStream()
+			opts := &commandOpts{
+				cs: &params.Run{
+					Clients: clients.Clients{
+						PipelineAsCode: stdata.PipelineAsCode,
+						Tekton:         stdata.Pipeline,
+						Kube:           stdata.Kube,
+					},
+					Info: info.Info{Kube: &info.KubeOpts{Namespace: ns}},
+				},
+				ioStreams:     ios,
+				clock:         fakeClock,
+				httpClient:    server.Client(),
+				command:       tt.command,
+				repoName:      "repo",
+				pullRequest:   tt.pullRequest,
+				pipelineRun:   tt.pipelineRun,
+				branch:        tt.branch,
+				controllerURL: server.URL + "/",
+			}
+
+			err := send(ctx, opts)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, gotRequest, tt.wantRequest)
+			if tt.wantSigned {
+				assert.Equal(t, gotHeader.Get(apincoming.TimestampHeader), "918014706")
+			} else {
+				assert.Equal(t, gotHeader.Get(apincoming.SignatureHeader), "")
+			}
+			assert.Equal(t, out.String(), strings.ReplaceAll(tt.wantOut, "{{URL}}", server.URL))
+			assert.Equal(t, statusRequests, len(tt.statuses))
+		})
+	}
+}
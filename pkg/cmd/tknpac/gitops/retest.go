@@ -0,0 +1,23 @@
+package gitops
+
+import (
+	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/spf13/cobra"
+)
+
+const retestLonghelp = `retest - run the PipelineRuns of a pull request again
+
+Ask the controller to run the PipelineRuns of a pull request again, the same
+way as a /retest comment, or a /retest <pipelinerun> comment with
+--pipelinerun, without commenting on the pull request.
+
+The request is sent to the incoming webhook of the Repository CR matching the
+branch targeted by the pull request, it needs to be of type pull-request. It is
+authenticated with the secret of the incoming webhook, read from the cluster.`
+
+// RetestCommand returns the retest command.
+func RetestCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	return newCommand(run, ioStreams, apincoming.CommandRetest, "Run the PipelineRuns of a pull request again", retestLonghelp)
+}
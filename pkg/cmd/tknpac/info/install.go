@@ -29,6 +29,61 @@ type InstallInfo struct {
 	HookConfig *github.HookConfig
 }
 
+// installOutput is the machine-readable output of the installation info.
+type installOutput struct {
+	InstallNamespace string             `json:"installNamespace"`
+	Version          string             `json:"version"`
+	GithubApp        *githubAppOutput   `json:"githubApp,omitempty"`
+	Repositories     []repositoryOutput `json:"repositories"`
+}
+
+type githubAppOutput struct {
+	Name               string            `json:"name"`
+	URL                string            `json:"url"`
+	HomePage           string            `json:"homePage"`
+	Description        string            `json:"description"`
+	Created            *github.Timestamp `json:"created,omitempty"`
+	InstallationsCount int               `json:"installationsCount"`
+	WebhookURL         string            `json:"webhookURL,omitempty"`
+}
+
+type repositoryOutput struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	URL       string `json:"url"`
+}
+
+func newInstallOutput(info *InstallInfo, targetNs, version string, repos *[]v1alpha1.Repository) installOutput {
+	out := installOutput{
+		InstallNamespace: targetNs,
+		Version:          version,
+		Repositories:     []repositoryOutput{},
+	}
+	if info.App != nil {
+		out.GithubApp = &githubAppOutput{
+			Name:               info.App.GetName(),
+			URL:                info.App.GetHTMLURL(),
+			HomePage:           info.App.GetExternalURL(),
+			Description:        info.App.GetDescription(),
+			Created:            info.App.CreatedAt,
+			InstallationsCount: info.App.GetInstallationsCount(),
+		}
+		if info.HookConfig != nil {
+			out.GithubApp.WebhookURL = info.HookConfig.GetURL()
+		}
+	}
+	if repos != nil {
+		for _, repo := range *repos {
+			out.Repositories = append(out.Repositories, repositoryOutput{
+				Name:      repo.GetName(),
+				Namespace: repo.GetNamespace(),
+				URL:       repo.Spec.URL,
+			})
+		}
+	}
+	return out
+}
+
 //go:embed templates/info.tmpl
 var infoTemplate string
 
@@ -60,7 +115,7 @@ func (g *InstallInfo) get(ctx context.Context) error {
 	return json.Unmarshal(data, &g.App)
 }
 
-func install(ctx context.Context, run *params.Run, ios *cli.IOStreams, apiURL string) error {
+func install(ctx context.Context, run *params.Run, ios *cli.IOStreams, apiURL, output string) error {
 	targetNs, version, err := params.GetInstallLocation(ctx, run)
 	if err != nil {
 		return err
@@ -89,6 +144,9 @@ func install(ctx context.Context, run *params.Run, ios *cli.IOStreams, apiURL st
 			reposItems = &repos.Items
 		}
 	}
+	if output != "" {
+		return cli.PrintOutput(ios.Out, output, newInstallOutput(info, targetNs, version, reposItems))
+	}
 	args := struct {
 		Info             *InstallInfo
 		InstallNamespace string
@@ -112,17 +170,20 @@ func install(ctx context.Context, run *params.Run, ios *cli.IOStreams, apiURL st
 }
 
 func installCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
-	var apiURL string
+	var apiURL, output string
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Provides installation info for pipelines-as-code.",
 		Long:  "Provides installation info for pipelines-as-code. This command is used to get the installation info\nIf you are running as administrator and use a GtiHub app it will print information about the GitHub app. ",
 		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := cli.ValidateOutputFormat(output); err != nil {
+				return err
+			}
 			ctx := context.Background()
 			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
 				return err
 			}
-			return install(ctx, run, ioStreams, apiURL)
+			return install(ctx, run, ioStreams, apiURL, output)
 		},
 		Annotations: map[string]string{
 			"commandType": "main",
@@ -130,6 +191,7 @@ func installCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	}
 	// add params for enteprise github
 	cmd.PersistentFlags().StringVarP(&apiURL, "github-api-url", "", "https://api.github.com", "Github API URL")
+	cli.AddOutputFlag(cmd, &output)
 	return cmd
 }
 
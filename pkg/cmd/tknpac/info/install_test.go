@@ -67,6 +67,7 @@ func TestInfo(t *testing.T) {
 		repositories     []*v1alpha1.Repository
 		controllerLabels map[string]string
 		controllerNs     string
+		output           string
 	}{
 		{
 			name:         "with github app",
@@ -92,6 +93,28 @@ func TestInfo(t *testing.T) {
 			name:         "no repos",
 			controllerNs: "pipelines-as-code",
 		},
+		{
+			name:         "with github app json output",
+			repositories: somerepositories,
+			controllerNs: "pipelines-as-code",
+			output:       "json",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pipelines-as-code-secret",
+					Namespace: "pipelines-as-code",
+				},
+				Data: map[string][]byte{
+					"github-application-id": []byte("12345"),
+					"github-private-key":    []byte(fakePrivateKey),
+				},
+			},
+		},
+		{
+			name:         "without github app yaml output",
+			repositories: somerepositories,
+			controllerNs: "pipelines-as-code",
+			output:       "yaml",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -175,7 +198,7 @@ func TestInfo(t *testing.T) {
 			}
 
 			io, out := tcli.NewIOStream()
-			err := install(ctx, cs, io, apiURL)
+			err := install(ctx, cs, io, apiURL, tt.output)
 			assert.NilError(t, err)
 			golden.Assert(t, out.String(), fmt.Sprintf("%s.golden", t.Name()))
 		})
@@ -0,0 +1,409 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"github.com/spf13/cobra"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+const (
+	outputFlag = "output"
+	strictFlag = "strict"
+	paramsFlag = "params"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// the rules reported by the linter, they are the ids of the SARIF rules.
+const (
+	ruleInvalidYAML           = "invalid-yaml"
+	ruleNoPipelineRun         = "no-pipelinerun"
+	ruleDuplicateName         = "duplicate-name"
+	ruleCelExpression         = "cel-expression"
+	ruleUnknownAnnotation     = "unknown-annotation"
+	ruleAnnotationFormat      = "annotation-format"
+	ruleMaxKeepRuns           = "max-keep-runs"
+	rulePathGlob              = "path-glob"
+	ruleOnComment             = "on-comment-regexp"
+	ruleQueueTimeout          = "queue-timeout"
+	ruleUnresolvedPlaceholder = "unresolved-placeholder"
+)
+
+var ruleDescriptions = map[string]string{
+	ruleInvalidYAML:           "The YAML document cannot be decoded as a Tekton resource",
+	ruleNoPipelineRun:         "No PipelineRun has been found",
+	ruleDuplicateName:         "Multiple PipelineRuns have the same name or generateName",
+	ruleCelExpression:         "The on-cel-expression annotation does not compile",
+	ruleUnknownAnnotation:     "The Pipelines-as-Code annotation is unknown",
+	ruleAnnotationFormat:      "The annotation value is not a value or a [value1, value2] list",
+	ruleMaxKeepRuns:           "The max-keep-runs annotation is not a positive integer",
+	rulePathGlob:              "The on-path-change or on-path-change-ignore glob is invalid",
+	ruleOnComment:             "The on-comment annotation is not a valid regular expression",
+	ruleQueueTimeout:          "The queue-timeout annotation is not a valid duration",
+	ruleUnresolvedPlaceholder: "The {{ }} placeholder is not a standard parameter",
+}
+
+// knownAnnotations are the Pipelines-as-Code annotations that can be set on
+// the PipelineRuns of the .tekton directory.
+var knownAnnotations = map[string]bool{
+	keys.OnEvent:             true,
+	keys.OnComment:           true,
+	keys.OnTargetBranch:      true,
+	keys.OnPathChange:        true,
+	keys.OnPathChangeIgnore:  true,
+	keys.OnLabel:             true,
+	keys.OnCelExpression:     true,
+	keys.TargetNamespace:     true,
+	keys.MaxKeepRuns:         true,
+	keys.CancelInProgress:    true,
+	keys.CancelInProgressKey: true,
+	keys.QueueTimeout:        true,
+	keys.PolicyTeams:         true,
+	keys.Pipeline:            true,
+	keys.Task:                true,
+}
+
+var taskAnnotationRe = regexp.MustCompile(`^` + regexp.QuoteMeta(keys.Task) + `-[0-9]+$`)
+
+// listAnnotations are the annotations taking a value or a list of values.
+var listAnnotations = []string{keys.OnEvent, keys.OnTargetBranch, keys.OnPathChange, keys.OnPathChangeIgnore, keys.OnLabel, keys.PolicyTeams}
+
+// standardParams are the parameters Pipelines-as-Code always substitutes in
+// the {{ }} placeholders, the ones with a body, headers, files or cel: prefix
+// are evaluated against the event.
+var standardParams = map[string]bool{
+	"revision": true, "repo_url": true, "repo_owner": true, "repo_name": true,
+	"target_branch": true, "source_branch": true, "git_tag": true, "source_url": true,
+	"sender": true, "target_namespace": true, "event_type": true, "trigger_comment": true,
+	"pull_request_labels": true, "pull_request_number": true, "git_auth_secret": true,
+}
+
+var yamlDocSeparatorRe = regexp.MustCompile(`(?m)^---\s*$`)
+
+var longhelp = fmt.Sprintf(`lint - check the PipelineRuns of a .tekton directory
+
+Check the PipelineRuns of the directories or files the same way the
+Pipelines-as-Code controller does before running them: the YAML documents are
+decoded as Tekton resources, the names of the PipelineRuns are unique, the
+on-cel-expression annotations compile, the annotation values are well formed
+and the {{ }} placeholders are standard parameters.
+
+Placeholders and CEL variables that are not standard parameters are reported
+as warnings, declare the custom parameters of your Repository CR with -p to
+check them.
+
+The command exits with a non-zero code when an error is found, or a warning
+with --strict, so it can be used as a pre-commit hook:
+
+%s pac lint .tekton/
+%s pac lint -o sarif .tekton/ > lint.sarif`, settings.TknBinaryName, settings.TknBinaryName)
+
+// Issue is a problem found in a file.
+type Issue struct {
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	PipelineRun string `json:"pipelinerun,omitempty"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+}
+
+type lintOpts struct {
+	output       string
+	strict       bool
+	customParams []string
+}
+
+// pipelineRunDoc is a PipelineRun decoded from a YAML document of a file.
+type pipelineRunDoc struct {
+	pipelineRun *tektonv1.PipelineRun
+	file        string
+	doc         string
+	line        int
+}
+
+func Command(ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &lintOpts{}
+	cmd := &cobra.Command{
+		Use:   "lint [dir|file]...",
+		Short: "Check the PipelineRuns of a .tekton directory",
+		Long:  longhelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{".tekton"}
+			}
+			switch opts.output {
+			case "human", "json", "sarif":
+			default:
+				return fmt.Errorf("invalid output format %q, it should be one of human, json or sarif", opts.output)
+			}
+			issues, err := lint(cmd.Context(), args, opts.customParams)
+			if err != nil {
+				return err
+			}
+			if err := printIssues(ioStreams, opts.output, issues); err != nil {
+				return err
+			}
+			errors, warnings := countIssues(issues)
+			if errors > 0 || (opts.strict && warnings > 0) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d error(s) and %d warning(s) found", errors, warnings)
+			}
+			return nil
+		},
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+	}
+	cmd.Flags().StringVarP(&opts.output, outputFlag, "o", "human", "output format: human, json or sarif")
+	cmd.Flags().BoolVar(&opts.strict, strictFlag, false, "exit with a non-zero code on warnings")
+	cmd.Flags().StringSliceVarP(&opts.customParams, paramsFlag, "p", nil, "name of the custom parameters of the Repository CR")
+	return cmd
+}
+
+// lint returns the issues found in the YAML files of the paths.
+func lint(ctx context.Context, paths, customParams []string) ([]Issue, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := zap.NewNop().Sugar()
+	files, err := listYAMLFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []Issue{}
+	prDocs := []pipelineRunDoc{}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		line := 1
+		for _, doc := range yamlDocSeparatorRe.Split(string(b), -1) {
+			docLine := line
+			line += strings.Count(doc, "\n")
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			types, err := resolve.ReadTektonTypes(ctx, logger, doc)
+			if err != nil {
+				issues = append(issues, Issue{File: file, Line: docLine, Rule: ruleInvalidYAML, Severity: severityError, Message: err.Error()})
+				continue
+			}
+			for _, verr := range types.ValidationErrors {
+				issues = append(issues, Issue{File: file, Line: docLine, PipelineRun: verr.Name, Rule: ruleInvalidYAML, Severity: severityError, Message: verr.Err.Error()})
+			}
+			for _, pr := range types.PipelineRuns {
+				prDocs = append(prDocs, pipelineRunDoc{pipelineRun: pr, file: file, doc: doc, line: docLine})
+			}
+			issues = append(issues, lintPlaceholders(file, doc, docLine, customParams)...)
+		}
+	}
+
+	if len(prDocs) == 0 && len(files) > 0 {
+		issues = append(issues, Issue{File: files[0], Rule: ruleNoPipelineRun, Severity: severityError, Message: "could not find any PipelineRun"})
+	}
+	for _, prDoc := range prDocs {
+		issues = append(issues, lintAnnotations(prDoc, customParams)...)
+	}
+	issues = append(issues, lintDuplicateNames(prDocs)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// listYAMLFiles returns the YAML files of the paths, directories are walked
+// recursively.
+func listYAMLFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(fname string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (filepath.Ext(fname) == ".yaml" || filepath.Ext(fname) == ".yml") {
+				files = append(files, fname)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// lintDuplicateNames reports the PipelineRuns sharing a name or generateName
+// with a previous one, like the controller refuses to run them.
+func lintDuplicateNames(prDocs []pipelineRunDoc) []Issue {
+	issues := []Issue{}
+	seen := []*tektonv1.PipelineRun{}
+	for _, prDoc := range prDocs {
+		if err := resolve.PipelineRunsWithSameName(append(seen, prDoc.pipelineRun)); err != nil {
+			issues = append(issues, prDoc.issue(ruleDuplicateName, severityError, err.Error(), "name:"))
+			continue
+		}
+		seen = append(seen, prDoc.pipelineRun)
+	}
+	return issues
+}
+
+// lintAnnotations checks the Pipelines-as-Code annotations of the PipelineRun.
+func lintAnnotations(prDoc pipelineRunDoc, customParams []string) []Issue {
+	issues := []Issue{}
+	annotations := prDoc.pipelineRun.GetAnnotations()
+
+	names := make([]string, 0, len(annotations))
+	for name := range annotations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.HasPrefix(name, pipelinesascode.GroupName+"/") && !knownAnnotations[name] && !taskAnnotationRe.MatchString(name) {
+			issues = append(issues, prDoc.issue(ruleUnknownAnnotation, severityWarning,
+				fmt.Sprintf("unknown annotation %s", name), name))
+		}
+	}
+
+	for _, name := range listAnnotations {
+		value, ok := annotations[name]
+		if !ok {
+			continue
+		}
+		values, err := matcher.GetAnnotationValues(value)
+		if err != nil {
+			issues = append(issues, prDoc.issue(ruleAnnotationFormat, severityError, fmt.Sprintf("%s: %s", name, err.Error()), name))
+			continue
+		}
+		if name != keys.OnPathChange && name != keys.OnPathChangeIgnore {
+			continue
+		}
+		for _, pattern := range values {
+			if _, err := glob.Compile(pattern); err != nil {
+				issues = append(issues, prDoc.issue(rulePathGlob, severityError,
+					fmt.Sprintf("%s: invalid glob %q: %s", name, pattern, err.Error()), name))
+			}
+		}
+	}
+
+	if value, ok := annotations[keys.OnCelExpression]; ok {
+		if err := matcher.CheckCelExpression(value, customParams); err != nil {
+			severity := severityError
+			if strings.Contains(err.Error(), "undeclared reference") {
+				// the variable may be a custom parameter of the Repository CR
+				severity = severityWarning
+			}
+			issues = append(issues, prDoc.issue(ruleCelExpression, severity, err.Error(), keys.OnCelExpression))
+		}
+	}
+
+	if value, ok := annotations[keys.OnComment]; ok {
+		if _, err := regexp.Compile(value); err != nil {
+			issues = append(issues, prDoc.issue(ruleOnComment, severityError,
+				fmt.Sprintf("%s: %s", keys.OnComment, err.Error()), keys.OnComment))
+		}
+	}
+
+	if value, ok := annotations[keys.MaxKeepRuns]; ok {
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			issues = append(issues, prDoc.issue(ruleMaxKeepRuns, severityError,
+				fmt.Sprintf("%s: %q is not a positive integer", keys.MaxKeepRuns, value), keys.MaxKeepRuns))
+		}
+	}
+
+	if value, ok := annotations[keys.QueueTimeout]; ok {
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			issues = append(issues, prDoc.issue(ruleQueueTimeout, severityError,
+				fmt.Sprintf("%s: %q is not a valid duration", keys.QueueTimeout, value), keys.QueueTimeout))
+		}
+	}
+	return issues
+}
+
+// lintPlaceholders reports the {{ }} placeholders of the document that are
+// neither standard nor declared custom parameters.
+func lintPlaceholders(file, doc string, docLine int, customParams []string) []Issue {
+	issues := []Issue{}
+	for i, line := range strings.Split(doc, "\n") {
+		for _, match := range keys.ParamsRe.FindAllStringSubmatch(line, -1) {
+			key := strings.TrimSpace(match[1])
+			if standardParams[key] || containsString(customParams, key) ||
+				strings.HasPrefix(key, "body") || strings.HasPrefix(key, "headers") ||
+				strings.HasPrefix(key, "files") || strings.HasPrefix(key, "cel:") {
+				continue
+			}
+			issues = append(issues, Issue{
+				File:     file,
+				Line:     docLine + i,
+				Rule:     ruleUnresolvedPlaceholder,
+				Severity: severityWarning,
+				Message:  fmt.Sprintf("%s is not a standard parameter, it is left as is unless it is a custom parameter of the Repository CR", match[0]),
+			})
+		}
+	}
+	return issues
+}
+
+// issue returns an issue located at the first line of the document containing
+// the needle.
+func (p pipelineRunDoc) issue(rule, severity, message, needle string) Issue {
+	name := p.pipelineRun.GetName()
+	if name == "" {
+		name = p.pipelineRun.GetGenerateName()
+	}
+	line := p.line
+	for i, l := range strings.Split(p.doc, "\n") {
+		if strings.Contains(l, needle) {
+			line = p.line + i
+			break
+		}
+	}
+	return Issue{File: p.file, Line: line, PipelineRun: name, Rule: rule, Severity: severity, Message: message}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func countIssues(issues []Issue) (int, int) {
+	errors, warnings := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == severityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
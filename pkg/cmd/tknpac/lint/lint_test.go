@@ -0,0 +1,201 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        []string
+		customParams []string
+		want         []string
+	}{
+		{
+			name:  "good",
+			files: []string{"testdata/good.yaml"},
+			want:  []string{},
+		},
+		{
+			name:  "bad",
+			files: []string{"testdata/bad.yaml"},
+			want: []string{
+				"6:error:cel-expression",
+				"7:error:max-keep-runs",
+				"8:warning:unknown-annotation",
+				"9:error:path-glob",
+				"10:error:on-comment-regexp",
+				"17:warning:unresolved-placeholder",
+				"24:error:duplicate-name",
+				"28:error:invalid-yaml",
+			},
+		},
+		{
+			name:         "bad with custom params",
+			files:        []string{"testdata/bad.yaml"},
+			customParams: []string{"image_registry"},
+			want: []string{
+				"6:error:cel-expression",
+				"7:error:max-keep-runs",
+				"8:warning:unknown-annotation",
+				"9:error:path-glob",
+				"10:error:on-comment-regexp",
+				"24:error:duplicate-name",
+				"28:error:invalid-yaml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := lint(context.Background(), tt.files, tt.customParams)
+			assert.NilError(t, err)
+			got := []string{}
+			for _, issue := range issues {
+				got = append(got, fmt.Sprintf("%d:%s:%s", issue.Line, issue.Severity, issue.Rule))
+			}
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestLintAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations string
+		wantRule    string
+		wantMessage string
+	}{
+		{
+			name:        "wrong list format",
+			annotations: `pipelinesascode.tekton.dev/on-target-branch: "[main"`,
+			wantRule:    ruleAnnotationFormat,
+			wantMessage: "annotations in pipeline are in wrong format",
+		},
+		{
+			name:        "negative max-keep-runs",
+			annotations: `pipelinesascode.tekton.dev/max-keep-runs: "-1"`,
+			wantRule:    ruleMaxKeepRuns,
+			wantMessage: `"-1" is not a positive integer`,
+		},
+		{
+			name:        "invalid queue-timeout",
+			annotations: `pipelinesascode.tekton.dev/queue-timeout: "ten minutes"`,
+			wantRule:    ruleQueueTimeout,
+			wantMessage: `"ten minutes" is not a valid duration`,
+		},
+		{
+			name:        "undeclared cel variable",
+			annotations: `pipelinesascode.tekton.dev/on-cel-expression: environment == "prod"`,
+			wantRule:    ruleCelExpression,
+			wantMessage: "undeclared reference to 'environment'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fs.NewDir(t, "lint", fs.WithFile("pr.yaml", `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: pr
+  annotations:
+    `+tt.annotations+`
+spec:
+  pipelineSpec:
+    tasks: []
+`))
+			issues, err := lint(context.Background(), []string{dir.Path()}, nil)
+			assert.NilError(t, err)
+			assert.Equal(t, len(issues), 1)
+			assert.Equal(t, issues[0].Rule, tt.wantRule)
+			assert.Equal(t, issues[0].Line, 6)
+			assert.Equal(t, issues[0].PipelineRun, "pr")
+			assert.Assert(t, strings.Contains(issues[0].Message, tt.wantMessage), issues[0].Message)
+		})
+	}
+}
+
+func TestLintNoPipelineRun(t *testing.T) {
+	dir := fs.NewDir(t, "lint", fs.WithFile("task.yaml", "apiVersion: tekton.dev/v1\nkind: Task\nmetadata:\n  name: task\n"))
+	issues, err := lint(context.Background(), []string{dir.Path()}, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, issues, []Issue{{File: filepath.Join(dir.Path(), "task.yaml"), Rule: ruleNoPipelineRun, Severity: severityError, Message: "could not find any PipelineRun"}})
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "human",
+			args: []string{"testdata/good.yaml"},
+		},
+		{
+			name:    "human errors",
+			args:    []string{"testdata/bad.yaml"},
+			wantErr: "6 error(s) and 2 warning(s) found",
+		},
+		{
+			name:    "strict",
+			args:    []string{"--strict", "-p", "image_registry", "testdata/good.yaml", "testdata/bad.yaml"},
+			wantErr: "6 error(s) and 1 warning(s) found",
+		},
+		{
+			name:    "json",
+			args:    []string{"-o", "json", "testdata/bad.yaml"},
+			wantErr: "6 error(s) and 2 warning(s) found",
+		},
+		{
+			name:    "invalid output",
+			args:    []string{"-o", "xml", "testdata/bad.yaml"},
+			wantErr: `invalid output format "xml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ioStreams, _, out, errOut := cli.IOTest()
+			cmd := Command(ioStreams)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(out)
+			cmd.SetErr(errOut)
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			if tt.name != "invalid output" {
+				golden.Assert(t, out.String(), strings.ReplaceAll(fmt.Sprintf("%s.golden", t.Name()), "/", "-"))
+			}
+		})
+	}
+}
+
+func TestSarif(t *testing.T) {
+	issues, err := lint(context.Background(), []string{"testdata/bad.yaml"}, nil)
+	assert.NilError(t, err)
+	b, err := json.Marshal(toSarif(issues))
+	assert.NilError(t, err)
+
+	log := sarifLog{}
+	assert.NilError(t, json.Unmarshal(b, &log))
+	assert.Equal(t, log.Version, "2.1.0")
+	assert.Equal(t, len(log.Runs), 1)
+	assert.Equal(t, len(log.Runs[0].Tool.Driver.Rules), len(ruleDescriptions))
+	assert.Equal(t, len(log.Runs[0].Results), len(issues))
+	result := log.Runs[0].Results[0]
+	assert.Equal(t, result.RuleID, ruleCelExpression)
+	assert.Equal(t, result.Level, "error")
+	assert.Equal(t, result.Locations[0].PhysicalLocation.ArtifactLocation.URI, "testdata/bad.yaml")
+	assert.Equal(t, result.Locations[0].PhysicalLocation.Region.StartLine, 6)
+}
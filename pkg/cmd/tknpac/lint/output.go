@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/versiondata"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifInfoURI = "https://pipelinesascode.com/docs/cli/lint/"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func printIssues(ioStreams *cli.IOStreams, output string, issues []Issue) error {
+	switch output {
+	case "json":
+		return printJSON(ioStreams, issues)
+	case "sarif":
+		return printJSON(ioStreams, toSarif(issues))
+	}
+
+	cs := ioStreams.ColorScheme()
+	for _, issue := range issues {
+		location := issue.File
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+		}
+		severity := cs.Red(issue.Severity)
+		if issue.Severity == severityWarning {
+			severity = cs.Yellow(issue.Severity)
+		}
+		fmt.Fprintf(ioStreams.Out, "%s: %s: %s %s\n", cs.Bold(location), severity, issue.Message, cs.Dimmed("["+issue.Rule+"]"))
+	}
+	errors, warnings := countIssues(issues)
+	if errors == 0 && warnings == 0 {
+		fmt.Fprintf(ioStreams.Out, "%s no issues found\n", cs.SuccessIcon())
+		return nil
+	}
+	fmt.Fprintf(ioStreams.Out, "\n%d error(s), %d warning(s)\n", errors, warnings)
+	return nil
+}
+
+func printJSON(ioStreams *cli.IOStreams, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ioStreams.Out, string(b))
+	return nil
+}
+
+// toSarif converts the issues to a SARIF log, as understood by the code
+// scanning of the git providers.
+func toSarif(issues []Issue) sarifLog {
+	ruleIDs := make([]string, 0, len(ruleDescriptions))
+	for id := range ruleDescriptions {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: ruleDescriptions[id]}})
+	}
+
+	results := make([]sarifResult, 0, len(issues))
+	for _, issue := range issues {
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(issue.File)},
+		}}
+		if issue.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line}
+		}
+		results = append(results, sarifResult{
+			RuleID:    issue.Rule,
+			Level:     issue.Severity,
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           settings.TknBinaryName + "-pac-lint",
+				Version:        strings.TrimSpace(versiondata.Version),
+				InformationURI: sarifInfoURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
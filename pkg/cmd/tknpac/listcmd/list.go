@@ -31,7 +31,7 @@ var (
 
 func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	var noheaders, useRealTime, allNamespaces bool
-	var selectors string
+	var selectors, output string
 
 	cmd := &cobra.Command{
 		Use:          "list",
@@ -64,6 +64,10 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			opts.Output = output
+			if err := cli.ValidateOutputFormat(opts.Output); err != nil {
+				return err
+			}
 			ctx := context.Background()
 			err = run.Clients.NewClients(ctx, &run.Info)
 			if err != nil {
@@ -97,9 +101,23 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 			"supports '=', "+
 			"'==',"+
 			" and '!='.(e.g. -l key1=value1,key2=value2)")
+	cli.AddOutputFlag(cmd, &output)
 	return cmd
 }
 
+// repositoryOutput is the machine-readable output of a repository.
+type repositoryOutput struct {
+	Name      string                        `json:"name"`
+	Namespace string                        `json:"namespace"`
+	URL       string                        `json:"url"`
+	LastRun   *v1alpha1.RepositoryRunStatus `json:"lastRun,omitempty"`
+	Queue     *status.QueueStatus           `json:"queue,omitempty"`
+}
+
+type listOutput struct {
+	Items []repositoryOutput `json:"items"`
+}
+
 func formatStatus(status *v1alpha1.RepositoryRunStatus, cs *cli.ColorScheme, c clockwork.Clock, ns string, opts *cli.PacCliOpts) string {
 	// TODO: we could make a hyperlink to the console namespace list of repo if
 	// we wanted to go the extra step
@@ -145,6 +163,25 @@ func list(ctx context.Context, cs *params.Run, opts *cli.PacCliOpts, ioStreams *
 		return err
 	}
 
+	if opts.Output != "" {
+		out := listOutput{Items: []repositoryOutput{}}
+		for _, repo := range repositories.Items {
+			ro := repositoryOutput{
+				Name:      repo.GetName(),
+				Namespace: repo.GetNamespace(),
+				URL:       repo.Spec.URL,
+			}
+			if statuses := status.MixLivePRandRepoStatus(ctx, cs, repo); len(statuses) > 0 {
+				ro.LastRun = &statuses[0]
+			}
+			if ro.Queue, err = status.RepositoryQueueStatus(ctx, cs, repo); err != nil {
+				return err
+			}
+			out.Items = append(out.Items, ro)
+		}
+		return cli.PrintOutput(ioStreams.Out, opts.Output, out)
+	}
+
 	type repoStatusInfo struct {
 		Status               *v1alpha1.RepositoryRunStatus
 		Name, Namespace, URL string
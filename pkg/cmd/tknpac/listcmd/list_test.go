@@ -110,6 +110,36 @@ func TestList(t *testing.T) {
 		},
 	}
 
+	queuedPR := func(name, state string, reason knativeapis.Condition, created time.Duration) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace1.GetName(),
+				CreationTimestamp: metav1.Time{Time: cw.Now().Add(created)},
+				Labels: map[string]string{
+					keys.Repository: repoNamespace1.GetName(),
+					keys.State:      state,
+				},
+				Annotations: map[string]string{
+					keys.SHA: name,
+				},
+			},
+			Status: tektonv1.PipelineRunStatus{
+				Status: knativeduckv1.Status{Conditions: knativeduckv1.Conditions{reason}},
+				PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+					StartTime: &metav1.Time{Time: cw.Now().Add(created)},
+				},
+			},
+		}
+	}
+	runningCondition := knativeapis.Condition{Type: knativeapis.ConditionSucceeded, Status: corev1.ConditionUnknown, Reason: running}
+	pendingCondition := knativeapis.Condition{Type: knativeapis.ConditionSucceeded, Status: corev1.ConditionUnknown, Reason: "PipelineRunPending"}
+	queueRuns := []*tektonv1.PipelineRun{
+		queuedPR("queued-second", "queued", pendingCondition, -time.Minute),
+		queuedPR("queued-first", "queued", pendingCondition, -2*time.Minute),
+		queuedPR("started", "started", runningCondition, -3*time.Minute),
+	}
+
 	type args struct {
 		namespaces       []*corev1.Namespace
 		repositories     []*pacv1alpha1.Repository
@@ -187,6 +217,50 @@ func TestList(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Test json output",
+			args: args{
+				opts:             &cli.PacCliOpts{Output: "json"},
+				currentNamespace: namespace1.GetName(),
+				namespaces:       []*corev1.Namespace{namespace1},
+				repositories:     []*pacv1alpha1.Repository{repoNamespace1},
+				pipelineruns:     queueRuns,
+			},
+		},
+		{
+			name: "Test yaml output",
+			args: args{
+				opts:             &cli.PacCliOpts{Output: "yaml"},
+				currentNamespace: namespace1.GetName(),
+				namespaces:       []*corev1.Namespace{namespace1},
+				repositories:     []*pacv1alpha1.Repository{repoNamespace1},
+			},
+		},
+		{
+			name: "Test jsonpath output",
+			args: args{
+				opts:             &cli.PacCliOpts{AllNameSpaces: true, Output: `jsonpath={range .items[*]}{.namespace}/{.name} {.lastRun.sha}{"\n"}{end}`},
+				currentNamespace: "namespace",
+				namespaces:       []*corev1.Namespace{namespace1, namespace2},
+				repositories:     []*pacv1alpha1.Repository{repoNamespace1, repoNamespace2},
+			},
+		},
+		{
+			name: "Test go-template output",
+			args: args{
+				opts:             &cli.PacCliOpts{Output: `go-template={{range .items}}{{.name}} pending: {{len .queue.pending}}{{"\n"}}{{end}}`},
+				currentNamespace: namespace1.GetName(),
+				namespaces:       []*corev1.Namespace{namespace1},
+				repositories:     []*pacv1alpha1.Repository{repoNamespace1},
+				pipelineruns:     queueRuns,
+			},
+		},
+		{
+			name: "Test json output with no repositories",
+			args: args{
+				opts: &cli.PacCliOpts{Namespace: "default", Output: "json"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
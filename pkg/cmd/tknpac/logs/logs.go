@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"syscall"
 
@@ -33,7 +31,10 @@ logs - show the PipelineRun logs attached to a Repository
 
 tkn pac logs will get the logs of a PipelineRun belonging to a Repository.
 
-the PipelineRun needs to exist on the kubernetes cluster to be able to display the logs.`
+the PipelineRun needs to exist on the kubernetes cluster to be able to display the logs.
+
+The logs of the steps are shown task by task in the order they have been
+started, the values of the secrets used by the PipelineRun are redacted.`
 
 const (
 	namespaceFlag          = "namespace"
@@ -42,6 +43,10 @@ const (
 	defaultLimit           = -1
 	openWebBrowserFlag     = "web"
 	useLastPipelineRunFlag = "last"
+	followFlag             = "follow"
+	taskFlag               = "task"
+	failedOnlyFlag         = "failed-only"
+	sinceFlag              = "since"
 )
 
 type logOption struct {
@@ -54,6 +59,7 @@ type logOption struct {
 	limit      int
 	webBrowser bool
 	useLastPR  bool
+	streamOpts StreamOpts
 }
 
 func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
@@ -104,14 +110,19 @@ func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if tknPath == "" {
-				if tknPath, err = getTknPath(); err != nil {
-					return err
-				}
-
-				if tknPath == "" {
-					return fmt.Errorf("cannot find tkn binary in Path")
-				}
+
+			streamOpts := StreamOpts{}
+			if streamOpts.Follow, err = cmd.Flags().GetBool(followFlag); err != nil {
+				return err
+			}
+			if streamOpts.Tasks, err = cmd.Flags().GetStringSlice(taskFlag); err != nil {
+				return err
+			}
+			if streamOpts.FailedOnly, err = cmd.Flags().GetBool(failedOnlyFlag); err != nil {
+				return err
+			}
+			if streamOpts.Since, err = cmd.Flags().GetDuration(sinceFlag); err != nil {
+				return err
 			}
 
 			lopts := &logOption{
@@ -124,13 +135,26 @@ func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 				webBrowser: webBrowser,
 				tknPath:    tknPath,
 				useLastPR:  useLastPR,
+				streamOpts: streamOpts,
 			}
 			return log(ctx, lopts)
 		},
 	}
 
 	cmd.Flags().StringP(
-		tknPathFlag, "", "", fmt.Sprintf("Path to the %s binary (default to search for it in you $PATH)", settings.TknBinaryName))
+		tknPathFlag, "", "", fmt.Sprintf("Path to the %s binary to show the logs with instead of streaming them", settings.TknBinaryName))
+
+	cmd.Flags().BoolP(
+		followFlag, "f", false, "follow the logs until the PipelineRun is done")
+
+	cmd.Flags().StringSlice(
+		taskFlag, nil, "only show the logs of those tasks of the pipeline")
+
+	cmd.Flags().Bool(
+		failedOnlyFlag, false, "only show the logs of the failed tasks")
+
+	cmd.Flags().Duration(
+		sinceFlag, 0, "only show the logs newer than a relative duration like 5s, 2m, or 3h")
 
 	cmd.Flags().StringP(
 		namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
@@ -152,15 +176,6 @@ func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func getTknPath() (string, error) {
-	fname, err := exec.LookPath(settings.TknBinaryName)
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Abs(fname)
-}
-
 // getPipelineRunsToRepo returns all PipelineRuns running in a namespace.
 func getPipelineRunsToRepo(ctx context.Context, lopt *logOption, repoName string) ([]string, error) {
 	opts := metav1.ListOptions{
@@ -240,7 +255,10 @@ func log(ctx context.Context, lo *logOption) error {
 	if lo.webBrowser {
 		return showLogsWithWebConsole(ctx, lo, replyName)
 	}
-	return showlogswithtkn(lo.tknPath, replyName, lo.cs.Info.Kube.Namespace)
+	if lo.tknPath != "" {
+		return ShowLogsWithTkn(lo.tknPath, replyName, lo.cs.Info.Kube.Namespace)
+	}
+	return StreamPipelineRunLogs(ctx, lo.cs, lo.ioStreams, replyName, lo.cs.Info.Kube.Namespace, lo.streamOpts)
 }
 
 func showLogsWithWebConsole(ctx context.Context, lo *logOption, pr string) error {
@@ -257,7 +275,9 @@ func showLogsWithWebConsole(ctx context.Context, lo *logOption, pr string) error
 	return browser.OpenWebBrowser(ctx, lo.cs.Clients.ConsoleUI().DetailURL(prObj))
 }
 
-func showlogswithtkn(tknPath, pr, ns string) error {
+// ShowLogsWithTkn replaces the current process with tkn following the logs of
+// the PipelineRun.
+func ShowLogsWithTkn(tknPath, pr, ns string) error {
 	//nolint: gosec
 	if err := syscall.Exec(tknPath, []string{tknPath, "pr", "logs", "-f", "-n", ns, pr}, os.Environ()); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Command finished with error: %v", err)
@@ -0,0 +1,246 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	ktypes "github.com/openshift-pipelines/pipelines-as-code/pkg/secrets/types"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const stepContainerPrefix = "step-"
+
+// pollInterval is how often the PipelineRun and its pods are checked while
+// following the logs.
+var pollInterval = time.Second
+
+// StreamOpts are the options to stream the logs of a PipelineRun.
+type StreamOpts struct {
+	// Follow waits for the TaskRuns to run and streams their logs until the
+	// PipelineRun is done.
+	Follow bool
+	// Tasks only shows the logs of those pipeline tasks.
+	Tasks []string
+	// FailedOnly only shows the logs of the failed TaskRuns.
+	FailedOnly bool
+	// Since only shows the logs newer than this duration.
+	Since time.Duration
+}
+
+type logStreamer struct {
+	cs           *params.Run
+	ioStreams    *cli.IOStreams
+	opts         StreamOpts
+	secretValues []ktypes.SecretValue
+}
+
+// StreamPipelineRunLogs writes the logs of the steps of the TaskRuns of a
+// PipelineRun in the order they have been started, each line is prefixed by
+// the task and step name and the values of the secrets used by the
+// PipelineRun are redacted.
+func StreamPipelineRunLogs(ctx context.Context, cs *params.Run, ioStreams *cli.IOStreams, name, ns string, opts StreamOpts) error {
+	pr, err := cs.Clients.Tekton.TektonV1().PipelineRuns(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	kinteract, err := kubeinteraction.NewKubernetesInteraction(cs)
+	if err != nil {
+		return err
+	}
+	ls := &logStreamer{
+		cs:           cs,
+		ioStreams:    ioStreams,
+		opts:         opts,
+		secretValues: secrets.GetSecretsAttachedToPipelineRun(ctx, kinteract, pr),
+	}
+
+	shown := map[string]bool{}
+	for {
+		taskRuns, err := ls.taskRuns(ctx, pr)
+		if err != nil {
+			return err
+		}
+		for _, tr := range taskRuns {
+			if shown[tr.GetName()] {
+				continue
+			}
+			ready, err := ls.showTaskRun(ctx, tr)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				// keep the order of the TaskRuns, wait for this one before the next ones
+				break
+			}
+			shown[tr.GetName()] = true
+		}
+
+		if !opts.Follow || (pr.IsDone() && len(shown) == len(taskRuns)) {
+			return nil
+		}
+		if err := wait(ctx); err != nil {
+			return err
+		}
+		if pr, err = cs.Clients.Tekton.TektonV1().PipelineRuns(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			return err
+		}
+	}
+}
+
+// taskRuns returns the TaskRuns of the PipelineRun sorted by start time, the
+// ones not started yet are last.
+func (ls *logStreamer) taskRuns(ctx context.Context, pr *tektonv1.PipelineRun) ([]*tektonv1.TaskRun, error) {
+	taskRuns := []*tektonv1.TaskRun{}
+	for _, child := range pr.Status.ChildReferences {
+		if child.Kind != "TaskRun" {
+			continue
+		}
+		tr, err := ls.cs.Clients.Tekton.TektonV1().TaskRuns(pr.GetNamespace()).Get(ctx, child.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		taskRuns = append(taskRuns, tr)
+	}
+	slices.SortStableFunc(taskRuns, func(a, b *tektonv1.TaskRun) int {
+		switch {
+		case a.Status.StartTime == nil && b.Status.StartTime == nil:
+			return 0
+		case a.Status.StartTime == nil:
+			return 1
+		case b.Status.StartTime == nil:
+			return -1
+		}
+		return a.Status.StartTime.Compare(b.Status.StartTime.Time)
+	})
+	return taskRuns, nil
+}
+
+// showTaskRun writes the logs of the steps of a TaskRun, it returns false
+// when the TaskRun is not ready yet while following the logs.
+func (ls *logStreamer) showTaskRun(ctx context.Context, tr *tektonv1.TaskRun) (bool, error) {
+	task := taskName(tr)
+	if len(ls.opts.Tasks) > 0 && !slices.Contains(ls.opts.Tasks, task) {
+		return true, nil
+	}
+	if ls.opts.FailedOnly {
+		if !tr.IsDone() {
+			return !ls.opts.Follow, nil
+		}
+		if !tr.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
+			return true, nil
+		}
+	}
+	if tr.Status.PodName == "" {
+		// a TaskRun skipped or cancelled before running has no pod
+		return tr.IsDone() || !ls.opts.Follow, nil
+	}
+
+	pod, err := ls.cs.Clients.Kube.CoreV1().Pods(tr.GetNamespace()).Get(ctx, tr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, container := range pod.Spec.Containers {
+		if !strings.HasPrefix(container.Name, stepContainerPrefix) {
+			continue
+		}
+		if ls.opts.Follow {
+			if pod, err = ls.waitForContainer(ctx, pod, container.Name); err != nil {
+				return false, err
+			}
+		}
+		if err := ls.showContainer(ctx, pod, task, container.Name); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// waitForContainer waits for a container to start, or for the pod to finish
+// when it never does.
+func (ls *logStreamer) waitForContainer(ctx context.Context, pod *corev1.Pod, container string) (*corev1.Pod, error) {
+	for {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return pod, nil
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == container && (status.State.Running != nil || status.State.Terminated != nil) {
+				return pod, nil
+			}
+		}
+		if err := wait(ctx); err != nil {
+			return nil, err
+		}
+		var err error
+		if pod, err = ls.cs.Clients.Kube.CoreV1().Pods(pod.GetNamespace()).Get(ctx, pod.GetName(), metav1.GetOptions{}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (ls *logStreamer) showContainer(ctx context.Context, pod *corev1.Pod, task, container string) error {
+	podOpts := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    ls.opts.Follow,
+	}
+	if ls.opts.Since > 0 {
+		seconds := int64(ls.opts.Since.Seconds())
+		podOpts.SinceSeconds = &seconds
+	}
+	stream, err := ls.cs.Clients.Kube.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), podOpts).Stream(ctx)
+	if err != nil {
+		fmt.Fprintf(ls.ioStreams.ErrOut, "cannot get the logs of %s: %v\n", prefix(task, container), err)
+		return nil
+	}
+	defer stream.Close()
+
+	cs := ls.ioStreams.ColorScheme()
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = secrets.ReplaceSecretsInText(strings.TrimSuffix(line, "\n"), ls.secretValues)
+			fmt.Fprintf(ls.ioStreams.Out, "%s %s\n", cs.Bold(prefix(task, container)), line)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func prefix(task, container string) string {
+	return fmt.Sprintf("[%s : %s]", task, strings.TrimPrefix(container, stepContainerPrefix))
+}
+
+// taskName returns the name of the task in the pipeline.
+func taskName(tr *tektonv1.TaskRun) string {
+	if name := tr.GetLabels()[pipeline.PipelineTaskLabelKey]; name != "" {
+		return name
+	}
+	return tr.GetName()
+}
+
+func wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(pollInterval):
+		return nil
+	}
+}
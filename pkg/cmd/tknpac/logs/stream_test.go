@@ -0,0 +1,200 @@
+package logs
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	knativeapis "knative.dev/pkg/apis"
+	knativeduckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func makeTaskRun(name, task string, status corev1.ConditionStatus, started time.Time, podName string) *tektonv1.TaskRun {
+	return &tektonv1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels:    map[string]string{pipeline.PipelineTaskLabelKey: task},
+		},
+		Status: tektonv1.TaskRunStatus{
+			Status: knativeduckv1.Status{Conditions: knativeduckv1.Conditions{
+				{Type: knativeapis.ConditionSucceeded, Status: status},
+			}},
+			TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+				PodName:   podName,
+				StartTime: &metav1.Time{Time: started},
+			},
+		},
+	}
+}
+
+func makePod(name string, phase corev1.PodPhase, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	for _, c := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: c})
+	}
+	return pod
+}
+
+func TestStreamPipelineRunLogs(t *testing.T) {
+	started := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "ns"},
+		Spec: tektonv1.PipelineRunSpec{
+			PipelineSpec: &tektonv1.PipelineSpec{
+				Tasks: []tektonv1.PipelineTask{{
+					Name: "build",
+					TaskSpec: &tektonv1.EmbeddedTask{TaskSpec: tektonv1.TaskSpec{
+						Steps: []tektonv1.Step{{
+							Name: "compile",
+							Env: []corev1.EnvVar{{
+								Name: "TOKEN",
+								ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "secret"},
+									Key:                  "token",
+								}},
+							}},
+						}},
+					}},
+				}},
+			},
+		},
+		Status: tektonv1.PipelineRunStatus{
+			Status: knativeduckv1.Status{Conditions: knativeduckv1.Conditions{
+				{Type: knativeapis.ConditionSucceeded, Status: corev1.ConditionFalse},
+			}},
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				ChildReferences: []tektonv1.ChildStatusReference{
+					{TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}, Name: "pr-build", PipelineTaskName: "build"},
+					{TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}, Name: "pr-clone", PipelineTaskName: "clone"},
+					{TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}, Name: "pr-skipped", PipelineTaskName: "skipped"},
+				},
+			},
+		},
+	}
+	taskRuns := []*tektonv1.TaskRun{
+		makeTaskRun("pr-build", "build", corev1.ConditionFalse, started.Add(time.Minute), "pr-build-pod"),
+		makeTaskRun("pr-clone", "clone", corev1.ConditionTrue, started, "pr-clone-pod"),
+		makeTaskRun("pr-skipped", "skipped", corev1.ConditionFalse, started.Add(2*time.Minute), ""),
+	}
+	pods := []*corev1.Pod{
+		makePod("pr-clone-pod", corev1.PodSucceeded, "step-clone"),
+		makePod("pr-build-pod", corev1.PodFailed, "step-compile", "step-test", "sidecar-registry"),
+	}
+
+	tests := []struct {
+		name   string
+		opts   StreamOpts
+		secret string
+		want   string
+	}{
+		{
+			name: "all tasks in order",
+			want: "[clone : clone] fake logs\n[build : compile] fake logs\n[build : test] fake logs\n",
+		},
+		{
+			name:   "secrets are redacted",
+			secret: "fake",
+			want:   "[clone : clone] ***** logs\n[build : compile] ***** logs\n[build : test] ***** logs\n",
+		},
+		{
+			name: "only some tasks",
+			opts: StreamOpts{Tasks: []string{"clone"}},
+			want: "[clone : clone] fake logs\n",
+		},
+		{
+			name: "failed only",
+			opts: StreamOpts{FailedOnly: true},
+			want: "[build : compile] fake logs\n[build : test] fake logs\n",
+		},
+		{
+			name: "follow a finished PipelineRun",
+			opts: StreamOpts{Follow: true, Since: time.Minute},
+			want: "[clone : clone] fake logs\n[build : compile] fake logs\n[build : test] fake logs\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			data := testclient.Data{
+				PipelineRuns: []*tektonv1.PipelineRun{pr},
+				TaskRuns:     taskRuns,
+			}
+			if tt.secret != "" {
+				data.Secret = []*corev1.Secret{{
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+					Data:       map[string][]byte{"token": []byte(tt.secret)},
+				}}
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, data)
+			for _, pod := range pods {
+				_, err := stdata.Kube.CoreV1().Pods("ns").Create(ctx, pod, metav1.CreateOptions{})
+				assert.NilError(t, err)
+			}
+			log, _ := logger.GetLogger()
+			cs := &params.Run{Clients: clients.Clients{
+				Tekton: stdata.Pipeline,
+				Kube:   stdata.Kube,
+				Log:    log,
+			}}
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			ios := &cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: errOut}
+			assert.NilError(t, StreamPipelineRunLogs(ctx, cs, ios, "pr", "ns", tt.opts))
+			assert.Equal(t, out.String(), tt.want)
+			assert.Equal(t, errOut.String(), "")
+		})
+	}
+}
+
+func TestStreamPipelineRunLogsWaitsForTheTaskRuns(t *testing.T) {
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = time.Second }()
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "ns"},
+		Status: tektonv1.PipelineRunStatus{
+			Status: knativeduckv1.Status{Conditions: knativeduckv1.Conditions{
+				{Type: knativeapis.ConditionSucceeded, Status: corev1.ConditionUnknown},
+			}},
+		},
+	}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{pr}})
+	log, _ := logger.GetLogger()
+	cs := &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline, Kube: stdata.Kube, Log: log}}
+
+	// the PipelineRun gets a TaskRun and finishes while the logs are followed
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tr := makeTaskRun("pr-task", "task", corev1.ConditionTrue, time.Now(), "pr-task-pod")
+		_, _ = stdata.Pipeline.TektonV1().TaskRuns("ns").Create(ctx, tr, metav1.CreateOptions{})
+		_, _ = stdata.Kube.CoreV1().Pods("ns").Create(ctx, makePod("pr-task-pod", corev1.PodSucceeded, "step-run"), metav1.CreateOptions{})
+		done := pr.DeepCopy()
+		done.Status.Conditions[0].Status = corev1.ConditionTrue
+		done.Status.ChildReferences = []tektonv1.ChildStatusReference{{TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}, Name: "pr-task"}}
+		_, _ = stdata.Pipeline.TektonV1().PipelineRuns("ns").UpdateStatus(ctx, done, metav1.UpdateOptions{})
+	}()
+
+	out := &bytes.Buffer{}
+	ios := &cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}}
+	assert.NilError(t, StreamPipelineRunLogs(ctx, cs, ios, "pr", "ns", StreamOpts{Follow: true}))
+	assert.Equal(t, out.String(), "[task : run] fake logs\n")
+}
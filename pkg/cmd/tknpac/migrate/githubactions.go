@@ -0,0 +1,503 @@
+package migrate
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/generate"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// placeholderTaskStart and pipelineRunWorkspaces delimit the placeholder
+	// task of the generic template of tkn pac generate, the migrated jobs take
+	// its place.
+	placeholderTaskStart  = "      # Customize this task"
+	pipelineRunWorkspaces = "\n  workspaces:\n"
+	fetchRepositoryTask   = "fetch-repository"
+	sourceWorkspace       = "source"
+	sourceWorkspacePath   = "$(workspaces.source.path)"
+)
+
+// defaultPullRequestTypes are the pull_request activity types the
+// pull_request event of Pipelines-as-Code matches.
+var defaultPullRequestTypes = []string{"opened", "synchronize", "reopened"}
+
+var githubExpressionRe = regexp.MustCompile(`\$\{\{\s*(.+?)\s*\}\}`)
+
+// githubContexts are the GitHub Actions expressions having a Pipelines-as-Code
+// equivalent.
+var githubContexts = map[string]string{
+	"github.sha":                         "{{ revision }}",
+	"github.event.pull_request.head.sha": "{{ revision }}",
+	"github.event.after":                 "{{ revision }}",
+	"github.head_ref":                    "{{ source_branch }}",
+	"github.base_ref":                    "{{ target_branch }}",
+	"github.event.pull_request.number":   "{{ pull_request_number }}",
+	"github.event.number":                "{{ pull_request_number }}",
+	"github.repository":                  "{{ repo_owner }}/{{ repo_name }}",
+	"github.repository_owner":            "{{ repo_owner }}",
+	"github.event.repository.name":       "{{ repo_name }}",
+	"github.actor":                       "{{ sender }}",
+	"github.event_name":                  "{{ event_type }}",
+	"github.workspace":                   sourceWorkspacePath,
+}
+
+type workflow struct {
+	On   workflowEvents    `yaml:"on"`
+	Env  map[string]string `yaml:"env"`
+	Jobs yaml.Node         `yaml:"jobs"`
+}
+
+type workflowEvent struct {
+	name   string
+	filter workflowFilter
+}
+
+// workflowEvents are the events of a workflow, written as a single event, a
+// list of events or a map of events to their filters.
+type workflowEvents []workflowEvent
+
+func (e *workflowEvents) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*e = workflowEvents{{name: node.Value}}
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			*e = append(*e, workflowEvent{name: n.Value})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			ev := workflowEvent{name: node.Content[i].Value}
+			if node.Content[i+1].Kind == yaml.MappingNode {
+				if err := node.Content[i+1].Decode(&ev.filter); err != nil {
+					return err
+				}
+			}
+			*e = append(*e, ev)
+		}
+	default:
+		return fmt.Errorf("cannot decode the events of the workflow")
+	}
+	return nil
+}
+
+type workflowFilter struct {
+	Branches       []string `yaml:"branches"`
+	BranchesIgnore []string `yaml:"branches-ignore"`
+	Tags           []string `yaml:"tags"`
+	TagsIgnore     []string `yaml:"tags-ignore"`
+	Paths          []string `yaml:"paths"`
+	PathsIgnore    []string `yaml:"paths-ignore"`
+	Types          []string `yaml:"types"`
+}
+
+type workflowJob struct {
+	Name      string            `yaml:"name"`
+	Needs     stringList        `yaml:"needs"`
+	If        string            `yaml:"if"`
+	Env       map[string]string `yaml:"env"`
+	Container workflowContainer `yaml:"container"`
+	Services  map[string]any    `yaml:"services"`
+	Strategy  struct {
+		Matrix any `yaml:"matrix"`
+	} `yaml:"strategy"`
+	Steps []workflowStep `yaml:"steps"`
+}
+
+type workflowStep struct {
+	ID               string            `yaml:"id"`
+	Name             string            `yaml:"name"`
+	If               string            `yaml:"if"`
+	Uses             string            `yaml:"uses"`
+	Run              string            `yaml:"run"`
+	Shell            string            `yaml:"shell"`
+	WorkingDirectory string            `yaml:"working-directory"`
+	Env              map[string]string `yaml:"env"`
+}
+
+// stringList is a value or a list of values.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = stringList{node.Value}
+		return nil
+	}
+	return node.Decode((*[]string)(l))
+}
+
+// workflowContainer is the container of a job, written as its image or as a
+// map.
+type workflowContainer struct {
+	Image string `yaml:"image"`
+}
+
+func (c *workflowContainer) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Image = node.Value
+		return nil
+	}
+	type plain workflowContainer
+	return node.Decode((*plain)(c))
+}
+
+// pipelineTask, taskSpec and step are the parts of the Tekton API written in
+// the generated PipelineRuns, in the order of the templates.
+type pipelineTask struct {
+	Name        string             `yaml:"name"`
+	DisplayName string             `yaml:"displayName,omitempty"`
+	RunAfter    []string           `yaml:"runAfter,omitempty"`
+	Workspaces  []workspaceBinding `yaml:"workspaces"`
+	TaskSpec    taskSpec           `yaml:"taskSpec"`
+}
+
+type workspaceBinding struct {
+	Name      string `yaml:"name"`
+	Workspace string `yaml:"workspace"`
+}
+
+type workspaceDeclaration struct {
+	Name string `yaml:"name"`
+}
+
+type taskSpec struct {
+	Workspaces []workspaceDeclaration `yaml:"workspaces"`
+	Steps      []step                 `yaml:"steps"`
+}
+
+type step struct {
+	Name       string   `yaml:"name"`
+	Image      string   `yaml:"image"`
+	WorkingDir string   `yaml:"workingDir"`
+	Env        []envVar `yaml:"env,omitempty"`
+	Script     string   `yaml:"script"`
+}
+
+type envVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// eventGroup are the events of a workflow sharing the same filters, they are
+// matched by the same PipelineRun.
+type eventGroup struct {
+	events      []string
+	branches    []string
+	paths       []string
+	pathsIgnore []string
+}
+
+func (g eventGroup) sameFilters(other eventGroup) bool {
+	return slices.Equal(g.branches, other.branches) && slices.Equal(g.paths, other.paths) && slices.Equal(g.pathsIgnore, other.pathsIgnore)
+}
+
+// isGitHubWorkflow tells if a file is a GitHub Actions workflow.
+func isGitHubWorkflow(b []byte) bool {
+	wf := map[string]any{}
+	if err := yaml.Unmarshal(b, &wf); err != nil {
+		return false
+	}
+	_, hasJobs := wf["jobs"]
+	_, hasOn := wf["on"]
+	return hasJobs && hasOn
+}
+
+// migrateGitHubWorkflow generates the PipelineRuns of a GitHub Actions
+// workflow, a PipelineRun for each group of events sharing the same filters.
+func migrateGitHubWorkflow(res *result, file string, b []byte, image string) error {
+	wf := workflow{}
+	if err := yaml.Unmarshal(b, &wf); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", file, err)
+	}
+
+	groups := workflowEventGroups(res, file, wf.On)
+	if len(groups) == 0 {
+		res.warnf(file, "skipped, it has no push or pull_request event")
+		return nil
+	}
+	tasks, err := workflowTasks(res, file, &wf, image)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		res.warnf(file, "skipped, none of its jobs has run steps")
+		return nil
+	}
+	tasksYAML, err := encodeYAML(tasks)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	for _, g := range groups {
+		name := sanitizeName(base)
+		if len(groups) > 1 {
+			name = sanitizeName(base + "-" + g.events[0])
+		}
+		content, err := renderWorkflowPipelineRun(name, g, tasksYAML)
+		if err != nil {
+			return err
+		}
+		res.pipelineRuns = append(res.pipelineRuns, pipelineRun{name: name, source: file, content: content})
+	}
+	return nil
+}
+
+// workflowEventGroups maps the events of a workflow to the Pipelines-as-Code
+// events and groups the ones sharing the same filters.
+func workflowEventGroups(res *result, file string, events workflowEvents) []eventGroup {
+	groups := []eventGroup{}
+	for _, ev := range events {
+		var pacEvent string
+		switch ev.name {
+		case triggertype.Push.String():
+			pacEvent = triggertype.Push.String()
+		case triggertype.PullRequest.String(), "pull_request_target":
+			pacEvent = triggertype.PullRequest.String()
+		case "issue_comment":
+			res.warnf(file, "the issue_comment event cannot be migrated, use the %s annotation instead", keys.OnComment)
+			continue
+		default:
+			res.warnf(file, "the %s event has no Pipelines-as-Code equivalent", ev.name)
+			continue
+		}
+
+		g := eventGroup{events: []string{pacEvent}, paths: ev.filter.Paths, pathsIgnore: ev.filter.PathsIgnore}
+		for _, branch := range ev.filter.Branches {
+			if strings.HasPrefix(branch, "!") {
+				res.warnf(file, "the negated branch %s of the %s event cannot be migrated", branch, ev.name)
+				continue
+			}
+			g.branches = append(g.branches, branch)
+		}
+		for _, tag := range ev.filter.Tags {
+			g.branches = append(g.branches, "refs/tags/"+tag)
+		}
+		if len(ev.filter.BranchesIgnore) > 0 || len(ev.filter.TagsIgnore) > 0 {
+			res.warnf(file, "the branches-ignore and tags-ignore filters of the %s event cannot be migrated, use the %s annotation instead", ev.name, keys.OnCelExpression)
+		}
+		if len(g.branches) == 0 {
+			g.branches = []string{"*"}
+		}
+		for _, t := range ev.filter.Types {
+			if pacEvent == triggertype.PullRequest.String() && !slices.Contains(defaultPullRequestTypes, t) {
+				res.warnf(file, "the %s type of the %s event cannot be migrated", t, ev.name)
+			}
+		}
+
+		merged := false
+		for i := range groups {
+			if groups[i].sameFilters(g) {
+				if !slices.Contains(groups[i].events, pacEvent) {
+					groups[i].events = append(groups[i].events, pacEvent)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// workflowTasks converts the jobs of a workflow to tasks running after the
+// fetch-repository task of the template, with a step for each run step.
+func workflowTasks(res *result, file string, wf *workflow, image string) ([]pipelineTask, error) {
+	tasks := []pipelineTask{}
+	taskNames := map[string]string{}
+	needs := map[string][]string{}
+	for i := 0; i+1 < len(wf.Jobs.Content); i += 2 {
+		jobID := wf.Jobs.Content[i].Value
+		job := workflowJob{}
+		if err := wf.Jobs.Content[i+1].Decode(&job); err != nil {
+			return nil, fmt.Errorf("cannot parse the job %s of %s: %w", jobID, file, err)
+		}
+		warnf := func(format string, args ...any) {
+			res.warnf(file, "job %s: %s", jobID, fmt.Sprintf(format, args...))
+		}
+		if job.If != "" {
+			warnf("its if condition is ignored")
+		}
+		if job.Strategy.Matrix != nil {
+			warnf("its matrix strategy cannot be migrated, the task runs once")
+		}
+		if len(job.Services) > 0 {
+			warnf("its services cannot be migrated, run them as sidecars of the task")
+		}
+
+		steps := jobSteps(job, wf.Env, image, warnf)
+		if len(steps) == 0 {
+			warnf("skipped, it has no run steps")
+			continue
+		}
+		name := sanitizeName(jobID)
+		taskNames[jobID] = name
+		needs[name] = job.Needs
+		tasks = append(tasks, pipelineTask{
+			Name:        name,
+			DisplayName: job.Name,
+			Workspaces:  []workspaceBinding{{Name: sourceWorkspace, Workspace: sourceWorkspace}},
+			TaskSpec: taskSpec{
+				Workspaces: []workspaceDeclaration{{Name: sourceWorkspace}},
+				Steps:      steps,
+			},
+		})
+	}
+
+	for i := range tasks {
+		for _, need := range needs[tasks[i].Name] {
+			if name, ok := taskNames[need]; ok {
+				tasks[i].RunAfter = append(tasks[i].RunAfter, name)
+			}
+		}
+		if len(tasks[i].RunAfter) == 0 {
+			tasks[i].RunAfter = []string{fetchRepositoryTask}
+		}
+	}
+	return tasks, nil
+}
+
+// jobSteps converts the run steps of a job, the checkout steps are dropped
+// since the fetch-repository task clones the repository.
+func jobSteps(job workflowJob, workflowEnv map[string]string, image string, warnf func(string, ...any)) []step {
+	if job.Container.Image != "" {
+		image = job.Container.Image
+	}
+	unknown := func(label string) func(string) {
+		return func(expr string) {
+			if secret, ok := strings.CutPrefix(expr, "secrets."); ok {
+				warnf("the secret %s of %s has to be created as a Kubernetes Secret and referenced by the step", secret, label)
+				return
+			}
+			warnf("the expression ${{ %s }} of %s has no Pipelines-as-Code equivalent", expr, label)
+		}
+	}
+
+	steps := []step{}
+	names := map[string]bool{}
+	for n, s := range job.Steps {
+		label := fmt.Sprintf("the step %d", n+1)
+		if s.Name != "" {
+			label = fmt.Sprintf("the step %q", s.Name)
+		}
+		if s.Uses != "" {
+			if s.Uses == "actions/checkout" || strings.HasPrefix(s.Uses, "actions/checkout@") {
+				continue
+			}
+			warnf("%s uses the action %s, replace it with a Tekton task or the commands it runs", label, s.Uses)
+			continue
+		}
+		if s.Run == "" {
+			continue
+		}
+		if s.If != "" {
+			warnf("the if condition of %s is ignored", label)
+		}
+
+		script := convertExpressions(s.Run, func(name string) string { return "${" + name + "}" }, unknown(label))
+		switch s.Shell {
+		case "", "bash", "sh":
+		case "python":
+			script = "#!/usr/bin/env python3\n" + script
+		default:
+			warnf("the %s shell of %s cannot be migrated", s.Shell, label)
+		}
+		workingDir := sourceWorkspacePath
+		if s.WorkingDirectory != "" {
+			workingDir += "/" + strings.TrimPrefix(s.WorkingDirectory, "./")
+		}
+
+		env := map[string]string{}
+		for _, m := range []map[string]string{workflowEnv, job.Env, s.Env} {
+			for k, v := range m {
+				env[k] = convertExpressions(v, func(name string) string { return "$(" + name + ")" }, unknown(label))
+			}
+		}
+		envNames := make([]string, 0, len(env))
+		for k := range env {
+			envNames = append(envNames, k)
+		}
+		sort.Strings(envNames)
+		envVars := []envVar{}
+		for _, k := range envNames {
+			envVars = append(envVars, envVar{Name: k, Value: env[k]})
+		}
+
+		name := sanitizeName(s.ID)
+		if name == "" {
+			name = sanitizeName(s.Name)
+		}
+		if name == "" || names[name] {
+			name = sanitizeName(fmt.Sprintf("%s-step-%d", name, n+1))
+		}
+		names[name] = true
+		steps = append(steps, step{Name: name, Image: image, WorkingDir: workingDir, Env: envVars, Script: script})
+	}
+	return steps
+}
+
+// convertExpressions replaces the ${{ }} expressions of GitHub Actions by their
+// Pipelines-as-Code equivalent, the env context by what envRef returns and
+// calls unknown for the others.
+func convertExpressions(s string, envRef func(string) string, unknown func(string)) string {
+	return githubExpressionRe.ReplaceAllStringFunc(s, func(m string) string {
+		expr := githubExpressionRe.FindStringSubmatch(m)[1]
+		if v, ok := githubContexts[expr]; ok {
+			return v
+		}
+		if name, ok := strings.CutPrefix(expr, "env."); ok {
+			return envRef(name)
+		}
+		unknown(expr)
+		return m
+	})
+}
+
+// renderWorkflowPipelineRun renders the generic template of tkn pac generate
+// for an event group, with the tasks in place of its placeholder task.
+func renderWorkflowPipelineRun(name string, g eventGroup, tasksYAML []byte) ([]byte, error) {
+	tmpl, err := generate.RenderTemplate("generic", name, strings.Join(g.events, ", "), strings.Join(g.branches, ", "))
+	if err != nil {
+		return nil, err
+	}
+	content := string(tmpl)
+
+	start := strings.Index(content, placeholderTaskStart)
+	if start == -1 {
+		return nil, fmt.Errorf("cannot find the placeholder task of the generic template")
+	}
+	end := strings.Index(content[start:], pipelineRunWorkspaces)
+	if end == -1 {
+		return nil, fmt.Errorf("cannot find the workspaces of the generic template")
+	}
+	var tasks strings.Builder
+	for _, line := range strings.SplitAfter(string(tasksYAML), "\n") {
+		if strings.TrimSpace(line) != "" {
+			tasks.WriteString("      ")
+		}
+		tasks.WriteString(line)
+	}
+	content = content[:start] + strings.TrimSuffix(tasks.String(), "\n") + content[start+end:]
+
+	if len(g.paths) > 0 || len(g.pathsIgnore) > 0 {
+		branchLine := fmt.Sprintf("    %s: %q\n", keys.OnTargetBranch, annotationList(g.branches))
+		pathLines := "\n    # The files whose changes trigger the PipelineRun, or not.\n"
+		if len(g.paths) > 0 {
+			pathLines += fmt.Sprintf("    %s: %q\n", keys.OnPathChange, annotationList(g.paths))
+		}
+		if len(g.pathsIgnore) > 0 {
+			pathLines += fmt.Sprintf("    %s: %q\n", keys.OnPathChangeIgnore, annotationList(g.pathsIgnore))
+		}
+		content = strings.Replace(content, branchLine, branchLine+pathLines, 1)
+	}
+	return []byte(content), nil
+}
@@ -0,0 +1,274 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputDirFlag = "output-dir"
+	overwriteFlag = "overwrite"
+	dryRunFlag    = "dry-run"
+	imageFlag     = "image"
+)
+
+// defaultImage is the image of the steps of the migrated GitHub Actions jobs
+// not running in a container, the one of the generic template of tkn pac
+// generate.
+const defaultImage = "registry.access.redhat.com/ubi10/ubi-micro"
+
+var longhelp = fmt.Sprintf(`migrate - generate Pipelines-as-Code PipelineRuns from another CI
+
+Generate the PipelineRuns of the .tekton directory from Tekton Triggers
+resources or GitHub Actions workflows:
+
+* the PipelineRuns of the TriggerTemplates get the on-event, on-target-branch
+  and on-path-change annotations matching the EventListener interceptors, and
+  their TriggerBinding values are replaced by their {{ }} equivalent.
+* the run steps of the GitHub Actions jobs are added to the generic template of
+  "%s pac generate", with the annotations matching the push and
+  pull_request events of the workflow.
+
+What cannot be migrated, like the actions used by a workflow or the secrets it
+references, is reported as warnings to be fixed by hand.
+
+The files of .github/workflows are migrated when no file or directory is given:
+
+%s pac migrate
+%s pac migrate triggers/ --dry-run`, settings.TknBinaryName, settings.TknBinaryName, settings.TknBinaryName)
+
+type migrateOpts struct {
+	ioStreams *cli.IOStreams
+	outputDir string
+	overwrite bool
+	dryRun    bool
+	image     string
+}
+
+// pipelineRun is a PipelineRun generated from a migrated file.
+type pipelineRun struct {
+	name    string
+	source  string
+	content []byte
+}
+
+// result is what came out of the migration of some files.
+type result struct {
+	pipelineRuns []pipelineRun
+	warnings     []string
+}
+
+func (r *result) warnf(source, format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf("%s: %s", source, fmt.Sprintf(format, args...)))
+}
+
+var yamlDocSeparatorRe = regexp.MustCompile(`(?m)^---\s*$`)
+
+// nameSanitizeRe matches what cannot be part of a Kubernetes name.
+var nameSanitizeRe = regexp.MustCompile(`[^a-z0-9-]+`)
+
+func Command(ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &migrateOpts{ioStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "migrate [dir|file]...",
+		Short: "Generate PipelineRuns from Tekton Triggers or GitHub Actions",
+		Long:  longhelp,
+		RunE: func(_ *cobra.Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			topLevel := git.GetGitInfo(cwd).TopLevelPath
+			if topLevel == "" {
+				topLevel = cwd
+			}
+			if len(args) == 0 {
+				args = []string{filepath.Join(topLevel, ".github", "workflows")}
+			}
+			if opts.outputDir == "" {
+				opts.outputDir = filepath.Join(topLevel, ".tekton")
+			}
+			return opts.run(args)
+		},
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputDir, outputDirFlag, "",
+		"directory where to write the PipelineRuns, the .tekton directory of the repository by default")
+	cmd.Flags().BoolVar(&opts.overwrite, overwriteFlag, false,
+		"overwrite the PipelineRuns already in the output directory")
+	cmd.Flags().BoolVar(&opts.dryRun, dryRunFlag, false,
+		"print the PipelineRuns instead of writing them")
+	cmd.Flags().StringVar(&opts.image, imageFlag, defaultImage,
+		"image of the steps of the GitHub Actions jobs not running in a container")
+	return cmd
+}
+
+func (o *migrateOpts) run(paths []string) error {
+	res, err := o.migrate(paths)
+	if err != nil {
+		return err
+	}
+	cs := o.ioStreams.ColorScheme()
+	for _, warning := range res.warnings {
+		fmt.Fprintf(o.ioStreams.ErrOut, "%s %s\n", cs.WarningIcon(), warning)
+	}
+	if len(res.pipelineRuns) == 0 {
+		return fmt.Errorf("no PipelineRun could be generated from %s", strings.Join(paths, ", "))
+	}
+
+	if o.dryRun {
+		for _, pr := range res.pipelineRuns {
+			fmt.Fprint(o.ioStreams.Out, string(pr.content))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(o.outputDir, 0o750); err != nil {
+		return err
+	}
+	for _, pr := range res.pipelineRuns {
+		fpath := filepath.Join(o.outputDir, pr.name+".yaml")
+		if _, err := os.Stat(fpath); err == nil && !o.overwrite {
+			fmt.Fprintf(o.ioStreams.Out, "%s File %s already exists, skipping it. Use --%s to replace it.\n", cs.InfoIcon(), fpath, overwriteFlag)
+			continue
+		}
+		if err := os.WriteFile(fpath, pr.content, 0o600); err != nil {
+			return fmt.Errorf("cannot write the PipelineRun to %s: %w", fpath, err)
+		}
+		fmt.Fprintf(o.ioStreams.Out, "%s PipelineRun %s has been generated in %s from %s\n", cs.SuccessIcon(), cs.Bold(pr.name), fpath, pr.source)
+	}
+	fmt.Fprintf(o.ioStreams.Out, "%s Review the generated PipelineRuns and check them with \"%s pac lint %s\"\n", cs.InfoIcon(), settings.TknBinaryName, o.outputDir)
+	return nil
+}
+
+// migrate generates the PipelineRuns of the Tekton Triggers resources and the
+// GitHub Actions workflows found in the paths. The Tekton Triggers resources
+// of all the files are migrated together since the bindings and the templates
+// are often in different files.
+func (o *migrateOpts) migrate(paths []string) (*result, error) {
+	files, err := listYAMLFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{}
+	triggers := newTriggersResources()
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case isGitHubWorkflow(b):
+			if err := migrateGitHubWorkflow(res, file, b, o.image); err != nil {
+				return nil, err
+			}
+		case isTriggersFile(b):
+			if err := triggers.add(file, b); err != nil {
+				return nil, err
+			}
+		default:
+			res.warnf(file, "skipped, it has no Tekton Triggers resources nor GitHub Actions jobs")
+		}
+	}
+	if err := triggers.migrate(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// listYAMLFiles returns the YAML files of the paths, directories are walked
+// recursively.
+func listYAMLFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(fname string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (filepath.Ext(fname) == ".yaml" || filepath.Ext(fname) == ".yml") {
+				files = append(files, fname)
+			}
+			return nil
+		})
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s does not exist, give the Tekton Triggers or GitHub Actions files to migrate", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// decodeDocuments decodes the YAML documents of a file.
+func decodeDocuments(file string, b []byte) ([]*yaml.Node, error) {
+	docs := []*yaml.Node{}
+	for _, doc := range yamlDocSeparatorRe.Split(string(b), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		node := &yaml.Node{}
+		if err := yaml.Unmarshal([]byte(doc), node); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", file, err)
+		}
+		if len(node.Content) > 0 {
+			docs = append(docs, node.Content[0])
+		}
+	}
+	return docs, nil
+}
+
+// mappingValue returns the value of a key of a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sanitizeName turns a string into a valid Kubernetes name.
+func sanitizeName(s string) string {
+	name := strings.Trim(nameSanitizeRe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// annotationList formats values as an annotation list.
+func annotationList(values []string) string {
+	return fmt.Sprintf("[%s]", strings.Join(values, ", "))
+}
+
+// encodeYAML encodes v with the two spaces indentation of the templates.
+func encodeYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
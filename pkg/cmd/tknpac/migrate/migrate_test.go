@@ -0,0 +1,241 @@
+package migrate
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "github actions",
+			args: []string{"--dry-run", "testdata/workflows"},
+			wantWarnings: []string{
+				"testdata/workflows/ci.yml: the workflow_dispatch event has no Pipelines-as-Code equivalent",
+				"testdata/workflows/ci.yml: job test: the step 2 uses the action actions/cache@v4, replace it with a Tekton task or the commands it runs",
+				`testdata/workflows/ci.yml: job test: the secret TOKEN of the step "Run tests" has to be created as a Kubernetes Secret and referenced by the step`,
+			},
+		},
+		{
+			name: "triggers",
+			args: []string{"--dry-run", "testdata/triggers"},
+			wantWarnings: []string{
+				"testdata/triggers/triggers.yaml: trigger pull-request: its CEL filter has been approximated",
+			},
+		},
+		{
+			name:    "nothing to migrate",
+			args:    []string{"--dry-run", "testdata/nothing.yaml"},
+			wantErr: "no PipelineRun could be generated from testdata/nothing.yaml",
+			wantWarnings: []string{
+				"testdata/nothing.yaml: skipped, it has no Tekton Triggers resources nor GitHub Actions jobs",
+			},
+		},
+		{
+			name:    "missing directory",
+			args:    []string{"testdata/missing"},
+			wantErr: "testdata/missing does not exist, give the Tekton Triggers or GitHub Actions files to migrate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ioStreams, _, out, errOut := cli.IOTest()
+			cmd := Command(ioStreams)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(out)
+			cmd.SetErr(errOut)
+			err := cmd.Execute()
+			for _, warning := range tt.wantWarnings {
+				assert.Assert(t, strings.Contains(errOut.String(), warning), errOut.String())
+			}
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			golden.Assert(t, out.String(), strings.ReplaceAll(fmt.Sprintf("%s.golden", t.Name()), "/", "-"))
+		})
+	}
+}
+
+func TestRunWritesFiles(t *testing.T) {
+	dir := fs.NewDir(t, "migrate", fs.WithDir(".tekton", fs.WithFile("ci-push.yaml", "existing")))
+	defer dir.Remove()
+	outputDir := filepath.Join(dir.Path(), ".tekton")
+
+	ioStreams, _, out, _ := cli.IOTest()
+	opts := &migrateOpts{ioStreams: ioStreams, outputDir: outputDir, image: defaultImage}
+	assert.NilError(t, opts.run([]string{"testdata/workflows"}))
+	assert.Assert(t, strings.Contains(out.String(), fmt.Sprintf("File %s already exists, skipping it. Use --overwrite to replace it.", filepath.Join(outputDir, "ci-push.yaml"))), out.String())
+	assert.Assert(t, strings.Contains(out.String(), fmt.Sprintf("PipelineRun ci-pull-request has been generated in %s from testdata/workflows/ci.yml", filepath.Join(outputDir, "ci-pull-request.yaml"))), out.String())
+	b, err := os.ReadFile(filepath.Join(outputDir, "ci-push.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "existing")
+
+	opts.overwrite = true
+	assert.NilError(t, opts.run([]string{"testdata/workflows"}))
+	b, err = os.ReadFile(filepath.Join(outputDir, "ci-push.yaml"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(b), "name: ci-push"))
+}
+
+func TestWorkflowEventGroups(t *testing.T) {
+	tests := []struct {
+		name         string
+		on           string
+		want         []eventGroup
+		wantWarnings []string
+	}{
+		{
+			name: "single event",
+			on:   "on: push",
+			want: []eventGroup{{events: []string{"push"}, branches: []string{"*"}}},
+		},
+		{
+			name: "events sharing the same filters",
+			on:   "on: [push, pull_request, pull_request_target]",
+			want: []eventGroup{{events: []string{"push", "pull_request"}, branches: []string{"*"}}},
+		},
+		{
+			name: "tags and ignored paths",
+			on:   "on:\n  push:\n    tags: [v*]\n    paths-ignore: [docs/**]\n",
+			want: []eventGroup{{events: []string{"push"}, branches: []string{"refs/tags/v*"}, pathsIgnore: []string{"docs/**"}}},
+		},
+		{
+			name: "unsupported filters",
+			on:   "on:\n  pull_request:\n    branches: [main, '!old']\n    branches-ignore: [wip]\n    types: [opened, labeled]\n  schedule:\n  - cron: '0 0 * * *'\n",
+			want: []eventGroup{{events: []string{"pull_request"}, branches: []string{"main"}}},
+			wantWarnings: []string{
+				"ci.yml: the negated branch !old of the pull_request event cannot be migrated",
+				"ci.yml: the branches-ignore and tags-ignore filters of the pull_request event cannot be migrated, use the pipelinesascode.tekton.dev/on-cel-expression annotation instead",
+				"ci.yml: the labeled type of the pull_request event cannot be migrated",
+				"ci.yml: the schedule event has no Pipelines-as-Code equivalent",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf := workflow{}
+			assert.NilError(t, yaml.Unmarshal([]byte(tt.on+"\njobs: {}\n"), &wf))
+			res := &result{}
+			groups := workflowEventGroups(res, "ci.yml", wf.On)
+			assert.DeepEqual(t, groups, tt.want, cmp.AllowUnexported(eventGroup{}))
+			assert.DeepEqual(t, res.warnings, tt.wantWarnings)
+		})
+	}
+}
+
+func TestConvertBindingValue(t *testing.T) {
+	tests := []struct {
+		value       string
+		want        string
+		wantUnknown []string
+	}{
+		{value: "$(body.pull_request.head.sha)", want: "{{ revision }}"},
+		{value: "$(body.repository.owner.login)/$(body.repository.name)", want: "{{ repo_owner }}/{{ repo_name }}"},
+		{value: "$(body.pull_request.title)", want: "{{ body.pull_request.title }}"},
+		{value: "$(header.X-GitHub-Event)", want: "{{ headers['X-Github-Event'] }}"},
+		{value: "$(extensions.changed_files)", want: "$(extensions.changed_files)", wantUnknown: []string{"extensions.changed_files"}},
+		{value: "static", want: "static"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			unknown := []string{}
+			got := convertBindingValue(tt.value, func(expr string) { unknown = append(unknown, expr) })
+			assert.Equal(t, got, tt.want)
+			if tt.wantUnknown == nil {
+				tt.wantUnknown = []string{}
+			}
+			assert.DeepEqual(t, unknown, tt.wantUnknown)
+		})
+	}
+}
+
+func TestConvertBindingValueRendered(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", "pull_request")
+	event := map[string]any{"pull_request": map[string]any{"title": "Fix the build"}}
+	value := convertBindingValue("$(header.X-GitHub-Event): $(body.pull_request.title)", func(string) {})
+	got := templates.ReplacePlaceHoldersVariables(value, map[string]string{}, event, headers, map[string]any{})
+	assert.Equal(t, got, "pull_request: Fix the build")
+}
+
+func TestTriggersWithoutEventListener(t *testing.T) {
+	dir := fs.NewDir(t, "migrate", fs.WithFile("triggers.yaml", `apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerBinding
+metadata:
+  name: push
+spec:
+  params:
+  - name: revision
+    value: $(body.head_commit.id)
+---
+apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerTemplate
+metadata:
+  name: deploy
+spec:
+  params:
+  - name: revision
+  - name: environment
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1
+    kind: PipelineRun
+    metadata:
+      name: deploy
+    spec:
+      pipelineRef:
+        name: deploy
+      params:
+      - name: revision
+        value: $(tt.params.revision)
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: deploy
+`))
+	defer dir.Remove()
+	file := filepath.Join(dir.Path(), "triggers.yaml")
+
+	opts := &migrateOpts{image: defaultImage}
+	res, err := opts.migrate([]string{file})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res.warnings, []string{
+		file + ": trigger deploy: the parameter environment of the TriggerTemplate deploy has no binding nor default",
+		file + ": trigger deploy: a resource template of the TriggerTemplate deploy is skipped, only PipelineRuns can be migrated",
+	})
+	assert.Equal(t, len(res.pipelineRuns), 1)
+	assert.Equal(t, res.pipelineRuns[0].name, "deploy")
+	assert.Equal(t, string(res.pipelineRuns[0].content), `---
+apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: deploy
+  annotations:
+    pipelinesascode.tekton.dev/on-event: "[push]"
+    pipelinesascode.tekton.dev/on-target-branch: "[*]"
+spec:
+  pipelineRef:
+    name: deploy
+  params:
+    - name: revision
+      value: "{{ revision }}"
+`)
+}
@@ -0,0 +1,526 @@
+package migrate
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"gopkg.in/yaml.v3"
+)
+
+const triggersAPIGroup = "triggers.tekton.dev/"
+
+var (
+	ttParamRe           = regexp.MustCompile(`\$\(tt\.params\.([A-Za-z0-9_.-]+)\)`)
+	triggerExpressionRe = regexp.MustCompile(`\$\(((body|header|extensions)\.[^)]+)\)`)
+	celEventRes         = []*regexp.Regexp{
+		regexp.MustCompile(`header\.match\(\s*['"]X-(?:GitHub|Gitlab)-Event['"]\s*,\s*['"]([^'"]+)['"]\s*\)`),
+		regexp.MustCompile(`header\.canonical\(\s*['"]X-(?:GitHub|Gitlab)-Event['"]\s*\)\s*==\s*['"]([^'"]+)['"]`),
+	}
+	celBranchRe       = regexp.MustCompile(`body\.(?:ref|pull_request\.base\.ref|object_attributes\.target_branch)(?:\.split\(\s*['"]/['"]\s*\)\[2\])?\s*==\s*['"]([^'"]+)['"]`)
+	celBranchPrefixRe = regexp.MustCompile(`body\.(?:ref|pull_request\.base\.ref|object_attributes\.target_branch)\.startsWith\(\s*['"]([^'"]+)['"]\s*\)`)
+	celPathRe         = regexp.MustCompile(`extensions\.changed_files\.matches\(\s*['"]([^'"]+)['"]\s*\)`)
+	plainPathRe       = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+)
+
+// triggersBodyParams are the TriggerBinding values having a standard
+// Pipelines-as-Code parameter, for GitHub, GitLab and Bitbucket payloads. The
+// other body and header values are kept as {{ body.* }} and {{ headers[] }}
+// parameters.
+var triggersBodyParams = map[string]string{
+	"body.head_commit.id":                    "{{ revision }}",
+	"body.after":                             "{{ revision }}",
+	"body.pull_request.head.sha":             "{{ revision }}",
+	"body.checkout_sha":                      "{{ revision }}",
+	"body.object_attributes.last_commit.id":  "{{ revision }}",
+	"body.repository.clone_url":              "{{ repo_url }}",
+	"body.repository.html_url":               "{{ repo_url }}",
+	"body.project.git_http_url":              "{{ repo_url }}",
+	"body.project.web_url":                   "{{ repo_url }}",
+	"body.repository.name":                   "{{ repo_name }}",
+	"body.project.name":                      "{{ repo_name }}",
+	"body.repository.owner.login":            "{{ repo_owner }}",
+	"body.project.namespace":                 "{{ repo_owner }}",
+	"body.pull_request.number":               "{{ pull_request_number }}",
+	"body.number":                            "{{ pull_request_number }}",
+	"body.object_attributes.iid":             "{{ pull_request_number }}",
+	"body.pull_request.head.ref":             "{{ source_branch }}",
+	"body.object_attributes.source_branch":   "{{ source_branch }}",
+	"body.pull_request.base.ref":             "{{ target_branch }}",
+	"body.object_attributes.target_branch":   "{{ target_branch }}",
+	"body.sender.login":                      "{{ sender }}",
+	"body.user.username":                     "{{ sender }}",
+	"body.pull_request.head.repo.clone_url":  "{{ source_url }}",
+	"body.object_attributes.source.git_http": "{{ source_url }}",
+}
+
+// triggersEventTypes maps the event types of the GitHub, GitLab and Bitbucket
+// interceptors to the Pipelines-as-Code events.
+var triggersEventTypes = map[string]string{
+	"push":                triggertype.Push.String(),
+	"pull_request":        triggertype.PullRequest.String(),
+	"Push Hook":           triggertype.Push.String(),
+	"Tag Push Hook":       triggertype.Push.String(),
+	"Merge Request Hook":  triggertype.PullRequest.String(),
+	"repo:refs_changed":   triggertype.Push.String(),
+	"repo:push":           triggertype.Push.String(),
+	"pr:opened":           triggertype.PullRequest.String(),
+	"pr:from_ref_updated": triggertype.PullRequest.String(),
+	"pullrequest:created": triggertype.PullRequest.String(),
+	"pullrequest:updated": triggertype.PullRequest.String(),
+}
+
+type triggersResource struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec yaml.Node `yaml:"spec"`
+}
+
+type triggerParam struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type triggerTemplate struct {
+	file   string
+	Params []struct {
+		Name    string  `yaml:"name"`
+		Default *string `yaml:"default"`
+	} `yaml:"params"`
+	ResourceTemplates []yaml.Node `yaml:"resourcetemplates"`
+}
+
+// triggerBinding is a reference to a TriggerBinding or an inline binding.
+type triggerBinding struct {
+	Ref   string `yaml:"ref"`
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// triggerSpec is a Trigger or a trigger of an EventListener.
+type triggerSpec struct {
+	file       string
+	Name       string           `yaml:"name"`
+	TriggerRef string           `yaml:"triggerRef"`
+	Bindings   []triggerBinding `yaml:"bindings"`
+	Template   struct {
+		Ref  string `yaml:"ref"`
+		Name string `yaml:"name"`
+	} `yaml:"template"`
+	Interceptors []struct {
+		Ref struct {
+			Name string `yaml:"name"`
+		} `yaml:"ref"`
+		Params []struct {
+			Name  string    `yaml:"name"`
+			Value yaml.Node `yaml:"value"`
+		} `yaml:"params"`
+	} `yaml:"interceptors"`
+}
+
+// triggersResources are the Tekton Triggers resources of the migrated files.
+type triggersResources struct {
+	bindings      map[string][]triggerParam
+	bindingOrder  []string
+	templates     map[string]*triggerTemplate
+	templateOrder []string
+	triggers      map[string]triggerSpec
+	triggerOrder  []string
+	listeners     []triggerSpec
+}
+
+func newTriggersResources() *triggersResources {
+	return &triggersResources{
+		bindings:  map[string][]triggerParam{},
+		templates: map[string]*triggerTemplate{},
+		triggers:  map[string]triggerSpec{},
+	}
+}
+
+// isTriggersFile tells if a file has Tekton Triggers resources.
+func isTriggersFile(b []byte) bool {
+	docs, err := decodeDocuments("", b)
+	if err != nil {
+		return false
+	}
+	for _, doc := range docs {
+		if apiVersion := mappingValue(doc, "apiVersion"); apiVersion != nil && strings.HasPrefix(apiVersion.Value, triggersAPIGroup) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *triggersResources) add(file string, b []byte) error {
+	docs, err := decodeDocuments(file, b)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		r := triggersResource{}
+		if err := doc.Decode(&r); err != nil {
+			return fmt.Errorf("cannot parse %s: %w", file, err)
+		}
+		if !strings.HasPrefix(r.APIVersion, triggersAPIGroup) {
+			continue
+		}
+		name := r.Metadata.Name
+		switch r.Kind {
+		case "TriggerBinding", "ClusterTriggerBinding":
+			spec := struct {
+				Params []triggerParam `yaml:"params"`
+			}{}
+			if err := r.Spec.Decode(&spec); err != nil {
+				return fmt.Errorf("cannot parse the %s %s of %s: %w", r.Kind, name, file, err)
+			}
+			t.bindings[name] = spec.Params
+			t.bindingOrder = append(t.bindingOrder, name)
+		case "TriggerTemplate":
+			tmpl := &triggerTemplate{file: file}
+			if err := r.Spec.Decode(tmpl); err != nil {
+				return fmt.Errorf("cannot parse the %s %s of %s: %w", r.Kind, name, file, err)
+			}
+			t.templates[name] = tmpl
+			t.templateOrder = append(t.templateOrder, name)
+		case "Trigger":
+			spec := triggerSpec{file: file}
+			if err := r.Spec.Decode(&spec); err != nil {
+				return fmt.Errorf("cannot parse the %s %s of %s: %w", r.Kind, name, file, err)
+			}
+			spec.Name = name
+			t.triggers[name] = spec
+			t.triggerOrder = append(t.triggerOrder, name)
+		case "EventListener":
+			spec := struct {
+				Triggers []triggerSpec `yaml:"triggers"`
+			}{}
+			if err := r.Spec.Decode(&spec); err != nil {
+				return fmt.Errorf("cannot parse the %s %s of %s: %w", r.Kind, name, file, err)
+			}
+			for i, tr := range spec.Triggers {
+				tr.file = file
+				if tr.Name == "" {
+					tr.Name = fmt.Sprintf("%s-%d", name, i+1)
+				}
+				t.listeners = append(t.listeners, tr)
+			}
+		}
+	}
+	return nil
+}
+
+// migrate generates a PipelineRun for each PipelineRun of the TriggerTemplate
+// of each trigger. Without EventListener nor Trigger, every TriggerTemplate is
+// migrated with all the TriggerBindings.
+func (t *triggersResources) migrate(res *result) error {
+	units := []triggerSpec{}
+	referenced := map[string]bool{}
+	for _, tr := range t.listeners {
+		if tr.TriggerRef == "" {
+			units = append(units, tr)
+			continue
+		}
+		ref, ok := t.triggers[tr.TriggerRef]
+		if !ok {
+			res.warnf(tr.file, "trigger %s: the Trigger %s cannot be found", tr.Name, tr.TriggerRef)
+			continue
+		}
+		referenced[tr.TriggerRef] = true
+		units = append(units, ref)
+	}
+	for _, name := range t.triggerOrder {
+		if !referenced[name] {
+			units = append(units, t.triggers[name])
+		}
+	}
+	if len(units) == 0 {
+		for _, name := range t.templateOrder {
+			tr := triggerSpec{file: t.templates[name].file, Name: name}
+			tr.Template.Ref = name
+			for _, binding := range t.bindingOrder {
+				tr.Bindings = append(tr.Bindings, triggerBinding{Ref: binding})
+			}
+			units = append(units, tr)
+		}
+	}
+
+	usedNames := map[string]bool{}
+	for _, tr := range units {
+		if err := t.migrateTrigger(res, tr, usedNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *triggersResources) migrateTrigger(res *result, tr triggerSpec, usedNames map[string]bool) error {
+	warnf := func(format string, args ...any) {
+		res.warnf(tr.file, "trigger %s: %s", tr.Name, fmt.Sprintf(format, args...))
+	}
+	tmplName := tr.Template.Ref
+	if tmplName == "" {
+		tmplName = tr.Template.Name
+	}
+	tmpl, ok := t.templates[tmplName]
+	if !ok {
+		warnf("the TriggerTemplate %s cannot be found", tmplName)
+		return nil
+	}
+
+	values := map[string]string{}
+	rawValues := []string{}
+	for _, binding := range tr.Bindings {
+		params := []triggerParam{{Name: binding.Name, Value: binding.Value}}
+		if binding.Ref != "" {
+			if params, ok = t.bindings[binding.Ref]; !ok {
+				warnf("the TriggerBinding %s cannot be found", binding.Ref)
+				continue
+			}
+		}
+		for _, p := range params {
+			rawValues = append(rawValues, p.Value)
+			values[p.Name] = convertBindingValue(p.Value, func(expr string) {
+				warnf("the value $(%s) of the parameter %s has no Pipelines-as-Code equivalent", expr, p.Name)
+			})
+		}
+	}
+	for _, p := range tmpl.Params {
+		if _, ok := values[p.Name]; ok {
+			continue
+		}
+		if p.Default != nil {
+			values[p.Name] = *p.Default
+			continue
+		}
+		warnf("the parameter %s of the TriggerTemplate %s has no binding nor default", p.Name, tmplName)
+	}
+
+	events, branches, paths := t.interceptorFilters(tr, warnf)
+	if len(events) == 0 {
+		events = eventsFromBindings(rawValues)
+	}
+	if len(events) == 0 {
+		events = []string{triggertype.PullRequest.String(), triggertype.Push.String()}
+		warnf("its events cannot be guessed, check the %s annotation", keys.OnEvent)
+	}
+	if len(branches) == 0 {
+		branches = []string{"*"}
+	}
+
+	for i := range tmpl.ResourceTemplates {
+		rt := &tmpl.ResourceTemplates[i]
+		if kind := mappingValue(rt, "kind"); kind == nil || kind.Value != "PipelineRun" {
+			warnf("a resource template of the TriggerTemplate %s is skipped, only PipelineRuns can be migrated", tmplName)
+			continue
+		}
+		// work on a copy, a template can be used by several triggers
+		b, err := yaml.Marshal(rt)
+		if err != nil {
+			return err
+		}
+		doc := &yaml.Node{}
+		if err := yaml.Unmarshal(b, doc); err != nil {
+			return err
+		}
+		pr := doc.Content[0]
+		replaceTriggerParams(pr, values)
+
+		metadata := mappingValue(pr, "metadata")
+		if metadata == nil {
+			metadata = &yaml.Node{Kind: yaml.MappingNode}
+			pr.Content = append(pr.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "metadata"}, metadata)
+		}
+		nameKey := "name"
+		prName := ""
+		if name := mappingValue(metadata, nameKey); name != nil {
+			prName = sanitizeName(name.Value)
+		} else if name := mappingValue(metadata, "generateName"); name != nil {
+			nameKey = "generateName"
+			prName = sanitizeName(name.Value)
+		}
+		if prName == "" {
+			prName = sanitizeName(tmplName)
+		}
+		if usedNames[prName] {
+			prName = sanitizeName(prName + "-" + tr.Name)
+		}
+		usedNames[prName] = true
+		if nameKey == "generateName" {
+			setMappingValue(metadata, nameKey, prName+"-", 0)
+		} else {
+			setMappingValue(metadata, nameKey, prName, 0)
+		}
+
+		annotations := mappingValue(metadata, "annotations")
+		if annotations == nil {
+			annotations = &yaml.Node{Kind: yaml.MappingNode}
+			metadata.Content = append(metadata.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "annotations"}, annotations)
+		}
+		setMappingValue(annotations, keys.OnEvent, annotationList(events), yaml.DoubleQuotedStyle)
+		setMappingValue(annotations, keys.OnTargetBranch, annotationList(branches), yaml.DoubleQuotedStyle)
+		if len(paths) > 0 {
+			setMappingValue(annotations, keys.OnPathChange, annotationList(paths), yaml.DoubleQuotedStyle)
+		}
+
+		content, err := encodeYAML(pr)
+		if err != nil {
+			return err
+		}
+		res.pipelineRuns = append(res.pipelineRuns, pipelineRun{name: prName, source: tmpl.file, content: append([]byte("---\n"), content...)})
+	}
+	return nil
+}
+
+// interceptorFilters returns the events, target branches and changed paths
+// the interceptors of a trigger filter on.
+func (t *triggersResources) interceptorFilters(tr triggerSpec, warnf func(string, ...any)) ([]string, []string, []string) {
+	events, branches, paths := []string{}, []string{}, []string{}
+	addEvent := func(eventType string) {
+		event, ok := triggersEventTypes[eventType]
+		if !ok {
+			warnf("the %s event type has no Pipelines-as-Code equivalent", eventType)
+			return
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	for _, interceptor := range tr.Interceptors {
+		switch interceptor.Ref.Name {
+		case "github", "gitlab", "bitbucket":
+			for _, p := range interceptor.Params {
+				if p.Name != "eventTypes" {
+					continue
+				}
+				eventTypes := []string{}
+				if err := p.Value.Decode(&eventTypes); err != nil {
+					warnf("cannot decode the event types of the %s interceptor: %v", interceptor.Ref.Name, err)
+					continue
+				}
+				for _, eventType := range eventTypes {
+					addEvent(eventType)
+				}
+			}
+		case "cel":
+			for _, p := range interceptor.Params {
+				switch p.Name {
+				case "filter":
+					filter := p.Value.Value
+					for _, re := range celEventRes {
+						for _, m := range re.FindAllStringSubmatch(filter, -1) {
+							addEvent(m[1])
+						}
+					}
+					for _, m := range celBranchRe.FindAllStringSubmatch(filter, -1) {
+						branches = appendUnique(branches, m[1])
+					}
+					for _, m := range celBranchPrefixRe.FindAllStringSubmatch(filter, -1) {
+						branches = appendUnique(branches, m[1]+"*")
+					}
+					for _, m := range celPathRe.FindAllStringSubmatch(filter, -1) {
+						path := strings.TrimPrefix(m[1], "^")
+						if !plainPathRe.MatchString(path) {
+							warnf("the changed files regexp %s cannot be migrated, add it to the %s annotation", m[1], keys.OnPathChange)
+							continue
+						}
+						paths = appendUnique(paths, path+"**")
+					}
+					warnf("its CEL filter has been approximated, check the annotations of the PipelineRun or use the %s annotation: %s", keys.OnCelExpression, filter)
+				case "overlays":
+					warnf("the overlays of its CEL interceptor cannot be migrated")
+				}
+			}
+		default:
+			warnf("the %s interceptor cannot be migrated", interceptor.Ref.Name)
+		}
+	}
+	return events, branches, paths
+}
+
+// eventsFromBindings guesses the events from the payload fields the bindings
+// use.
+func eventsFromBindings(rawValues []string) []string {
+	events := []string{}
+	for _, v := range rawValues {
+		switch {
+		case strings.Contains(v, "body.pull_request.") || strings.Contains(v, "body.object_attributes."):
+			events = appendUnique(events, triggertype.PullRequest.String())
+		case strings.Contains(v, "body.head_commit.") || strings.Contains(v, "body.after") || strings.Contains(v, "body.checkout_sha"):
+			events = appendUnique(events, triggertype.Push.String())
+		}
+	}
+	return events
+}
+
+// convertBindingValue replaces the $(body.*) and $(header.*) of a
+// TriggerBinding value by their Pipelines-as-Code parameters, unknown is called
+// for the ones having no equivalent.
+func convertBindingValue(value string, unknown func(string)) string {
+	return triggerExpressionRe.ReplaceAllStringFunc(value, func(m string) string {
+		sub := triggerExpressionRe.FindStringSubmatch(m)
+		expr := sub[1]
+		if v, ok := triggersBodyParams[expr]; ok {
+			return v
+		}
+		switch sub[2] {
+		case "body":
+			return fmt.Sprintf("{{ %s }}", expr)
+		case "header":
+			// the headers are looked up by their canonical name when rendering
+			return fmt.Sprintf("{{ headers['%s'] }}", http.CanonicalHeaderKey(strings.TrimPrefix(expr, "header.")))
+		}
+		unknown(expr)
+		return m
+	})
+}
+
+// replaceTriggerParams replaces the $(tt.params.*) of the scalars of a node by
+// their values, the scalars getting a {{ }} parameter are quoted to stay valid
+// YAML.
+func replaceTriggerParams(node *yaml.Node, values map[string]string) {
+	if node.Kind == yaml.ScalarNode {
+		replaced := ttParamRe.ReplaceAllStringFunc(node.Value, func(m string) string {
+			if v, ok := values[ttParamRe.FindStringSubmatch(m)[1]]; ok {
+				return v
+			}
+			return m
+		})
+		if replaced != node.Value {
+			node.Value = replaced
+			if strings.Contains(replaced, "{{") && node.Style != yaml.LiteralStyle && node.Style != yaml.FoldedStyle {
+				node.Style = yaml.DoubleQuotedStyle
+			}
+		}
+		return
+	}
+	for _, child := range node.Content {
+		replaceTriggerParams(child, values)
+	}
+}
+
+// setMappingValue sets the string value of a key of a mapping node, adding it
+// when missing.
+func setMappingValue(node *yaml.Node, key, value string, style yaml.Style) {
+	if v := mappingValue(node, key); v != nil {
+		v.Kind, v.Tag, v.Value, v.Style, v.Content = yaml.ScalarNode, "!!str", value, style, nil
+		return
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style})
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
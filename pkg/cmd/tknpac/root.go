@@ -9,11 +9,17 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/deleterepo"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/describe"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/generate"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/gitops"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/lint"
 	list "github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/listcmd"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/logs"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/migrate"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/resolve"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/runcmd"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/simulate"
 	versioncmd "github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/versioncmd"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/watch"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/webhook"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/spf13/cobra"
@@ -40,11 +46,18 @@ func Root(clients *params.Run) *cobra.Command {
 	cmd.AddCommand(deleterepo.Root(clients, ioStreams))
 	cmd.AddCommand(describe.Root(clients, ioStreams))
 	cmd.AddCommand(logs.Command(clients, ioStreams))
+	cmd.AddCommand(watch.Command(clients, ioStreams))
+	cmd.AddCommand(gitops.RetestCommand(clients, ioStreams))
+	cmd.AddCommand(gitops.CancelCommand(clients, ioStreams))
 	cmd.AddCommand(resolve.Command(clients, ioStreams))
+	cmd.AddCommand(runcmd.Command(clients, ioStreams))
 	cmd.AddCommand(completion.Command())
 	cmd.AddCommand(bootstrap.Command(clients, ioStreams))
 	cmd.AddCommand(generate.Command(clients, ioStreams))
 	cmd.AddCommand(cel.Command(ioStreams))
+	cmd.AddCommand(simulate.Command(ioStreams))
+	cmd.AddCommand(lint.Command(ioStreams))
+	cmd.AddCommand(migrate.Command(ioStreams))
 	cmd.AddCommand(webhook.Root(clients, ioStreams))
 	return cmd
 }
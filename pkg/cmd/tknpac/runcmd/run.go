@@ -0,0 +1,282 @@
+package runcmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/logs"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/customparams"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	"github.com/spf13/cobra"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	namespaceFlag  = "namespace"
+	repositoryFlag = "repository"
+	tektonDirFlag  = "tekton-dir"
+	paramsFlag     = "params"
+	noLogsFlag     = "no-logs"
+	tknPathFlag    = "tkn-path"
+)
+
+var gitAuthSecretRe = regexp.MustCompile(`{{\s*git_auth_secret\s*}}`)
+
+var longhelp = fmt.Sprintf(`run - start a PipelineRun of the local .tekton directory
+
+Resolve the PipelineRun of the .tekton directory the same way Pipelines-as-Code
+does and start it on the cluster for the current branch and commit of the local
+git checkout, then follow its logs.
+
+The PipelineRun is created in the namespace of the Repository CR matching the
+remote URL of the checkout, with the labels and annotations Pipelines-as-Code
+sets on the PipelineRuns it starts. Its status is not reported on the git
+provider.
+
+The changes of the .tekton directory are used right away, but the source code
+is cloned by the PipelineRun at the current commit, uncommitted changes outside
+of the .tekton directory are not part of the run.
+
+%s pac run pull-request -p git_auth_secret=my-secret`, settings.TknBinaryName)
+
+type runOpts struct {
+	cs              *params.Run
+	ioStreams       *cli.IOStreams
+	pipelineRunName string
+	namespace       string
+	repoName        string
+	tektonDir       string
+	gitDir          string
+	parameters      []string
+}
+
+func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &runOpts{cs: run, ioStreams: ioStreams, gitDir: "."}
+	var noLogs bool
+	var tknPath string
+	cmd := &cobra.Command{
+		Use:   "run pipelinerun-name",
+		Short: "Start a PipelineRun from the local .tekton directory",
+		Long:  longhelp,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.pipelineRunName = args[0]
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			// only report error here on CLI
+			zaplog, err := zap.NewProduction(zap.IncreaseLevel(zap.FatalLevel))
+			if err != nil {
+				return err
+			}
+			run.Clients.Log = zaplog.Sugar()
+
+			pr, err := runPipelineRun(ctx, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(ioStreams.Out, "PipelineRun %s has been started in namespace %s\n", pr.GetName(), pr.GetNamespace())
+			if noLogs {
+				return nil
+			}
+			if tknPath != "" {
+				return logs.ShowLogsWithTkn(tknPath, pr.GetName(), pr.GetNamespace())
+			}
+			return logs.StreamPipelineRunLogs(ctx, run, ioStreams, pr.GetName(), pr.GetNamespace(), logs.StreamOpts{Follow: true})
+		},
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.namespace, namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
+	_ = cmd.RegisterFlagCompletionFunc(namespaceFlag,
+		func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion(namespaceFlag, args)
+		},
+	)
+	cmd.Flags().StringVarP(&opts.repoName, repositoryFlag, "r", "", "name of the Repository CR (default to the one matching the remote URL of the checkout)")
+	cmd.Flags().StringVar(&opts.tektonDir, tektonDirFlag, ".tekton", "directory of the PipelineRuns")
+	cmd.Flags().StringSliceVarP(&opts.parameters, paramsFlag, "p", nil, "Params to override (ie: revision, git_auth_secret)")
+	cmd.Flags().BoolVar(&noLogs, noLogsFlag, false, "don't follow the logs of the PipelineRun")
+	cmd.Flags().StringVar(&tknPath, tknPathFlag, "", fmt.Sprintf("Path to the %s binary to follow the logs with instead of streaming them", settings.TknBinaryName))
+	return cmd
+}
+
+// runPipelineRun resolves the PipelineRun of the local .tekton directory and
+// creates it in the namespace of the Repository CR.
+func runPipelineRun(ctx context.Context, opts *runOpts) (*tektonv1.PipelineRun, error) {
+	logger := opts.cs.Clients.Log
+	event, err := localEvent(opts.gitDir)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := getRepository(ctx, opts, event)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTemplates, err := resolve.ReadTektonDir(filepath.Join(opts.gitDir, opts.tektonDir))
+	if err != nil {
+		return nil, err
+	}
+	types, err := resolve.ReadTektonTypes(ctx, logger, rawTemplates)
+	if err != nil {
+		return nil, err
+	}
+	rawPipelineRun := findPipelineRun(types.PipelineRuns, opts.pipelineRunName)
+	if rawPipelineRun == nil {
+		return nil, fmt.Errorf("cannot find PipelineRun %s in %s", opts.pipelineRunName, opts.tektonDir)
+	}
+	rawPipelineRunYAML, err := yaml.Marshal(rawPipelineRun)
+	if err != nil {
+		return nil, err
+	}
+
+	eventEmitter := events.NewEventEmitter(nil, logger)
+	kint, err := kubeinteraction.NewKubernetesInteraction(opts.cs)
+	if err != nil {
+		return nil, err
+	}
+	cp := customparams.NewCustomParams(event, repo, opts.cs, kint, eventEmitter, nil)
+	maptemplate, changedFiles, err := cp.GetParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range opts.parameters {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid param %q, it should be in the form name=value", param)
+		}
+		maptemplate[key] = value
+	}
+	// the secret with the token of the git provider is only generated by the
+	// controller, an existing one has to be used.
+	if _, ok := maptemplate["git_auth_secret"]; !ok && gitAuthSecretRe.Match(rawPipelineRunYAML) {
+		return nil, fmt.Errorf("the PipelineRun uses {{ git_auth_secret }}, pass an existing secret with -p git_auth_secret=<secret-name>")
+	}
+
+	allTemplates := templates.ReplacePlaceHoldersVariables(rawTemplates, maptemplate, nil, http.Header{}, changedFiles)
+	types, err = resolve.ReadTektonTypes(ctx, logger, allTemplates)
+	if err != nil {
+		return nil, err
+	}
+	types.PipelineRuns = []*tektonv1.PipelineRun{findPipelineRun(types.PipelineRuns, opts.pipelineRunName)}
+	// remote tasks are fetched from the hub or over HTTP like tkn pac resolve does
+	pipelineRuns, err := resolve.Resolve(ctx, opts.cs, logger, github.New(), types, event, &resolve.Opts{
+		GenerateName: true,
+		RemoteTasks:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pr := pipelineRuns[0]
+
+	if pr.Labels == nil {
+		pr.Labels = map[string]string{}
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	providerName := "github"
+	if repo.Spec.GitProvider != nil && repo.Spec.GitProvider.Type != "" {
+		providerName = repo.Spec.GitProvider.Type
+	}
+	if err := kubeinteraction.AddLabelsAndAnnotations(event, pr, repo, &info.ProviderConfig{Name: providerName}, opts.cs); err != nil {
+		return nil, err
+	}
+	pr.Annotations[keys.LocalRun] = "true"
+	// the controller doesn't track a local run, it must not be taken for a
+	// started PipelineRun of the concurrency queues.
+	delete(pr.Labels, keys.State)
+	delete(pr.Annotations, keys.State)
+
+	return opts.cs.Clients.Tekton.TektonV1().PipelineRuns(repo.GetNamespace()).Create(ctx, pr, metav1.CreateOptions{})
+}
+
+// localEvent returns a push event for the current branch and commit of the
+// git checkout.
+func localEvent(dir string) (*info.Event, error) {
+	gitinfo := git.GetGitInfo(dir)
+	if gitinfo.URL == "" || gitinfo.SHA == "" {
+		return nil, fmt.Errorf("cannot detect the remote URL and the current commit of the git checkout in %s", dir)
+	}
+	repoOwner, err := formatting.GetRepoOwnerFromURL(gitinfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	org, repository, _ := strings.Cut(repoOwner, "/")
+
+	event := info.NewEvent()
+	event.EventType = triggertype.Push.String()
+	event.TriggerTarget = triggertype.Push
+	event.URL = gitinfo.URL
+	event.HeadURL = gitinfo.URL
+	event.SHA = gitinfo.SHA
+	event.SHAURL = fmt.Sprintf("%s/commit/%s", gitinfo.URL, gitinfo.SHA)
+	event.BaseBranch = gitinfo.Branch
+	event.HeadBranch = gitinfo.Branch
+	event.DefaultBranch = gitinfo.Branch
+	event.Organization = org
+	event.Repository = repository
+	if title, err := git.RunGit(dir, "log", "-1", "--format=%s"); err == nil {
+		event.SHATitle = strings.TrimSpace(title)
+	}
+	if sender, err := git.RunGit(dir, "config", "user.name"); err == nil {
+		event.Sender = strings.TrimSpace(sender)
+	}
+	return event, nil
+}
+
+// getRepository returns the Repository CR set with the --repository flag, or
+// the one matching the URL of the event.
+func getRepository(ctx context.Context, opts *runOpts, event *info.Event) (*v1alpha1.Repository, error) {
+	ns := opts.cs.Info.Kube.Namespace
+	if opts.namespace != "" {
+		ns = opts.namespace
+	}
+	if opts.repoName != "" {
+		return opts.cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(ns).Get(ctx, opts.repoName, metav1.GetOptions{})
+	}
+	repo, err := matcher.MatchEventURLRepo(ctx, opts.cs, event, ns)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("cannot find a Repository CR matching %s in namespace %s", event.URL, ns)
+	}
+	return repo, nil
+}
+
+// findPipelineRun returns the PipelineRun with the name or generateName.
+func findPipelineRun(pipelineRuns []*tektonv1.PipelineRun, name string) *tektonv1.PipelineRun {
+	for _, pr := range pipelineRuns {
+		if pr.GetName() == name || strings.TrimSuffix(pr.GetGenerateName(), "-") == name {
+			return pr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package runcmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+const pullRequestPipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: pull-request
+  annotations:
+    pipelinesascode.tekton.dev/on-event: "[pull_request]"
+    pipelinesascode.tekton.dev/on-target-branch: "[main]"
+spec:
+  params:
+    - name: revision
+      value: "{{ revision }}"
+    - name: repo_url
+      value: "{{ repo_url }}"
+    - name: branch
+      value: "{{ source_branch }}"
+  pipelineSpec:
+    tasks:
+      - name: task
+        taskSpec:
+          steps:
+            - name: step
+              image: alpine
+              script: echo hello
+`
+
+const gitAuthPipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  generateName: clone-
+spec:
+  workspaces:
+    - name: basic-auth
+      secret:
+        secretName: "{{ git_auth_secret }}"
+  pipelineSpec:
+    tasks:
+      - name: task
+        taskSpec:
+          steps:
+            - name: step
+              image: alpine
+              script: echo hello
+`
+
+func TestRunPipelineRun(t *testing.T) {
+	if os.Getenv("TEST_SKIP_GIT") != "" {
+		t.Skip("Skipping Git test")
+		return
+	}
+	if gitPath, _ := exec.LookPath("git"); gitPath == "" {
+		t.Skip("could not find the git binary in path, skipping test")
+		return
+	}
+
+	tests := []struct {
+		name            string
+		pipelineRunName string
+		repoName        string
+		parameters      []string
+		repoURL         string
+		wantErr         string
+		wantParams      map[string]string
+	}{
+		{
+			name:            "run pipelinerun",
+			pipelineRunName: "pull-request",
+			repoURL:         "https://github.com/owner/repo",
+			wantParams:      map[string]string{"repo_url": "https://github.com/owner/repo", "branch": "main"},
+		},
+		{
+			name:            "run pipelinerun with params and repository name",
+			pipelineRunName: "pull-request",
+			repoName:        "repo",
+			repoURL:         "https://github.com/other/repo",
+			parameters:      []string{"source_branch=feature"},
+			wantParams:      map[string]string{"repo_url": "https://github.com/owner/repo", "branch": "feature"},
+		},
+		{
+			name:            "run pipelinerun with generateName and git_auth_secret",
+			pipelineRunName: "clone",
+			repoURL:         "https://github.com/owner/repo",
+			parameters:      []string{"git_auth_secret=my-secret"},
+		},
+		{
+			name:            "git_auth_secret is required",
+			pipelineRunName: "clone",
+			repoURL:         "https://github.com/owner/repo",
+			wantErr:         "pass an existing secret with -p git_auth_secret=<secret-name>",
+		},
+		{
+			name:            "unknown pipelinerun",
+			pipelineRunName: "push",
+			repoURL:         "https://github.com/owner/repo",
+			wantErr:         "cannot find PipelineRun push in .tekton",
+		},
+		{
+			name:            "no repository",
+			pipelineRunName: "pull-request",
+			repoURL:         "https://github.com/other/repo",
+			wantErr:         "cannot find a Repository CR matching https://github.com/owner/repo in namespace ns",
+		},
+		{
+			name:            "invalid param",
+			pipelineRunName: "pull-request",
+			repoURL:         "https://github.com/owner/repo",
+			parameters:      []string{"revision"},
+			wantErr:         `invalid param "revision"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkout := fs.NewDir(t, "checkout",
+				fs.WithDir(".tekton",
+					fs.WithFile("pull-request.yaml", pullRequestPipelineRun),
+					fs.WithFile("clone.yaml", gitAuthPipelineRun)),
+			)
+			for _, args := range [][]string{
+				{"init", "-b", "main"},
+				{"config", "--local", "user.email", "foo@foo.com"},
+				{"config", "--local", "user.name", "Mister Ze Foo"},
+				{"remote", "add", "origin", "https://github.com/owner/repo"},
+				{"add", "."},
+				{"commit", "-m", "Add pipelineruns"},
+			} {
+				_, err := git.RunGit(checkout.Path(), args...)
+				assert.NilError(t, err)
+			}
+			sha, err := git.RunGit(checkout.Path(), "rev-parse", "HEAD")
+			assert.NilError(t, err)
+			sha = strings.TrimSpace(sha)
+
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{{
+					ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+					Spec:       v1alpha1.RepositorySpec{URL: tt.repoURL},
+				}},
+			})
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode:    stdata.PipelineAsCode,
+					Tekton:            stdata.Pipeline,
+					Kube:              stdata.Kube,
+					Log:               zap.NewNop().Sugar(),
+					ClientInitialized: true,
+				},
+				Info: info.Info{Pac: info.NewPacOpts(), Kube: &info.KubeOpts{Namespace: "ns"}, Controller: &info.ControllerInfo{}},
+			}
+			pr, err := runPipelineRun(ctx, &runOpts{
+				cs:              cs,
+				pipelineRunName: tt.pipelineRunName,
+				repoName:        tt.repoName,
+				tektonDir:       ".tekton",
+				gitDir:          checkout.Path(),
+				parameters:      tt.parameters,
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, pr.GetNamespace(), "ns")
+			assert.Equal(t, pr.GetGenerateName(), tt.pipelineRunName+"-")
+			assert.Equal(t, pr.GetAnnotations()[keys.LocalRun], "true")
+			_, hasState := pr.GetLabels()[keys.State]
+			assert.Assert(t, !hasState, "a local run must not have a state label")
+			assert.Assert(t, strings.HasPrefix(pr.GetAnnotations()[keys.OriginalPRName], tt.pipelineRunName))
+			assert.Equal(t, pr.GetAnnotations()[keys.SHA], sha)
+			assert.Equal(t, pr.GetAnnotations()[keys.Sender], "Mister Ze Foo")
+			assert.Equal(t, pr.GetAnnotations()[keys.ShaTitle], "Add pipelineruns")
+			assert.Equal(t, pr.GetLabels()[keys.Repository], "repo")
+			assert.Equal(t, pr.GetLabels()[keys.URLOrg], "owner")
+
+			params := map[string]string{}
+			for _, param := range pr.Spec.Params {
+				params[param.Name] = param.Value.StringVal
+			}
+			for name, value := range tt.wantParams {
+				assert.Equal(t, params[name], value)
+			}
+			if tt.wantParams != nil {
+				assert.Equal(t, params["revision"], sha)
+			}
+		})
+	}
+}
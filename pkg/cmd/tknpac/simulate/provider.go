@@ -0,0 +1,178 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/status"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"go.uber.org/zap"
+)
+
+var errOffline = errors.New("not supported when simulating offline")
+
+// localProvider is a provider backed by the local git checkout, the changed
+// files of the event are the changes of HEAD against the base revision and
+// the files of the repository are read from the checkout.
+type localProvider struct {
+	name   string
+	dir    string
+	base   string
+	logger *zap.SugaredLogger
+}
+
+var _ provider.Interface = (*localProvider)(nil)
+
+func (l *localProvider) SetLogger(logger *zap.SugaredLogger) {
+	l.logger = logger
+}
+
+func (l *localProvider) Validate(context.Context, *params.Run, *info.Event) error {
+	return nil
+}
+
+func (l *localProvider) Detect(*http.Request, string, *zap.SugaredLogger) (bool, bool, *zap.SugaredLogger, string, error) {
+	return false, false, l.logger, "", errOffline
+}
+
+func (l *localProvider) ParsePayload(context.Context, *params.Run, *http.Request, string) (*info.Event, error) {
+	return nil, errOffline
+}
+
+func (l *localProvider) IsAllowed(context.Context, *info.Event) (bool, error) {
+	return true, nil
+}
+
+// IsAllowedOwnersFile checks the OWNERS files of the checkout.
+func (l *localProvider) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (bool, error) {
+	changedFiles, err := l.GetFiles(ctx, event)
+	if err != nil {
+		return false, err
+	}
+	return acl.UserInOwnersFiles(ctx, l.readFile, changedFiles.All, event.Sender)
+}
+
+func (l *localProvider) CreateStatus(context.Context, *info.Event, status.StatusOpts) error {
+	return nil
+}
+
+// GetTektonDir returns the content of the YAML files of the directory.
+func (l *localProvider) GetTektonDir(_ context.Context, _ *info.Event, path, _ string) (string, error) {
+	return resolve.ReadTektonDir(filepath.Join(l.dir, path))
+}
+
+func (l *localProvider) GetFileInsideRepo(ctx context.Context, _ *info.Event, path, _ string) (string, error) {
+	content, err := l.readFile(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if content == "" {
+		return "", fmt.Errorf("cannot find %s in the checkout", path)
+	}
+	return content, nil
+}
+
+func (l *localProvider) SetClient(context.Context, *params.Run, *info.Event, *v1alpha1.Repository, *events.EventEmitter) error {
+	return nil
+}
+
+func (l *localProvider) SetPacInfo(*info.PacOpts) {}
+
+func (l *localProvider) IsCommitOnBranch(_ context.Context, _ *info.Event, sha, branch string) (bool, error) {
+	_, err := git.RunGit(l.dir, "merge-base", "--is-ancestor", sha, branch)
+	return err == nil, nil
+}
+
+func (l *localProvider) GetCommitInfo(_ context.Context, event *info.Event) error {
+	if event.SHA == "" {
+		event.SHA = git.GetGitInfo(l.dir).SHA
+	}
+	return nil
+}
+
+func (l *localProvider) GetConfig() *info.ProviderConfig {
+	return &info.ProviderConfig{Name: l.name}
+}
+
+// GetFiles returns the files changed in HEAD since the merge base with the
+// base revision.
+func (l *localProvider) GetFiles(context.Context, *info.Event) (changedfiles.ChangedFiles, error) {
+	if l.base == "" {
+		return changedfiles.ChangedFiles{}, nil
+	}
+	out, err := git.RunGit(l.dir, "diff", "--name-status", "-M", l.base+"...HEAD")
+	if err != nil {
+		return changedfiles.ChangedFiles{}, err
+	}
+	return parseNameStatus(out), nil
+}
+
+func (l *localProvider) GetTaskURI(context.Context, *info.Event, string) (bool, string, error) {
+	return false, "", nil
+}
+
+func (l *localProvider) CreateToken(context.Context, []string, *info.Event) (string, error) {
+	return "", errOffline
+}
+
+func (l *localProvider) CheckPolicyAllowing(context.Context, *info.Event, []string) (bool, string) {
+	return false, "teams cannot be checked when simulating offline"
+}
+
+func (l *localProvider) GetTemplate(provider.CommentType) string {
+	return ""
+}
+
+func (l *localProvider) CreateComment(context.Context, *info.Event, string, string) error {
+	return nil
+}
+
+// readFile returns the content of a file of the checkout, or an empty string
+// when it doesn't exist.
+func (l *localProvider) readFile(_ context.Context, path string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(l.dir, path))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// parseNameStatus parses the output of git diff --name-status.
+func parseNameStatus(out string) changedfiles.ChangedFiles {
+	files := changedfiles.ChangedFiles{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		file := fields[len(fields)-1]
+		files.All = append(files.All, file)
+		switch fields[0][0] {
+		case 'A':
+			files.Added = append(files.Added, file)
+		case 'D':
+			files.Deleted = append(files.Deleted, file)
+		case 'M':
+			files.Modified = append(files.Modified, file)
+		case 'R':
+			files.Renamed = append(files.Renamed, file)
+		}
+	}
+	return files
+}
@@ -0,0 +1,356 @@
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/cel"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/customparams"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	versionedfake "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/clientset/versioned/fake"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	"github.com/spf13/cobra"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	payloadFlag    = "payload"
+	headersFlag    = "headers"
+	providerFlag   = "provider"
+	baseFlag       = "base"
+	repositoryFlag = "repository"
+	noYAMLFlag     = "no-yaml"
+)
+
+var longHelp = fmt.Sprintf(`Simulate which PipelineRuns of a .tekton/ directory would be triggered by a webhook event.
+
+The webhook payload and headers are parsed like the %[1]s pac cel command does,
+the PipelineRuns of the directory are matched against the event the same way
+the Pipelines-as-Code controller does, and the matched PipelineRuns are printed
+with the reasons they matched and their resolved YAML.
+
+Nothing is contacted: the changed files of the event are the files changed in
+the local git checkout since the merge base with the base revision (the target
+branch of a pull request, or HEAD~1 for a push), and the OWNERS files are read
+from the checkout. Remote tasks are not fetched, the parameters of a Repository
+CR passed with --repository are used but the parameters coming from secrets are
+left unresolved, and the teams of a policy-teams annotation cannot be checked.
+
+  %[1]s pac simulate --payload push.json --headers headers.txt .tekton/`, settings.TknBinaryName)
+
+type simulateOpts struct {
+	payloadFile    string
+	headersFile    string
+	provider       string
+	base           string
+	repositoryFile string
+	noYAML         bool
+	tektonDir      string
+}
+
+func Command(ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &simulateOpts{}
+	cmd := &cobra.Command{
+		Use:   "simulate [tekton-dir]",
+		Short: "Simulate the PipelineRuns matched by a webhook event",
+		Long:  longHelp,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.tektonDir = ".tekton"
+			if len(args) > 0 {
+				opts.tektonDir = args[0]
+			}
+			return simulate(cmd.Context(), ioStreams, opts)
+		},
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+	}
+	cmd.Flags().StringVarP(&opts.payloadFile, payloadFlag, "b", "", "path to the JSON payload of the webhook event (required)")
+	cmd.Flags().StringVarP(&opts.headersFile, headersFlag, "H", "", "path to the headers of the webhook event (required, JSON, HTTP format, or gosmee-generated shell script)")
+	cmd.Flags().StringVarP(&opts.provider, providerFlag, "p", "auto", "payload provider (auto, github, gitlab, bitbucket-cloud, bitbucket-datacenter, gitea, forgejo)")
+	cmd.Flags().StringVar(&opts.base, baseFlag, "", "git revision the changed files are computed against (default: the target branch of a pull request, HEAD~1 for a push)")
+	cmd.Flags().StringVarP(&opts.repositoryFile, repositoryFlag, "r", "", "path to a Repository CR providing the custom parameters")
+	cmd.Flags().BoolVar(&opts.noYAML, noYAMLFlag, false, "don't print the resolved YAML of the matched PipelineRuns")
+	_ = cmd.MarkFlagRequired(payloadFlag)
+	_ = cmd.MarkFlagRequired(headersFlag)
+	return cmd
+}
+
+func simulate(ctx context.Context, ioStreams *cli.IOStreams, opts *simulateOpts) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	body, err := os.ReadFile(opts.payloadFile)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(opts.headersFile)
+	if err != nil {
+		return err
+	}
+	headers, err := cel.ParseHeaders(b)
+	if err != nil {
+		return err
+	}
+	event, providerName, err := cel.EventFromPayload(opts.provider, body, headers, "")
+	if err != nil {
+		return err
+	}
+	if event.TriggerComment != "" {
+		opscomments.SetEventTypeAndTargetPR(event, event.TriggerComment)
+	}
+
+	repo, err := readRepository(opts.repositoryFile, event)
+	if err != nil {
+		return err
+	}
+
+	rawTemplates, err := resolve.ReadTektonDir(opts.tektonDir)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(rawTemplates) == "" {
+		return fmt.Errorf("cannot find any YAML file in %s", opts.tektonDir)
+	}
+
+	checkout := git.GetGitInfo(opts.tektonDir).TopLevelPath
+	if checkout == "" {
+		checkout = "."
+	}
+	vcx := &localProvider{name: providerName, dir: checkout, base: baseRevision(checkout, opts.base, event)}
+
+	logger := zap.NewNop().Sugar()
+	vcx.SetLogger(logger)
+	run := offlineRun(logger, repo)
+	eventEmitter := events.NewEventEmitter(nil, logger)
+	repos := targetRepositories(repo)
+
+	// match a first time on the raw templates like the controller does, to
+	// expand the event type of the comments matching an on-comment annotation.
+	rtypes, err := resolve.ReadTektonTypes(ctx, logger, rawTemplates)
+	if err != nil {
+		return err
+	}
+	_, _, _ = matcher.ExplainMatchPipelinerunByAnnotation(ctx, logger, rtypes.PipelineRuns, run, repos, event, vcx, eventEmitter, repo)
+
+	cp := customparams.NewCustomParams(event, repo, run, nil, eventEmitter, vcx)
+	maptemplate, changedFiles, err := cp.GetParams(ctx)
+	if err != nil {
+		return err
+	}
+	if event.PullRequestNumber != 0 {
+		maptemplate["pull_request_number"] = fmt.Sprintf("%d", event.PullRequestNumber)
+	}
+	allTemplates := templates.ReplacePlaceHoldersVariables(rawTemplates, maptemplate, event.Event, event.Request.Header, changedFiles)
+	types, err := resolve.ReadTektonTypes(ctx, logger, allTemplates)
+	if err != nil {
+		return err
+	}
+	pipelineRuns, err := resolve.MetadataResolve(types.PipelineRuns)
+	if err != nil {
+		return err
+	}
+
+	printEvent(ioStreams, providerName, event, vcx, changedFiles)
+	matches, skipped, matchErr := matcher.ExplainMatchPipelinerunByAnnotation(ctx, logger, pipelineRuns, run, repos, event, vcx, eventEmitter, repo)
+	printMatches(ioStreams, pipelineRuns, matches, skipped)
+	if matchErr != nil || len(matches) == 0 || opts.noYAML {
+		return nil
+	}
+
+	types.PipelineRuns = nil
+	for _, match := range matches {
+		types.PipelineRuns = append(types.PipelineRuns, match.PipelineRun)
+	}
+	resolved, err := resolve.Resolve(ctx, run, logger, vcx, types, event, &resolve.Opts{GenerateName: true})
+	if err != nil {
+		return err
+	}
+	return printYAML(ioStreams, resolved)
+}
+
+// baseRevision returns the revision the changed files are computed against.
+func baseRevision(checkout, base string, event *info.Event) string {
+	if base != "" {
+		return base
+	}
+	if event.TriggerTarget != triggertype.PullRequest || event.BaseBranch == "" {
+		return "HEAD~1"
+	}
+	for _, rev := range []string{"origin/" + event.BaseBranch, event.BaseBranch} {
+		if _, err := git.RunGit(checkout, "rev-parse", "--verify", "--quiet", rev); err == nil {
+			return rev
+		}
+	}
+	return ""
+}
+
+// offlineRun returns the clients used by the matcher, backed by fake
+// clientsets.
+func offlineRun(logger *zap.SugaredLogger, repo *v1alpha1.Repository) *params.Run {
+	return &params.Run{
+		Clients: clients.Clients{
+			Log:            logger,
+			PipelineAsCode: versionedfake.NewSimpleClientset(repo),
+			Tekton:         tektonfake.NewSimpleClientset(),
+		},
+		Info: info.Info{Pac: info.NewPacOpts()},
+	}
+}
+
+// targetRepositories returns a copy of the Repository in any namespace, so
+// the PipelineRuns with a target-namespace annotation can match.
+func targetRepositories(repo *v1alpha1.Repository) matcher.RepositorySource {
+	return func(_ context.Context, _ *info.Event, ns string) (*v1alpha1.Repository, error) {
+		targetRepo := repo.DeepCopy()
+		targetRepo.SetNamespace(ns)
+		return targetRepo, nil
+	}
+}
+
+// readRepository reads the Repository CR from the file, or returns a
+// Repository matching the URL of the event. The parameters coming from
+// secrets are dropped since they cannot be read.
+func readRepository(path string, event *info.Event) (*v1alpha1.Repository, error) {
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "simulate", Namespace: "default"},
+	}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(b, repo); err != nil {
+			return nil, fmt.Errorf("cannot parse the Repository CR %s: %w", path, err)
+		}
+		if repo.GetNamespace() == "" {
+			repo.SetNamespace("default")
+		}
+	}
+	repo.Spec.URL = event.URL
+	if repo.Spec.Params != nil {
+		params := []v1alpha1.Params{}
+		for _, param := range *repo.Spec.Params {
+			if param.SecretRef == nil {
+				params = append(params, param)
+			}
+		}
+		repo.Spec.Params = &params
+	}
+	return repo, nil
+}
+
+func printEvent(ioStreams *cli.IOStreams, providerName string, event *info.Event, vcx *localProvider, changedFiles map[string]any) {
+	cs := ioStreams.ColorScheme()
+	fmt.Fprintf(ioStreams.Out, "%s %s event %s on %s\n", cs.Bold("Event:"), providerName, event.EventType, event.URL)
+	fmt.Fprintf(ioStreams.Out, "  trigger: %s, target branch: %s, source branch: %s, sender: %s\n",
+		event.TriggerTarget, event.BaseBranch, event.HeadBranch, event.Sender)
+	if event.PullRequestNumber != 0 {
+		fmt.Fprintf(ioStreams.Out, "  pull request: %d\n", event.PullRequestNumber)
+	}
+	if all, ok := changedFiles["all"].([]string); ok {
+		fmt.Fprintf(ioStreams.Out, "  changed files: %d since %s\n", len(all), vcx.base)
+	}
+	fmt.Fprintln(ioStreams.Out)
+}
+
+func printMatches(ioStreams *cli.IOStreams, pipelineRuns []*tektonv1.PipelineRun, matches []matcher.Match, skipped []matcher.Skip) {
+	cs := ioStreams.ColorScheme()
+	for _, prun := range pipelineRuns {
+		name := pipelineRunName(prun)
+		idx := slices.IndexFunc(matches, func(m matcher.Match) bool { return m.PipelineRun == prun })
+		if idx == -1 {
+			fmt.Fprintf(ioStreams.Out, "%s %s\n", cs.Red("✗"), cs.Bold(name))
+			for _, reason := range skipReasons(prun, skipped) {
+				fmt.Fprintf(ioStreams.Out, "    %s\n", cs.Dimmed(reason))
+			}
+			continue
+		}
+		fmt.Fprintf(ioStreams.Out, "%s %s\n", cs.Green("✓"), cs.Bold(name))
+		for _, reason := range matchReasons(prun, matches[idx]) {
+			fmt.Fprintf(ioStreams.Out, "    %s\n", reason)
+		}
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(ioStreams.Out, "\n%s\n", cs.Yellow("no PipelineRun matched the event"))
+	}
+}
+
+// matchReasons returns why the PipelineRun has matched.
+func matchReasons(prun *tektonv1.PipelineRun, match matcher.Match) []string {
+	annotations := prun.GetAnnotations()
+	switch {
+	case annotations[keys.OnComment] != "" && match.Config["target-event"] == "":
+		return []string{fmt.Sprintf("on-comment: %s", annotations[keys.OnComment])}
+	case annotations[keys.OnCelExpression] != "":
+		return []string{fmt.Sprintf("on-cel-expression: %s", strings.TrimSpace(annotations[keys.OnCelExpression]))}
+	}
+	reasons := []string{}
+	for _, key := range []string{"target-event", "target-branch", "path-change", "path-change-ignore", "label", "target-namespace"} {
+		if value, ok := match.Config[key]; ok {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", key, value))
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "targeted by the event")
+	}
+	return reasons
+}
+
+// skipReasons returns why the matcher skipped the PipelineRun.
+func skipReasons(prun *tektonv1.PipelineRun, skipped []matcher.Skip) []string {
+	reasons := []string{}
+	for _, skip := range skipped {
+		if skip.PipelineRun == prun {
+			reasons = append(reasons, skip.Reason)
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no annotation is matching the event")
+	}
+	return reasons
+}
+
+func pipelineRunName(prun *tektonv1.PipelineRun) string {
+	if name := prun.GetAnnotations()[keys.OriginalPRName]; name != "" {
+		return name
+	}
+	if prun.GetName() != "" {
+		return prun.GetName()
+	}
+	return prun.GetGenerateName()
+}
+
+func printYAML(ioStreams *cli.IOStreams, pipelineRuns []*tektonv1.PipelineRun) error {
+	for _, prun := range pipelineRuns {
+		prun.APIVersion = tektonv1.SchemeGroupVersion.String()
+		prun.Kind = "PipelineRun"
+		prun.SetNamespace("")
+		doc, err := yaml.Marshal(prun)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(ioStreams.Out, "---\n%s", doc)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+const pushPayload = `{
+  "ref": "refs/heads/main",
+  "before": "0000000000000000000000000000000000000000",
+  "after": "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+  "repository": {
+    "name": "repo",
+    "html_url": "https://github.com/owner/repo",
+    "default_branch": "main",
+    "owner": {"login": "owner"}
+  },
+  "sender": {"login": "user"},
+  "head_commit": {"id": "6113728f27ae82c7b1a177c8d03f9e96e0adf246", "message": "Update docs"}
+}`
+
+const pushPipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: push
+  annotations:
+    pipelinesascode.tekton.dev/on-event: "[push]"
+    pipelinesascode.tekton.dev/on-target-branch: "[main]"
+spec:
+  params:
+    - name: revision
+      value: "{{ revision }}"
+  pipelineSpec:
+    tasks:
+      - name: task
+        taskSpec:
+          steps:
+            - name: step
+              image: alpine
+              script: echo {{ repo_url }}
+`
+
+const docsPipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: docs
+  annotations:
+    pipelinesascode.tekton.dev/on-cel-expression: event == "push" && "docs/**".pathChanged()
+spec:
+  pipelineSpec:
+    tasks:
+      - name: task
+        taskSpec:
+          steps:
+            - name: step
+              image: alpine
+              script: echo docs
+`
+
+const pullRequestPipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: pull-request
+  annotations:
+    pipelinesascode.tekton.dev/on-event: "[pull_request]"
+    pipelinesascode.tekton.dev/on-target-branch: "[main]"
+spec:
+  pipelineSpec:
+    tasks:
+      - name: task
+        taskSpec:
+          steps:
+            - name: step
+              image: alpine
+              script: echo pr
+`
+
+func TestSimulate(t *testing.T) {
+	if os.Getenv("TEST_SKIP_GIT") != "" {
+		t.Skip("Skipping Git test")
+		return
+	}
+	if gitPath, _ := exec.LookPath("git"); gitPath == "" {
+		t.Skip("could not find the git binary in path, skipping test")
+		return
+	}
+
+	tests := []struct {
+		name        string
+		changedFile string
+		noYAML      bool
+	}{
+		{
+			name:        "push",
+			changedFile: "main.go",
+		},
+		{
+			name:        "push docs",
+			changedFile: "docs/index.md",
+			noYAML:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkout := fs.NewDir(t, "checkout",
+				fs.WithDir(".tekton",
+					fs.WithFile("push.yaml", pushPipelineRun),
+					fs.WithFile("docs.yaml", docsPipelineRun),
+					fs.WithFile("pull-request.yaml", pullRequestPipelineRun)),
+			)
+			for _, args := range [][]string{
+				{"init", "-b", "main"},
+				{"config", "--local", "user.email", "foo@foo.com"},
+				{"config", "--local", "user.name", "Mister Ze Foo"},
+				{"add", "."},
+				{"commit", "-m", "Initial commit"},
+			} {
+				_, err := git.RunGit(checkout.Path(), args...)
+				assert.NilError(t, err)
+			}
+			assert.NilError(t, os.MkdirAll(filepath.Dir(checkout.Join(tt.changedFile)), 0o755))
+			assert.NilError(t, os.WriteFile(checkout.Join(tt.changedFile), []byte("change"), 0o600))
+			for _, args := range [][]string{{"add", "."}, {"commit", "-m", "Change"}} {
+				_, err := git.RunGit(checkout.Path(), args...)
+				assert.NilError(t, err)
+			}
+
+			event := fs.NewDir(t, "event",
+				fs.WithFile("payload.json", pushPayload),
+				fs.WithFile("headers.json", `{"X-GitHub-Event": "push"}`))
+			ioStreams, _, out, _ := cli.IOTest()
+			err := simulate(context.Background(), ioStreams, &simulateOpts{
+				payloadFile: event.Join("payload.json"),
+				headersFile: event.Join("headers.json"),
+				provider:    "auto",
+				noYAML:      tt.noYAML,
+				tektonDir:   checkout.Join(".tekton"),
+			})
+			assert.NilError(t, err)
+			golden.Assert(t, out.String(), strings.ReplaceAll(fmt.Sprintf("%s.golden", t.Name()), "/", "-"))
+		})
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	out := "A\tadded.go\nM\tmodified.go\nD\tdeleted.go\nR100\told.go\trenamed.go\n"
+	assert.DeepEqual(t, parseNameStatus(out), changedfiles.ChangedFiles{
+		All:      []string{"added.go", "modified.go", "deleted.go", "renamed.go"},
+		Added:    []string{"added.go"},
+		Deleted:  []string{"deleted.go"},
+		Modified: []string{"modified.go"},
+		Renamed:  []string{"renamed.go"},
+	})
+	assert.DeepEqual(t, parseNameStatus(""), changedfiles.ChangedFiles{})
+}
+
+func TestIsAllowedOwnersFileGetFilesError(t *testing.T) {
+	if gitPath, _ := exec.LookPath("git"); gitPath == "" {
+		t.Skip("could not find the git binary in path, skipping test")
+		return
+	}
+	l := &localProvider{dir: t.TempDir(), base: "nonexistent"}
+	allowed, err := l.IsAllowedOwnersFile(context.Background(), &info.Event{Sender: "user"})
+	assert.Assert(t, err != nil)
+	assert.Assert(t, !allowed)
+}
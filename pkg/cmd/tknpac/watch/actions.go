@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	kstatus "github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction/status"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// cancelMergePatch cancels a PipelineRun and lets its finally tasks run, the
+// same way the controller does on /cancel.
+var cancelMergePatch = map[string]any{
+	"spec": map[string]any{
+		"status": tektonv1.PipelineRunSpecStatusCancelledRunFinally,
+	},
+}
+
+func cancelPipelineRun(ctx context.Context, cs *params.Run, pr *tektonv1.PipelineRun) (string, error) {
+	if pr.IsDone() {
+		return "", fmt.Errorf("PipelineRun %s has already finished", pr.GetName())
+	}
+	if _, err := action.PatchPipelineRun(ctx, cs.Clients.Log, "cancel patch", cs.Clients.Tekton, pr, cancelMergePatch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("PipelineRun %s has been cancelled", pr.GetName()), nil
+}
+
+// rerunPipelineRun queues a copy of a finished PipelineRun, the controller
+// starts it within the concurrency limit of the Repository CR and reports its
+// status like for the PipelineRuns it creates. The secret generated by the
+// controller to clone the repository is removed when the PipelineRun
+// finishes, and the check run of a GitHub App is created by the controller,
+// those PipelineRuns have to be retested from the pull request.
+func rerunPipelineRun(ctx context.Context, cs *params.Run, pr *tektonv1.PipelineRun) (string, error) {
+	if !pr.IsDone() {
+		return "", fmt.Errorf("PipelineRun %s is still running", pr.GetName())
+	}
+	if secret := pr.GetAnnotations()[keys.GitAuthSecret]; secret != "" {
+		return "", fmt.Errorf("PipelineRun %s uses the generated secret %s, comment /retest on the pull request to retest it", pr.GetName(), secret)
+	}
+	if _, ok := pr.GetAnnotations()[keys.InstallationID]; ok {
+		return "", fmt.Errorf("PipelineRun %s reports on a check run of the GitHub App, comment /retest on the pull request to retest it", pr.GetName())
+	}
+
+	generateName := pr.GetGenerateName()
+	if generateName == "" {
+		generateName = pr.GetName() + "-"
+	}
+	rerun := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    pr.GetNamespace(),
+			Labels:       map[string]string{},
+			Annotations:  map[string]string{},
+		},
+		Spec: *pr.Spec.DeepCopy(),
+	}
+	for k, v := range pr.GetLabels() {
+		rerun.Labels[k] = v
+	}
+	for k, v := range pr.GetAnnotations() {
+		rerun.Annotations[k] = v
+	}
+	// the controller set those while running and reporting the original run
+	for _, key := range []string{keys.LogURL, keys.CheckRunID, keys.ExecutionOrder, keys.SCMReportingPLRStarted, keys.IncomingID} {
+		delete(rerun.Annotations, key)
+		delete(rerun.Labels, key)
+	}
+	rerun.Labels[keys.State] = kubeinteraction.StateQueued
+	rerun.Annotations[keys.State] = kubeinteraction.StateQueued
+	rerun.Spec.Status = tektonv1.PipelineRunSpecStatusPending
+
+	created, err := cs.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).Create(ctx, rerun, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	// the controller only queues a PipelineRun listed in its execution order
+	orderPatch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				keys.ExecutionOrder: created.GetNamespace() + "/" + created.GetName(),
+			},
+		},
+	}
+	if _, err := action.PatchPipelineRun(ctx, cs.Clients.Log, "execution order", cs.Clients.Tekton, created, orderPatch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("PipelineRun %s has been queued", created.GetName()), nil
+}
+
+// taskRunProgress counts the finished tasks of a PipelineRun, the total is
+// the number of tasks of the resolved pipeline when it is known.
+func taskRunProgress(ctx context.Context, cs *params.Run) taskProgress {
+	return func(pr *tektonv1.PipelineRun) (int, int) {
+		statuses := kstatus.GetStatusFromTaskStatusOrFromAsking(ctx, pr, cs)
+		done := 0
+		for _, status := range statuses {
+			if status.Status == nil {
+				continue
+			}
+			if cond := status.Status.GetCondition(apis.ConditionSucceeded); cond != nil && !cond.IsUnknown() {
+				done++
+			}
+		}
+		total := len(pr.Status.ChildReferences)
+		if spec := pr.Status.PipelineSpec; spec != nil {
+			total = max(total, len(spec.Tasks)+len(spec.Finally))
+		}
+		return done, total
+	}
+}
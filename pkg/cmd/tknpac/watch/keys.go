@@ -0,0 +1,70 @@
+package watch
+
+import "io"
+
+type key int
+
+const (
+	keyUnknown key = iota
+	keyUp
+	keyDown
+	keyQuit
+	keyLogs
+	keyCancel
+	keyRetest
+	keyYes
+)
+
+// parseKeys returns the keys of the bytes read from a terminal in raw mode.
+func parseKeys(b []byte) []key {
+	ks := []key{}
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case 0x1b:
+			// arrow keys are sent as ESC [ A or ESC O A
+			if i+2 < len(b) && (b[i+1] == '[' || b[i+1] == 'O') {
+				switch b[i+2] {
+				case 'A':
+					ks = append(ks, keyUp)
+				case 'B':
+					ks = append(ks, keyDown)
+				}
+				i += 2
+				continue
+			}
+			ks = append(ks, keyQuit)
+		case 'k':
+			ks = append(ks, keyUp)
+		case 'j':
+			ks = append(ks, keyDown)
+		case 'q', 0x03:
+			ks = append(ks, keyQuit)
+		case 'l':
+			ks = append(ks, keyLogs)
+		case 'c':
+			ks = append(ks, keyCancel)
+		case 'r':
+			ks = append(ks, keyRetest)
+		case 'y', 'Y':
+			ks = append(ks, keyYes)
+		default:
+			ks = append(ks, keyUnknown)
+		}
+	}
+	return ks
+}
+
+// readKeys sends the keys read from the reader until it fails.
+func readKeys(in io.Reader, keysCh chan<- []key) {
+	defer close(keysCh)
+	buf := make([]byte, 32)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			keysCh <- parseKeys(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
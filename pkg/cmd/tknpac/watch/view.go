@@ -0,0 +1,194 @@
+package watch
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/juju/ansiterm"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const keyHelp = "↑/↓ select   l logs   c cancel   r retest   q quit"
+
+// taskProgress returns the number of finished tasks and the number of tasks
+// of a running PipelineRun.
+type taskProgress func(pr *tektonv1.PipelineRun) (int, int)
+
+type runView struct {
+	pr         *tektonv1.PipelineRun
+	reason     string
+	position   int
+	tasksDone  int
+	tasksTotal int
+}
+
+type repoView struct {
+	repo     *v1alpha1.Repository
+	running  []runView
+	queued   []runView
+	finished []runView
+}
+
+// runs returns the runs of the repository in the order they are displayed.
+func (r repoView) runs() []runView {
+	return slices.Concat(r.running, r.queued, r.finished)
+}
+
+// buildView groups the PipelineRuns by repository into the running ones, the
+// queued ones with their position in the queue and the last finished ones.
+func buildView(repos []*v1alpha1.Repository, prs []*tektonv1.PipelineRun, finishedLimit int, progress taskProgress) []repoView {
+	views := make([]repoView, 0, len(repos))
+	for _, repo := range repos {
+		view := repoView{repo: repo}
+		for _, pr := range prs {
+			if pr.GetNamespace() != repo.GetNamespace() || pr.GetLabels()[keys.Repository] != repo.GetName() {
+				continue
+			}
+			rv := runView{pr: pr, reason: runReason(pr)}
+			switch {
+			case pr.IsDone():
+				view.finished = append(view.finished, rv)
+			case pr.GetLabels()[keys.State] == kubeinteraction.StateQueued:
+				view.queued = append(view.queued, rv)
+			default:
+				if progress != nil {
+					rv.tasksDone, rv.tasksTotal = progress(pr)
+				}
+				view.running = append(view.running, rv)
+			}
+		}
+		byCreation := func(a, b runView) int {
+			return a.pr.GetCreationTimestamp().Compare(b.pr.GetCreationTimestamp().Time)
+		}
+		slices.SortStableFunc(view.running, byCreation)
+		slices.SortStableFunc(view.queued, byCreation)
+		for i := range view.queued {
+			view.queued[i].position = i + 1
+		}
+		slices.SortStableFunc(view.finished, func(a, b runView) int {
+			return completionTime(b.pr).Compare(completionTime(a.pr).Time)
+		})
+		if len(view.finished) > finishedLimit {
+			view.finished = view.finished[:finishedLimit]
+		}
+		views = append(views, view)
+	}
+	slices.SortStableFunc(views, func(a, b repoView) int {
+		return strings.Compare(a.repo.GetNamespace()+"/"+a.repo.GetName(), b.repo.GetNamespace()+"/"+b.repo.GetName())
+	})
+	return views
+}
+
+// selectable returns all the runs of the view in the order they are displayed.
+func selectable(views []repoView) []runView {
+	runs := []runView{}
+	for _, view := range views {
+		runs = append(runs, view.runs()...)
+	}
+	return runs
+}
+
+func runReason(pr *tektonv1.PipelineRun) string {
+	if pr.GetLabels()[keys.State] == kubeinteraction.StateQueued && !pr.IsDone() {
+		return "Queued"
+	}
+	if cond := pr.Status.GetCondition(apis.ConditionSucceeded); cond != nil && cond.Reason != "" {
+		return cond.Reason
+	}
+	return "Pending"
+}
+
+func completionTime(pr *tektonv1.PipelineRun) *metav1.Time {
+	if pr.Status.CompletionTime != nil {
+		return pr.Status.CompletionTime
+	}
+	return &pr.CreationTimestamp
+}
+
+func formatRun(rv runView, cs *cli.ColorScheme, clock clockwork.Clock) string {
+	pr := rv.pr
+	annotations := pr.GetAnnotations()
+	columns := []string{
+		pr.GetName(),
+		cs.ColorStatus(rv.reason),
+		annotations[keys.EventType],
+		formatting.SanitizeBranch(annotations[keys.Branch]),
+		formatting.ShortSHA(annotations[keys.SHA]),
+	}
+	switch {
+	case rv.position > 0:
+		columns = append(columns, fmt.Sprintf("position %d", rv.position), "queued "+formatting.Age(&pr.CreationTimestamp, clock))
+	case pr.IsDone():
+		columns = append(columns, formatting.Age(pr.Status.CompletionTime, clock), formatting.Duration(pr.Status.StartTime, pr.Status.CompletionTime))
+	default:
+		tasks := "tasks -/-"
+		if rv.tasksTotal > 0 {
+			tasks = fmt.Sprintf("tasks %d/%d", rv.tasksDone, rv.tasksTotal)
+		}
+		columns = append(columns, tasks, "started "+formatting.Age(pr.Status.StartTime, clock))
+	}
+	return strings.Join(columns, "\t")
+}
+
+// render writes a frame of the view, the selected run is highlighted and the
+// message is shown below the key bindings.
+func render(out io.Writer, views []repoView, selected int, cs *cli.ColorScheme, clock clockwork.Clock, interactive bool, message string) error {
+	w := ansiterm.NewTabWriter(out, 0, 5, 3, ' ', tabwriter.TabIndent)
+	if len(views) == 0 {
+		fmt.Fprintln(w, cs.Dimmed("no repository found"))
+	}
+	index := 0
+	for i, view := range views {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		header := fmt.Sprintf("%s %s", cs.Bold(view.repo.GetNamespace()+"/"+view.repo.GetName()), cs.Dimmed(view.repo.Spec.URL))
+		if limit := view.repo.Spec.ConcurrencyLimit; limit != nil {
+			header += cs.Dimmed(fmt.Sprintf(" (concurrency limit: %d)", *limit))
+		}
+		fmt.Fprintln(w, header)
+		if len(view.runs()) == 0 {
+			fmt.Fprintf(w, "    %s\n", cs.Dimmed("no PipelineRun"))
+			continue
+		}
+		for _, section := range []struct {
+			title string
+			runs  []runView
+		}{
+			{"Running", view.running},
+			{"Queued", view.queued},
+			{"Finished", view.finished},
+		} {
+			if len(section.runs) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "  %s\n", cs.Underline(section.title))
+			for _, rv := range section.runs {
+				cursor := "  "
+				if interactive && index == selected {
+					cursor = cs.Bold("> ")
+				}
+				fmt.Fprintf(w, "  %s%s\n", cursor, formatRun(rv, cs, clock))
+				index++
+			}
+		}
+	}
+	if interactive {
+		fmt.Fprintf(w, "\n%s\n", cs.Dimmed(keyHelp))
+		if message != "" {
+			fmt.Fprintln(w, message)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,363 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/logs"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	pacinformers "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/informers/externalversions"
+	paclisters "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/listers/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/spf13/cobra"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	tektoninformers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	tektonlisters "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	namespaceFlag     = "namespace"
+	allNamespacesFlag = "all-namespaces"
+	finishedFlag      = "finished"
+	tknPathFlag       = "tkn-path"
+
+	refreshInterval = time.Second
+	// escape sequences to switch to the alternate screen of the terminal,
+	// hide the cursor and clear the screen.
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	exitScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+var longhelp = fmt.Sprintf(`watch - live view of the PipelineRuns of the repositories
+
+Show the running, queued and recently finished PipelineRuns of the
+repositories of a namespace (or of a single repository) and refresh the view
+as they change.
+
+When run in a terminal, select a PipelineRun with the arrow keys and press:
+
+  l  to follow its logs
+  c  to cancel it
+  r  to retest it
+  q  to quit
+
+When the output is not a terminal, the view is printed once.
+
+%s pac watch my-repo -n my-namespace`, settings.TknBinaryName)
+
+type watchOpts struct {
+	cs            *params.Run
+	ioStreams     *cli.IOStreams
+	namespace     string
+	allNamespaces bool
+	repoName      string
+	finishedLimit int
+	tknPath       string
+	clock         clockwork.Clock
+}
+
+func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &watchOpts{cs: run, ioStreams: ioStreams, clock: clockwork.NewRealClock()}
+	cmd := &cobra.Command{
+		Use:   "watch [repository]",
+		Short: "Live view of the PipelineRuns of the repositories",
+		Long:  longhelp,
+		Args:  cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion("repositories", args)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.repoName = args[0]
+			}
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			// only report error here on CLI
+			zaplog, err := zap.NewProduction(zap.IncreaseLevel(zap.FatalLevel))
+			if err != nil {
+				return err
+			}
+			run.Clients.Log = zaplog.Sugar()
+			return watch(ctx, opts)
+		},
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.namespace, namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
+	_ = cmd.RegisterFlagCompletionFunc(namespaceFlag,
+		func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion(namespaceFlag, args)
+		},
+	)
+	cmd.Flags().BoolVarP(&opts.allNamespaces, allNamespacesFlag, "A", false, "watch the repositories across all namespaces")
+	cmd.Flags().IntVar(&opts.finishedLimit, finishedFlag, 5, "number of finished PipelineRuns to show per repository")
+	cmd.Flags().StringVar(&opts.tknPath, tknPathFlag, "", fmt.Sprintf("Path to the %s binary to follow the logs with instead of streaming them", settings.TknBinaryName))
+	return cmd
+}
+
+// watcher holds the state of the live view.
+type watcher struct {
+	cs            *params.Run
+	clock         clockwork.Clock
+	repoName      string
+	finishedLimit int
+	repoLister    paclisters.RepositoryLister
+	prLister      tektonlisters.PipelineRunLister
+	progress      taskProgress
+
+	selected int
+	message  string
+	// confirm is the PipelineRun waiting for a confirmation to be cancelled.
+	confirm *tektonv1.PipelineRun
+}
+
+func watch(ctx context.Context, opts *watchOpts) error {
+	ns := opts.cs.Info.Kube.Namespace
+	if opts.namespace != "" {
+		ns = opts.namespace
+	}
+	if opts.allNamespaces {
+		ns = ""
+	}
+	selector := keys.Repository
+	if opts.repoName != "" {
+		selector = keys.Repository + "=" + formatting.CleanValueKubernetes(opts.repoName)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	changed := make(chan struct{}, 1)
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { notify(changed) },
+		UpdateFunc: func(any, any) { notify(changed) },
+		DeleteFunc: func(any) { notify(changed) },
+	}
+
+	pacFactory := pacinformers.NewSharedInformerFactoryWithOptions(opts.cs.Clients.PipelineAsCode, 0, pacinformers.WithNamespace(ns))
+	repoInformer := pacFactory.Pipelinesascode().V1alpha1().Repositories()
+	if _, err := repoInformer.Informer().AddEventHandler(handler); err != nil {
+		return err
+	}
+	tektonFactory := tektoninformers.NewSharedInformerFactoryWithOptions(opts.cs.Clients.Tekton, 0,
+		tektoninformers.WithNamespace(ns),
+		tektoninformers.WithTweakListOptions(func(lo *metav1.ListOptions) { lo.LabelSelector = selector }))
+	prInformer := tektonFactory.Tekton().V1().PipelineRuns()
+	if _, err := prInformer.Informer().AddEventHandler(handler); err != nil {
+		return err
+	}
+	pacFactory.Start(stopCh)
+	tektonFactory.Start(stopCh)
+	pacFactory.WaitForCacheSync(stopCh)
+	tektonFactory.WaitForCacheSync(stopCh)
+
+	w := &watcher{
+		cs:            opts.cs,
+		clock:         opts.clock,
+		repoName:      opts.repoName,
+		finishedLimit: opts.finishedLimit,
+		repoLister:    repoInformer.Lister(),
+		prLister:      prInformer.Lister(),
+		progress:      cachedProgress(taskRunProgress(ctx, opts.cs)),
+	}
+	views, err := w.views()
+	if err != nil {
+		return err
+	}
+	if opts.repoName != "" && len(views) == 0 {
+		return fmt.Errorf("cannot find repository %s", opts.repoName)
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !opts.ioStreams.IsStdoutTTY() || !term.IsTerminal(fd) {
+		return render(opts.ioStreams.Out, views, -1, opts.ioStreams.ColorScheme(), w.clock, false, "")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(opts.ioStreams.Out, enterScreen)
+	restore := func() {
+		fmt.Fprint(opts.ioStreams.Out, exitScreen)
+		_ = term.Restore(fd, state)
+	}
+
+	logsOf, err := w.loop(ctx, opts.ioStreams, os.Stdin, changed)
+	restore()
+	if err != nil || logsOf == nil {
+		return err
+	}
+	if opts.tknPath != "" {
+		return logs.ShowLogsWithTkn(opts.tknPath, logsOf.GetName(), logsOf.GetNamespace())
+	}
+	return logs.StreamPipelineRunLogs(ctx, opts.cs, opts.ioStreams, logsOf.GetName(), logsOf.GetNamespace(), logs.StreamOpts{Follow: true})
+}
+
+// loop redraws the view on every change and handles the keys until the user
+// quits or asks for the logs of a PipelineRun.
+func (w *watcher) loop(ctx context.Context, ioStreams *cli.IOStreams, in io.Reader, changed <-chan struct{}) (*tektonv1.PipelineRun, error) {
+	keysCh := make(chan []key)
+	go readKeys(in, keysCh)
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	cs := ioStreams.ColorScheme()
+	for {
+		views, err := w.views()
+		if err != nil {
+			return nil, err
+		}
+		w.selected = min(max(w.selected, 0), max(len(selectable(views))-1, 0))
+		frame := &bytes.Buffer{}
+		if err := render(frame, views, w.selected, cs, w.clock, true, w.message); err != nil {
+			return nil, err
+		}
+		// the terminal is in raw mode, the lines have to be returned explicitly
+		fmt.Fprint(ioStreams.Out, clearScreen+strings.ReplaceAll(frame.String(), "\n", "\r\n"))
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+		case ks, ok := <-keysCh:
+			if !ok {
+				return nil, nil
+			}
+			for _, k := range ks {
+				quit, logsOf := w.handleKey(ctx, k, selectable(views))
+				if quit || logsOf != nil {
+					return logsOf, nil
+				}
+			}
+		}
+	}
+}
+
+// views returns the view of the repositories from the informer caches.
+func (w *watcher) views() ([]repoView, error) {
+	repos, err := w.repoLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	if w.repoName != "" {
+		filtered := []*v1alpha1.Repository{}
+		for _, repo := range repos {
+			if repo.GetName() == w.repoName {
+				filtered = append(filtered, repo)
+			}
+		}
+		repos = filtered
+	}
+	prs, err := w.prLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	return buildView(repos, prs, w.finishedLimit, w.progress), nil
+}
+
+// handleKey acts on a key for the selected run, it returns true when the user
+// quits or the PipelineRun to show the logs of.
+func (w *watcher) handleKey(ctx context.Context, k key, runs []runView) (bool, *tektonv1.PipelineRun) {
+	var current *tektonv1.PipelineRun
+	if w.selected >= 0 && w.selected < len(runs) {
+		current = runs[w.selected].pr
+	}
+
+	if w.confirm != nil {
+		pr := w.confirm
+		w.confirm = nil
+		w.message = ""
+		if k == keyYes {
+			w.setResult(cancelPipelineRun(ctx, w.cs, pr))
+		}
+		return false, nil
+	}
+
+	w.message = ""
+	switch k {
+	case keyQuit:
+		return true, nil
+	case keyUp:
+		w.selected = max(w.selected-1, 0)
+	case keyDown:
+		w.selected = min(w.selected+1, max(len(runs)-1, 0))
+	case keyLogs:
+		if current != nil {
+			return false, current
+		}
+	case keyCancel:
+		if current == nil {
+			return false, nil
+		}
+		if current.IsDone() {
+			w.message = fmt.Sprintf("PipelineRun %s has already finished", current.GetName())
+			return false, nil
+		}
+		w.confirm = current
+		w.message = fmt.Sprintf("Cancel PipelineRun %s? (y/N)", current.GetName())
+	case keyRetest:
+		if current != nil {
+			w.setResult(rerunPipelineRun(ctx, w.cs, current))
+		}
+	}
+	return false, nil
+}
+
+func (w *watcher) setResult(message string, err error) {
+	if err != nil {
+		w.message = err.Error()
+		return
+	}
+	w.message = message
+}
+
+// notify signals a change without blocking when one is already pending.
+func notify(changed chan<- struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// cachedProgress only asks for the task progress of a PipelineRun when it
+// has changed since the last time.
+func cachedProgress(progress taskProgress) taskProgress {
+	type entry struct {
+		resourceVersion string
+		done, total     int
+	}
+	var mu sync.Mutex
+	entries := map[string]entry{}
+	return func(pr *tektonv1.PipelineRun) (int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := pr.GetNamespace() + "/" + pr.GetName()
+		if e, ok := entries[key]; ok && e.resourceVersion == pr.GetResourceVersion() {
+			return e.done, e.total
+		}
+		done, total := progress(pr)
+		entries[key] = entry{resourceVersion: pr.GetResourceVersion(), done: done, total: total}
+		return done, total
+	}
+}
@@ -0,0 +1,255 @@
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tcli "github.com/openshift-pipelines/pipelines-as-code/pkg/test/cli"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	knativeapis "knative.dev/pkg/apis"
+	knativeduckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+const ns = "namespace"
+
+var now = time.Date(1999, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+func makePR(name, repo, state string, status corev1.ConditionStatus, reason string, created, completed time.Duration) *tektonv1.PipelineRun {
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         ns,
+			CreationTimestamp: metav1.Time{Time: now.Add(-created)},
+			Labels: map[string]string{
+				keys.Repository: repo,
+				keys.State:      state,
+			},
+			Annotations: map[string]string{
+				keys.EventType: "pull_request",
+				keys.Branch:    "main",
+				keys.SHA:       "1234567890abcdef",
+			},
+		},
+		Status: tektonv1.PipelineRunStatus{
+			Status: knativeduckv1.Status{Conditions: knativeduckv1.Conditions{
+				{Type: knativeapis.ConditionSucceeded, Status: status, Reason: reason},
+			}},
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				StartTime: &metav1.Time{Time: now.Add(-created)},
+			},
+		},
+	}
+	if completed > 0 {
+		pr.Status.CompletionTime = &metav1.Time{Time: now.Add(-completed)}
+	}
+	return pr
+}
+
+func fixtures() ([]*v1alpha1.Repository, []*tektonv1.PipelineRun) {
+	limit := 1
+	repos := []*v1alpha1.Repository{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo2", Namespace: ns},
+			Spec:       v1alpha1.RepositorySpec{URL: "https://forge/owner/repo2"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "repo1", Namespace: ns},
+			Spec:       v1alpha1.RepositorySpec{URL: "https://forge/owner/repo1", ConcurrencyLimit: &limit},
+		},
+	}
+	prs := []*tektonv1.PipelineRun{
+		makePR("finished-old", "repo1", "completed", corev1.ConditionTrue, "Succeeded", 30*time.Minute, 25*time.Minute),
+		makePR("finished-new", "repo1", "completed", corev1.ConditionFalse, "Failed", 20*time.Minute, 15*time.Minute),
+		makePR("queued-second", "repo1", "queued", corev1.ConditionUnknown, "PipelineRunPending", 2*time.Minute, 0),
+		makePR("queued-first", "repo1", "queued", corev1.ConditionUnknown, "PipelineRunPending", 3*time.Minute, 0),
+		makePR("running", "repo1", "started", corev1.ConditionUnknown, "Running", 10*time.Minute, 0),
+		makePR("other-repo", "repo3", "started", corev1.ConditionUnknown, "Running", 10*time.Minute, 0),
+	}
+	return repos, prs
+}
+
+func TestBuildView(t *testing.T) {
+	repos, prs := fixtures()
+	views := buildView(repos, prs, 1, func(*tektonv1.PipelineRun) (int, int) { return 2, 5 })
+	assert.Equal(t, len(views), 2)
+	assert.Equal(t, views[0].repo.GetName(), "repo1")
+	assert.Equal(t, len(views[0].running), 1)
+	assert.Equal(t, views[0].running[0].tasksDone, 2)
+	assert.Equal(t, views[0].queued[0].pr.GetName(), "queued-first")
+	assert.Equal(t, views[0].queued[1].position, 2)
+	assert.Equal(t, len(views[0].finished), 1)
+	assert.Equal(t, views[0].finished[0].pr.GetName(), "finished-new")
+	assert.Equal(t, len(views[1].runs()), 0)
+
+	runs := selectable(views)
+	assert.Equal(t, len(runs), 4)
+	assert.Equal(t, runs[3].pr.GetName(), "finished-new")
+}
+
+func TestRender(t *testing.T) {
+	repos, prs := fixtures()
+	views := buildView(repos, prs, 5, func(*tektonv1.PipelineRun) (int, int) { return 2, 5 })
+	cs := cli.NewColorScheme(false, false)
+	clock := clockwork.NewFakeClockAt(now)
+
+	tests := []struct {
+		name        string
+		views       []repoView
+		interactive bool
+		message     string
+	}{
+		{name: "static", views: views},
+		{name: "interactive", views: views, interactive: true, message: "Cancel PipelineRun queued-first? (y/N)"},
+		{name: "no repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			assert.NilError(t, render(out, tt.views, 2, cs, clock, tt.interactive, tt.message))
+			golden.Assert(t, out.String(), strings.ReplaceAll(fmt.Sprintf("%s.golden", t.Name()), "/", "-"))
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{name: "arrows", input: "\x1b[A\x1b[B\x1bOA", want: []key{keyUp, keyDown, keyUp}},
+		{name: "vi keys", input: "jk", want: []key{keyDown, keyUp}},
+		{name: "actions", input: "lcry", want: []key{keyLogs, keyCancel, keyRetest, keyYes}},
+		{name: "quit", input: "q\x03\x1b", want: []key{keyQuit, keyQuit, keyQuit}},
+		{name: "unknown", input: "x", want: []key{keyUnknown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, parseKeys([]byte(tt.input)), tt.want)
+		})
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	repos, prs := fixtures()
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+		Namespaces:   []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: ns}}},
+		PipelineRuns: prs,
+	})
+	log, _ := logger.GetLogger()
+	w := &watcher{cs: &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline, Log: log}}}
+	runs := selectable(buildView(repos, prs, 5, nil))
+
+	quit, logsOf := w.handleKey(ctx, keyDown, runs)
+	assert.Assert(t, !quit && logsOf == nil)
+	assert.Equal(t, w.selected, 1)
+
+	_, logsOf = w.handleKey(ctx, keyLogs, runs)
+	assert.Equal(t, logsOf.GetName(), "queued-first")
+
+	// cancel needs a confirmation
+	w.handleKey(ctx, keyCancel, runs)
+	assert.Equal(t, w.message, "Cancel PipelineRun queued-first? (y/N)")
+	w.handleKey(ctx, keyUnknown, runs)
+	assert.Equal(t, w.message, "")
+	pr, err := stdata.Pipeline.TektonV1().PipelineRuns(ns).Get(ctx, "queued-first", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, pr.Spec.Status, tektonv1.PipelineRunSpecStatus(""))
+
+	w.handleKey(ctx, keyCancel, runs)
+	w.handleKey(ctx, keyYes, runs)
+	assert.Equal(t, w.message, "PipelineRun queued-first has been cancelled")
+	pr, err = stdata.Pipeline.TektonV1().PipelineRuns(ns).Get(ctx, "queued-first", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, pr.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusCancelledRunFinally))
+
+	// a running PipelineRun cannot be retested
+	w.handleKey(ctx, keyUp, runs)
+	w.handleKey(ctx, keyRetest, runs)
+	assert.Equal(t, w.message, "PipelineRun running is still running")
+
+	// a finished one is queued again
+	w.selected = 3
+	w.handleKey(ctx, keyRetest, runs)
+	assert.Assert(t, strings.HasSuffix(w.message, "has been queued"), w.message)
+	all, err := stdata.Pipeline.TektonV1().PipelineRuns(ns).List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(all.Items), len(prs)+1)
+	found := false
+	for _, rerun := range all.Items {
+		if rerun.GetGenerateName() == "finished-new-" {
+			found = true
+			assert.Equal(t, rerun.GetLabels()[keys.State], "queued")
+			assert.Equal(t, rerun.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusPending))
+			assert.Equal(t, rerun.GetAnnotations()[keys.ExecutionOrder], ns+"/"+rerun.GetName())
+			assert.Equal(t, rerun.GetAnnotations()[keys.SHA], "1234567890abcdef")
+		}
+	}
+	assert.Assert(t, found)
+	w.handleKey(ctx, keyCancel, runs)
+	assert.Equal(t, w.message, "PipelineRun finished-new has already finished")
+
+	quit, _ = w.handleKey(ctx, keyQuit, runs)
+	assert.Assert(t, quit)
+}
+
+func TestRerunWithGitAuthSecret(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	pr := makePR("finished", "repo1", "completed", corev1.ConditionTrue, "Succeeded", 10*time.Minute, 5*time.Minute)
+	pr.Annotations[keys.GitAuthSecret] = "pac-gitauth-abcd"
+	_, err := rerunPipelineRun(ctx, &params.Run{}, pr)
+	assert.ErrorContains(t, err, "comment /retest on the pull request")
+}
+
+func TestRerunGitHubApp(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	pr := makePR("finished", "repo1", "completed", corev1.ConditionTrue, "Succeeded", 10*time.Minute, 5*time.Minute)
+	pr.Annotations[keys.InstallationID] = "12345"
+	_, err := rerunPipelineRun(ctx, &params.Run{}, pr)
+	assert.ErrorContains(t, err, "comment /retest on the pull request")
+}
+
+func TestWatchNotATerminal(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	repos, prs := fixtures()
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+		Namespaces:   []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: ns}}},
+		Repositories: repos,
+		PipelineRuns: prs,
+	})
+	log, _ := logger.GetLogger()
+	cs := &params.Run{
+		Clients: clients.Clients{
+			PipelineAsCode: stdata.PipelineAsCode,
+			Tekton:         stdata.Pipeline,
+			Log:            log,
+		},
+		Info: info.Info{Kube: &info.KubeOpts{Namespace: ns}},
+	}
+
+	ios, out := tcli.NewIOStream()
+	err := watch(ctx, &watchOpts{cs: cs, ioStreams: ios, repoName: "repo1", finishedLimit: 1, clock: clockwork.NewFakeClockAt(now)})
+	assert.NilError(t, err)
+	golden.Assert(t, out.String(), strings.ReplaceAll(fmt.Sprintf("%s.golden", t.Name()), "/", "-"))
+
+	err = watch(ctx, &watchOpts{cs: cs, ioStreams: ios, repoName: "nothere", clock: clockwork.NewFakeClockAt(now)})
+	assert.ErrorContains(t, err, "cannot find repository nothere")
+}
@@ -86,7 +86,9 @@ func add(ctx context.Context, opts *cli.PacCliOpts, run *params.Run, ioStreams *
 		}
 	}
 
-	if providerName, err = webhook.GetProviderName(repo.Spec.URL); err != nil {
+	if repo.Spec.GitProvider != nil && repo.Spec.GitProvider.Type != "" {
+		providerName = repo.Spec.GitProvider.Type
+	} else if providerName, err = webhook.GetProviderName(repo.Spec.URL); err != nil {
 		return err
 	}
 
@@ -133,6 +135,7 @@ func add(ctx context.Context, opts *cli.PacCliOpts, run *params.Run, ioStreams *
 		PACNamespace:             pacNamespace,
 		RepositoryURL:            repo.Spec.URL,
 		ProviderAPIURL:           repo.Spec.GitProvider.URL,
+		UserName:                 repo.Spec.GitProvider.User,
 		IOStreams:                ioStreams,
 		PersonalAccessToken:      string(tokenData),
 		RepositoryCreateORUpdate: false,
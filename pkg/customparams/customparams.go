@@ -3,6 +3,7 @@ package customparams
 import (
 	"context"
 	"fmt"
+	"maps"
 
 	celTypes "github.com/google/cel-go/common/types"
 	"go.uber.org/zap"
@@ -41,6 +42,12 @@ func NewCustomParams(event *info.Event, repo *v1alpha1.Repository, run *params.R
 
 // applyIncomingParams apply incoming params to an existing map (overwriting existing keys).
 func (p *CustomParams) applyIncomingParams(ret map[string]string) map[string]string {
+	if p.event.IncomingParams != nil {
+		for k, v := range p.event.IncomingParams {
+			ret[k] = v
+		}
+		return ret
+	}
 	if p.event.Request == nil {
 		return ret
 	}
@@ -56,6 +63,21 @@ func (p *CustomParams) applyIncomingParams(ret map[string]string) map[string]str
 	return ret
 }
 
+// applyCommandArgs apply the arguments of a custom GitOps command to an
+// existing map (overwriting existing keys). The standard parameters are
+// reserved and cannot be overridden by a command argument.
+func (p *CustomParams) applyCommandArgs(ret, stdParams map[string]string) map[string]string {
+	for k, v := range p.event.CommandArgs {
+		if _, ok := stdParams[k]; ok {
+			p.eventEmitter.EmitMessage(p.repo, zap.WarnLevel, "CommandArgReserved",
+				fmt.Sprintf("ignoring the argument %s of the GitOps command, it is a standard parameter", k))
+			continue
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
 // GetParams will process the parameters as set in the repo.Spec CR.
 // value can come from a string or from a secretKeyRef or from a string value
 // if both is set we pick the value and issue a warning in the user namespace
@@ -64,6 +86,7 @@ func (p *CustomParams) applyIncomingParams(ret map[string]string) map[string]str
 // matched true.
 func (p *CustomParams) GetParams(ctx context.Context) (map[string]string, map[string]any, error) {
 	stdParams, changedFiles := p.makeStandardParamsFromEvent(ctx)
+	reserved := maps.Clone(stdParams)
 	resolvedParams, mapFilters, parsedFromComment := map[string]string{}, map[string]string{}, map[string]string{}
 	if p.event.TriggerComment != "" {
 		parsedFromComment = opscomments.ParseKeyValueArgs(p.event.TriggerComment)
@@ -75,7 +98,7 @@ func (p *CustomParams) GetParams(ctx context.Context) (map[string]string, map[st
 	}
 
 	if p.repo.Spec.Params == nil {
-		return p.applyIncomingParams(stdParams), changedFiles, nil
+		return p.applyCommandArgs(p.applyIncomingParams(stdParams), reserved), changedFiles, nil
 	}
 
 	for index, value := range *p.repo.Spec.Params {
@@ -161,5 +184,5 @@ func (p *CustomParams) GetParams(ctx context.Context) (map[string]string, map[st
 		}
 	}
 
-	return p.applyIncomingParams(resolvedParams), changedFiles, nil
+	return p.applyCommandArgs(p.applyIncomingParams(resolvedParams), reserved), changedFiles, nil
 }
@@ -110,6 +110,34 @@ func TestApplyIncomingParams(t *testing.T) {
 	}
 }
 
+func TestApplyCommandArgs(t *testing.T) {
+	p := &CustomParams{
+		event: &info.Event{
+			State: info.State{
+				CommandArgs: map[string]string{"environment": "staging", "revision": "evil"},
+			},
+		},
+		repo:         &v1alpha1.Repository{},
+		eventEmitter: events.NewEventEmitter(nil, zap.NewNop().Sugar()),
+	}
+	got := p.applyCommandArgs(map[string]string{"environment": "dev", "revision": "main"}, map[string]string{"revision": "main"})
+	assert.DeepEqual(t, got, map[string]string{"environment": "staging", "revision": "main"})
+}
+
+func TestApplyIncomingParamsMapped(t *testing.T) {
+	p := &CustomParams{
+		event: &info.Event{
+			State: info.State{
+				IncomingParams: map[string]string{"image": "quay.io/org/app:v1"},
+			},
+			// the params in the third-party payload are not used
+			Request: &info.Request{Payload: []byte(`{"params": {"revision": "injected"}}`)},
+		},
+	}
+	got := p.applyIncomingParams(map[string]string{"revision": "main"})
+	assert.DeepEqual(t, got, map[string]string{"image": "quay.io/org/app:v1", "revision": "main"})
+}
+
 func TestProcessTemplates(t *testing.T) {
 	ns := "there"
 	// event_type is a standard params and should override it from the command line
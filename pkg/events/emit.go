@@ -31,8 +31,17 @@ func (e *EventEmitter) SetLogger(logger *zap.SugaredLogger) {
 }
 
 func (e *EventEmitter) EmitMessage(repo *v1alpha1.Repository, loggerLevel zapcore.Level, reason, message string) {
+	e.EmitLabelledMessage(repo, loggerLevel, reason, message, nil)
+}
+
+// EmitLabelledMessage emits the message with extra labels on the event, to
+// find it back later.
+func (e *EventEmitter) EmitLabelledMessage(repo *v1alpha1.Repository, loggerLevel zapcore.Level, reason, message string, labels map[string]string) {
 	if repo != nil && e.client != nil {
 		event := makeEvent(repo, loggerLevel, reason, message)
+		for k, v := range labels {
+			event.Labels[k] = v
+		}
 		if _, err := e.client.CoreV1().Events(event.Namespace).Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
 			if e.logger != nil {
 				e.logger.Infof("Cannot create event: %s", err.Error())
@@ -1,6 +1,8 @@
 package kubeinteraction
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 
@@ -21,6 +23,14 @@ const (
 	StateFailed    = "failed"
 )
 
+// CancelInProgressKeyLabel returns the label value used to select the
+// PipelineRuns sharing the same cancel-in-progress key. The key can be any
+// user provided string so we hash it to always get a valid label value.
+func CancelInProgressKeyLabel(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
 func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRun, repo *apipac.Repository, providerConfig *info.ProviderConfig, paramsRun *params.Run) error {
 	if event == nil {
 		return fmt.Errorf("event should not be nil")
@@ -59,6 +69,10 @@ func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRu
 			paramsinfo.Controller.Name, paramsinfo.Controller.Configmap, paramsinfo.Controller.Secret, paramsinfo.Controller.GlobalRepository),
 	}
 
+	if event.IncomingID != "" {
+		labels[keys.IncomingID] = event.IncomingID
+	}
+
 	if event.PullRequestNumber != 0 {
 		labels[keys.PullRequest] = strconv.Itoa(event.PullRequestNumber)
 		annotations[keys.PullRequest] = strconv.Itoa(event.PullRequestNumber)
@@ -85,6 +99,9 @@ func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRu
 	if value, ok := pipelineRun.GetObjectMeta().GetAnnotations()[keys.CancelInProgress]; ok {
 		labels[keys.CancelInProgress] = value
 	}
+	if value, ok := pipelineRun.GetObjectMeta().GetAnnotations()[keys.CancelInProgressKey]; ok && value != "" {
+		labels[keys.CancelInProgressKey] = CancelInProgressKeyLabel(value)
+	}
 
 	for k, v := range labels {
 		pipelineRun.Labels[k] = v
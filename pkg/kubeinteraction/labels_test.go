@@ -24,6 +24,7 @@ func TestAddLabelsAndAnnotations(t *testing.T) {
 	event.SHAURL = "https://url/sha"
 	event.HeadBranch = "pr_branch"
 	event.HeadURL = "https://url/pr"
+	event.IncomingID = "0123456789abcdef"
 
 	type args struct {
 		event          *info.Event
@@ -43,7 +44,8 @@ func TestAddLabelsAndAnnotations(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{
 						Labels: map[string]string{},
 						Annotations: map[string]string{
-							keys.CancelInProgress: "true",
+							keys.CancelInProgress:    "true",
+							keys.CancelInProgressKey: "deploy-main",
 						},
 					},
 				},
@@ -74,6 +76,8 @@ func TestAddLabelsAndAnnotations(t *testing.T) {
 				tt.args.pipelineRun.Labels[keys.URLOrg], tt.args.event.Organization)
 			assert.Equal(t, tt.args.pipelineRun.Labels[keys.CancelInProgress], tt.args.pipelineRun.Annotations[keys.CancelInProgress], "'%s' != %s",
 				tt.args.pipelineRun.Labels[keys.CancelInProgress], tt.args.pipelineRun.Annotations[keys.CancelInProgress])
+			assert.Equal(t, tt.args.pipelineRun.Labels[keys.CancelInProgressKey], CancelInProgressKeyLabel("deploy-main"))
+			assert.Equal(t, tt.args.pipelineRun.Labels[keys.IncomingID], tt.args.event.IncomingID)
 			assert.Equal(t, tt.args.pipelineRun.Annotations[keys.URLOrg], tt.args.event.Organization, "'%s' != %s",
 				tt.args.pipelineRun.Annotations[keys.URLOrg], tt.args.event.Organization)
 			assert.Equal(t, tt.args.pipelineRun.Annotations[keys.ShaURL], tt.args.event.SHAURL)
@@ -84,3 +88,10 @@ func TestAddLabelsAndAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelInProgressKeyLabel(t *testing.T) {
+	label := CancelInProgressKeyLabel("a very long key with spaces/slashes and {{ chars }} that are not valid in labels")
+	assert.Equal(t, len(label), 32)
+	assert.Equal(t, label, CancelInProgressKeyLabel("a very long key with spaces/slashes and {{ chars }} that are not valid in labels"))
+	assert.Assert(t, label != CancelInProgressKeyLabel("another key"))
+}
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 
+	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
@@ -41,6 +42,10 @@ const (
 // but all matching pipelines have already succeeded for this commit.
 var ErrNoFailedPipelineToRetest = errors.New("All PipelineRuns for this commit have already succeeded. Use `/retest <pipeline-name>` to re-run a specific pipeline or `/test` to re-run all pipelines.") // nolint:revive,staticcheck
 
+// ErrPolicyTeamsDenied is returned when the sender is not allowed to trigger
+// any of the matched PipelineRuns by their policy-teams annotation.
+var ErrPolicyTeamsDenied = errors.New("not allowed by the policy-teams annotation of the matched PipelineRuns")
+
 // prunBranch is value from annotations and baseBranch is event.Base value from event.
 func branchMatch(prunBranch, baseBranch string) bool {
 	// Helper function to match glob pattern
@@ -75,8 +80,11 @@ func branchMatch(prunBranch, baseBranch string) bool {
 	return matchGlob(prunBranch, baseBranch)
 }
 
+// GetAnnotationValues returns the values of an annotation, either a single
+// value or a list of values in the [value1, value2] format.
+//
 // TODO: move to another file since it's common to all annotations_* files.
-func getAnnotationValues(annotation string) ([]string, error) {
+func GetAnnotationValues(annotation string) ([]string, error) {
 	re := regexp.MustCompile(reValidateTag)
 	annotation = strings.TrimSpace(annotation)
 	match := re.MatchString(annotation)
@@ -112,8 +120,12 @@ func getTargetBranch(prun *tektonv1.PipelineRun, event *info.Event) (bool, strin
 		}
 		targetEvents := []string{event.TriggerTarget.String()}
 		if event.EventType == triggertype.Incoming.String() {
-			// if we have a incoming event, we want to match pipelineruns on both incoming and push
+			// if we have a incoming event, we want to match pipelineruns on both incoming and push,
+			// or pull_request for the incoming webhooks of type pull-request
 			targetEvents = []string{triggertype.Incoming.String(), triggertype.Push.String()}
+			if event.TriggerTarget == triggertype.PullRequest {
+				targetEvents[1] = triggertype.PullRequest.String()
+			}
 		}
 		matched, err := matchOnAnnotation(key, targetEvents, false)
 		targetEvent = key
@@ -197,8 +209,60 @@ func checkPipelineRunAnnotation(prun *tektonv1.PipelineRun, eventEmitter *events
 	}
 }
 
+// Skip is a PipelineRun which has not been matched to the event, with the
+// reason why.
+type Skip struct {
+	PipelineRun *tektonv1.PipelineRun
+	Reason      string
+}
+
+// RepositorySource returns the Repository CR matching the URL of the event in
+// the namespace, or nil when there is none.
+type RepositorySource func(ctx context.Context, event *info.Event, ns string) (*apipac.Repository, error)
+
 func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger, pruns []*tektonv1.PipelineRun, cs *params.Run, event *info.Event, vcx provider.Interface, eventEmitter *events.EventEmitter, repo *apipac.Repository, reportErrors bool) ([]Match, error) {
+	repos := func(ctx context.Context, event *info.Event, ns string) (*apipac.Repository, error) {
+		return MatchEventURLRepo(ctx, cs, event, ns)
+	}
+	matchedPRs, _, err := matchPipelineRuns(ctx, logger, pruns, cs, repos, event, vcx, eventEmitter, repo, reportErrors)
+	if err != nil {
+		return matchedPRs, err
+	}
+
+	// Filter out templates that already have successful PipelineRuns for /retest and /ok-to-test,
+	// the retest command of an incoming webhook acts like a /retest comment
+	if event.EventType == opscomments.RetestAllCommentEventType.String() ||
+		event.EventType == opscomments.UnholdCommentEventType.String() ||
+		event.EventType == opscomments.OkToTestCommentEventType.String() ||
+		isIncomingRetestAll(event) {
+		logger.Debugf("MatchPipelinerunByAnnotation: filtering successful templates for event_type=%s", event.EventType)
+		filtered := filterSuccessfulTemplates(ctx, logger, cs, event, repo, matchedPRs)
+		if len(filtered) == 0 {
+			return nil, ErrNoFailedPipelineToRetest
+		}
+		return filtered, nil
+	}
+	return matchedPRs, nil
+}
+
+// isIncomingRetestAll returns true if the event is a retest command of an
+// incoming webhook without a targeted PipelineRun.
+func isIncomingRetestAll(event *info.Event) bool {
+	return event.EventType == triggertype.Incoming.String() && event.IncomingCommand == apincoming.CommandRetest && event.TargetPipelineRun == ""
+}
+
+// ExplainMatchPipelinerunByAnnotation matches the PipelineRuns to the event
+// like MatchPipelinerunByAnnotation, without reporting the errors, and returns
+// why the other PipelineRuns have been skipped. It doesn't need a cluster: the
+// Repository CRs targeted by the target-namespace annotations come from repos,
+// and the templates which already have a successful PipelineRun are kept.
+func ExplainMatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger, pruns []*tektonv1.PipelineRun, cs *params.Run, repos RepositorySource, event *info.Event, vcx provider.Interface, eventEmitter *events.EventEmitter, repo *apipac.Repository) ([]Match, []Skip, error) {
+	return matchPipelineRuns(ctx, logger, pruns, cs, repos, event, vcx, eventEmitter, repo, false)
+}
+
+func matchPipelineRuns(ctx context.Context, logger *zap.SugaredLogger, pruns []*tektonv1.PipelineRun, cs *params.Run, repos RepositorySource, event *info.Event, vcx provider.Interface, eventEmitter *events.EventEmitter, repo *apipac.Repository, reportErrors bool) ([]Match, []Skip, error) {
 	matchedPRs := []Match{}
+	skipped := []Skip{}
 	logger.Debugf("MatchPipelinerunByAnnotation: pipelineruns=%d event_type=%s trigger_target=%s report_errors=%t", len(pruns), event.EventType, event.TriggerTarget, reportErrors)
 	infomsg := fmt.Sprintf("matching pipelineruns to event: URL=%s, target-branch=%s, source-branch=%s, target-event=%s",
 		event.URL,
@@ -233,6 +297,9 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			PipelineRun: prun,
 			Config:      map[string]string{},
 		}
+		skip := func(reason string) {
+			skipped = append(skipped, Skip{PipelineRun: prun, Reason: reason})
+		}
 
 		prName := getName(prun)
 		if event.TargetPipelineRun != "" && event.TargetPipelineRun == strings.TrimSuffix(prName, "-") {
@@ -243,6 +310,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 
 		if prun.GetObjectMeta().GetAnnotations() == nil {
 			logger.Debugf("PipelineRun %s does not have any annotations", prName)
+			skip("no annotation")
 			continue
 		}
 
@@ -253,9 +321,10 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 
 		if targetNS, ok := prun.GetObjectMeta().GetAnnotations()[keys.TargetNamespace]; ok {
 			prMatch.Config["target-namespace"] = targetNS
-			prMatch.Repo, _ = MatchEventURLRepo(ctx, cs, event, targetNS)
+			prMatch.Repo, _ = repos(ctx, event, targetNS)
 			if prMatch.Repo == nil {
 				logger.Warnf("could not find Repository CRD in branch %s, the pipelineRun %s has a label that explicitly targets it", targetNS, prName)
+				skip(fmt.Sprintf("no Repository CR in the target namespace %s", targetNS))
 				continue
 			}
 			logger.Debugf("PipelineRun %s: matched target namespace repo=%s/%s", prName, prMatch.Repo.GetNamespace(), prMatch.Repo.GetName())
@@ -265,6 +334,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			re, err := regexp.Compile(targetComment)
 			if err != nil {
 				logger.Warnf("could not compile regexp %s from pipelineRun %s", targetComment, prName)
+				skip(fmt.Sprintf("invalid on-comment regexp %s", targetComment))
 				continue
 			}
 
@@ -292,6 +362,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 		}
 		// if the event is a comment event, but we don't have any match from the keys.OnComment then skip the other evaluations
 		if event.EventType == opscomments.NoOpsCommentEventType.String() || event.EventType == opscomments.OnCommentEventType.String() {
+			skip("the comment is not matching an on-comment annotation")
 			continue
 		}
 
@@ -302,6 +373,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			out, err := celEvaluate(ctx, celExpr, event, vcx, customParams, eventEmitter, repo)
 			if err != nil {
 				logger.Errorf("there was an error evaluating the CEL expression, skipping: %v", err)
+				skip(fmt.Sprintf("error evaluating the on-cel-expression: %v", err))
 				if checkIfCELEvaluateError(err) {
 					if provider.IsCommentStrategyUpdate(repo) {
 						// Using the same logic for getting OriginalPipelineRun as the one used for setting
@@ -329,6 +401,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			logger.Debugf("PipelineRun %s: CEL result=%v", prName, out)
 			if out != types.True {
 				logger.Infof("CEL expression for PipelineRun %s is not matching, skipping", prName)
+				skip("on-cel-expression is not matching")
 				continue
 			}
 			logger.Infof("CEL expression has been evaluated and matched")
@@ -339,15 +412,18 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			_, hasOnLabel := prun.GetObjectMeta().GetAnnotations()[keys.OnLabel]
 			if event.TriggerTarget == triggertype.PullRequest && event.EventType == string(triggertype.PullRequestLabeled) && !hasOnLabel {
 				logger.Infof("label update event, PipelineRun %s does not have a on-label for any of those labels: %s", prName, strings.Join(event.PullRequestLabel, "|"))
+				skip("label update event without an on-label annotation")
 				continue
 			}
 
 			matched, targetEvent, targetBranch, err := getTargetBranch(prun, event)
 			if err != nil {
-				return matchedPRs, err
+				return matchedPRs, skipped, err
 			}
 			if !matched {
 				logger.Debugf("PipelineRun %s: target branch/event did not match", prName)
+				skip(fmt.Sprintf("on-event %s or on-target-branch %s is not matching",
+					prun.GetObjectMeta().GetAnnotations()[keys.OnEvent], prun.GetObjectMeta().GetAnnotations()[keys.OnTargetBranch]))
 				continue
 			}
 			prMatch.Config["target-branch"] = targetBranch
@@ -358,6 +434,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 				changedFiles, err := vcx.GetFiles(ctx, event)
 				if err != nil {
 					logger.Errorf("error getting changed files: %v", err)
+					skip(fmt.Sprintf("cannot get the changed files: %v", err))
 					continue
 				}
 				// // TODO(chmou): we use the matchOnAnnotation function, it's
@@ -365,10 +442,11 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 				// our own path changes. we may split up if needed to refine.
 				matched, err := matchOnAnnotation(key, changedFiles.All, true)
 				if err != nil {
-					return matchedPRs, err
+					return matchedPRs, skipped, err
 				}
 				if !matched {
 					logger.Debugf("PipelineRun %s: path-change annotation did not match", prName)
+					skip(fmt.Sprintf("on-path-change %s is not matching the changed files", key))
 					continue
 				}
 				logger.Infof("matched PipelineRun with name: %s, annotation PathChange: %q", prName, key)
@@ -378,10 +456,11 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			if key, ok := prun.GetObjectMeta().GetAnnotations()[keys.OnLabel]; ok {
 				matched, err := matchOnAnnotation(key, event.PullRequestLabel, false)
 				if err != nil {
-					return matchedPRs, err
+					return matchedPRs, skipped, err
 				}
 				if !matched {
 					logger.Debugf("PipelineRun %s: label annotation did not match", prName)
+					skip(fmt.Sprintf("on-label %s is not matching the labels", key))
 					continue
 				}
 				logger.Infof("matched PipelineRun with name: %s, annotation Label: %q", prName, key)
@@ -392,6 +471,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 				changedFiles, err := vcx.GetFiles(ctx, event)
 				if err != nil {
 					logger.Errorf("error getting changed files: %v", err)
+					skip(fmt.Sprintf("cannot get the changed files: %v", err))
 					continue
 				}
 				// // TODO(chmou): we use the matchOnAnnotation function, it's
@@ -399,10 +479,11 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 				// our own path changes. we may split up if needed to refine.
 				matched, err := matchOnAnnotation(key, changedFiles.All, true)
 				if err != nil {
-					return matchedPRs, err
+					return matchedPRs, skipped, err
 				}
 				if matched {
 					logger.Infof("Skipping pipelinerun with name: %s, annotation PathChangeIgnore: %q", prName, key)
+					skip(fmt.Sprintf("on-path-change-ignore %s is matching the changed files", key))
 					continue
 				}
 				prMatch.Config["path-change-ignore"] = key
@@ -420,20 +501,26 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 	}
 
 	if len(matchedPRs) > 0 {
-		// Filter out templates that already have successful PipelineRuns for /retest and /ok-to-test
-		if event.EventType == opscomments.RetestAllCommentEventType.String() ||
-			event.EventType == opscomments.OkToTestCommentEventType.String() {
-			logger.Debugf("MatchPipelinerunByAnnotation: filtering successful templates for event_type=%s", event.EventType)
-			filtered := filterSuccessfulTemplates(ctx, logger, cs, event, repo, matchedPRs)
-			if len(filtered) == 0 {
-				return nil, ErrNoFailedPipelineToRetest
+		allowedPRs := []Match{}
+		for _, match := range matchedPRs {
+			msg := policyTeamsDenial(ctx, logger, match.PipelineRun, event, vcx)
+			if msg == "" {
+				allowedPRs = append(allowedPRs, match)
+				continue
 			}
-			return filtered, nil
+			logger.Info(msg)
+			if reportErrors {
+				reportPolicyTeamsDenial(ctx, match.PipelineRun, event, vcx, eventEmitter, repo, msg)
+			}
+			skipped = append(skipped, Skip{PipelineRun: match.PipelineRun, Reason: msg})
+		}
+		if len(allowedPRs) == 0 {
+			return nil, skipped, fmt.Errorf("sender %s is not allowed to trigger any of the matched PipelineRuns by their %s annotation: %w", event.Sender, keys.PolicyTeams, ErrPolicyTeamsDenied)
 		}
-		return matchedPRs, nil
+		return allowedPRs, skipped, nil
 	}
 
-	return nil, fmt.Errorf("%s", buildAvailableMatchingAnnotationErr(event, pruns))
+	return nil, skipped, fmt.Errorf("%s", buildAvailableMatchingAnnotationErr(event, pruns))
 }
 
 // filterSuccessfulTemplates filters out templates that already have successful PipelineRuns
@@ -528,7 +615,7 @@ func buildAvailableMatchingAnnotationErr(event *info.Event, pruns []*tektonv1.Pi
 }
 
 func matchOnAnnotation(annotations string, eventType []string, branchMatching bool) (bool, error) {
-	targets, err := getAnnotationValues(annotations)
+	targets, err := GetAnnotationValues(annotations)
 	if err != nil {
 		return false, err
 	}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/go-github/v81/github"
 	"github.com/jonboulle/clockwork"
+	apincoming "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/incoming"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
@@ -998,6 +999,32 @@ func TestMatchPipelinerunAnnotationAndRepositories(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "matching pull-request incoming webhook event on pull_request target",
+			args: annotationTestArgs{
+				pruns: []*tektonv1.PipelineRun{
+					makePipelineRunTargetNS(triggertype.PullRequest.String(), ""),
+				},
+				runevent: info.Event{
+					URL:               targetURL,
+					EventType:         triggertype.Incoming.String(),
+					TriggerTarget:     triggertype.PullRequest,
+					PullRequestNumber: 10,
+					BaseBranch:        mainBranch,
+				},
+				data: testclient.Data{
+					Repositories: []*v1alpha1.Repository{
+						testnewrepo.NewRepo(
+							testnewrepo.RepoTestcreationOpts{
+								Name:             "test-good",
+								URL:              targetURL,
+								InstallNamespace: pipelineTargetNSName,
+							},
+						),
+					},
+				},
+			},
+		},
 		{
 			name: "matching incoming webhook event on incoming target",
 			args: annotationTestArgs{
@@ -2366,6 +2393,50 @@ func TestMatchPipelinerunByAnnotation(t *testing.T) {
 	}
 }
 
+func TestExplainMatchPipelinerunByAnnotation(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+	prun := func(name string, annotations map[string]string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+	pruns := []*tektonv1.PipelineRun{
+		prun("push", map[string]string{keys.OnEvent: "[push]", keys.OnTargetBranch: "[main]"}),
+		prun("pull-request", map[string]string{keys.OnEvent: "[pull_request]", keys.OnTargetBranch: "[main]"}),
+		prun("cel", map[string]string{keys.OnCelExpression: `event == "pull_request"`}),
+		prun("target-ns", map[string]string{keys.OnEvent: "[push]", keys.OnTargetBranch: "[main]", keys.TargetNamespace: "other"}),
+		prun("no-annotation", nil),
+	}
+	event := &info.Event{
+		TriggerTarget: triggertype.Push,
+		EventType:     "push",
+		BaseBranch:    "main",
+		URL:           "https://github.com/org/repo",
+		Request:       &info.Request{Header: http.Header{}},
+	}
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	repos := func(_ context.Context, _ *info.Event, _ string) (*v1alpha1.Repository, error) {
+		return nil, nil
+	}
+	eventEmitter := events.NewEventEmitter(nil, logger)
+
+	matches, skipped, err := ExplainMatchPipelinerunByAnnotation(ctx, logger, pruns, &params.Run{}, repos, event, &ghprovider.Provider{}, eventEmitter, repo)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 1)
+	assert.Equal(t, matches[0].PipelineRun.GetName(), "push")
+
+	reasons := map[string]string{}
+	for _, skip := range skipped {
+		reasons[skip.PipelineRun.GetName()] = skip.Reason
+	}
+	assert.DeepEqual(t, reasons, map[string]string{
+		"pull-request":  "on-event [pull_request] or on-target-branch [main] is not matching",
+		"cel":           "on-cel-expression is not matching",
+		"target-ns":     "no Repository CR in the target namespace other",
+		"no-annotation": "no annotation",
+	})
+}
+
 func Test_getAnnotationValues(t *testing.T) {
 	type args struct {
 		annotation string
@@ -2440,13 +2511,13 @@ func Test_getAnnotationValues(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getAnnotationValues(tt.args.annotation)
+			got, err := GetAnnotationValues(tt.args.annotation)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("getAnnotationValues() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetAnnotationValues() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getAnnotationValues() got = %v, want %v", got, tt.want)
+				t.Errorf("GetAnnotationValues() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -3288,3 +3359,38 @@ func TestFilterSuccessfulTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestIsIncomingRetestAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *info.Event
+		want  bool
+	}{
+		{
+			name:  "incoming retest",
+			event: &info.Event{EventType: triggertype.Incoming.String(), State: info.State{IncomingCommand: apincoming.CommandRetest}},
+			want:  true,
+		},
+		{
+			name:  "incoming retest of a pipelinerun",
+			event: &info.Event{EventType: triggertype.Incoming.String(), TargetPipelineRun: "pr1", State: info.State{IncomingCommand: apincoming.CommandRetest}},
+		},
+		{
+			name:  "incoming cancel",
+			event: &info.Event{EventType: triggertype.Incoming.String(), State: info.State{IncomingCommand: apincoming.CommandCancel}},
+		},
+		{
+			name:  "incoming without command",
+			event: &info.Event{EventType: triggertype.Incoming.String()},
+		},
+		{
+			name:  "retest comment",
+			event: &info.Event{EventType: opscomments.RetestAllCommentEventType.String()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isIncomingRetestAll(tt.event))
+		})
+	}
+}
@@ -20,32 +20,17 @@ import (
 	"go.uber.org/zap"
 )
 
-func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provider.Interface, customParams map[string]string, eventEmitter *events.EventEmitter, repo *apipac.Repository) (ref.Val, error) {
-	eventTitle := event.PullRequestTitle
-	if event.TriggerTarget == triggertype.Push {
-		eventTitle = event.SHATitle
-	}
-
-	nbody, err := json.Marshal(event.Event)
-	if err != nil {
-		return nil, err
-	}
-	var jsonMap map[string]any
-	err = json.Unmarshal(nbody, &jsonMap)
-	if err != nil {
-		return nil, err
-	}
-	headerMap := make(map[string]string)
-	for k, v := range event.Request.Header {
-		headerMap[strings.ToLower(k)] = v[0]
-	}
-
-	standardParams := map[string]bool{
-		"event": true, "event_type": true, "headers": true, "body": true,
-		"event_title": true, "target_branch": true, "source_branch": true,
-		"target_url": true, "source_url": true, "files": true,
-	}
+// celStandardVariables are the variables always available to the
+// on-cel-expression annotations, custom parameters cannot override them.
+var celStandardVariables = map[string]bool{
+	"event": true, "event_type": true, "headers": true, "body": true,
+	"event_title": true, "target_branch": true, "source_branch": true,
+	"target_url": true, "source_url": true, "files": true,
+}
 
+// newCelEnv returns the environment the on-cel-expression annotations are
+// evaluated in, the custom parameters are declared as strings.
+func newCelEnv(ctx context.Context, vcx provider.Interface, event *info.Event, customParams map[string]string) (*cel.Env, error) {
 	varDecls := []cel.EnvOption{
 		cel.Lib(celPac{vcx, ctx, event}),
 		cel.VariableDecls(
@@ -63,16 +48,14 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 	}
 
 	for k := range customParams {
-		if !standardParams[k] {
+		if !celStandardVariables[k] {
 			varDecls = append(varDecls, cel.VariableDecls(decls.NewVariable(k, types.StringType)))
 		}
 	}
+	return cel.NewEnv(varDecls...)
+}
 
-	env, err := cel.NewEnv(varDecls...)
-	if err != nil {
-		return nil, err
-	}
-
+func compileCelExpression(env *cel.Env, expr string) (*cel.Ast, error) {
 	parsed, issues := env.Parse(expr)
 	if issues != nil && issues.Err() != nil {
 		return nil, fmt.Errorf("failed to parse expression %#v: %w", expr, issues.Err())
@@ -82,6 +65,53 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 	if issues != nil && issues.Err() != nil {
 		return nil, fmt.Errorf("expression %#v check failed: %w", expr, issues.Err())
 	}
+	return checked, nil
+}
+
+// CheckCelExpression parses and type-checks an on-cel-expression annotation
+// without evaluating it, the custom parameters are declared as strings.
+func CheckCelExpression(expr string, customParams []string) error {
+	params := map[string]string{}
+	for _, name := range customParams {
+		params[name] = ""
+	}
+	env, err := newCelEnv(context.Background(), nil, info.NewEvent(), params)
+	if err != nil {
+		return err
+	}
+	_, err = compileCelExpression(env, expr)
+	return err
+}
+
+func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provider.Interface, customParams map[string]string, eventEmitter *events.EventEmitter, repo *apipac.Repository) (ref.Val, error) {
+	eventTitle := event.PullRequestTitle
+	if event.TriggerTarget == triggertype.Push {
+		eventTitle = event.SHATitle
+	}
+
+	nbody, err := json.Marshal(event.Event)
+	if err != nil {
+		return nil, err
+	}
+	var jsonMap map[string]any
+	err = json.Unmarshal(nbody, &jsonMap)
+	if err != nil {
+		return nil, err
+	}
+	headerMap := make(map[string]string)
+	for k, v := range event.Request.Header {
+		headerMap[strings.ToLower(k)] = v[0]
+	}
+
+	env, err := newCelEnv(ctx, vcx, event, customParams)
+	if err != nil {
+		return nil, err
+	}
+
+	checked, err := compileCelExpression(env, expr)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert AST for inspection
 	checkedExpr, err := cel.AstToCheckedExpr(checked)
@@ -151,7 +181,7 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 	}
 
 	for k, v := range customParams {
-		if !standardParams[k] {
+		if !celStandardVariables[k] {
 			data[k] = v
 		} else if eventEmitter != nil && repo != nil {
 			eventEmitter.EmitMessage(repo, zap.WarnLevel, "CELParamConflict",
@@ -195,3 +195,47 @@ func TestWalkExprForLabelReferences(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckCelExpression(t *testing.T) {
+	tests := []struct {
+		name         string
+		expr         string
+		customParams []string
+		wantErr      string
+	}{
+		{
+			name: "valid",
+			expr: `event == "pull_request" && target_branch == "main" && "docs/*.md".pathChanged()`,
+		},
+		{
+			name:         "custom param",
+			expr:         `event == "push" && environment == "prod"`,
+			customParams: []string{"environment"},
+		},
+		{
+			name:    "syntax error",
+			expr:    `event == "push" &&`,
+			wantErr: "failed to parse expression",
+		},
+		{
+			name:    "undeclared reference",
+			expr:    `event == "push" && environment == "prod"`,
+			wantErr: "undeclared reference to 'environment'",
+		},
+		{
+			name:    "type error",
+			expr:    `event == 1`,
+			wantErr: "check failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCelExpression(tt.expr, tt.customParams)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
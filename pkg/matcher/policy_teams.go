@@ -0,0 +1,123 @@
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	providerstatus "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/status"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+const policyTeamsDeniedTitle = "Access denied"
+
+// IsAllowedByPolicyTeams checks the sender of the event against the teams or
+// users listed in the policy-teams annotation of the PipelineRun. Members of
+// the OWNERS file are allowed as they are for the policy of the Repository.
+// When the sender is denied and report is set, a neutral status is created
+// for this PipelineRun only. The membership checks are cached on the event,
+// the PipelineRuns of an event often share the same teams.
+//
+// An incoming webhook request has no sender to check, it has been
+// authenticated by the secret of the webhook and is allowed.
+func IsAllowedByPolicyTeams(ctx context.Context, logger *zap.SugaredLogger, prun *tektonv1.PipelineRun, event *info.Event, vcx provider.Interface, eventEmitter *events.EventEmitter, repo *apipac.Repository, report bool) bool {
+	msg := policyTeamsDenial(ctx, logger, prun, event, vcx)
+	if msg == "" {
+		return true
+	}
+	logger.Info(msg)
+	if report {
+		reportPolicyTeamsDenial(ctx, prun, event, vcx, eventEmitter, repo, msg)
+	}
+	return false
+}
+
+// policyTeamsDenial returns why the sender is not allowed to trigger the
+// PipelineRun by its policy-teams annotation, or an empty string when allowed.
+func policyTeamsDenial(ctx context.Context, logger *zap.SugaredLogger, prun *tektonv1.PipelineRun, event *info.Event, vcx provider.Interface) string {
+	value, ok := prun.GetAnnotations()[keys.PolicyTeams]
+	if !ok {
+		return ""
+	}
+	prName := getName(prun)
+	if event.EventType == triggertype.Incoming.String() {
+		logger.Debugf("PipelineRun %s: %s annotation not checked for the incoming webhook request", prName, keys.PolicyTeams)
+		return ""
+	}
+	teams, err := GetAnnotationValues(value)
+	if err != nil {
+		logger.Warnf("PipelineRun %s has an invalid %s annotation, denying: %v", prName, keys.PolicyTeams, err)
+		teams = []string{}
+	}
+	teams = slices.DeleteFunc(teams, func(team string) bool { return team == "" })
+
+	if slices.Contains(teams, event.Sender) {
+		return ""
+	}
+	reason := "no teams set"
+	if len(teams) > 0 {
+		check := policyTeamsCheck(ctx, event, vcx, teams)
+		if check.Allowed {
+			return ""
+		}
+		reason = check.Reason
+	}
+	// the OWNERS check is cached on the event for the sender by the acl package
+	allowed, err := vcx.IsAllowedOwnersFile(ctx, event)
+	if err != nil {
+		logger.Errorf("cannot check the OWNERS file for the policy of PipelineRun %s: %v", prName, err)
+	} else if allowed {
+		logger.Infof("sender %s is not in the policy teams of PipelineRun %s but allowed via OWNERS file", event.Sender, prName)
+		return ""
+	}
+	return fmt.Sprintf("User %s is not allowed to trigger the PipelineRun %s, only the members of %s can: %s", event.Sender, prName, strings.Join(teams, ", "), reason)
+}
+
+// reportPolicyTeamsDenial reports the denial as an event and as a neutral
+// status of the PipelineRun.
+func reportPolicyTeamsDenial(ctx context.Context, prun *tektonv1.PipelineRun, event *info.Event, vcx provider.Interface, eventEmitter *events.EventEmitter, repo *apipac.Repository, msg string) {
+	eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPolicyTeamsDenied", msg)
+	// use the same name as the OriginalPRName annotation so the status is
+	// the one of the PipelineRun
+	originPipelineRunName := prun.GetAnnotations()[keys.OriginalPRName]
+	if originPipelineRunName == "" {
+		originPipelineRunName = prun.GetName()
+	}
+	if originPipelineRunName == "" {
+		originPipelineRunName = prun.GetGenerateName()
+	}
+	status := providerstatus.StatusOpts{
+		Status:                  "completed",
+		Title:                   policyTeamsDeniedTitle,
+		Text:                    msg,
+		Conclusion:              providerstatus.ConclusionNeutral,
+		DetailsURL:              event.URL,
+		OriginalPipelineRunName: originPipelineRunName,
+		AccessDenied:            true,
+	}
+	if err := vcx.CreateStatus(ctx, event, status); err != nil {
+		eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create the access denied status of PipelineRun %s: %v", getName(prun), err))
+	}
+}
+
+// policyTeamsCheck checks the sender against the teams, once per event.
+func policyTeamsCheck(ctx context.Context, event *info.Event, vcx provider.Interface, teams []string) info.PolicyCheck {
+	key := strings.Join(teams, ",")
+	if check, ok := event.PolicyTeamsChecks[key]; ok {
+		return check
+	}
+	allowed, reason := vcx.CheckPolicyAllowing(ctx, event, teams)
+	if event.PolicyTeamsChecks == nil {
+		event.PolicyTeamsChecks = map[string]info.PolicyCheck{}
+	}
+	event.PolicyTeamsChecks[key] = info.PolicyCheck{Allowed: allowed, Reason: reason}
+	return event.PolicyTeamsChecks[key]
+}
@@ -0,0 +1,167 @@
+package matcher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestIsAllowedByPolicyTeams(t *testing.T) {
+	tests := []struct {
+		name                string
+		policyTeams         string
+		sender              string
+		policyDisallowing   bool
+		allowedInOwnersFile bool
+		want                bool
+	}{
+		{
+			name: "no annotation",
+			want: true,
+		},
+		{
+			name:        "member of the team",
+			policyTeams: "release-managers",
+			sender:      "contributor",
+			want:        true,
+		},
+		{
+			name:              "not member of the teams",
+			policyTeams:       "[release-managers, admins]",
+			sender:            "contributor",
+			policyDisallowing: true,
+			want:              false,
+		},
+		{
+			name:              "user listed directly",
+			policyTeams:       "[release-managers, maintainer]",
+			sender:            "maintainer",
+			policyDisallowing: true,
+			want:              true,
+		},
+		{
+			name:                "allowed via OWNERS file",
+			policyTeams:         "release-managers",
+			sender:              "owner",
+			policyDisallowing:   true,
+			allowedInOwnersFile: true,
+			want:                true,
+		},
+		{
+			name:        "empty annotation",
+			policyTeams: "[]",
+			sender:      "contributor",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			prun := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "release",
+					Annotations: map[string]string{},
+				},
+			}
+			if tt.policyTeams != "" {
+				prun.Annotations[keys.PolicyTeams] = tt.policyTeams
+			}
+			vcx := &testprovider.TestProviderImp{
+				PolicyDisallowing:   tt.policyDisallowing,
+				AllowedInOwnersFile: tt.allowedInOwnersFile,
+			}
+			event := &info.Event{Sender: tt.sender, TriggerTarget: triggertype.PullRequest}
+			eventEmitter := events.NewEventEmitter(nil, logger)
+			got := IsAllowedByPolicyTeams(ctx, logger, prun, event, vcx, eventEmitter, nil, true)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestMatchPipelinerunByAnnotationPolicyTeams(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+	annotations := func(extra map[string]string) map[string]string {
+		ret := map[string]string{
+			keys.OnEvent:        "[pull_request]",
+			keys.OnTargetBranch: "[main]",
+		}
+		for k, v := range extra {
+			ret[k] = v
+		}
+		return ret
+	}
+	pruns := []*tektonv1.PipelineRun{
+		{ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: annotations(nil)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "release", Annotations: annotations(map[string]string{keys.PolicyTeams: "release-managers"})}},
+	}
+	event := &info.Event{
+		Sender:        "contributor",
+		TriggerTarget: triggertype.PullRequest,
+		EventType:     triggertype.PullRequest.String(),
+		BaseBranch:    "main",
+	}
+	cs := &params.Run{}
+	eventEmitter := events.NewEventEmitter(nil, logger)
+
+	newEvent := func() *info.Event {
+		ev := *event
+		return &ev
+	}
+
+	matches, err := MatchPipelinerunByAnnotation(ctx, logger, pruns, cs, newEvent(), &testprovider.TestProviderImp{PolicyDisallowing: true}, eventEmitter, nil, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 1)
+	assert.Equal(t, matches[0].PipelineRun.GetName(), "test")
+
+	_, err = MatchPipelinerunByAnnotation(ctx, logger, pruns[1:], cs, newEvent(), &testprovider.TestProviderImp{PolicyDisallowing: true}, eventEmitter, nil, true)
+	assert.ErrorContains(t, err, "sender contributor is not allowed to trigger any of the matched PipelineRuns")
+	assert.Assert(t, errors.Is(err, ErrPolicyTeamsDenied))
+
+	matches, err = MatchPipelinerunByAnnotation(ctx, logger, pruns, cs, newEvent(), &testprovider.TestProviderImp{}, eventEmitter, nil, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 2)
+
+	// an incoming webhook request has been authenticated by its secret
+	incoming := newEvent()
+	incoming.EventType = triggertype.Incoming.String()
+	incoming.TriggerTarget = triggertype.Push
+	incoming.Sender = "incoming"
+	assert.Assert(t, IsAllowedByPolicyTeams(ctx, logger, pruns[1], incoming, &testprovider.TestProviderImp{PolicyDisallowing: true}, eventEmitter, nil, true))
+}
+
+func TestIsAllowedByPolicyTeamsCache(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+	eventEmitter := events.NewEventEmitter(nil, logger)
+	prun := func(name, teams string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{keys.PolicyTeams: teams}}}
+	}
+	event := &info.Event{Sender: "contributor", TriggerTarget: triggertype.PullRequest, EventType: triggertype.PullRequest.String()}
+	vcx := &testprovider.TestProviderImp{PolicyDisallowing: true}
+
+	for _, pr := range []*tektonv1.PipelineRun{prun("release", "release-managers"), prun("deploy", "release-managers"), prun("publish", "release-managers")} {
+		assert.Assert(t, !IsAllowedByPolicyTeams(ctx, logger, pr, event, vcx, eventEmitter, nil, true))
+	}
+	// the teams and the OWNERS file are only checked once
+	assert.Equal(t, vcx.PolicyChecks, 2)
+
+	assert.Assert(t, !IsAllowedByPolicyTeams(ctx, logger, prun("admin", "admins"), event, vcx, eventEmitter, nil, true))
+	assert.Equal(t, vcx.PolicyChecks, 3)
+}
@@ -0,0 +1,184 @@
+package opscomments
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+var (
+	helpRegex          = regexp.MustCompile(`(?m)^/help\s*$`)
+	commandLineRegex   = regexp.MustCompile(`^/([a-z0-9][a-z0-9_-]*)(?:[ \t]+(.*))?$`)
+	commandTokensRegex = regexp.MustCompile(`([\w.-]+)=(?:"([^"\\]*(?:\\.[^"\\]*)*)"|([^"'\s]+))|"([^"\\]*(?:\\.[^"\\]*)*)"|(\S+)`)
+)
+
+// Command describes a GitOps command provided by Pipelines-as-Code.
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+}
+
+// BuiltinCommands is the list of GitOps commands provided by
+// Pipelines-as-Code, custom commands declared in the Repository CR cannot
+// override them.
+var BuiltinCommands = []Command{
+	{Name: "test", Usage: "/test [pipelinerun]", Description: "Run all the matched PipelineRuns, or only the given one."},
+	{Name: "retest", Usage: "/retest [pipelinerun]", Description: "Rerun the failed PipelineRuns, or the given one."},
+	{Name: "retest-failed", Usage: "/retest-failed <pipelinerun>", Description: "Rerun only the failed tasks of a PipelineRun."},
+	{Name: "cancel", Usage: "/cancel [pipelinerun]", Description: "Cancel the running PipelineRuns, or only the given one."},
+	{Name: "ok-to-test", Usage: "/ok-to-test", Description: "Allow CI to run on a pull request from an external contributor."},
+	{Name: "revoke-ok-to-test", Usage: "/revoke-ok-to-test", Description: "Revoke the /ok-to-test approval of the pull request."},
+	{Name: "hold", Usage: "/hold", Description: "Stop starting new PipelineRuns for the pull request."},
+	{Name: "unhold", Usage: "/unhold", Description: "Release a hold and run the PipelineRuns for the latest commit."},
+	{Name: "help", Usage: "/help", Description: "Show the available GitOps commands."},
+}
+
+// IsBuiltinCommand returns true if name is the name of a command provided by
+// Pipelines-as-Code.
+func IsBuiltinCommand(name string) bool {
+	return slices.ContainsFunc(BuiltinCommands, func(c Command) bool { return c.Name == name })
+}
+
+func IsHelpComment(comment string) bool {
+	return helpRegex.MatchString(comment)
+}
+
+// CommandArgsError is returned when the arguments passed to a custom command
+// don't match its arguments schema.
+type CommandArgsError struct {
+	Command string
+	Reason  string
+}
+
+func (e *CommandArgsError) Error() string {
+	return fmt.Sprintf("invalid arguments for /%s: %s", e.Command, e.Reason)
+}
+
+// MatchCustomCommand looks for the first line of the comment using one of the
+// custom commands and returns it with its validated arguments. It returns a
+// nil command when the comment is not using any of the custom commands.
+func MatchCustomCommand(comment string, commands []v1alpha1.Command) (*v1alpha1.Command, map[string]string, error) {
+	for _, line := range strings.Split(comment, "\n") {
+		matches := commandLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil || IsBuiltinCommand(matches[1]) {
+			continue
+		}
+		for i := range commands {
+			if commands[i].Name != matches[1] {
+				continue
+			}
+			args, err := parseCommandArgs(&commands[i], matches[2])
+			return &commands[i], args, err
+		}
+	}
+	return nil, nil, nil
+}
+
+// parseCommandArgs assigns the positional and name=value arguments to the
+// arguments declared by the command and validates them.
+func parseCommandArgs(command *v1alpha1.Command, input string) (map[string]string, error) {
+	declared := map[string]*v1alpha1.CommandArg{}
+	for i := range command.Args {
+		declared[command.Args[i].Name] = &command.Args[i]
+	}
+
+	args := map[string]string{}
+	position := 0
+	for _, token := range commandTokensRegex.FindAllStringSubmatch(input, -1) {
+		if token[1] != "" {
+			value := token[3]
+			if token[2] != "" {
+				value = strings.ReplaceAll(token[2], `\"`, `"`)
+			}
+			if _, ok := declared[token[1]]; !ok {
+				return nil, &CommandArgsError{Command: command.Name, Reason: fmt.Sprintf("unknown argument %q", token[1])}
+			}
+			args[token[1]] = value
+			continue
+		}
+
+		value := token[5]
+		if token[4] != "" {
+			value = strings.ReplaceAll(token[4], `\"`, `"`)
+		}
+		// skip the arguments already given by name
+		for position < len(command.Args) {
+			if _, ok := args[command.Args[position].Name]; !ok {
+				break
+			}
+			position++
+		}
+		if position >= len(command.Args) {
+			return nil, &CommandArgsError{Command: command.Name, Reason: fmt.Sprintf("unexpected argument %q", value)}
+		}
+		args[command.Args[position].Name] = value
+		position++
+	}
+
+	for _, arg := range command.Args {
+		value, ok := args[arg.Name]
+		if !ok {
+			if arg.Required {
+				return nil, &CommandArgsError{Command: command.Name, Reason: fmt.Sprintf("missing required argument %q", arg.Name)}
+			}
+			if arg.Default != "" {
+				args[arg.Name] = arg.Default
+			}
+			continue
+		}
+		if len(arg.Enum) > 0 && !slices.Contains(arg.Enum, value) {
+			return nil, &CommandArgsError{
+				Command: command.Name,
+				Reason:  fmt.Sprintf("argument %q must be one of %s, got %q", arg.Name, strings.Join(arg.Enum, ", "), value),
+			}
+		}
+	}
+	return args, nil
+}
+
+// CommandUsage returns the usage line of a custom command.
+func CommandUsage(command *v1alpha1.Command) string {
+	usage := "/" + command.Name
+	for _, arg := range command.Args {
+		name := arg.Name
+		if len(arg.Enum) > 0 {
+			name = strings.Join(arg.Enum, "|")
+		}
+		if arg.Required {
+			usage += fmt.Sprintf(" <%s>", name)
+		} else {
+			usage += fmt.Sprintf(" [%s]", name)
+		}
+	}
+	return usage
+}
+
+// HelpMessage returns a markdown description of the builtin commands and the
+// custom commands declared in the Repository CR.
+func HelpMessage(commands []v1alpha1.Command) string {
+	var b strings.Builder
+	b.WriteString("### GitOps commands\n\n| Command | Description |\n|---------|-------------|\n")
+	for _, command := range BuiltinCommands {
+		fmt.Fprintf(&b, "| `%s` | %s |\n", command.Usage, command.Description)
+	}
+	for i := range commands {
+		if IsBuiltinCommand(commands[i].Name) {
+			continue
+		}
+		description := commands[i].Description
+		if description == "" {
+			description = fmt.Sprintf("Run the %s PipelineRun.", commands[i].PipelineRun)
+		}
+		for _, arg := range commands[i].Args {
+			if arg.Description != "" {
+				description += fmt.Sprintf("<br>`%s`: %s", arg.Name, arg.Description)
+			}
+		}
+		fmt.Fprintf(&b, "| `%s` | %s |\n", CommandUsage(&commands[i]), description)
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package opscomments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestMatchCustomCommand(t *testing.T) {
+	commands := []v1alpha1.Command{
+		{
+			Name:        "deploy",
+			PipelineRun: "deploy-pr",
+			Args: []v1alpha1.CommandArg{
+				{Name: "environment", Required: true, Enum: []string{"staging", "production"}},
+				{Name: "revision", Default: "main"},
+				{Name: "dry-run"},
+			},
+		},
+		{
+			Name:        "lint",
+			PipelineRun: "lint-pr",
+		},
+		{
+			Name:        "test",
+			PipelineRun: "shadowed",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		comment     string
+		wantCommand string
+		wantArgs    map[string]string
+		wantErr     string
+	}{
+		{
+			name:    "not a command",
+			comment: "looks good to me",
+		},
+		{
+			name:    "unknown command",
+			comment: "/unknown",
+		},
+		{
+			name:    "builtin command cannot be overridden",
+			comment: "/test",
+		},
+		{
+			name:        "command without args",
+			comment:     "/lint",
+			wantCommand: "lint",
+			wantArgs:    map[string]string{},
+		},
+		{
+			name:        "positional args and default",
+			comment:     "/deploy staging",
+			wantCommand: "deploy",
+			wantArgs:    map[string]string{"environment": "staging", "revision": "main"},
+		},
+		{
+			name:        "named and positional args",
+			comment:     "please\n/deploy revision=\"v1 rc\" production\nthanks",
+			wantCommand: "deploy",
+			wantArgs:    map[string]string{"environment": "production", "revision": "v1 rc"},
+		},
+		{
+			name:        "named argument with a dash",
+			comment:     "/deploy staging dry-run=true",
+			wantCommand: "deploy",
+			wantArgs:    map[string]string{"environment": "staging", "revision": "main", "dry-run": "true"},
+		},
+		{
+			name:        "missing required argument",
+			comment:     "/deploy",
+			wantCommand: "deploy",
+			wantErr:     `invalid arguments for /deploy: missing required argument "environment"`,
+		},
+		{
+			name:        "value not in enum",
+			comment:     "/deploy dev",
+			wantCommand: "deploy",
+			wantErr:     `invalid arguments for /deploy: argument "environment" must be one of staging, production, got "dev"`,
+		},
+		{
+			name:        "too many arguments",
+			comment:     "/deploy staging v1 true extra",
+			wantCommand: "deploy",
+			wantErr:     `invalid arguments for /deploy: unexpected argument "extra"`,
+		},
+		{
+			name:        "unknown named argument",
+			comment:     "/lint fix=true",
+			wantCommand: "lint",
+			wantErr:     `invalid arguments for /lint: unknown argument "fix"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args, err := MatchCustomCommand(tt.comment, commands)
+			if tt.wantCommand == "" {
+				assert.Assert(t, command == nil)
+				return
+			}
+			assert.Equal(t, command.Name, tt.wantCommand)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, args, tt.wantArgs)
+		})
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	command := &v1alpha1.Command{
+		Name: "deploy",
+		Args: []v1alpha1.CommandArg{
+			{Name: "environment", Required: true, Enum: []string{"staging", "production"}},
+			{Name: "revision"},
+		},
+	}
+	assert.Equal(t, CommandUsage(command), "/deploy <staging|production> [revision]")
+}
+
+func TestHelpMessage(t *testing.T) {
+	help := HelpMessage([]v1alpha1.Command{
+		{
+			Name:        "deploy",
+			Description: "Deploy the pull request.",
+			PipelineRun: "deploy-pr",
+			Args:        []v1alpha1.CommandArg{{Name: "environment", Description: "where to deploy", Required: true}},
+		},
+		{Name: "lint", PipelineRun: "lint-pr"},
+	})
+	assert.Assert(t, strings.Contains(help, "| `/retest-failed <pipelinerun>` |"))
+	assert.Assert(t, strings.Contains(help, "| `/deploy <environment>` | Deploy the pull request.<br>`environment`: where to deploy |"))
+	assert.Assert(t, strings.Contains(help, "| `/lint` | Run the lint-pr PipelineRun. |"))
+	assert.Assert(t, !IsHelpComment(help), "help output must not trigger itself")
+}
@@ -15,13 +15,17 @@ import (
 )
 
 var (
-	testAllRegex      = regexp.MustCompile(`(?m)^/test\s*$`)
-	retestAllRegex    = regexp.MustCompile(`(?m)^/retest\s*$`)
-	testSingleRegex   = regexp.MustCompile(`(?m)^/test[ \t]+\S+`)
-	retestSingleRegex = regexp.MustCompile(`(?m)^/retest[ \t]+\S+`)
-	oktotestRegex     = regexp.MustCompile(acl.OKToTestCommentRegexp)
-	cancelAllRegex    = regexp.MustCompile(`(?m)^(/cancel)\s*$`)
-	cancelSingleRegex = regexp.MustCompile(`(?m)^(/cancel)[ \t]+\S+`)
+	testAllRegex        = regexp.MustCompile(`(?m)^/test\s*$`)
+	retestAllRegex      = regexp.MustCompile(`(?m)^/retest\s*$`)
+	testSingleRegex     = regexp.MustCompile(`(?m)^/test[ \t]+\S+`)
+	retestSingleRegex   = regexp.MustCompile(`(?m)^/retest[ \t]+\S+`)
+	retestFailedRegex   = regexp.MustCompile(`(?m)^/retest-failed[ \t]+\S+`)
+	oktotestRegex       = regexp.MustCompile(acl.OKToTestCommentRegexp)
+	cancelAllRegex      = regexp.MustCompile(`(?m)^(/cancel)\s*$`)
+	cancelSingleRegex   = regexp.MustCompile(`(?m)^(/cancel)[ \t]+\S+`)
+	holdRegex           = regexp.MustCompile(`(?m)^/hold\s*$`)
+	unholdRegex         = regexp.MustCompile(`(?m)^/unhold\s*$`)
+	revokeOkToTestRegex = regexp.MustCompile(`(?m)^/revoke-ok-to-test\s*$`)
 )
 
 type EventType string
@@ -31,25 +35,33 @@ func (e EventType) String() string {
 }
 
 var (
-	NoOpsCommentEventType        = EventType("no-ops-comment")
-	TestAllCommentEventType      = EventType("test-all-comment")
-	TestSingleCommentEventType   = EventType("test-comment")
-	RetestSingleCommentEventType = EventType("retest-comment")
-	RetestAllCommentEventType    = EventType("retest-all-comment")
-	OnCommentEventType           = EventType("on-comment")
-	CancelCommentSingleEventType = EventType("cancel-comment")
-	CancelCommentAllEventType    = EventType("cancel-all-comment")
-	OkToTestCommentEventType     = EventType("ok-to-test-comment")
+	NoOpsCommentEventType          = EventType("no-ops-comment")
+	TestAllCommentEventType        = EventType("test-all-comment")
+	TestSingleCommentEventType     = EventType("test-comment")
+	RetestSingleCommentEventType   = EventType("retest-comment")
+	RetestAllCommentEventType      = EventType("retest-all-comment")
+	RetestFailedCommentEventType   = EventType("retest-failed-comment")
+	OnCommentEventType             = EventType("on-comment")
+	CancelCommentSingleEventType   = EventType("cancel-comment")
+	CancelCommentAllEventType      = EventType("cancel-all-comment")
+	OkToTestCommentEventType       = EventType("ok-to-test-comment")
+	CustomCommandEventType         = EventType("custom-command-comment")
+	HoldCommentEventType           = EventType("hold-comment")
+	UnholdCommentEventType         = EventType("unhold-comment")
+	RevokeOkToTestCommentEventType = EventType("revoke-ok-to-test-comment")
 )
 
 const (
-	testComment   = "/test"
-	retestComment = "/retest"
-	cancelComment = "/cancel"
+	testComment         = "/test"
+	retestComment       = "/retest"
+	retestFailedComment = "/retest-failed"
+	cancelComment       = "/cancel"
 )
 
 func CommentEventType(comment string) EventType {
 	switch {
+	case retestFailedRegex.MatchString(comment):
+		return RetestFailedCommentEventType
 	case retestAllRegex.MatchString(comment):
 		return RetestAllCommentEventType
 	case retestSingleRegex.MatchString(comment):
@@ -64,6 +76,12 @@ func CommentEventType(comment string) EventType {
 		return CancelCommentAllEventType
 	case cancelSingleRegex.MatchString(comment):
 		return CancelCommentSingleEventType
+	case holdRegex.MatchString(comment):
+		return HoldCommentEventType
+	case unholdRegex.MatchString(comment):
+		return UnholdCommentEventType
+	case revokeOkToTestRegex.MatchString(comment):
+		return RevokeOkToTestCommentEventType
 	default:
 		return NoOpsCommentEventType
 	}
@@ -75,6 +93,9 @@ func SetEventTypeAndTargetPR(event *info.Event, comment string) {
 	if commentType == RetestSingleCommentEventType || commentType == TestSingleCommentEventType {
 		event.TargetTestPipelineRun = GetPipelineRunFromTestComment(comment)
 	}
+	if commentType == RetestFailedCommentEventType {
+		event.TargetTestPipelineRun = GetPipelineRunFromRetestFailedComment(comment)
+	}
 	if commentType == CancelCommentAllEventType || commentType == CancelCommentSingleEventType {
 		event.CancelPipelineRuns = true
 	}
@@ -123,23 +144,31 @@ func IsAnyOpsEventType(eventType string) bool {
 		eventType == TestAllCommentEventType.String() ||
 		eventType == RetestAllCommentEventType.String() ||
 		eventType == RetestSingleCommentEventType.String() ||
+		eventType == RetestFailedCommentEventType.String() ||
 		eventType == CancelCommentSingleEventType.String() ||
 		eventType == CancelCommentAllEventType.String() ||
 		eventType == OkToTestCommentEventType.String() ||
+		eventType == CustomCommandEventType.String() ||
+		eventType == HoldCommentEventType.String() ||
+		eventType == UnholdCommentEventType.String() ||
+		eventType == RevokeOkToTestCommentEventType.String() ||
 		eventType == OnCommentEventType.String()
 }
 
 // AnyOpsKubeLabelInSelector will output a Kubernetes label out of all possible
 // CommentEvent Type for selection.
 func AnyOpsKubeLabelInSelector() string {
-	return fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s",
+	return fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s",
 		TestSingleCommentEventType.String(),
 		TestAllCommentEventType.String(),
 		RetestAllCommentEventType.String(),
 		RetestSingleCommentEventType.String(),
+		RetestFailedCommentEventType.String(),
 		CancelCommentSingleEventType.String(),
 		CancelCommentAllEventType.String(),
 		OkToTestCommentEventType.String(),
+		CustomCommandEventType.String(),
+		UnholdCommentEventType.String(),
 		OnCommentEventType.String())
 }
 
@@ -150,6 +179,12 @@ func GetPipelineRunFromTestComment(comment string) string {
 	return getNameFromComment(retestComment, comment)
 }
 
+// GetPipelineRunFromRetestFailedComment returns the PipelineRun targeted by a
+// /retest-failed comment.
+func GetPipelineRunFromRetestFailedComment(comment string) string {
+	return getNameFromComment(retestFailedComment, comment)
+}
+
 func GetPipelineRunFromCancelComment(comment string) string {
 	return getNameFromComment(cancelComment, comment)
 }
@@ -147,6 +147,21 @@ func TestIsAnyOpsEventType(t *testing.T) {
 			eventType: OnCommentEventType.String(),
 			want:      true,
 		},
+		{
+			name:      "HoldCommentEventType",
+			eventType: HoldCommentEventType.String(),
+			want:      true,
+		},
+		{
+			name:      "UnholdCommentEventType",
+			eventType: UnholdCommentEventType.String(),
+			want:      true,
+		},
+		{
+			name:      "RevokeOkToTestCommentEventType",
+			eventType: RevokeOkToTestCommentEventType.String(),
+			want:      true,
+		},
 		{
 			name:      "NoOpsCommentEventType",
 			eventType: NoOpsCommentEventType.String(),
@@ -183,6 +198,36 @@ func TestCommentEventTypeTest(t *testing.T) {
 			comment: "/retest prname",
 			want:    RetestSingleCommentEventType,
 		},
+		{
+			name:    "retest failed",
+			comment: "/retest-failed prname",
+			want:    RetestFailedCommentEventType,
+		},
+		{
+			name:    "retest failed without pipelinerun",
+			comment: "/retest-failed",
+			want:    NoOpsCommentEventType,
+		},
+		{
+			name:    "hold",
+			comment: "/hold",
+			want:    HoldCommentEventType,
+		},
+		{
+			name:    "unhold",
+			comment: "/unhold",
+			want:    UnholdCommentEventType,
+		},
+		{
+			name:    "revoke ok-to-test",
+			comment: "/revoke-ok-to-test",
+			want:    RevokeOkToTestCommentEventType,
+		},
+		{
+			name:    "hold with a reason is not hold",
+			comment: "/hold on",
+			want:    NoOpsCommentEventType,
+		},
 		{
 			name:    "test all",
 			comment: "/test",
@@ -249,6 +294,12 @@ func TestSetEventTypeTestPipelineRun(t *testing.T) {
 			wantType:   TestSingleCommentEventType.String(),
 			wantTestPr: "prname",
 		},
+		{
+			name:       "retest failed event type",
+			comment:    "/lgtm\n/retest-failed prname\nthanks",
+			wantType:   RetestFailedCommentEventType.String(),
+			wantTestPr: "prname",
+		},
 		{
 			name:         "cancel single pr",
 			comment:      "/cancel prname",
@@ -86,6 +86,28 @@ type State struct {
 	TargetTestPipelineRun   string
 	CancelPipelineRuns      bool
 	TargetCancelPipelineRun string
+	// CommandArgs are the validated arguments of a custom GitOps command
+	CommandArgs map[string]string
+	// IncomingParams are the params mapped from the payload of an incoming webhook
+	IncomingParams map[string]string
+	// IncomingID identifies the PipelineRuns created by an incoming webhook request
+	IncomingID string
+	// IncomingCommand is the GitOps command sent to an incoming webhook of type pull-request
+	IncomingCommand string
+	// PolicyTeamsChecks caches the membership checks of the sender against the
+	// teams of the policy-teams annotations, keyed by the teams.
+	PolicyTeamsChecks map[string]PolicyCheck
+	// OwnersFilesChecks caches the checks against the OWNERS files of the
+	// changed files, keyed by the user checked.
+	OwnersFilesChecks map[string]PolicyCheck
+}
+
+// PolicyCheck is the result of checking the sender of the event against a
+// policy, the provider is asked once per event.
+type PolicyCheck struct {
+	Allowed bool
+	Reason  string
+	Err     error
 }
 
 type Provider struct {
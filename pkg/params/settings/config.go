@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/configutil"
 	hubType "github.com/openshift-pipelines/pipelines-as-code/pkg/hub/vars"
@@ -74,14 +75,17 @@ type Settings struct {
 
 	SkipPushEventForPRCommits bool `json:"skip-push-event-for-pr-commits" default:"true"` // nolint:tagalign
 
+	QueueTimeout string `json:"queue-timeout"`
+
 	CustomConsoleName         string `json:"custom-console-name"`
 	CustomConsoleURL          string `json:"custom-console-url"`
 	CustomConsolePRdetail     string `json:"custom-console-url-pr-details"`
 	CustomConsolePRTaskLog    string `json:"custom-console-url-pr-tasklog"`
 	CustomConsoleNamespaceURL string `json:"custom-console-url-namespace"`
 
-	RememberOKToTest   bool `json:"remember-ok-to-test"`
-	RequireOkToTestSHA bool `json:"require-ok-to-test-sha"`
+	RememberOKToTest   bool   `json:"remember-ok-to-test"`
+	RequireOkToTestSHA bool   `json:"require-ok-to-test-sha"`
+	OkToTestTTL        string `json:"ok-to-test-ttl"`
 }
 
 func (s *Settings) DeepCopy(out *Settings) {
@@ -110,6 +114,8 @@ func DefaultValidators() map[string]func(string) error {
 		"CustomConsoleURL":           isValidURL,
 		"CustomConsolePRTaskLog":     startWithHTTPorHTTPS,
 		"CustomConsolePRDetail":      startWithHTTPorHTTPS,
+		"QueueTimeout":               isValidDuration,
+		"OkToTestTTL":                isValidDuration,
 	}
 }
 
@@ -153,6 +159,17 @@ func isValidRegex(regex string) error {
 	return nil
 }
 
+func isValidDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration: %w", err)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid duration: %s must not be negative", value)
+	}
+	return nil
+}
+
 func startWithHTTPorHTTPS(url string) error {
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 		return fmt.Errorf("invalid value, must start with http:// or https://")
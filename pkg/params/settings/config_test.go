@@ -79,6 +79,7 @@ func TestSyncConfig(t *testing.T) {
 				"remember-ok-to-test":                     "false",
 				"skip-push-event-for-pr-commits":          "true",
 				"require-ok-to-test-sha":                  "true",
+				"queue-timeout":                           "30m",
 			},
 			expectedStruct: Settings{
 				ApplicationName:                      "pac-pac",
@@ -110,6 +111,7 @@ func TestSyncConfig(t *testing.T) {
 				CustomConsoleNamespaceURL:            "https://custom-console-namespace",
 				RememberOKToTest:                     false,
 				RequireOkToTestSHA:                   true,
+				QueueTimeout:                         "30m",
 			},
 		},
 		{
@@ -147,6 +149,13 @@ func TestSyncConfig(t *testing.T) {
 			},
 			expectedError: "custom validation failed for field CustomConsolePRTaskLog: invalid value, must start with http:// or https://",
 		},
+		{
+			name: "invalid value for queue timeout",
+			configMap: map[string]string{
+				"queue-timeout": "forever",
+			},
+			expectedError: "custom validation failed for field QueueTimeout: invalid duration: time: invalid duration \"forever\"",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -8,7 +8,7 @@ type (
 func IsPullRequestType(s string) Trigger {
 	eventType := s
 	switch s {
-	case PullRequest.String(), OkToTest.String(), Retest.String(), Cancel.String(), PullRequestLabeled.String():
+	case PullRequest.String(), OkToTest.String(), Retest.String(), Cancel.String(), Hold.String(), PullRequestLabeled.String():
 		eventType = PullRequest.String()
 	}
 	return Trigger(eventType)
@@ -38,6 +38,8 @@ func StringToType(s string) Trigger {
 		return Incoming
 	case Comment.String():
 		return Comment
+	case Hold.String():
+		return Hold
 	case PullRequestLabeled.String():
 		return PullRequestLabeled
 	}
@@ -49,6 +51,7 @@ const (
 	CheckRunRerequested   Trigger = "check-run-rerequested"
 	CheckSuiteRerequested Trigger = "check-suite-rerequested"
 	Comment               Trigger = "comment"
+	Hold                  Trigger = "hold"
 	Incoming              Trigger = "incoming"
 	PullRequestLabeled    Trigger = "pull_request_labeled"
 	OkToTest              Trigger = "ok-to-test"
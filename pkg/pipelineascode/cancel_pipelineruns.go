@@ -22,6 +22,10 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 )
 
+// cancelInProgressQueuedOnly is the cancel-in-progress value to only cancel
+// the queued PipelineRuns and let the running one finish.
+const cancelInProgressQueuedOnly = "queued-only"
+
 type matchingCond func(pr tektonv1.PipelineRun) bool
 
 var cancelMergePatch = map[string]any{
@@ -57,14 +61,14 @@ func (p *PacRun) cancelAllInProgressBelongingToClosedPullRequest(ctx context.Con
 		// 'cancel-in-progress' annotation explicitly set to 'false', effectively opting them out of cancellation.
 		labelsMap = map[string]string{keys.CancelInProgress: "false"}
 		operator = selection.NotIn //codespell:ignore 'NotIn'
+		// Append the label selector filter for the 'cancel-in-progress' annotation.
+		labelSelector += fmt.Sprintf(",%s", getLabelSelector(labelsMap, operator))
 	} else {
 		// When the 'cancel-in-progress' setting is disabled globally via the Pipelines-as-Code ConfigMap,
 		// filter and list only those PipelineRuns that explicitly override the global setting by having the
-		// 'cancel-in-progress' annotation set to 'true'.
-		labelsMap = map[string]string{keys.CancelInProgress: "true"}
+		// 'cancel-in-progress' annotation set to 'true' or 'queued-only'.
+		labelSelector += fmt.Sprintf(",%s in (true, %s)", keys.CancelInProgress, cancelInProgressQueuedOnly)
 	}
-	// Append the label selector filter for the 'cancel-in-progress' annotation.
-	labelSelector += fmt.Sprintf(",%s", getLabelSelector(labelsMap, operator))
 	p.debugf("cancelAllInProgress: labelSelector=%s", labelSelector)
 
 	prs, err := p.run.Clients.Tekton.TektonV1().PipelineRuns(repo.Namespace).List(ctx, metav1.ListOptions{
@@ -82,7 +86,11 @@ func (p *PacRun) cancelAllInProgressBelongingToClosedPullRequest(ctx context.Con
 	}
 	p.debugf("cancelAllInProgress: found %d pipelineruns to consider", len(prs.Items))
 
-	p.cancelPipelineRuns(ctx, prs, repo, func(_ tektonv1.PipelineRun) bool {
+	p.cancelPipelineRuns(ctx, prs, repo, func(pr tektonv1.PipelineRun) bool {
+		// let the running PipelineRuns finish when only the queued ones should be cancelled
+		if pr.GetLabels()[keys.CancelInProgress] == cancelInProgressQueuedOnly {
+			return pr.IsPending()
+		}
 		return true
 	})
 
@@ -92,8 +100,10 @@ func (p *PacRun) cancelAllInProgressBelongingToClosedPullRequest(ctx context.Con
 // cancelInProgressMatchingPR cancels all PipelineRuns associated with a given repository and pull request,
 // except for the one that triggered the cancellation. It first checks if the cancellation is in progress
 // and if the repository has a concurrency limit. If a concurrency limit is set, it returns an error as
-// cancellation is not supported with concurrency limits. It then retrieves the original pull request name
-// from the annotations and lists all PipelineRuns with matching labels. For each PipelineRun that is not
+// cancellation is not supported with concurrency limits unless only the queued PipelineRuns are cancelled.
+// It then retrieves the original pull request name from the annotations and lists all PipelineRuns with
+// matching labels, when a cancel-in-progress-key annotation is set the PipelineRuns sharing the same key
+// are selected instead of the ones from the same pull request or branch. For each PipelineRun that is not
 // already done, cancelled, or gracefully stopped, it patches the PipelineRun to cancel it.
 func (p *PacRun) cancelInProgressMatchingPipelineRun(ctx context.Context, matchPR *tektonv1.PipelineRun, repo *v1alpha1.Repository) error {
 	if matchPR == nil {
@@ -115,13 +125,14 @@ func (p *PacRun) cancelInProgressMatchingPipelineRun(ctx context.Context, matchP
 		cancellingVia = "via PipelineRun annotation"
 	}
 
-	if cancelInProgress != "true" {
+	if cancelInProgress != "true" && cancelInProgress != cancelInProgressQueuedOnly {
 		p.debugf("cancelInProgress: disabled for pipelinerun=%s via=%s", matchPR.GetName(), cancellingVia)
 		return nil
 	}
+	queuedOnly := cancelInProgress == cancelInProgressQueuedOnly
 
 	p.run.Clients.Log.Infof("cancel-in-progress for event %s is enabled %s", string(p.event.TriggerTarget), cancellingVia)
-	p.debugf("cancelInProgress: enabled for pipelinerun=%s", matchPR.GetName())
+	p.debugf("cancelInProgress: enabled for pipelinerun=%s queued-only=%t", matchPR.GetName(), queuedOnly)
 
 	// As PipelineRuns are filtered by name, OriginalPRName should be taken from
 	// labels instead of annotations because of constraints imposed by kube API.
@@ -131,7 +142,9 @@ func (p *PacRun) cancelInProgressMatchingPipelineRun(ctx context.Context, matchP
 		return nil
 	}
 
-	if repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit > 0 {
+	// queued PipelineRuns are only there when a concurrency limit is set, so
+	// dropping them while letting the running ones finish is fine.
+	if !queuedOnly && repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit > 0 {
 		return fmt.Errorf("cancel in progress is not supported with concurrency limit")
 	}
 
@@ -139,11 +152,18 @@ func (p *PacRun) cancelInProgressMatchingPipelineRun(ctx context.Context, matchP
 		keys.URLRepository:  formatting.CleanValueKubernetes(p.event.Repository),
 		keys.OriginalPRName: prName,
 	}
-	if p.event.TriggerTarget == triggertype.PullRequest {
+
+	// a custom cancel-in-progress key defines the grouping of the
+	// PipelineRuns, it replaces the default grouping by pull request or
+	// branch.
+	cancelKey, hasCancelKey := matchPR.GetLabels()[keys.CancelInProgressKey]
+	if hasCancelKey {
+		labelMap[keys.CancelInProgressKey] = cancelKey
+	} else if p.event.TriggerTarget == triggertype.PullRequest {
 		labelMap[keys.PullRequest] = strconv.Itoa(p.event.PullRequestNumber)
 	}
 	labelSelector := getLabelSelector(labelMap, selection.Equals)
-	if p.event.TriggerTarget == triggertype.PullRequest {
+	if !hasCancelKey && p.event.TriggerTarget == triggertype.PullRequest {
 		// "Merge_Request" included since EventType is not normalized to "Pull Request" like TriggerTarget
 		labelSelector += fmt.Sprintf(",%s in (pull_request, Merge_Request, %s)", keys.EventType, opscomments.AnyOpsKubeLabelInSelector())
 	}
@@ -157,8 +177,13 @@ func (p *PacRun) cancelInProgressMatchingPipelineRun(ctx context.Context, matchP
 	}
 
 	p.cancelPipelineRuns(ctx, prs, repo, func(pr tektonv1.PipelineRun) bool {
+		if queuedOnly && !pr.IsPending() {
+			p.logger.Infof("cancel-in-progress: skipping pipelinerun %v/%v as it is not queued", pr.GetNamespace(), pr.GetName())
+			return false
+		}
+
 		// skip our own for cancellation
-		if sourceBranch, ok := pr.GetAnnotations()[keys.SourceBranch]; ok {
+		if sourceBranch, ok := pr.GetAnnotations()[keys.SourceBranch]; ok && !hasCancelKey {
 			// NOTE(chmouel): Every PR has their own branch and so is every push to different branch
 			// it means we only cancel pipelinerun of the same name that runs to
 			// the unique branch. Note: HeadBranch is the branch from where the PR
@@ -8,6 +8,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
@@ -1314,6 +1315,156 @@ func TestCancelInProgressMatchingPipelineRun(t *testing.T) {
 			},
 			wantLog: "cancel-in-progress for event push is enabled via PipelineRun annotation",
 		},
+		{
+			name: "match/cancel in progress grouped by custom key across pull requests",
+			event: &info.Event{
+				Repository:        "foo",
+				SHA:               "foosha",
+				HeadBranch:        "head",
+				EventType:         string(triggertype.PullRequest),
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: pullReqNumber,
+			},
+			pipelineRuns: []*pipelinev1.PipelineRun{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pr-foo",
+						Namespace: "foo",
+						Labels: map[string]string{
+							keys.OriginalPRName:      "pr-foo",
+							keys.URLRepository:       formatting.CleanValueKubernetes("foo"),
+							keys.PullRequest:         strconv.Itoa(pullReqNumber),
+							keys.EventType:           string(triggertype.PullRequest),
+							keys.CancelInProgressKey: kubeinteraction.CancelInProgressKeyLabel("main"),
+						},
+						Annotations: map[string]string{
+							keys.CancelInProgress:    "true",
+							keys.CancelInProgressKey: "main",
+							keys.OriginalPRName:      "pr-foo",
+							keys.Repository:          "foo",
+							keys.SourceBranch:        "head",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pr-foo-other-pr",
+						Namespace: "foo",
+						Labels: map[string]string{
+							keys.OriginalPRName:      "pr-foo",
+							keys.URLRepository:       formatting.CleanValueKubernetes("foo"),
+							keys.PullRequest:         "12",
+							keys.EventType:           string(triggertype.PullRequest),
+							keys.CancelInProgressKey: kubeinteraction.CancelInProgressKeyLabel("main"),
+						},
+						Annotations: map[string]string{
+							keys.CancelInProgress:    "true",
+							keys.CancelInProgressKey: "main",
+							keys.OriginalPRName:      "pr-foo",
+							keys.Repository:          "foo",
+							keys.SourceBranch:        "another-branch",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pr-foo-other-key",
+						Namespace: "foo",
+						Labels: map[string]string{
+							keys.OriginalPRName:      "pr-foo",
+							keys.URLRepository:       formatting.CleanValueKubernetes("foo"),
+							keys.PullRequest:         strconv.Itoa(pullReqNumber),
+							keys.EventType:           string(triggertype.PullRequest),
+							keys.CancelInProgressKey: kubeinteraction.CancelInProgressKeyLabel("release"),
+						},
+						Annotations: map[string]string{
+							keys.CancelInProgress:    "true",
+							keys.CancelInProgressKey: "release",
+							keys.OriginalPRName:      "pr-foo",
+							keys.Repository:          "foo",
+							keys.SourceBranch:        "head",
+						},
+					},
+				},
+			},
+			repo: fooRepo,
+			cancelledPipelineRuns: map[string]bool{
+				"pr-foo-other-pr": true,
+			},
+			wantLog: "cancel-in-progress: cancelling pipelinerun foo/pr-foo-other-pr",
+		},
+		{
+			name: "match/cancel in progress queued-only with concurrency limit",
+			event: &info.Event{
+				Repository:        "foo",
+				SHA:               "foosha",
+				HeadBranch:        "head",
+				EventType:         string(triggertype.PullRequest),
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: pullReqNumber,
+			},
+			pipelineRuns: []*pipelinev1.PipelineRun{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pr-foo",
+						Namespace: "foo",
+						Labels:    fooRepoLabels,
+						Annotations: map[string]string{
+							keys.CancelInProgress: "queued-only",
+							keys.OriginalPRName:   "pr-foo",
+							keys.Repository:       "foo",
+							keys.SourceBranch:     "head",
+						},
+					},
+					Spec: pipelinev1.PipelineRunSpec{
+						Status: pipelinev1.PipelineRunSpecStatusPending,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pr-foo-running",
+						Namespace: "foo",
+						Labels:    fooRepoLabels,
+						Annotations: map[string]string{
+							keys.CancelInProgress: "queued-only",
+							keys.OriginalPRName:   "pr-foo",
+							keys.Repository:       "foo",
+							keys.SourceBranch:     "head",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pr-foo-queued",
+						Namespace: "foo",
+						Labels:    fooRepoLabels,
+						Annotations: map[string]string{
+							keys.CancelInProgress: "queued-only",
+							keys.OriginalPRName:   "pr-foo",
+							keys.Repository:       "foo",
+							keys.SourceBranch:     "head",
+						},
+					},
+					Spec: pipelinev1.PipelineRunSpec{
+						Status: pipelinev1.PipelineRunSpecStatusPending,
+					},
+				},
+			},
+			repo: &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "foo",
+				},
+				Spec: v1alpha1.RepositorySpec{
+					URL:              "https://github.com/fooorg/foo",
+					ConcurrencyLimit: github.Ptr(1),
+				},
+			},
+			cancelledPipelineRuns: map[string]bool{
+				"pr-foo-queued": true,
+			},
+			wantLog: "cancel-in-progress: skipping pipelinerun foo/pr-foo-running as it is not queued",
+		},
 		{
 			name: "skip/cancel in progress with concurrency limit",
 			event: &info.Event{
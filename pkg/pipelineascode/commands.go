@@ -0,0 +1,96 @@
+package pipelineascode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	providerstatus "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/status"
+	"go.uber.org/zap"
+)
+
+// handleCommandComment handles the /help command and the custom GitOps
+// commands declared in the Repository CR. A matched custom command targets
+// its PipelineRun like a /test command would. It returns true when the event
+// has been fully handled and no PipelineRun should be run.
+func (p *PacRun) handleCommandComment(ctx context.Context, repo *v1alpha1.Repository) (bool, error) {
+	if p.event.EventType != opscomments.NoOpsCommentEventType.String() || p.event.TriggerTarget == triggertype.Push {
+		return false, nil
+	}
+	var commands []v1alpha1.Command
+	if repo.Spec.Settings != nil {
+		commands = repo.Spec.Settings.Commands
+	}
+
+	if opscomments.IsHelpComment(p.event.TriggerComment) {
+		if err := p.vcx.CreateComment(ctx, p.event, opscomments.HelpMessage(commands), ""); err != nil {
+			return true, fmt.Errorf("error adding help comment: %w", err)
+		}
+		return true, nil
+	}
+
+	command, args, argsErr := opscomments.MatchCustomCommand(p.event.TriggerComment, commands)
+	if command == nil {
+		return false, nil
+	}
+	p.debugf("handleCommandComment: matched custom command /%s for pipelinerun=%s", command.Name, command.PipelineRun)
+
+	if allowed, err := p.isAllowedCommand(ctx, repo, command); !allowed {
+		return true, err
+	}
+
+	var cmdArgsErr *opscomments.CommandArgsError
+	if errors.As(argsErr, &cmdArgsErr) {
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "CustomCommandInvalidArgs", argsErr.Error())
+		msg := fmt.Sprintf("%s\n\nUsage: `%s`", argsErr.Error(), opscomments.CommandUsage(command))
+		if err := p.vcx.CreateComment(ctx, p.event, msg, ""); err != nil {
+			return true, fmt.Errorf("error adding invalid arguments comment: %w", err)
+		}
+		return true, nil
+	}
+
+	p.event.EventType = opscomments.CustomCommandEventType.String()
+	p.event.TargetTestPipelineRun = command.PipelineRun
+	p.event.CommandArgs = args
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "CustomCommandMatched",
+		fmt.Sprintf("custom GitOps command /%s is running PipelineRun %s", command.Name, command.PipelineRun))
+	return false, nil
+}
+
+// isAllowedCommand checks if the sender is allowed to run a custom command,
+// falling back to the regular ACL when the command doesn't restrict its users.
+func (p *PacRun) isAllowedCommand(ctx context.Context, repo *v1alpha1.Repository, command *v1alpha1.Command) (bool, error) {
+	if len(command.Users) == 0 && len(command.PolicyGroups) == 0 {
+		status := providerstatus.StatusOpts{
+			Status:       queuedStatus,
+			Title:        "Pending approval, waiting for an /ok-to-test",
+			Conclusion:   providerstatus.ConclusionPending,
+			DetailsURL:   p.event.URL,
+			AccessDenied: true,
+		}
+		return p.checkAccessOrError(ctx, repo, status, fmt.Sprintf("by GitOps command /%s", command.Name))
+	}
+
+	if slices.Contains(command.Users, p.event.Sender) {
+		return true, nil
+	}
+	msg := fmt.Sprintf("User %s is not allowed to run the GitOps command /%s.", p.event.Sender, command.Name)
+	if len(command.PolicyGroups) > 0 {
+		allowed, reason := p.vcx.CheckPolicyAllowing(ctx, p.event, command.PolicyGroups)
+		if allowed {
+			return true, nil
+		}
+		if reason != "" {
+			msg = fmt.Sprintf("User %s is not allowed to run the GitOps command /%s: %s", p.event.Sender, command.Name, reason)
+		}
+	}
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "CustomCommandDisallowed", msg)
+	if err := p.vcx.CreateComment(ctx, p.event, msg, ""); err != nil {
+		return false, fmt.Errorf("failed to create comment, user is not allowed to run the GitOps command: %w", err)
+	}
+	return false, nil
+}
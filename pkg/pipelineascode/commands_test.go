@@ -0,0 +1,192 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestHandleCommandComment(t *testing.T) {
+	commands := []v1alpha1.Command{
+		{
+			Name:        "deploy",
+			PipelineRun: "deploy-pr",
+			Users:       []string{"admin"},
+			Args:        []v1alpha1.CommandArg{{Name: "environment", Required: true}},
+		},
+		{
+			Name:         "release",
+			PipelineRun:  "release-pr",
+			PolicyGroups: []string{"release-team"},
+		},
+		{
+			Name:        "lint",
+			PipelineRun: "lint-pr",
+		},
+	}
+
+	tests := []struct {
+		name              string
+		comment           string
+		eventType         string
+		triggerTarget     triggertype.Trigger
+		sender            string
+		allowIT           bool
+		policyDisallowing bool
+		wantHandled       bool
+		wantEventType     string
+		wantTarget        string
+		wantArgs          map[string]string
+		wantLog           string
+		wantComment       string
+	}{
+		{
+			name:          "not a no-ops comment",
+			comment:       "/test",
+			eventType:     opscomments.TestAllCommentEventType.String(),
+			wantEventType: opscomments.TestAllCommentEventType.String(),
+		},
+		{
+			name:          "not a command",
+			comment:       "/unknown",
+			wantEventType: opscomments.NoOpsCommentEventType.String(),
+		},
+		{
+			name:          "help",
+			comment:       "/help",
+			wantHandled:   true,
+			wantEventType: opscomments.NoOpsCommentEventType.String(),
+		},
+		{
+			name:          "command on push is ignored",
+			comment:       "/deploy staging",
+			sender:        "admin",
+			triggerTarget: triggertype.Push,
+			wantEventType: opscomments.NoOpsCommentEventType.String(),
+		},
+		{
+			name:          "allowed user",
+			comment:       "/deploy staging",
+			sender:        "admin",
+			wantEventType: opscomments.CustomCommandEventType.String(),
+			wantTarget:    "deploy-pr",
+			wantArgs:      map[string]string{"environment": "staging"},
+		},
+		{
+			name:          "disallowed user",
+			comment:       "/deploy staging",
+			sender:        "someone",
+			allowIT:       true,
+			wantHandled:   true,
+			wantEventType: opscomments.NoOpsCommentEventType.String(),
+			wantLog:       "User someone is not allowed to run the GitOps command /deploy.",
+			wantComment:   "User someone is not allowed to run the GitOps command /deploy.",
+		},
+		{
+			name:          "invalid arguments",
+			comment:       "/deploy",
+			sender:        "admin",
+			wantHandled:   true,
+			wantEventType: opscomments.NoOpsCommentEventType.String(),
+			wantLog:       `invalid arguments for /deploy: missing required argument "environment"`,
+		},
+		{
+			name:          "allowed by policy group",
+			comment:       "/release",
+			sender:        "someone",
+			wantEventType: opscomments.CustomCommandEventType.String(),
+			wantTarget:    "release-pr",
+			wantArgs:      map[string]string{},
+		},
+		{
+			name:              "disallowed by policy group",
+			comment:           "/release",
+			sender:            "someone",
+			policyDisallowing: true,
+			wantHandled:       true,
+			wantEventType:     opscomments.NoOpsCommentEventType.String(),
+			wantLog:           "User someone is not allowed to run the GitOps command /release: policy disallowing",
+			wantComment:       "User someone is not allowed to run the GitOps command /release: policy disallowing",
+		},
+		{
+			name:          "regular acl allowed",
+			comment:       "/lint",
+			sender:        "someone",
+			allowIT:       true,
+			wantEventType: opscomments.CustomCommandEventType.String(),
+			wantTarget:    "lint-pr",
+			wantArgs:      map[string]string{},
+		},
+		{
+			name:          "regular acl disallowed",
+			comment:       "/lint",
+			sender:        "someone",
+			wantHandled:   true,
+			wantEventType: opscomments.NoOpsCommentEventType.String(),
+			wantLog:       "User someone is not allowed to trigger CI by GitOps command /lint in this repo.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, logs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:  logger,
+					Kube: stdata.Kube,
+				},
+			}
+			eventType := tt.eventType
+			if eventType == "" {
+				eventType = opscomments.NoOpsCommentEventType.String()
+			}
+			triggerTarget := tt.triggerTarget
+			if triggerTarget == "" {
+				triggerTarget = triggertype.PullRequest
+			}
+			event := &info.Event{
+				EventType:      eventType,
+				TriggerTarget:  triggerTarget,
+				TriggerComment: tt.comment,
+				Sender:         tt.sender,
+			}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{Commands: commands},
+				},
+			}
+			vcx := &testprovider.TestProviderImp{AllowIT: tt.allowIT, PolicyDisallowing: tt.policyDisallowing}
+
+			pac := NewPacs(event, vcx, run, &info.PacOpts{}, nil, logger, nil)
+			handled, err := pac.handleCommandComment(ctx, repo)
+			assert.NilError(t, err)
+			assert.Equal(t, handled, tt.wantHandled)
+			assert.Equal(t, event.EventType, tt.wantEventType)
+			assert.Equal(t, event.TargetTestPipelineRun, tt.wantTarget)
+			if tt.wantArgs != nil {
+				assert.DeepEqual(t, event.CommandArgs, tt.wantArgs)
+			}
+			if tt.wantLog != "" {
+				assert.Assert(t, logs.FilterMessageSnippet(tt.wantLog).Len() > 0, "log %q not found in %v", tt.wantLog, logs.All())
+			}
+			if tt.wantComment != "" {
+				assert.DeepEqual(t, vcx.CreatedComments, []string{tt.wantComment})
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const heldStatusTitle = "PipelineRuns on hold"
+
+// holdAnnotation returns the Repository annotation recording that the pull
+// request is on hold, its value is the user who has put it on hold.
+func holdAnnotation(pullRequestNumber int) string {
+	return fmt.Sprintf("%s-%d", keys.Hold, pullRequestNumber)
+}
+
+// heldBy returns who has put the pull request of the event on hold, or an
+// empty string if it isn't on hold.
+func (p *PacRun) heldBy(repo *v1alpha1.Repository) string {
+	if repo == nil || p.event.PullRequestNumber == 0 {
+		return ""
+	}
+	return repo.GetAnnotations()[holdAnnotation(p.event.PullRequestNumber)]
+}
+
+// handleHoldComment handles the /hold and /unhold GitOps commands. It returns
+// true when the event has been fully handled and no PipelineRun should be
+// matched, /unhold goes on with the matching to run the latest commit.
+func (p *PacRun) handleHoldComment(ctx context.Context, repo *v1alpha1.Repository) (bool, error) {
+	if p.event.EventType != opscomments.HoldCommentEventType.String() && p.event.EventType != opscomments.UnholdCommentEventType.String() {
+		return false, nil
+	}
+	if p.event.PullRequestNumber == 0 {
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryHoldNotSupported", "the /hold and /unhold commands are only supported on pull requests")
+		return true, nil
+	}
+
+	switch p.event.EventType {
+	case opscomments.HoldCommentEventType.String():
+		if err := p.setHold(ctx, repo, p.event.Sender); err != nil {
+			return true, err
+		}
+		msg := fmt.Sprintf("pull request %d has been put on hold by %s", p.event.PullRequestNumber, p.event.Sender)
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPullRequestHeld", msg)
+		return true, p.createNeutralStatus(ctx, heldStatusTitle, heldStatusText(p.event.Sender))
+	case opscomments.UnholdCommentEventType.String():
+		if err := p.setHold(ctx, repo, ""); err != nil {
+			return true, err
+		}
+		msg := fmt.Sprintf("pull request %d has been released from hold by %s", p.event.PullRequestNumber, p.event.Sender)
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPullRequestUnheld", msg)
+	}
+	return false, nil
+}
+
+// setHold records the pull request of the event as held by user on the
+// Repository CR, an empty user releases the hold.
+func (p *PacRun) setHold(ctx context.Context, repo *v1alpha1.Repository, user string) error {
+	var value any
+	if user != "" {
+		value = user
+	}
+	mergePatch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				holdAnnotation(p.event.PullRequestNumber): value,
+			},
+		},
+	}
+	patch, err := json.Marshal(mergePatch)
+	if err != nil {
+		return err
+	}
+	updated, err := p.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(repo.GetNamespace()).Patch(
+		ctx, repo.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot update hold of pull request %d on repository %s/%s: %w",
+			p.event.PullRequestNumber, repo.GetNamespace(), repo.GetName(), err)
+	}
+	repo.SetAnnotations(updated.GetAnnotations())
+	return nil
+}
+
+func heldStatusText(user string) string {
+	return fmt.Sprintf("The PipelineRuns of this pull request have been put on hold by %s, comment `/unhold` to run them.", user)
+}
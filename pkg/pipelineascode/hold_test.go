@@ -0,0 +1,101 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestHandleHoldComment(t *testing.T) {
+	tests := []struct {
+		name              string
+		eventType         string
+		pullRequestNumber int
+		annotations       map[string]string
+		wantHandled       bool
+		wantHeldBy        string
+	}{
+		{
+			name:              "hold",
+			eventType:         opscomments.HoldCommentEventType.String(),
+			pullRequestNumber: 42,
+			wantHandled:       true,
+			wantHeldBy:        "reviewer",
+		},
+		{
+			name:              "unhold runs the pipelineruns",
+			eventType:         opscomments.UnholdCommentEventType.String(),
+			pullRequestNumber: 42,
+			annotations:       map[string]string{holdAnnotation(42): "someone"},
+		},
+		{
+			name:              "unhold keeps the other pull requests on hold",
+			eventType:         opscomments.UnholdCommentEventType.String(),
+			pullRequestNumber: 42,
+			annotations:       map[string]string{holdAnnotation(43): "someone"},
+		},
+		{
+			name:              "other event types are ignored",
+			eventType:         opscomments.TestAllCommentEventType.String(),
+			pullRequestNumber: 42,
+			annotations:       map[string]string{holdAnnotation(42): "someone"},
+			wantHeldBy:        "someone",
+		},
+		{
+			name:        "hold without pull request",
+			eventType:   opscomments.HoldCommentEventType.String(),
+			wantHandled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns", Annotations: tt.annotations},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{repo},
+			})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:            logger,
+					Kube:           stdata.Kube,
+					PipelineAsCode: stdata.PipelineAsCode,
+				},
+			}
+			event := &info.Event{
+				EventType:         tt.eventType,
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: tt.pullRequestNumber,
+				Sender:            "reviewer",
+			}
+
+			pac := NewPacs(event, &testprovider.TestProviderImp{}, run, &info.PacOpts{}, nil, logger, nil)
+			handled, err := pac.handleHoldComment(ctx, repo)
+			assert.NilError(t, err)
+			assert.Equal(t, handled, tt.wantHandled)
+			assert.Equal(t, pac.heldBy(repo), tt.wantHeldBy)
+
+			got, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Get(ctx, "repo", metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, pac.heldBy(got), tt.wantHeldBy)
+			if _, ok := tt.annotations[holdAnnotation(43)]; ok {
+				assert.Equal(t, got.GetAnnotations()[holdAnnotation(43)], "someone")
+			}
+		})
+	}
+}
@@ -33,11 +33,29 @@ func (p *PacRun) matchRepoPR(ctx context.Context) ([]matcher.Match, *v1alpha1.Re
 		return nil, nil, nil
 	}
 
+	if handled, err := p.handleCommandComment(ctx, repo); handled || err != nil {
+		return nil, repo, err
+	}
+
 	if p.event.CancelPipelineRuns {
 		p.debugf("matchRepoPR: cancel pipeline runs requested, skipping match")
+		// a cancel command of an incoming webhook has been authenticated by its secret
+		if p.event.EventType != triggertype.Incoming.String() {
+			if allowed, err := p.checkCancelPolicyOrError(ctx, repo); !allowed {
+				return nil, repo, err
+			}
+		}
 		return nil, repo, p.cancelPipelineRunsOpsComment(ctx, repo)
 	}
 
+	if handled, err := p.handleHoldComment(ctx, repo); handled || err != nil {
+		return nil, repo, err
+	}
+
+	if handled, err := p.handleRevokeOkToTestComment(ctx, repo); handled || err != nil {
+		return nil, repo, err
+	}
+
 	p.debugf("matchRepoPR: fetching pipelineruns from repo=%s/%s", repo.GetNamespace(), repo.GetName())
 	matchedPRs, err := p.getPipelineRunsFromRepo(ctx, repo)
 	if err != nil {
@@ -81,6 +99,11 @@ func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, e
 	p.debugf("verifyRepoAndUser: authenticated client setup complete")
 
 	// Get the SHA commit info, we want to get the URL and commit title
+	incomingBranch := p.event.BaseBranch
+	incomingSHA := ""
+	if p.event.EventType == triggertype.Incoming.String() && p.event.TriggerTarget == triggertype.Push {
+		incomingSHA = p.event.SHA
+	}
 	if p.event.SHA == "" || p.event.SHATitle == "" || p.event.SHAURL == "" {
 		p.debugf("verifyRepoAndUser: fetching commit info")
 		if err = p.vcx.GetCommitInfo(ctx, p.event); err != nil {
@@ -89,6 +112,33 @@ func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, e
 		p.debugf("verifyRepoAndUser: commit info loaded sha=%s title=%s", p.event.SHA, p.event.SHATitle)
 	}
 
+	// the pull request of a pull-request incoming webhook must target the branch
+	// allowed by the webhook rule, not any branch of the repository.
+	if p.event.EventType == triggertype.Incoming.String() && p.event.TriggerTarget == triggertype.PullRequest && p.event.BaseBranch != incomingBranch {
+		return nil, fmt.Errorf("pull request %d targets the branch %s and not the branch %s of the incoming webhook",
+			p.event.PullRequestNumber, p.event.BaseBranch, incomingBranch)
+	}
+	// same for the commit of a sha incoming webhook, it would otherwise run
+	// any commit, ie: the head of a pull request from a fork, with the trust of
+	// the branch.
+	if incomingSHA != "" {
+		onBranch, err := p.vcx.IsCommitOnBranch(ctx, p.event, incomingSHA, incomingBranch)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check the commit %s is on the branch %s of the incoming webhook: %w", incomingSHA, incomingBranch, err)
+		}
+		if !onBranch {
+			return nil, fmt.Errorf("commit %s is not on the branch %s of the incoming webhook", incomingSHA, incomingBranch)
+		}
+	}
+
+	// the push policy restricts who can trigger CI by pushing to the protected branches.
+	if p.event.TriggerTarget == triggertype.Push && p.event.EventType != triggertype.Incoming.String() &&
+		!opscomments.IsAnyOpsEventType(p.event.EventType) {
+		if allowed, err := p.checkPushPolicyOrError(ctx, repo); !allowed {
+			return nil, err
+		}
+	}
+
 	// Verify whether the sender of the GitOps command (e.g., /test) has the appropriate permissions to
 	// trigger CI on the repository, as any user is able to comment on a pushed commit in open-source repositories.
 	if p.event.TriggerTarget == triggertype.Push && opscomments.IsAnyOpsEventType(p.event.EventType) {
@@ -108,7 +158,11 @@ func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, e
 	// Check if the submitter is allowed to run this.
 	// on push we don't need to check the policy since the user has pushed to the repo so it has access to it.
 	// on comment we skip it for now, we are going to check later on
-	if p.event.TriggerTarget != triggertype.Push && p.event.EventType != opscomments.NoOpsCommentEventType.String() {
+	// on incoming the request has already been authenticated by the webhook secret
+	// on /revoke-ok-to-test the sender is checked when handling the command
+	if p.event.TriggerTarget != triggertype.Push && p.event.EventType != opscomments.NoOpsCommentEventType.String() &&
+		p.event.EventType != triggertype.Incoming.String() &&
+		p.event.EventType != opscomments.RevokeOkToTestCommentEventType.String() {
 		p.debugf("verifyRepoAndUser: checking access for trigger target=%s event_type=%s", p.event.TriggerTarget, p.event.EventType)
 		status := providerstatus.StatusOpts{
 			Status:       queuedStatus,
@@ -132,6 +186,9 @@ func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, e
 			if err := p.vcx.CreateStatus(ctx, p.event, approvalStatus); err != nil {
 				p.logger.Warnf("failed to update parent status on /ok-to-test approval: %v", err)
 			}
+			if err := p.recordOkToTestApproval(ctx, repo); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return repo, nil
@@ -277,6 +334,11 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 				}
 				return nil, nil
 			}
+			// the matcher has reported the denial on each PipelineRun
+			if errors.Is(err, matcher.ErrPolicyTeamsDenied) {
+				p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPolicyTeamsDenied", err.Error())
+				return nil, nil
+			}
 			// Don't fail when you don't have a match between pipeline and annotations
 			p.eventEmitter.EmitMessage(nil, zap.WarnLevel, "RepositoryNoMatch", err.Error())
 			// In a scenario where an external user submits a pull request and the repository owner uses the
@@ -370,12 +432,27 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 			p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryCannotLocatePipelineRun", msg)
 			return nil, nil
 		}
+		if !matcher.IsAllowedByPolicyTeams(ctx, p.logger, selectedPr, p.event, p.vcx, p.eventEmitter, repo, true) {
+			return nil, nil
+		}
 		selectedRepo := p.resolveTargetNamespaceRepo(ctx, repo, selectedPr)
 		if selectedRepo == nil {
 			msg := fmt.Sprintf("skipping pipelinerun %s: target-namespace repo not found", pipelineRunIdentifier(selectedPr))
 			p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryTargetNamespaceNotFound", msg)
 			return nil, nil
 		}
+		if p.event.EventType == opscomments.RetestFailedCommentEventType.String() {
+			if err := p.skipSucceededTasks(ctx, selectedRepo, selectedPr); err != nil {
+				if errors.Is(err, errNoFailedTasksToRetest) {
+					p.eventEmitter.EmitMessage(selectedRepo, zap.InfoLevel, "RepositoryAllTasksSucceeded", err.Error())
+					if commentErr := p.vcx.CreateComment(ctx, p.event, err.Error(), ""); commentErr != nil {
+						return nil, fmt.Errorf("error adding no failed tasks to retest comment: %w", commentErr)
+					}
+					return nil, nil
+				}
+				return nil, err
+			}
+		}
 		p.debugf("getPipelineRunsFromRepo: explicit /test using repo=%s/%s for pipelinerun=%s", selectedRepo.GetNamespace(), selectedRepo.GetName(), pipelineRunIdentifier(selectedPr))
 		return []matcher.Match{{
 			PipelineRun: selectedPr,
@@ -384,6 +461,9 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 	}
 
 	matchedPRs, err = matcher.MatchPipelinerunByAnnotation(ctx, p.logger, pipelineRuns, p.run, p.event, p.vcx, p.eventEmitter, repo, false)
+	if errors.Is(err, matcher.ErrPolicyTeamsDenied) {
+		return nil, p.reportPolicyTeamsDenied(ctx, repo, err)
+	}
 	if err != nil {
 		// Don't fail when you don't have a match between pipeline and annotations
 		p.eventEmitter.EmitMessage(nil, zap.WarnLevel, "RepositoryNoMatch", err.Error())
@@ -498,6 +578,25 @@ func (p *PacRun) checkNeedUpdate(_ string) (string, bool) {
 	return "", false
 }
 
+// reportPolicyTeamsDenied reports that the sender is not allowed to trigger
+// any of the matched PipelineRuns by their policy-teams annotation, with a
+// neutral status so the sender cannot fail the CI.
+func (p *PacRun) reportPolicyTeamsDenied(ctx context.Context, repo *v1alpha1.Repository, denial error) error {
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPolicyTeamsDenied", denial.Error())
+	status := providerstatus.StatusOpts{
+		Status:       CompletedStatus,
+		Title:        "Not allowed by policy-teams",
+		Text:         denial.Error(),
+		Conclusion:   providerstatus.ConclusionNeutral,
+		DetailsURL:   p.event.URL,
+		AccessDenied: true,
+	}
+	if err := p.vcx.CreateStatus(ctx, p.event, status); err != nil {
+		return fmt.Errorf("failed to create status, user is not allowed by policy-teams: %w", err)
+	}
+	return nil
+}
+
 func (p *PacRun) createNeutralStatus(ctx context.Context, title, text string) error {
 	p.debugf("createNeutralStatus: title=%s", title)
 	status := providerstatus.StatusOpts{
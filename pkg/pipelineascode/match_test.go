@@ -14,6 +14,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
@@ -61,6 +62,46 @@ func TestPacRun_checkNeedUpdate(t *testing.T) {
 	}
 }
 
+func TestReportPolicyTeamsDenied(t *testing.T) {
+	denial := fmt.Errorf("sender fantasio is not allowed to trigger any of the matched PipelineRuns: %w", matcher.ErrPolicyTeamsDenied)
+	tests := []struct {
+		name              string
+		createStatusError bool
+		wantErr           string
+	}{
+		{
+			name: "neutral status created",
+		},
+		{
+			name:              "create status error",
+			createStatusError: true,
+			wantErr:           "failed to create status, user is not allowed by policy-teams",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, logs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			p := &PacRun{
+				event:        &info.Event{Sender: "fantasio"},
+				vcx:          &testprovider.TestProviderImp{CreateStatusErorring: tt.createStatusError},
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"}}
+			err := p.reportPolicyTeamsDenied(ctx, repo, denial)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.Assert(t, logs.FilterMessageSnippet("not allowed to trigger any of the matched PipelineRuns").Len() > 0)
+		})
+	}
+}
+
 func TestChangePipelineRun(t *testing.T) {
 	repo := &v1alpha1.Repository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -612,6 +653,7 @@ func TestVerifyRepoAndUser(t *testing.T) {
 		runevent      info.Event
 		repositories  []*v1alpha1.Repository
 		webhookSecret string
+		aheadBy       int
 		wantRepoNil   bool
 		wantErr       bool
 		wantErrMsg    string
@@ -731,6 +773,52 @@ func TestVerifyRepoAndUser(t *testing.T) {
 			wantErr:       true,
 			wantErrMsg:    "failed to run create status, user is not allowed to run the CI",
 		},
+		{
+			name: "incoming sha not on the branch",
+			runevent: info.Event{
+				Organization:   "owner",
+				Repository:     "repo",
+				URL:            "https://example.com/owner/repo",
+				SHA:            "123abc",
+				BaseBranch:     "main",
+				HeadBranch:     "main",
+				EventType:      triggertype.Incoming.String(),
+				TriggerTarget:  triggertype.Push,
+				InstallationID: 1,
+				Sender:         "incoming",
+				Request:        request,
+			},
+			repositories: []*v1alpha1.Repository{{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://example.com/owner/repo"},
+			}},
+			webhookSecret: "secret",
+			aheadBy:       1,
+			wantRepoNil:   true,
+			wantErr:       true,
+			wantErrMsg:    "commit 123abc is not on the branch main of the incoming webhook",
+		},
+		{
+			name: "incoming sha on the branch",
+			runevent: info.Event{
+				Organization:   "owner",
+				Repository:     "repo",
+				URL:            "https://example.com/owner/repo",
+				SHA:            "123abc",
+				BaseBranch:     "main",
+				HeadBranch:     "main",
+				EventType:      triggertype.Incoming.String(),
+				TriggerTarget:  triggertype.Push,
+				InstallationID: 1,
+				Sender:         "incoming",
+				Request:        request,
+			},
+			repositories: []*v1alpha1.Repository{{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://example.com/owner/repo"},
+			}},
+			webhookSecret: "secret",
+		},
 		{
 			name: "happy path",
 			runevent: info.Event{
@@ -774,8 +862,16 @@ func TestVerifyRepoAndUser(t *testing.T) {
 				fmt.Fprint(rw, `{"sha":"123abc","html_url":"https://example.com/commit/123abc","message":"msg"}`)
 			})
 
+			// commits of the sha which are not on the branch
+			mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/compare/%s...%s", tt.runevent.Organization, tt.runevent.Repository, tt.runevent.BaseBranch, tt.runevent.SHA),
+				func(rw http.ResponseWriter, _ *http.Request) { fmt.Fprintf(rw, `{"ahead_by":%d}`, tt.aheadBy) })
 			// org members empty
 			mux.HandleFunc(fmt.Sprintf("/orgs/%s/members", tt.runevent.Organization), func(rw http.ResponseWriter, _ *http.Request) { fmt.Fprint(rw, `[]`) })
+			// changed files of the push and pull request, listed for the OWNERS check
+			mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/commits/%s", tt.runevent.Organization, tt.runevent.Repository, tt.runevent.SHA),
+				func(rw http.ResponseWriter, _ *http.Request) { fmt.Fprint(rw, `{"files":[]}`) })
+			mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/pulls/%d/files", tt.runevent.Organization, tt.runevent.Repository, tt.runevent.PullRequestNumber),
+				func(rw http.ResponseWriter, _ *http.Request) { fmt.Fprint(rw, `[]`) })
 			// collaborator check – return 404 for non‐collaborator sender when defined
 			if tt.runevent.Sender != "" && tt.runevent.Sender != tt.runevent.Organization {
 				mux.HandleFunc(
@@ -0,0 +1,108 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const revokedStatusTitle = "Approval revoked"
+
+// recordOkToTestApproval records the /ok-to-test approval of the pull request
+// of the event on the Repository CR, with its approver, commit and expiry. A
+// failure is reported as an event on the Repository.
+func (p *PacRun) recordOkToTestApproval(ctx context.Context, repo *v1alpha1.Repository) error {
+	if p.event.PullRequestNumber == 0 {
+		return nil
+	}
+	approval, err := acl.NewOkToTestApproval(p.event.Sender, p.event.SHA, p.pacInfo.OkToTestTTL, time.Now())
+	if err == nil {
+		err = p.setOkToTestApproval(ctx, repo, approval)
+	}
+	if err != nil {
+		err = fmt.Errorf("cannot record the /ok-to-test approval of %s: %w", p.event.Sender, err)
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryOkToTestApprovalFailed", err.Error())
+		return err
+	}
+	msg := fmt.Sprintf("pull request %d has been approved by %s on commit %s", p.event.PullRequestNumber, p.event.Sender, p.event.SHA)
+	if approval.ExpiresAt != nil {
+		msg += fmt.Sprintf(" until %s", approval.ExpiresAt.Format(time.RFC3339))
+	}
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryOkToTestApproved", msg)
+	return nil
+}
+
+// handleRevokeOkToTestComment handles the /revoke-ok-to-test GitOps command,
+// it returns true when the event was a /revoke-ok-to-test command.
+func (p *PacRun) handleRevokeOkToTestComment(ctx context.Context, repo *v1alpha1.Repository) (bool, error) {
+	if p.event.EventType != opscomments.RevokeOkToTestCommentEventType.String() {
+		return false, nil
+	}
+	if p.event.PullRequestNumber == 0 {
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryRevokeOkToTestNotSupported", "the /revoke-ok-to-test command is only supported on pull requests")
+		return true, nil
+	}
+	if allowed, err := p.checkRevokeOkToTestAccessOrError(ctx, repo); !allowed {
+		return true, err
+	}
+
+	approval, err := acl.GetOkToTestApproval(repo, p.event.PullRequestNumber)
+	if err != nil || approval == nil {
+		// a revoked record without approver still stops honouring the
+		// /ok-to-test comments already posted on the pull request.
+		approval = &acl.OkToTestApproval{}
+	}
+	approval.Revoke(p.event.Sender, time.Now())
+	if err := p.setOkToTestApproval(ctx, repo, approval); err != nil {
+		err = fmt.Errorf("cannot revoke the /ok-to-test approval: %w", err)
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryOkToTestRevokeFailed", err.Error())
+		return true, err
+	}
+	msg := fmt.Sprintf("the /ok-to-test approval of pull request %d has been revoked by %s", p.event.PullRequestNumber, p.event.Sender)
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryOkToTestRevoked", msg)
+	return true, p.createNeutralStatus(ctx, revokedStatusTitle, revokedStatusText(p.event.Sender))
+}
+
+// setOkToTestApproval stores the approval of the pull request of the event on
+// the Repository CR, a nil approval removes it.
+func (p *PacRun) setOkToTestApproval(ctx context.Context, repo *v1alpha1.Repository, approval *acl.OkToTestApproval) error {
+	var value any
+	if approval != nil {
+		b, err := json.Marshal(approval)
+		if err != nil {
+			return err
+		}
+		value = string(b)
+	}
+	mergePatch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				acl.OkToTestApprovalAnnotation(p.event.PullRequestNumber): value,
+			},
+		},
+	}
+	patch, err := json.Marshal(mergePatch)
+	if err != nil {
+		return err
+	}
+	updated, err := p.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(repo.GetNamespace()).Patch(
+		ctx, repo.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot update /ok-to-test approval of pull request %d on repository %s/%s: %w",
+			p.event.PullRequestNumber, repo.GetNamespace(), repo.GetName(), err)
+	}
+	repo.SetAnnotations(updated.GetAnnotations())
+	return nil
+}
+
+func revokedStatusText(user string) string {
+	return fmt.Sprintf("The `/ok-to-test` approval of this pull request has been revoked by %s, a new `/ok-to-test` is needed to run the CI.", user)
+}
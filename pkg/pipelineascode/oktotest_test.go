@@ -0,0 +1,166 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestOkToTestApproval(t *testing.T) {
+	tests := []struct {
+		name         string
+		eventType    string
+		annotations  map[string]string
+		ttl          string
+		denied       bool
+		wantHandled  bool
+		wantApprover string
+		wantRevoked  string
+		wantExpiry   bool
+	}{
+		{
+			name:         "approve",
+			eventType:    opscomments.OkToTestCommentEventType.String(),
+			wantApprover: "reviewer",
+		},
+		{
+			name:         "approve with ttl",
+			eventType:    opscomments.OkToTestCommentEventType.String(),
+			ttl:          "24h",
+			wantApprover: "reviewer",
+			wantExpiry:   true,
+		},
+		{
+			name:         "approve again after revoke",
+			eventType:    opscomments.OkToTestCommentEventType.String(),
+			annotations:  map[string]string{acl.OkToTestApprovalAnnotation(42): `{"approver":"owner","revoked_by":"security","revoked_at":"2025-01-01T11:00:00Z"}`},
+			wantApprover: "reviewer",
+		},
+		{
+			name:         "revoke",
+			eventType:    opscomments.RevokeOkToTestCommentEventType.String(),
+			annotations:  map[string]string{acl.OkToTestApprovalAnnotation(42): `{"approver":"owner","sha":"abc","approved_at":"2025-01-01T10:00:00Z"}`},
+			wantHandled:  true,
+			wantApprover: "owner",
+			wantRevoked:  "reviewer",
+		},
+		{
+			name:         "revoke denied",
+			eventType:    opscomments.RevokeOkToTestCommentEventType.String(),
+			annotations:  map[string]string{acl.OkToTestApprovalAnnotation(42): `{"approver":"owner","sha":"abc","approved_at":"2025-01-01T10:00:00Z"}`},
+			denied:       true,
+			wantHandled:  true,
+			wantApprover: "owner",
+		},
+		{
+			name:        "revoke without approval",
+			eventType:   opscomments.RevokeOkToTestCommentEventType.String(),
+			wantHandled: true,
+			wantRevoked: "reviewer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, observerLogs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns", Annotations: tt.annotations},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{repo},
+			})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:            logger,
+					Kube:           stdata.Kube,
+					PipelineAsCode: stdata.PipelineAsCode,
+				},
+			}
+			event := &info.Event{
+				EventType:         tt.eventType,
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: 42,
+				Sender:            "reviewer",
+				SHA:               "123abc",
+			}
+
+			pacInfo := &info.PacOpts{Settings: settings.Settings{OkToTestTTL: tt.ttl}}
+			pac := NewPacs(event, &testprovider.TestProviderImp{AllowIT: !tt.denied}, run, pacInfo, nil, logger, nil)
+			handled, err := pac.handleRevokeOkToTestComment(ctx, repo)
+			assert.NilError(t, err)
+			assert.Equal(t, handled, tt.wantHandled)
+			if !handled && tt.eventType == opscomments.OkToTestCommentEventType.String() {
+				assert.NilError(t, pac.recordOkToTestApproval(ctx, repo))
+			}
+
+			got, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Get(ctx, "repo", metav1.GetOptions{})
+			assert.NilError(t, err)
+			approval, err := acl.GetOkToTestApproval(got, 42)
+			assert.NilError(t, err)
+			assert.Assert(t, approval != nil)
+			assert.Equal(t, approval.Approver, tt.wantApprover)
+			assert.Equal(t, approval.RevokedBy, tt.wantRevoked)
+			assert.Equal(t, approval.ExpiresAt != nil, tt.wantExpiry)
+			if tt.wantRevoked == "" && !tt.denied {
+				assert.Equal(t, approval.SHA, "123abc")
+			}
+			denials := observerLogs.FilterMessage("User reviewer is not allowed to revoke the /ok-to-test approval in this repo.").Len()
+			assert.Equal(t, denials == 1, tt.denied)
+		})
+	}
+}
+
+func TestOkToTestApprovalFailure(t *testing.T) {
+	for _, eventType := range []string{opscomments.OkToTestCommentEventType.String(), opscomments.RevokeOkToTestCommentEventType.String()} {
+		t.Run(eventType, func(t *testing.T) {
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			// the Repository is not in the cluster, patching it fails
+			repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:            logger,
+					Kube:           stdata.Kube,
+					PipelineAsCode: stdata.PipelineAsCode,
+				},
+			}
+			event := &info.Event{
+				EventType:         eventType,
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: 42,
+				Sender:            "reviewer",
+				SHA:               "123abc",
+			}
+			pac := NewPacs(event, &testprovider.TestProviderImp{AllowIT: true}, run, &info.PacOpts{}, nil, logger, nil)
+
+			handled, err := pac.handleRevokeOkToTestComment(ctx, repo)
+			if !handled {
+				err = pac.recordOkToTestApproval(ctx, repo)
+			}
+			assert.ErrorContains(t, err, "/ok-to-test approval")
+
+			events, err := stdata.Kube.CoreV1().Events("ns").List(ctx, metav1.ListOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, len(events.Items), 1)
+			assert.Assert(t, events.Items[0].Reason == "RepositoryOkToTestApprovalFailed" || events.Items[0].Reason == "RepositoryOkToTestRevokeFailed",
+				events.Items[0].Reason)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
@@ -76,6 +77,16 @@ func (p *PacRun) Run(ctx context.Context) error {
 			if err := p.cancelAllInProgressBelongingToClosedPullRequest(ctx, repo); err != nil {
 				return fmt.Errorf("error cancelling in progress pipelineRuns belonging to pull request %d: %w", p.event.PullRequestNumber, err)
 			}
+			if p.heldBy(repo) != "" {
+				if err := p.setHold(ctx, repo, ""); err != nil {
+					p.logger.Warnf("cannot release hold of closed pull request %d: %v", p.event.PullRequestNumber, err)
+				}
+			}
+			if _, ok := repo.GetAnnotations()[acl.OkToTestApprovalAnnotation(p.event.PullRequestNumber)]; ok {
+				if err := p.setOkToTestApproval(ctx, repo, nil); err != nil {
+					p.logger.Warnf("cannot remove /ok-to-test approval of closed pull request %d: %v", p.event.PullRequestNumber, err)
+				}
+			}
 		} else {
 			p.debugf("pull request closed: no repo match found for event url=%s", p.event.URL)
 		}
@@ -106,6 +117,14 @@ func (p *PacRun) Run(ctx context.Context) error {
 		p.debugf("no pipelineruns matched; returning without starting any runs")
 		return nil
 	}
+	if heldBy := p.heldBy(repo); heldBy != "" {
+		msg := fmt.Sprintf("pull request %d is on hold, skipping %d matched pipelineruns", p.event.PullRequestNumber, len(matchedPRs))
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPullRequestOnHold", msg)
+		if err := p.createNeutralStatus(ctx, heldStatusTitle, heldStatusText(heldBy)); err != nil {
+			p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", err.Error())
+		}
+		return nil
+	}
 	if repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit != 0 {
 		p.debugf("enabling concurrency manager with limit=%d", *repo.Spec.ConcurrencyLimit)
 		p.manager.Enable()
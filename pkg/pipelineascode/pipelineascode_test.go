@@ -71,6 +71,14 @@ func testSetupCommonGhReplies(t *testing.T, mux *http.ServeMux, runevent info.Ev
 		fmt.Sprintf("/repos/%s/%s/git/commits/%s", runevent.Organization, runevent.Repository, runevent.SHA),
 		jj)
 
+	// changed files of the push and pull request, listed for the OWNERS check
+	replyString(mux,
+		fmt.Sprintf("/repos/%s/%s/commits/%s", runevent.Organization, runevent.Repository, runevent.SHA),
+		`{"files": []}`)
+	replyString(mux,
+		fmt.Sprintf("/repos/%s/%s/pulls/%d/files", runevent.Organization, runevent.Repository, runevent.PullRequestNumber),
+		`[]`)
+
 	if !noReplyOrgPublicMembers {
 		mux.HandleFunc("/orgs/"+runevent.Organization+"/members", func(rw http.ResponseWriter, _ *http.Request) {
 			_, _ = fmt.Fprintf(rw, `[{"login": "%s"}]`, runevent.Sender)
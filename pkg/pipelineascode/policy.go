@@ -0,0 +1,96 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
+	providerstatus "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/status"
+	"go.uber.org/zap"
+)
+
+// isAllowedByPolicy checks the push and cancel policies of the repository,
+// which are enforced for every provider through CheckPolicyAllowing. A
+// trigger not restricted by the policy is allowed.
+func (p *PacRun) isAllowedByPolicy(ctx context.Context, repo *v1alpha1.Repository, tType triggertype.Trigger) bool {
+	aclPolicy := policy.Policy{
+		Repository:   repo,
+		EventEmitter: p.eventEmitter,
+		Event:        p.event,
+		VCX:          p.vcx,
+		Logger:       p.logger,
+	}
+	result, _ := aclPolicy.IsAllowed(ctx, tType)
+	return result != policy.ResultDisallowed
+}
+
+// checkPushPolicyOrError checks the sender is allowed to trigger PipelineRuns
+// by pushing to the branch and reports a failure status on the commit when
+// not.
+func (p *PacRun) checkPushPolicyOrError(ctx context.Context, repo *v1alpha1.Repository) (bool, error) {
+	if p.isAllowedByPolicy(ctx, repo, triggertype.Push) {
+		return true, nil
+	}
+	msg := fmt.Sprintf("User %s is not allowed to trigger CI by pushing to the branch %s in this repo.",
+		p.event.Sender, strings.TrimPrefix(p.event.BaseBranch, "refs/heads/"))
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPermissionDenied", msg)
+	status := providerstatus.StatusOpts{
+		Status:       CompletedStatus,
+		Title:        "Permission denied",
+		Conclusion:   providerstatus.ConclusionFailure,
+		DetailsURL:   p.event.URL,
+		AccessDenied: true,
+		Text:         msg,
+	}
+	if err := p.vcx.CreateStatus(ctx, p.event, status); err != nil {
+		return false, fmt.Errorf("failed to run create status, user is not allowed to run the CI:: %w", err)
+	}
+	return false, nil
+}
+
+// checkCancelPolicyOrError checks the sender is allowed to cancel the
+// PipelineRuns and tells the sender on the pull request when not.
+func (p *PacRun) checkCancelPolicyOrError(ctx context.Context, repo *v1alpha1.Repository) (bool, error) {
+	if p.isAllowedByPolicy(ctx, repo, triggertype.Cancel) {
+		return true, nil
+	}
+	msg := fmt.Sprintf("User %s is not allowed to cancel the PipelineRuns in this repo.", p.event.Sender)
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPermissionDenied", msg)
+	if p.event.PullRequestNumber == 0 {
+		return false, nil
+	}
+	if err := p.vcx.CreateComment(ctx, p.event, msg, ""); err != nil {
+		return false, fmt.Errorf("failed to create comment, user is not allowed to cancel the PipelineRuns: %w", err)
+	}
+	return false, nil
+}
+
+// checkRevokeOkToTestAccessOrError checks the sender is allowed to revoke the
+// /ok-to-test approval, as they would be to give it, and reports a neutral
+// status on the pull request when not so the sender cannot fail its CI.
+func (p *PacRun) checkRevokeOkToTestAccessOrError(ctx context.Context, repo *v1alpha1.Repository) (bool, error) {
+	allowed, err := p.vcx.IsAllowed(ctx, p.event)
+	if err != nil {
+		return false, fmt.Errorf("unable to verify event authorization: %w", err)
+	}
+	if allowed {
+		return true, nil
+	}
+	msg := fmt.Sprintf("User %s is not allowed to revoke the /ok-to-test approval in this repo.", p.event.Sender)
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPermissionDenied", msg)
+	status := providerstatus.StatusOpts{
+		Status:       CompletedStatus,
+		Title:        "Permission denied",
+		Conclusion:   providerstatus.ConclusionNeutral,
+		DetailsURL:   p.event.URL,
+		AccessDenied: true,
+		Text:         msg,
+	}
+	if err := p.vcx.CreateStatus(ctx, p.event, status); err != nil {
+		return false, fmt.Errorf("failed to create status, user is not allowed to revoke the /ok-to-test approval: %w", err)
+	}
+	return false, nil
+}
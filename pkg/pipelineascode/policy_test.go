@@ -0,0 +1,152 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestIsAllowedByPolicy(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            *v1alpha1.Policy
+		tType             triggertype.Trigger
+		branch            string
+		policyDisallowing bool
+		want              bool
+	}{
+		{
+			name:   "push to an unprotected branch",
+			policy: &v1alpha1.Policy{Push: []v1alpha1.PushPolicy{{Branches: []string{"main"}, Allowed: []string{"admins"}}}},
+			tType:  triggertype.Push,
+			branch: "refs/heads/feature",
+			want:   true,
+		},
+		{
+			name:              "push to a protected branch by a user not allowed",
+			policy:            &v1alpha1.Policy{Push: []v1alpha1.PushPolicy{{Branches: []string{"main"}, Allowed: []string{"admins"}}}},
+			tType:             triggertype.Push,
+			branch:            "refs/heads/main",
+			policyDisallowing: true,
+		},
+		{
+			name:   "push to a protected branch by a member of the team",
+			policy: &v1alpha1.Policy{Push: []v1alpha1.PushPolicy{{Branches: []string{"main"}, Allowed: []string{"admins"}}}},
+			tType:  triggertype.Push,
+			branch: "refs/heads/main",
+			want:   true,
+		},
+		{
+			name:              "cancel by a user not allowed",
+			policy:            &v1alpha1.Policy{Cancel: []string{"admins"}},
+			tType:             triggertype.Cancel,
+			policyDisallowing: true,
+		},
+		{
+			name:              "cancel without cancel policy",
+			policy:            &v1alpha1.Policy{},
+			tType:             triggertype.Cancel,
+			policyDisallowing: true,
+			want:              true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:  logger,
+					Kube: stdata.Kube,
+				},
+			}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{Policy: tt.policy}},
+			}
+			event := &info.Event{
+				EventType:     tt.tType.String(),
+				TriggerTarget: triggertype.Push,
+				BaseBranch:    tt.branch,
+				Sender:        "pusher",
+			}
+			pac := NewPacs(event, &testprovider.TestProviderImp{PolicyDisallowing: tt.policyDisallowing}, run, &info.PacOpts{}, nil, logger, nil)
+			assert.Equal(t, pac.isAllowedByPolicy(ctx, repo, tt.tType), tt.want)
+		})
+	}
+}
+
+func TestCheckCancelPolicyOrError(t *testing.T) {
+	tests := []struct {
+		name              string
+		pullRequest       int
+		policyDisallowing bool
+		want              bool
+		wantComments      []string
+		wantEvent         bool
+	}{
+		{
+			name: "allowed",
+			want: true,
+		},
+		{
+			name:              "denied on a pull request",
+			pullRequest:       42,
+			policyDisallowing: true,
+			wantComments:      []string{"User canceller is not allowed to cancel the PipelineRuns in this repo."},
+			wantEvent:         true,
+		},
+		{
+			name:              "denied on a commit",
+			policyDisallowing: true,
+			wantEvent:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, observerLogs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:  logger,
+					Kube: stdata.Kube,
+				},
+			}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{Policy: &v1alpha1.Policy{Cancel: []string{"admins"}}}},
+			}
+			event := &info.Event{
+				EventType:         opscomments.CancelCommentAllEventType.String(),
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: tt.pullRequest,
+				Sender:            "canceller",
+			}
+			vcx := &testprovider.TestProviderImp{PolicyDisallowing: tt.policyDisallowing}
+			pac := NewPacs(event, vcx, run, &info.PacOpts{}, nil, logger, nil)
+			allowed, err := pac.checkCancelPolicyOrError(ctx, repo)
+			assert.NilError(t, err)
+			assert.Equal(t, allowed, tt.want)
+			assert.DeepEqual(t, vcx.CreatedComments, tt.wantComments)
+
+			denied := observerLogs.FilterMessage("User canceller is not allowed to cancel the PipelineRuns in this repo.").Len()
+			assert.Equal(t, denied == 1, tt.wantEvent)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"knative.dev/pkg/apis"
+)
+
+// errNoFailedTasksToRetest is returned by /retest-failed when the previous
+// PipelineRun has succeeded and there is nothing to rerun.
+var errNoFailedTasksToRetest = errors.New("the last PipelineRun has succeeded, there are no failed tasks to retest")
+
+// skippedTaskWhen is a when expression that always evaluates to false, it is
+// used to skip the tasks which have already succeeded in the previous run.
+var skippedTaskWhen = tektonv1.WhenExpression{
+	Input:    "succeeded",
+	Operator: selection.NotIn,
+	Values:   []string{"succeeded"},
+}
+
+// skipSucceededTasks changes the PipelineRun so only the tasks which have not
+// succeeded in the previous run of the same PipelineRun are executed again.
+// The succeeded tasks are skipped with a when expression and the references
+// to their results are replaced by the values of the previous run.
+func (p *PacRun) skipSucceededTasks(ctx context.Context, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun) error {
+	originalPRName := pr.GetAnnotations()[keys.OriginalPRName]
+	if pr.Spec.PipelineSpec == nil {
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RetestFailedFullRun",
+			fmt.Sprintf("cannot reuse the task results of PipelineRun %s without an embedded pipelineSpec, rerunning all tasks", originalPRName))
+		return nil
+	}
+
+	previous, err := p.lastCompletedPipelineRun(ctx, repo, originalPRName)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RetestFailedFullRun",
+			fmt.Sprintf("no previous run of PipelineRun %s for this commit, rerunning all tasks", originalPRName))
+		return nil
+	}
+	if previous.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		return errNoFailedTasksToRetest
+	}
+
+	succeeded, err := p.succeededTaskResults(ctx, previous)
+	if err != nil {
+		return err
+	}
+
+	replacements := map[string]string{}
+	skipped := []string{}
+	for i := range pr.Spec.PipelineSpec.Tasks {
+		task := &pr.Spec.PipelineSpec.Tasks[i]
+		results, ok := succeeded[task.Name]
+		if !ok {
+			continue
+		}
+		task.When = append(task.When, skippedTaskWhen)
+		for name, value := range results {
+			replacements[fmt.Sprintf("$(tasks.%s.results.%s)", task.Name, name)] = value
+		}
+		skipped = append(skipped, task.Name)
+	}
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	spec, err := replaceTaskResults(pr.Spec.PipelineSpec, replacements)
+	if err != nil {
+		return fmt.Errorf("cannot reuse task results of pipelinerun %s: %w", previous.GetName(), err)
+	}
+	pr.Spec.PipelineSpec = spec
+
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RetestFailedSkippedTasks",
+		fmt.Sprintf("retesting failed tasks of PipelineRun %s, reusing the results of %s for tasks: %s",
+			originalPRName, previous.GetName(), strings.Join(skipped, ", ")))
+	return nil
+}
+
+// lastCompletedPipelineRun returns the most recent completed run of the
+// PipelineRun template for the commit of the event.
+func (p *PacRun) lastCompletedPipelineRun(ctx context.Context, repo *v1alpha1.Repository, originalPRName string) (*tektonv1.PipelineRun, error) {
+	labelSelector := getLabelSelector(map[string]string{
+		keys.SHA:            formatting.CleanValueKubernetes(p.event.SHA),
+		keys.OriginalPRName: formatting.CleanValueKubernetes(originalPRName),
+	}, selection.Equals)
+	prs, err := p.run.Clients.Tekton.TektonV1().PipelineRuns(repo.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipelineruns: %w", err)
+	}
+
+	var last *tektonv1.PipelineRun
+	for i := range prs.Items {
+		pr := &prs.Items[i]
+		if !pr.IsDone() {
+			continue
+		}
+		if last == nil || last.CreationTimestamp.Before(&pr.CreationTimestamp) {
+			last = pr
+		}
+	}
+	return last, nil
+}
+
+// succeededTaskResults returns the string results of every succeeded task of
+// a PipelineRun, indexed by pipeline task name. They are read from the
+// TaskRuns of the PipelineRun, the tasks of which the TaskRuns have been
+// pruned are not known to have succeeded and run again.
+func (p *PacRun) succeededTaskResults(ctx context.Context, pr *tektonv1.PipelineRun) (map[string]map[string]string, error) {
+	trs, err := p.run.Clients.Tekton.TektonV1().TaskRuns(pr.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", pipeline.PipelineRunLabelKey, pr.GetName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list taskruns of pipelinerun %s: %w", pr.GetName(), err)
+	}
+
+	succeeded := map[string]map[string]string{}
+	for _, tr := range trs.Items {
+		taskName, ok := tr.GetLabels()[pipeline.PipelineTaskLabelKey]
+		if !ok || !tr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			continue
+		}
+		results := map[string]string{}
+		for _, result := range tr.Status.Results {
+			if result.Value.Type == tektonv1.ParamTypeArray || result.Value.Type == tektonv1.ParamTypeObject {
+				continue
+			}
+			results[result.Name] = result.Value.StringVal
+		}
+		succeeded[taskName] = results
+	}
+	return succeeded, nil
+}
+
+// replaceTaskResults substitutes the task results references in a pipeline
+// spec by their values.
+func replaceTaskResults(spec *tektonv1.PipelineSpec, replacements map[string]string) (*tektonv1.PipelineSpec, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(replacements))
+	for ref := range replacements {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	content := string(raw)
+	for _, ref := range refs {
+		value, err := json.Marshal(replacements[ref])
+		if err != nil {
+			return nil, err
+		}
+		// strip the surrounding quotes, the value is replaced inside a json string
+		content = strings.ReplaceAll(content, ref, string(value[1:len(value)-1]))
+	}
+
+	newSpec := &tektonv1.PipelineSpec{}
+	if err := json.Unmarshal([]byte(content), newSpec); err != nil {
+		return nil, err
+	}
+	return newSpec, nil
+}
@@ -0,0 +1,196 @@
+package pipelineascode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func retestFailedPipelineRun(name string, created time.Time, status corev1.ConditionStatus) *tektonv1.PipelineRun {
+	return &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				keys.SHA:            "sha",
+				keys.OriginalPRName: "pr",
+			},
+		},
+		Status: tektonv1.PipelineRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}},
+			},
+		},
+	}
+}
+
+func retestFailedTaskRun(name, pipelineRun, pipelineTask string, status corev1.ConditionStatus, results ...tektonv1.TaskRunResult) *tektonv1.TaskRun {
+	return &tektonv1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels: map[string]string{
+				pipeline.PipelineRunLabelKey:  pipelineRun,
+				pipeline.PipelineTaskLabelKey: pipelineTask,
+			},
+		},
+		Status: tektonv1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}},
+			},
+			TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+				Results: results,
+			},
+		},
+	}
+}
+
+func TestSkipSucceededTasks(t *testing.T) {
+	now := time.Now()
+	imageResult := tektonv1.TaskRunResult{
+		Name:  "image",
+		Value: *tektonv1.NewStructuredValues("quay.io/app:\"sha\""),
+	}
+
+	tests := []struct {
+		name            string
+		pipelineRuns    []*tektonv1.PipelineRun
+		taskRuns        []*tektonv1.TaskRun
+		noPipelineSpec  bool
+		wantErr         error
+		wantSkipped     []string
+		wantDeployParam string
+	}{
+		{
+			name: "skip succeeded tasks of the last failed run",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				retestFailedPipelineRun("pr-old", now.Add(-time.Hour), corev1.ConditionFalse),
+				retestFailedPipelineRun("pr-last", now, corev1.ConditionFalse),
+			},
+			taskRuns: []*tektonv1.TaskRun{
+				retestFailedTaskRun("pr-old-build", "pr-old", "build", corev1.ConditionTrue, imageResult),
+				retestFailedTaskRun("pr-old-test", "pr-old", "test", corev1.ConditionTrue),
+				retestFailedTaskRun("pr-last-build", "pr-last", "build", corev1.ConditionTrue, imageResult),
+				retestFailedTaskRun("pr-last-test", "pr-last", "test", corev1.ConditionFalse),
+			},
+			wantSkipped:     []string{"build"},
+			wantDeployParam: "quay.io/app:\"sha\"",
+		},
+		{
+			name:            "no previous run",
+			wantDeployParam: "$(tasks.build.results.image)",
+		},
+		{
+			name: "last run has succeeded",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				retestFailedPipelineRun("pr-old", now.Add(-time.Hour), corev1.ConditionFalse),
+				retestFailedPipelineRun("pr-last", now, corev1.ConditionTrue),
+			},
+			wantErr:         errNoFailedTasksToRetest,
+			wantDeployParam: "$(tasks.build.results.image)",
+		},
+		{
+			name: "running pipelinerun is ignored",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				retestFailedPipelineRun("pr-last", now.Add(-time.Hour), corev1.ConditionFalse),
+				retestFailedPipelineRun("pr-running", now, corev1.ConditionUnknown),
+			},
+			taskRuns: []*tektonv1.TaskRun{
+				retestFailedTaskRun("pr-last-build", "pr-last", "build", corev1.ConditionTrue, imageResult),
+				retestFailedTaskRun("pr-last-test", "pr-last", "test", corev1.ConditionTrue),
+			},
+			wantSkipped:     []string{"build", "test"},
+			wantDeployParam: "quay.io/app:\"sha\"",
+		},
+		{
+			name: "no embedded pipelineSpec",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				retestFailedPipelineRun("pr-last", now, corev1.ConditionFalse),
+			},
+			noPipelineSpec: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				PipelineRuns: tt.pipelineRuns,
+				TaskRuns:     tt.taskRuns,
+			})
+			run := &params.Run{
+				Clients: clients.Clients{
+					Log:    logger,
+					Tekton: stdata.Pipeline,
+					Kube:   stdata.Kube,
+				},
+			}
+			repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "pr-",
+					Annotations:  map[string]string{keys.OriginalPRName: "pr"},
+				},
+			}
+			if !tt.noPipelineSpec {
+				pr.Spec.PipelineSpec = &tektonv1.PipelineSpec{
+					Tasks: []tektonv1.PipelineTask{
+						{Name: "build"},
+						{Name: "test", RunAfter: []string{"build"}},
+						{
+							Name: "deploy",
+							Params: tektonv1.Params{
+								{Name: "image", Value: *tektonv1.NewStructuredValues("$(tasks.build.results.image)")},
+							},
+						},
+					},
+				}
+			}
+
+			pac := NewPacs(&info.Event{SHA: "sha"}, nil, run, &info.PacOpts{}, nil, logger, nil)
+			err := pac.skipSucceededTasks(ctx, repo, pr)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			if tt.noPipelineSpec {
+				assert.Assert(t, pr.Spec.PipelineSpec == nil)
+				return
+			}
+
+			skipped := []string{}
+			for _, task := range pr.Spec.PipelineSpec.Tasks {
+				if len(task.When) > 0 {
+					assert.DeepEqual(t, task.When, tektonv1.WhenExpressions{skippedTaskWhen})
+					skipped = append(skipped, task.Name)
+				}
+			}
+			if len(tt.wantSkipped) == 0 {
+				assert.Equal(t, len(skipped), 0)
+			} else {
+				assert.DeepEqual(t, skipped, tt.wantSkipped)
+			}
+			assert.Equal(t, pr.Spec.PipelineSpec.Tasks[2].Params[0].Value.StringVal, tt.wantDeployParam)
+		})
+	}
+}
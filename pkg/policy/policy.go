@@ -3,6 +3,10 @@ package policy
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gobwas/glob"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
@@ -41,14 +45,26 @@ func (p *Policy) checkAllowed(ctx context.Context, tType triggertype.Trigger) (R
 	var sType []string
 	switch tType {
 	// NOTE: This make /retest /ok-to-test /test bound to the same policy, which is fine from a security standpoint but maybe we want to refine this in the future.
-	case triggertype.OkToTest, triggertype.Retest:
+	// /hold and /unhold are reviewer commands and follow the same policy.
+	case triggertype.OkToTest, triggertype.Retest, triggertype.Hold:
 		sType = settings.Policy.OkToTest
 	// apply the same policy for PullRequest and comment
 	// we don't support comments on PRs yet but if we do on the future we will need our own policy
 	case triggertype.PullRequest, triggertype.Comment, triggertype.PullRequestLabeled, triggertype.PullRequestClosed:
 		sType = settings.Policy.PullRequest
+	// a push is only restricted when a rule of the push policy matches its branch
+	case triggertype.Push:
+		var matched bool
+		if sType, matched = p.pushPolicy(); !matched {
+			return ResultNotSet, ""
+		}
+	case triggertype.Cancel:
+		if settings.Policy.Cancel == nil {
+			return ResultNotSet, ""
+		}
+		sType = settings.Policy.Cancel
 	// NOTE: not supported yet, will imp if it gets requested and reasonable to implement
-	case triggertype.Push, triggertype.Cancel, triggertype.CheckSuiteRerequested, triggertype.CheckRunRerequested, triggertype.Incoming:
+	case triggertype.CheckSuiteRerequested, triggertype.CheckRunRerequested, triggertype.Incoming:
 		return ResultNotSet, ""
 	default:
 		return ResultNotSet, ""
@@ -73,6 +89,11 @@ func (p *Policy) checkAllowed(ctx context.Context, tType triggertype.Trigger) (R
 		return ResultDisallowed, "policy set and empty with no groups"
 	}
 
+	// the push and cancel policies list users as well as teams
+	if (tType == triggertype.Push || tType == triggertype.Cancel) && slices.Contains(sType, p.Event.Sender) {
+		return ResultAllowed, ""
+	}
+
 	allowed, reason := p.VCX.CheckPolicyAllowing(ctx, p.Event, sType)
 	if allowed {
 		return ResultAllowed, ""
@@ -80,6 +101,29 @@ func (p *Policy) checkAllowed(ctx context.Context, tType triggertype.Trigger) (R
 	return ResultDisallowed, fmt.Sprintf("policy check: %s, %s", string(tType), reason)
 }
 
+// pushPolicy returns the teams and users allowed by the push policy rules
+// matching the branch of the event, and whether any rule has matched.
+func (p *Policy) pushPolicy() ([]string, bool) {
+	branch := strings.TrimPrefix(p.Event.BaseBranch, "refs/heads/")
+	allowed := []string{}
+	matched := false
+	for _, rule := range p.Repository.Spec.Settings.Policy.Push {
+		for _, pattern := range rule.Branches {
+			g, err := glob.Compile(pattern)
+			if err != nil {
+				p.Logger.Warnf("policy check: skipping invalid branch pattern %q of the push policy: %v", pattern, err)
+				continue
+			}
+			if g.Match(branch) || g.Match(p.Event.BaseBranch) {
+				matched = true
+				allowed = append(allowed, rule.Allowed...)
+				break
+			}
+		}
+	}
+	return allowed, matched
+}
+
 // IsAllowed determines if a given event trigger is permitted based on repository policy settings and OWNERS file.
 //
 // The function first checks if a policy is set for the repository and if the event trigger type is allowed by the policy.
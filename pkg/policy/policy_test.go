@@ -32,6 +32,13 @@ func TestPolicy_IsAllowed(t *testing.T) {
 	eventWithSender := info.NewEvent()
 	eventWithSender.Sender = senderName
 
+	pushEvent := info.NewEvent()
+	pushEvent.Sender = senderName
+	pushEvent.BaseBranch = "refs/heads/release-1.0"
+	pushPolicy := &v1alpha1.Policy{Push: []v1alpha1.PushPolicy{
+		{Branches: []string{"main", "release-*"}, Allowed: []string{"release-team"}},
+	}}
+
 	type fields struct {
 		repository *v1alpha1.Repository
 		event      *info.Event
@@ -83,6 +90,90 @@ func TestPolicy_IsAllowed(t *testing.T) {
 			},
 			want: ResultNotSet,
 		},
+		{
+			name: "notset/push to a branch without push policy",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{Push: []v1alpha1.PushPolicy{
+					{Branches: []string{"main"}, Allowed: []string{"release-team"}},
+				}}),
+				event: pushEvent,
+			},
+			args: args{
+				tType: triggertype.Push,
+			},
+			want: ResultNotSet,
+		},
+		{
+			name: "allowed/push by a member of the team",
+			fields: fields{
+				repository: newRepoWithPolicy(pushPolicy),
+				event:      pushEvent,
+			},
+			args: args{
+				tType: triggertype.Push,
+			},
+			vcsReplyAllowed: true,
+			want:            ResultAllowed,
+		},
+		{
+			name: "allowed/push by a listed user",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{Push: []v1alpha1.PushPolicy{
+					{Branches: []string{"release-*"}, Allowed: []string{senderName}},
+				}}),
+				event: pushEvent,
+			},
+			args: args{
+				tType: triggertype.Push,
+			},
+			want: ResultAllowed,
+		},
+		{
+			name: "disallowed/push by a user not in the team",
+			fields: fields{
+				repository: newRepoWithPolicy(pushPolicy),
+				event:      pushEvent,
+			},
+			args: args{
+				tType: triggertype.Push,
+			},
+			want:                 ResultDisallowed,
+			expectedLogsSnippets: []string{"policy check: push, policy disallowing"},
+		},
+		{
+			name: "notset/cancel without cancel policy",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"ok-to-test"}}),
+				event:      eventWithSender,
+			},
+			args: args{
+				tType: triggertype.Cancel,
+			},
+			want: ResultNotSet,
+		},
+		{
+			name: "allowed/cancel by a listed user",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{Cancel: []string{senderName}}),
+				event:      eventWithSender,
+			},
+			args: args{
+				tType: triggertype.Cancel,
+			},
+			want: ResultAllowed,
+		},
+		{
+			name: "disallowed/cancel by a user not in the team",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{Cancel: []string{"maintainers"}}),
+				event:      eventWithSender,
+			},
+			args: args{
+				tType: triggertype.Cancel,
+			},
+			want:                 ResultDisallowed,
+			expectedLogsSnippets: []string{"policy check: cancel, policy disallowing"},
+		},
 		{
 			name: "allowed/allowing member for pull request",
 			fields: fields{
@@ -217,9 +308,11 @@ func TestPolicy_IsAllowed(t *testing.T) {
 			if tt.fields.event == nil {
 				tt.fields.event = info.NewEvent()
 			}
+			// the OWNERS checks are cached on the event, the test cases share it
+			event := *tt.fields.event
 			p := &Policy{
 				Repository:   tt.fields.repository,
-				Event:        tt.fields.event,
+				Event:        &event,
 				VCX:          vcx,
 				Logger:       logger,
 				EventEmitter: events.NewEventEmitter(stdata.Kube, logger),
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ktrysmt/go-bitbucket"
 	"github.com/mitchellh/mapstructure"
@@ -22,6 +23,11 @@ func (v *Provider) IsAllowed(ctx context.Context, event *info.Event) (bool, erro
 		return true, nil
 	}
 
+	// a revoked or expired approval doesn't let the previous /ok-to-test run the CI
+	if !acl.RememberOkToTest(v.repo, event, v.pacInfo, time.Now()) {
+		return false, nil
+	}
+
 	// Check then from comment if there is a approved user that has done a /ok-to-test
 	return v.checkOkToTestCommentFromApprovedMember(ctx, event)
 }
@@ -43,23 +49,15 @@ func (v *Provider) isWorkspaceMember(event *info.Event) (bool, error) {
 // IsAllowedOwnersFile get the owner files (OWNERS, OWNERS_ALIASES) from main branch
 // and check if we have explicitly allowed the user in there.
 func (v *Provider) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (bool, error) {
-	ownerContent, err := v.GetFileInsideRepo(ctx, event, "OWNERS", event.DefaultBranch)
-	if err != nil {
-		if strings.Contains(err.Error(), "cannot find") {
+	getFile := func(ctx context.Context, path string) (string, error) {
+		content, err := v.GetFileInsideRepo(ctx, event, path, event.DefaultBranch)
+		if err != nil && strings.Contains(err.Error(), "cannot find") {
 			// no owner file, skipping
-			return false, nil
-		}
-		return false, err
-	}
-	// If there is OWNERS file, check for OWNERS_ALIASES
-	ownerAliasesContent, err := v.GetFileInsideRepo(ctx, event, "OWNERS_ALIASES", event.DefaultBranch)
-	if err != nil {
-		if !strings.Contains(err.Error(), "cannot find") {
-			return false, err
+			return "", nil
 		}
+		return content, err
 	}
-
-	return acl.UserInOwnerFile(ownerContent, ownerAliasesContent, event.AccountID)
+	return acl.UserInEventOwnersFiles(ctx, getFile, v.GetFiles, event, event.AccountID)
 }
 
 func (v *Provider) checkMember(ctx context.Context, event *info.Event) (bool, error) {
@@ -3,6 +3,8 @@ package bitbucketcloud
 import (
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	bbcloudtest "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud/test"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud/types"
@@ -16,11 +18,12 @@ func TestIsAllowed(t *testing.T) {
 		filescontents    map[string]string
 	}
 	tests := []struct {
-		name    string
-		event   *info.Event
-		fields  fields
-		want    bool
-		wantErr bool
+		name     string
+		event    *info.Event
+		fields   fields
+		approval string
+		want     bool
+		wantErr  bool
 	}{
 		{
 			name:  "allowed/user is owner",
@@ -168,6 +171,51 @@ func TestIsAllowed(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name:     "disallowed/from a comment owner with a revoked approval",
+			event:    bbcloudtest.MakeEvent(&info.Event{Sender: "NotAllowedAtFirst"}),
+			approval: `{"approver":"Owner","revoked_by":"Owner","revoked_at":"2025-01-01T11:00:00Z"}`,
+			fields: fields{
+				workspaceMembers: []types.Member{
+					{
+						User: types.User{
+							AccountID: "Owner",
+						},
+					},
+				},
+				comments: []types.Comment{
+					{
+						Content: types.Content{Raw: "/ok-to-test"},
+						User: types.User{
+							AccountID: "Owner",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name:  "disallowed/revoke-ok-to-test not allowed by a comment owner",
+			event: bbcloudtest.MakeEvent(&info.Event{Sender: "NotAllowedAtFirst", TriggerComment: "/revoke-ok-to-test"}),
+			fields: fields{
+				workspaceMembers: []types.Member{
+					{
+						User: types.User{
+							AccountID: "Owner",
+						},
+					},
+				},
+				comments: []types.Comment{
+					{
+						Content: types.Content{Raw: "/ok-to-test"},
+						User: types.User{
+							AccountID: "Owner",
+						},
+					},
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -179,7 +227,11 @@ func TestIsAllowed(t *testing.T) {
 			bbcloudtest.MuxComments(t, mux, tt.event, tt.fields.comments)
 			bbcloudtest.MuxFiles(t, mux, tt.event, tt.fields.filescontents, "")
 
-			v := &Provider{bbClient: bbclient}
+			repo := &v1alpha1.Repository{}
+			if tt.approval != "" {
+				repo.Annotations = map[string]string{acl.OkToTestApprovalAnnotation(tt.event.PullRequestNumber): tt.approval}
+			}
+			v := &Provider{bbClient: bbclient, repo: repo, pacInfo: &info.PacOpts{}}
 			got, err := v.IsAllowed(ctx, tt.event)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Provider.IsAllowed() error = %v, wantErr %v", err, tt.wantErr)
@@ -225,6 +225,29 @@ func (v *Provider) SetClient(_ context.Context, run *params.Run, event *info.Eve
 	return nil
 }
 
+// IsCommitOnBranch checks the commit is reachable from the branch, ie: for an
+// incoming webhook on a given SHA.
+func (v *Provider) IsCommitOnBranch(_ context.Context, event *info.Event, sha, branch string) (bool, error) {
+	page := 1
+	// the commits of sha which are not on the branch
+	response, err := v.Client().Repositories.Commits.GetCommits(&bitbucket.CommitsOptions{
+		Owner:       event.Organization,
+		RepoSlug:    event.Repository,
+		Branchortag: sha,
+		Exclude:     branch,
+		Page:        &page,
+	})
+	if err != nil {
+		return false, fmt.Errorf("cannot compare %s with the branch %s: %w", sha, branch, err)
+	}
+	commits, ok := response.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("cannot convert commits response")
+	}
+	values, _ := commits["values"].([]any)
+	return len(values) == 0, nil
+}
+
 func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 	// If we don't have a SHA, get it from the branch first
 	sha := event.SHA
@@ -244,6 +267,11 @@ func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 		} else {
 			return fmt.Errorf("cannot extract commit hash from branch %s", event.HeadBranch)
 		}
+	} else if sha == "" && event.PullRequestNumber != 0 {
+		if err := v.getPullRequest(event); err != nil {
+			return err
+		}
+		sha = event.SHA
 	}
 
 	// Use GetCommit API for direct single-commit fetch (no pagination)
@@ -297,6 +325,34 @@ func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 	return nil
 }
 
+// getPullRequest populates the event with the head and base of the pull
+// request, ie: for an incoming webhook on a pull request.
+func (v *Provider) getPullRequest(event *info.Event) error {
+	response, err := v.Client().Repositories.PullRequests.Get(&bitbucket.PullRequestsOptions{
+		Owner:    event.Organization,
+		RepoSlug: event.Repository,
+		ID:       strconv.Itoa(event.PullRequestNumber),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get pull request %d: %w", event.PullRequestNumber, err)
+	}
+	prMap, ok := response.(map[string]any)
+	if !ok {
+		return fmt.Errorf("cannot convert pull request response")
+	}
+	pr := &types.PullRequest{}
+	if err := mapstructure.Decode(prMap, pr); err != nil {
+		return err
+	}
+	event.SHA = pr.Source.Commit.Hash
+	event.HeadBranch = pr.Source.Branch.Name
+	event.BaseBranch = pr.Destination.Branch.Name
+	event.HeadURL = pr.Source.Repository.Links.HTML.HRef
+	event.BaseURL = pr.Destination.Repository.Links.HTML.HRef
+	event.PullRequestTitle = pr.Title
+	return nil
+}
+
 func (v *Provider) concatAllYamlFiles(objects []bitbucket.RepositoryFile, event *info.Event) (string, error) {
 	var allTemplates string
 
@@ -55,15 +55,18 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 		}
 
 		if provider.Valid(event, pullRequestsCommentCreated) {
-			if provider.IsTestRetestComment(e.Comment.Content.Raw) {
+			if provider.IsTestRetestComment(e.Comment.Content.Raw) || provider.IsRetestFailedComment(e.Comment.Content.Raw) {
 				return setLoggerAndProceed(true, "", nil)
 			}
-			if provider.IsOkToTestComment(e.Comment.Content.Raw) {
+			if provider.IsOkToTestComment(e.Comment.Content.Raw) || provider.IsRevokeOkToTestComment(e.Comment.Content.Raw) {
 				return setLoggerAndProceed(true, "", nil)
 			}
 			if provider.IsCancelComment(e.Comment.Content.Raw) {
 				return setLoggerAndProceed(true, "", nil)
 			}
+			if provider.IsCommandComment(e.Comment.Content.Raw) {
+				return setLoggerAndProceed(true, "", nil)
+			}
 		}
 		return setLoggerAndProceed(false, fmt.Sprintf("not a valid gitops comment: \"%s\"", event), nil)
 
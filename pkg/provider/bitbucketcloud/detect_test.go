@@ -104,6 +104,19 @@ func TestProvider_Detect(t *testing.T) {
 			isBC:       true,
 			processReq: true,
 		},
+		{
+			name: "revoke-ok-to-test comment",
+			event: types.PullRequestEvent{
+				Comment: types.Comment{
+					Content: types.Content{
+						Raw: "/revoke-ok-to-test",
+					},
+				},
+			},
+			eventType:  "pullrequest:comment_created",
+			isBC:       true,
+			processReq: true,
+		},
 		{
 			name: "cancel comment",
 			event: types.PullRequestEvent{
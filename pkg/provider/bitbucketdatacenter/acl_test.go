@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	bbv1test "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketdatacenter/test"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketdatacenter/types"
@@ -46,6 +48,7 @@ func TestIsAllowed(t *testing.T) {
 		name          string
 		event         *info.Event
 		fields        fields
+		approval      string
 		isAllowed     bool
 		wantErrSubstr string
 	}{
@@ -93,6 +96,66 @@ func TestIsAllowed(t *testing.T) {
 			},
 			isAllowed: true,
 		},
+		{
+			name: "disallowed/from a comment owner with a revoked approval",
+			event: bbv1test.MakeEvent(&info.Event{
+				AccountID: fmt.Sprintf("%d", otherAccountID),
+				Sender:    "NotAllowedAtFirst",
+			}),
+			approval: `{"approver":"member","revoked_by":"member","revoked_at":"2025-01-01T11:00:00Z"}`,
+			fields: fields{
+				projectMembers: []*bbv1test.UserPermission{
+					{
+						User: types.User{
+							Slug: "member",
+						},
+					},
+				},
+				activities: []*bbv1test.Activity{
+					{
+						Action: "COMMENTED",
+						Comment: types.ActivityComment{
+							Text: "/ok-to-test",
+							Author: types.User{
+								Slug: "member",
+							},
+						},
+					},
+				},
+				pullRequestNumber: 1,
+			},
+			isAllowed: false,
+		},
+		{
+			name: "disallowed/revoke-ok-to-test not allowed by a comment owner",
+			event: bbv1test.MakeEvent(&info.Event{
+				AccountID:      fmt.Sprintf("%d", otherAccountID),
+				Sender:         "NotAllowedAtFirst",
+				TriggerComment: "/revoke-ok-to-test",
+			}),
+			fields: fields{
+				projectMembers: []*bbv1test.UserPermission{
+					{
+						User: types.User{
+							Slug: "member",
+						},
+					},
+				},
+				activities: []*bbv1test.Activity{
+					{
+						Action: "COMMENTED",
+						Comment: types.ActivityComment{
+							Text: "/ok-to-test",
+							Author: types.User{
+								Slug: "member",
+							},
+						},
+					},
+				},
+				pullRequestNumber: 1,
+			},
+			isAllowed: false,
+		},
 		{
 			name: "allowed/from owner file who is not part of workspace",
 			event: bbv1test.MakeEvent(&info.Event{
@@ -283,12 +346,18 @@ func TestIsAllowed(t *testing.T) {
 			bbv1test.MuxPullRequestActivities(t, mux, tt.event, tt.fields.pullRequestNumber, tt.fields.activities)
 			bbv1test.MuxFiles(t, mux, tt.event, tt.fields.defaultBranchLatestCommit, "", tt.fields.filescontents, false)
 
+			repo := &v1alpha1.Repository{}
+			if tt.approval != "" {
+				repo.Annotations = map[string]string{acl.OkToTestApprovalAnnotation(tt.event.PullRequestNumber): tt.approval}
+			}
 			v := &Provider{
 				baseURL:                   tURL,
 				client:                    client,
 				defaultBranchLatestCommit: tt.fields.defaultBranchLatestCommit,
 				pullRequestNumber:         tt.fields.pullRequestNumber,
 				projectKey:                tt.event.Organization,
+				repo:                      repo,
+				pacInfo:                   &info.PacOpts{},
 			}
 
 			got, err := v.IsAllowed(ctx, tt.event)
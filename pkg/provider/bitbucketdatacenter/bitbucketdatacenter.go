@@ -2,6 +2,7 @@ package bitbucketdatacenter
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -323,8 +324,44 @@ func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.E
 	return nil
 }
 
+// IsCommitOnBranch checks the commit is reachable from the branch, ie: for an
+// incoming webhook on a given SHA.
+func (v *Provider) IsCommitOnBranch(ctx context.Context, event *info.Event, sha, branch string) (bool, error) {
+	// the commits of sha which are not on the branch
+	path := fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s/commits?since=%s&until=%s&limit=1",
+		url.PathEscape(event.Organization), url.PathEscape(event.Repository), url.QueryEscape(branch), url.QueryEscape(sha))
+	res, err := v.Client().Do(ctx, &scm.Request{Method: http.MethodGet, Path: path})
+	if err != nil {
+		return false, fmt.Errorf("cannot compare %s with the branch %s: %w", sha, branch, err)
+	}
+	defer res.Body.Close()
+	if res.Status != http.StatusOK {
+		return false, fmt.Errorf("cannot compare %s with the branch %s: status code %d", sha, branch, res.Status)
+	}
+	commits := struct {
+		Values []json.RawMessage `json:"values"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&commits); err != nil {
+		return false, err
+	}
+	return len(commits.Values) == 0, nil
+}
+
 func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 	OrgAndRepo := fmt.Sprintf("%s/%s", event.Organization, event.Repository)
+	if event.SHA == "" && event.PullRequestNumber != 0 {
+		// an incoming webhook on a pull request, get the head of the pull request
+		pr, _, err := v.Client().PullRequests.Find(context.Background(), OrgAndRepo, event.PullRequestNumber)
+		if err != nil {
+			return fmt.Errorf("cannot get pull request %d: %w", event.PullRequestNumber, err)
+		}
+		event.SHA = pr.Head.Sha
+		event.HeadBranch = pr.Head.Ref
+		event.BaseBranch = pr.Base.Ref
+		event.HeadURL = pr.Head.Repo.Link
+		event.BaseURL = pr.Base.Repo.Link
+		event.PullRequestTitle = pr.Title
+	}
 	commit, _, err := v.Client().Git.FindCommit(context.Background(), OrgAndRepo, event.SHA)
 	if err != nil {
 		return err
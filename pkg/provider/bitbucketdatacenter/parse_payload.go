@@ -112,6 +112,10 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 			processedEvent.EventType = triggertype.PullRequest.String()
 		} else if provider.Valid(eventType, []string{"pr:comment:added", "pr:comment:edited"}) {
 			switch {
+			case provider.IsRetestFailedComment(e.Comment.Text):
+				processedEvent.TriggerTarget = triggertype.PullRequest
+				processedEvent.EventType = "retest-failed-comment"
+				processedEvent.TargetTestPipelineRun = provider.GetPipelineRunFromRetestFailedComment(e.Comment.Text)
 			case provider.IsTestRetestComment(e.Comment.Text):
 				processedEvent.TriggerTarget = triggertype.PullRequest
 				if strings.Contains(e.Comment.Text, "/test") {
@@ -123,11 +127,23 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 			case provider.IsOkToTestComment(e.Comment.Text):
 				processedEvent.TriggerTarget = triggertype.PullRequest
 				processedEvent.EventType = "ok-to-test-comment"
+			case provider.IsRevokeOkToTestComment(e.Comment.Text):
+				processedEvent.TriggerTarget = triggertype.PullRequest
+				processedEvent.EventType = "revoke-ok-to-test-comment"
 			case provider.IsCancelComment(e.Comment.Text):
 				processedEvent.TriggerTarget = triggertype.PullRequest
 				processedEvent.EventType = "cancel-comment"
 				processedEvent.CancelPipelineRuns = true
 				processedEvent.TargetCancelPipelineRun = provider.GetPipelineRunFromCancelComment(e.Comment.Text)
+			case provider.IsHoldComment(e.Comment.Text):
+				processedEvent.TriggerTarget = triggertype.PullRequest
+				processedEvent.EventType = "hold-comment"
+				if provider.IsUnholdComment(e.Comment.Text) {
+					processedEvent.EventType = "unhold-comment"
+				}
+			case provider.IsCommandComment(e.Comment.Text):
+				processedEvent.TriggerTarget = triggertype.PullRequest
+				processedEvent.EventType = "no-ops-comment"
 			}
 			processedEvent.TriggerComment = e.Comment.Text
 		}
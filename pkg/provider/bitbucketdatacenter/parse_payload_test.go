@@ -574,6 +574,7 @@ func TestParsePayload(t *testing.T) {
 		rawStr                  string
 		targetPipelinerun       string
 		canceltargetPipelinerun string
+		wantEventType           string
 	}{
 		{
 			name:          "bad/invalid event type",
@@ -660,6 +661,20 @@ func TestParsePayload(t *testing.T) {
 			payloadEvent: bbv1test.MakePREvent(ev1, "/cancel"),
 			expEvent:     ev1,
 		},
+		{
+			name:          "good/comment hold",
+			eventType:     "pr:comment:added",
+			payloadEvent:  bbv1test.MakePREvent(ev1, "/hold\ndon't /unhold it before the release"),
+			expEvent:      ev1,
+			wantEventType: "hold-comment",
+		},
+		{
+			name:          "good/comment unhold",
+			eventType:     "pr:comment:added",
+			payloadEvent:  bbv1test.MakePREvent(ev1, "/unhold"),
+			expEvent:      ev1,
+			wantEventType: "unhold-comment",
+		},
 		{
 			name:      "branch/deleted with zero hash",
 			eventType: "repo:refs_changed",
@@ -720,6 +735,9 @@ func TestParsePayload(t *testing.T) {
 			if tt.canceltargetPipelinerun != "" {
 				assert.Equal(t, got.TargetCancelPipelineRun, tt.canceltargetPipelinerun)
 			}
+			if tt.wantEventType != "" {
+				assert.Equal(t, got.EventType, tt.wantEventType)
+			}
 		})
 	}
 }
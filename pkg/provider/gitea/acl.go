@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v3"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
@@ -112,14 +113,14 @@ func (v *Provider) aclAllowedOkToTestFromAnOwner(ctx context.Context, event *inf
 	case *forgejostructs.IssueCommentPayload:
 		// if we don't need to check old comments, then on issue comment we
 		// need to check if comment have /ok-to-test and is from allowed user
-		if !v.pacInfo.RememberOKToTest {
+		if !v.pacInfo.RememberOKToTest || !acl.RememberOkToTest(v.repo, revent, v.pacInfo, time.Now()) {
 			return v.aclAllowedOkToTestCurrentComment(ctx, revent, event.Comment.ID)
 		}
 		revent.URL = event.Issue.URL
 	case *forgejostructs.PullRequestPayload:
 		// if we don't need to check old comments, then on push event we don't need
 		// to check anything for the non-allowed user
-		if !v.pacInfo.RememberOKToTest {
+		if !v.pacInfo.RememberOKToTest || !acl.RememberOkToTest(v.repo, revent, v.pacInfo, time.Now()) {
 			return false, nil
 		}
 		revent.URL = event.PullRequest.HTMLURL
@@ -183,28 +184,19 @@ func (v *Provider) aclCheckAll(ctx context.Context, rev *info.Event) (bool, erro
 }
 
 // IsAllowedOwnersFile get the OWNERS files from main branch and check if we have
-// explicitly allowed the user in there.
+// explicitly allowed the user in there, for every changed file.
 func (v *Provider) IsAllowedOwnersFile(ctx context.Context, rev *info.Event) (bool, error) {
-	// If we have a OWNERS and OWNERS_ALIASE files in the defaultBranch (ie: master) then
+	// If we have OWNERS and OWNERS_ALIASES files in the defaultBranch (ie: master) then
 	// parse them and check if sender is in there.
-	ownerContent, err := v.getFileFromDefaultBranch(ctx, "OWNERS", rev)
-	if err != nil {
-		if strings.Contains(err.Error(), "cannot find") {
+	getFile := func(ctx context.Context, path string) (string, error) {
+		content, err := v.getFileFromDefaultBranch(ctx, path, rev)
+		if err != nil && strings.Contains(err.Error(), "cannot find") {
 			// no owner file, skipping
-			return false, nil
-		}
-		return false, err
-	}
-	// If there is OWNERS file, check for OWNERS_ALIASES. OWNERS can exist without OWNERS_ALIASES.
-	// OWNERS_ALIASES can't exist without OWNERS.
-	ownerAliasesContent, err := v.getFileFromDefaultBranch(ctx, "OWNERS_ALIASES", rev)
-	if err != nil {
-		if !strings.Contains(err.Error(), "cannot find") {
-			return false, err
+			return "", nil
 		}
+		return content, err
 	}
-
-	return acl.UserInOwnerFile(ownerContent, ownerAliasesContent, rev.Sender)
+	return acl.UserInEventOwnersFiles(ctx, getFile, v.GetFiles, rev, rev.Sender)
 }
 
 func (v *Provider) checkSenderRepoMembership(_ context.Context, runevent *info.Event) (bool, error) {
@@ -12,6 +12,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/forgejostructs"
 	tgitea "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
 	"go.uber.org/zap"
@@ -277,9 +278,14 @@ func TestOkToTestComment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			observer, _ := zapobserver.New(zap.InfoLevel)
 			logger := zap.New(observer).Sugar()
-			tt.runevent.TriggerTarget = "ok-to-test-comment"
+			tt.runevent.TriggerTarget = triggertype.PullRequest
+			tt.runevent.PullRequestNumber = 1
 			fakeclient, mux, teardown := tgitea.Setup(t)
 			defer teardown()
+			mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/pulls/1/files", tt.runevent.Organization,
+				tt.runevent.Repository), func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, "[]")
+			})
 			mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/issues/1/comments", tt.runevent.Organization,
 				tt.runevent.Repository),
 				func(rw http.ResponseWriter,
@@ -383,6 +389,12 @@ func TestAclCheckAll(t *testing.T) {
 					rw.WriteHeader(http.StatusNoContent)
 				})
 			}
+			tt.runevent.TriggerTarget = triggertype.PullRequest
+			tt.runevent.PullRequestNumber = 1
+			mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/pulls/1/files", tt.runevent.Organization,
+				tt.runevent.Repository), func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, "[]")
+			})
 			if tt.allowedRules.ownerFile {
 				url := fmt.Sprintf("/repos/%s/%s/contents/OWNERS", tt.runevent.Organization, tt.runevent.Repository)
 				mux.HandleFunc(url, func(rw http.ResponseWriter, r *http.Request) {
@@ -65,15 +65,18 @@ func detectTriggerTypeFromPayload(ghEventType string, eventInt any) (triggertype
 		if event.Action == "created" &&
 			event.Issue.PullRequest != nil &&
 			event.Issue.State == "open" {
-			if provider.IsTestRetestComment(event.Comment.Body) {
+			if provider.IsTestRetestComment(event.Comment.Body) || provider.IsRetestFailedComment(event.Comment.Body) {
 				return triggertype.Retest, ""
 			}
-			if provider.IsOkToTestComment(event.Comment.Body) {
+			if provider.IsOkToTestComment(event.Comment.Body) || provider.IsRevokeOkToTestComment(event.Comment.Body) {
 				return triggertype.OkToTest, ""
 			}
 			if provider.IsCancelComment(event.Comment.Body) {
 				return triggertype.Cancel, ""
 			}
+			if provider.IsHoldComment(event.Comment.Body) {
+				return triggertype.Hold, ""
+			}
 			// this ignores the comment if it is not a PAC gitops comment and not return an error
 			return triggertype.Comment, ""
 		}
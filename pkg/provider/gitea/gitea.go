@@ -455,6 +455,21 @@ func (v *Provider) GetFileInsideRepo(_ context.Context, runevent *info.Event, pa
 	return string(decoded), nil
 }
 
+// IsCommitOnBranch checks the commit is reachable from the branch, ie: for an
+// incoming webhook on a given SHA.
+func (v *Provider) IsCommitOnBranch(_ context.Context, runevent *info.Event, sha, branch string) (bool, error) {
+	if v.giteaClient == nil {
+		return false, fmt.Errorf("no gitea client has been initialized, " +
+			"exiting... (hint: did you forget setting a secret on your repo?)")
+	}
+	// the commits of sha which are not on the branch
+	compare, _, err := v.Client().CompareCommits(runevent.Organization, runevent.Repository, branch, sha)
+	if err != nil {
+		return false, fmt.Errorf("cannot compare %s with the branch %s: %w", sha, branch, err)
+	}
+	return compare.TotalCommits == 0, nil
+}
+
 func (v *Provider) GetCommitInfo(_ context.Context, runevent *info.Event) error {
 	if v.giteaClient == nil {
 		return fmt.Errorf("no gitea client has been initialized, " +
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v81/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
@@ -49,25 +50,43 @@ func (v *Provider) CheckPolicyAllowing(ctx context.Context, event *info.Event, a
 }
 
 // IsAllowedOwnersFile get the owner files (OWNERS, OWNERS_ALIASES) from main branch
-// and check if we have explicitly allowed the user in there.
+// and check if we have explicitly allowed the user in there, for every changed file.
 func (v *Provider) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (bool, error) {
-	ownerContent, err := v.getFileFromDefaultBranch(ctx, "OWNERS", event)
-	if err != nil {
-		if strings.Contains(err.Error(), "cannot find") {
+	getFile := func(ctx context.Context, path string) (string, error) {
+		content, err := v.getFileFromDefaultBranch(ctx, path, event)
+		if err != nil && strings.Contains(err.Error(), "cannot find") {
 			// no owner file, skipping
-			return false, nil
+			return "", nil
 		}
-		return false, err
+		return content, err
 	}
-	// If there is OWNERS file, check for OWNERS_ALIASES
-	ownerAliasesContent, err := v.getFileFromDefaultBranch(ctx, "OWNERS_ALIASES", event)
-	if err != nil {
-		if !strings.Contains(err.Error(), "cannot find") {
-			return false, err
-		}
+	allowed, err := acl.UserInEventOwnersFiles(ctx, getFile, v.GetFiles, event, event.Sender)
+	if err != nil || allowed || v.repo == nil || v.repo.Spec.Settings == nil || !v.repo.Spec.Settings.CodeOwners {
+		return allowed, err
 	}
+	return acl.UserInEventCodeOwnersFile(ctx, getFile, v.GetFiles, event, event.Sender, func(ctx context.Context, team string) (bool, error) {
+		return v.isTeamMember(ctx, team, event.Sender)
+	})
+}
 
-	return acl.UserInOwnerFile(ownerContent, ownerAliasesContent, event.Sender)
+// isTeamMember checks if the user is an active member of a team mentioned as
+// org/team-slug in a CODEOWNERS file.
+func (v *Provider) isTeamMember(ctx context.Context, team, user string) (bool, error) {
+	org, slug, ok := strings.Cut(team, "/")
+	if !ok {
+		// a user mention
+		return false, nil
+	}
+	membership, resp, err := wrapAPI(v, "get_team_membership_by_slug", func() (*github.Membership, *github.Response, error) {
+		return v.Client().Teams.GetTeamMembershipBySlug(ctx, org, slug, user)
+	})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error while getting team membership for user: %s in team: %s, error: %w", user, team, err)
+	}
+	return membership.GetState() == "active", nil
 }
 
 func (v *Provider) IsAllowed(ctx context.Context, event *info.Event) (bool, error) {
@@ -135,14 +154,14 @@ func (v *Provider) aclAllowedOkToTestFromAnOwner(ctx context.Context, event *inf
 	case *github.IssueCommentEvent:
 		// if we don't need to check old comments, then on issue comment we
 		// need to check if comment have /ok-to-test and is from allowed user
-		if !v.pacInfo.RememberOKToTest {
+		if !v.pacInfo.RememberOKToTest || !acl.RememberOkToTest(v.repo, revent, v.pacInfo, time.Now()) {
 			return v.aclAllowedOkToTestCurrentComment(ctx, revent, event.Comment.GetID())
 		}
 		revent.URL = event.Issue.GetPullRequestLinks().GetHTMLURL()
 	case *github.PullRequestEvent:
 		// if we don't need to check old comments, then on push event we don't need
 		// to check anything for the non-allowed user
-		if !v.pacInfo.RememberOKToTest {
+		if !v.pacInfo.RememberOKToTest || !acl.RememberOkToTest(v.repo, revent, v.pacInfo, time.Now()) {
 			return false, nil
 		}
 		revent.URL = event.GetPullRequest().GetHTMLURL()
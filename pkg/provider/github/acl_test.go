@@ -8,7 +8,9 @@ import (
 	"testing"
 
 	"github.com/google/go-github/v81/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
@@ -16,6 +18,7 @@ import (
 	"go.uber.org/zap"
 	zapobserver "go.uber.org/zap/zaptest/observer"
 	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	rtesting "knative.dev/pkg/reconciler/testing"
 )
 
@@ -110,6 +113,8 @@ func TestOkToTestComment(t *testing.T) {
 		allowed          bool
 		wantErr          bool
 		rememberOkToTest bool
+		okToTestTTL      string
+		annotations      map[string]string
 	}{
 		{
 			name:          "good issue comment event",
@@ -202,6 +207,62 @@ func TestOkToTestComment(t *testing.T) {
 			wantErr:          false,
 			rememberOkToTest: true,
 		},
+		{
+			name:          "revoked approval on pull request event",
+			commentsReply: `[{"body": "/ok-to-test", "user": {"login": "owner"}}]`,
+			runevent: info.Event{
+				Organization:      "owner",
+				Sender:            "nonowner",
+				PullRequestNumber: 1,
+				Event: &github.PullRequestEvent{
+					PullRequest: &github.PullRequest{
+						HTMLURL: github.Ptr("http://url.com/owner/repo/1"),
+					},
+				},
+			},
+			annotations: map[string]string{
+				acl.OkToTestApprovalAnnotation(1): `{"approver":"owner","revoked_by":"owner","revoked_at":"2025-01-01T11:00:00Z"}`,
+			},
+			allowed:          false,
+			rememberOkToTest: true,
+		},
+		{
+			name:          "unrecorded approval with ttl on pull request event",
+			commentsReply: `[{"body": "/ok-to-test", "user": {"login": "owner"}}]`,
+			runevent: info.Event{
+				Organization:      "owner",
+				Sender:            "nonowner",
+				PullRequestNumber: 1,
+				Event: &github.PullRequestEvent{
+					PullRequest: &github.PullRequest{
+						HTMLURL: github.Ptr("http://url.com/owner/repo/1"),
+					},
+				},
+			},
+			allowed:          false,
+			rememberOkToTest: true,
+			okToTestTTL:      "24h",
+		},
+		{
+			name:          "recorded approval with ttl on pull request event",
+			commentsReply: `[{"body": "/ok-to-test", "user": {"login": "owner"}}]`,
+			runevent: info.Event{
+				Organization:      "owner",
+				Sender:            "nonowner",
+				PullRequestNumber: 1,
+				Event: &github.PullRequestEvent{
+					PullRequest: &github.PullRequest{
+						HTMLURL: github.Ptr("http://url.com/owner/repo/1"),
+					},
+				},
+			},
+			annotations: map[string]string{
+				acl.OkToTestApprovalAnnotation(1): `{"approver":"owner","approved_at":"2025-01-01T10:00:00Z","expires_at":"2999-01-01T10:00:00Z"}`,
+			},
+			allowed:          true,
+			rememberOkToTest: true,
+			okToTestTTL:      "24h",
+		},
 		{
 			name:          "good issue comment event without remember",
 			commentsReply: `{"body": "/ok-to-test", "user": {"login": "owner"}}`,
@@ -314,15 +375,25 @@ func TestOkToTestComment(t *testing.T) {
 			mux.HandleFunc("/repos/owner/collaborators", func(rw http.ResponseWriter, _ *http.Request) {
 				fmt.Fprint(rw, "[]")
 			})
+			mux.HandleFunc("/repos/owner/pulls/1", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"head": {"repo": {"clone_url": "https://fork"}}, "base": {"repo": {"clone_url": "https://repo"}}}`)
+			})
+			mux.HandleFunc("/repos/owner/pulls/1/files", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, "[]")
+			})
 			ctx, _ := rtesting.SetupFakeContext(t)
 			observer, _ := zapobserver.New(zap.InfoLevel)
 			logger := zap.New(observer).Sugar()
-			repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{
-				Settings: &v1alpha1.Settings{},
-			}}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{},
+				},
+			}
 			pacopts := &info.PacOpts{
 				Settings: settings.Settings{
 					RememberOKToTest: tt.rememberOkToTest,
+					OkToTestTTL:      tt.okToTestTTL,
 				},
 			}
 			gprovider := Provider{
@@ -759,3 +830,78 @@ func TestIfPullRequestIsForSameRepoWithoutFork(t *testing.T) {
 		})
 	}
 }
+
+func TestIsAllowedOwnersFileCodeOwners(t *testing.T) {
+	tests := []struct {
+		name       string
+		codeOwners bool
+		sender     string
+		want       bool
+	}{
+		{
+			name:       "user listed in CODEOWNERS",
+			codeOwners: true,
+			sender:     "maintainer",
+			want:       true,
+		},
+		{
+			name:       "member of a team of CODEOWNERS",
+			codeOwners: true,
+			sender:     "team-member",
+			want:       true,
+		},
+		{
+			name:       "not in CODEOWNERS",
+			codeOwners: true,
+			sender:     "contributor",
+			want:       false,
+		},
+		{
+			name:       "owner of another path of CODEOWNERS",
+			codeOwners: true,
+			sender:     "docs-writer",
+			want:       false,
+		},
+		{
+			name:   "CODEOWNERS not enabled",
+			sender: "maintainer",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			mux.HandleFunc("/repos/org/repo/contents/.github/CODEOWNERS", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"name": "CODEOWNERS", "path": ".github/CODEOWNERS", "sha": "codeownerssha"}`)
+			})
+			mux.HandleFunc("/repos/org/repo/git/blobs/codeownerssha", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprintf(rw, `{"content": "%s"}`, base64.StdEncoding.EncodeToString([]byte("* @maintainer @org/maintainers\n/docs/ @docs-writer\n")))
+			})
+			mux.HandleFunc("/orgs/org/teams/maintainers/memberships/team-member", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"state": "active", "role": "member"}`)
+			})
+
+			ctx, _ := rtesting.SetupFakeContext(t)
+			gprovider := Provider{
+				ghClient:      fakeclient,
+				PaginedNumber: 1,
+				repo: &v1alpha1.Repository{
+					Spec: v1alpha1.RepositorySpec{
+						Settings: &v1alpha1.Settings{CodeOwners: tt.codeOwners},
+					},
+				},
+				cachedChangedFiles: &changedfiles.ChangedFiles{All: []string{"main.go"}},
+			}
+			event := &info.Event{
+				Organization:  "org",
+				Repository:    "repo",
+				DefaultBranch: "main",
+				Sender:        tt.sender,
+			}
+			got, err := gprovider.IsAllowedOwnersFile(ctx, event)
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
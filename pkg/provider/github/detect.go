@@ -76,15 +76,18 @@ func (v *Provider) detectTriggerTypeFromPayload(ghEventType string, eventInt any
 		if event.GetAction() == "created" &&
 			event.GetIssue().IsPullRequest() &&
 			event.GetIssue().GetState() == "open" {
-			if provider.IsTestRetestComment(event.GetComment().GetBody()) {
+			if provider.IsTestRetestComment(event.GetComment().GetBody()) || provider.IsRetestFailedComment(event.GetComment().GetBody()) {
 				return triggertype.Retest, ""
 			}
-			if provider.IsOkToTestComment(event.GetComment().GetBody()) {
+			if provider.IsOkToTestComment(event.GetComment().GetBody()) || provider.IsRevokeOkToTestComment(event.GetComment().GetBody()) {
 				return triggertype.OkToTest, ""
 			}
 			if provider.IsCancelComment(event.GetComment().GetBody()) {
 				return triggertype.Cancel, ""
 			}
+			if provider.IsHoldComment(event.GetComment().GetBody()) {
+				return triggertype.Hold, ""
+			}
 		}
 		return triggertype.Comment, ""
 	case *github.CheckSuiteEvent:
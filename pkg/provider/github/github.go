@@ -382,6 +382,19 @@ func (v *Provider) GetTektonDir(ctx context.Context, runevent *info.Event, path,
 	return v.concatAllYamlFiles(ctx, tektonDirObjects.Entries, runevent)
 }
 
+// IsCommitOnBranch checks the commit is reachable from the branch, ie: for an
+// incoming webhook on a given SHA.
+func (v *Provider) IsCommitOnBranch(ctx context.Context, runevent *info.Event, sha, branch string) (bool, error) {
+	comparison, _, err := wrapAPI(v, "compare_commits", func() (*github.CommitsComparison, *github.Response, error) {
+		return v.Client().Repositories.CompareCommits(ctx, runevent.Organization, runevent.Repository, branch, sha, &github.ListOptions{PerPage: 1})
+	})
+	if err != nil {
+		return false, err
+	}
+	// the commit is on the branch when it's not ahead of it
+	return comparison.GetAheadBy() == 0, nil
+}
+
 // GetCommitInfo get info (url and title) on a commit in runevent, this needs to
 // be run after sewebhook while we already matched a token.
 func (v *Provider) GetCommitInfo(ctx context.Context, runevent *info.Event) error {
@@ -402,6 +415,12 @@ func (v *Provider) GetCommitInfo(ctx context.Context, runevent *info.Event) erro
 			return err
 		}
 		sha = branchinfo.Commit.GetSHA()
+	} else if runevent.SHA == "" && runevent.PullRequestNumber != 0 {
+		// an incoming webhook on a pull request, get the head of the pull request
+		if _, err := v.getPullRequest(ctx, runevent); err != nil {
+			return err
+		}
+		sha = runevent.SHA
 	}
 	var err error
 
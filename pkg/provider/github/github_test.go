@@ -797,6 +797,52 @@ func TestGithubGetCommitInfo(t *testing.T) {
 	}
 }
 
+func TestGithubGetCommitInfoPullRequest(t *testing.T) {
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	mux.HandleFunc("/repos/owner/repository/pulls/42", func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, `{"title":"my pr","head":{"sha":"headsha","ref":"feature"},"base":{"ref":"main"}}`)
+	})
+	mux.HandleFunc("/repos/owner/repository/git/commits/headsha", func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, `{"sha":"headsha","html_url":"https://git.provider/commit/headsha","message":"My beautiful pony"}`)
+	})
+	ctx, _ := rtesting.SetupFakeContext(t)
+	provider := &Provider{ghClient: fakeclient}
+	event := &info.Event{
+		Organization:      "owner",
+		Repository:        "repository",
+		EventType:         triggertype.Incoming.String(),
+		PullRequestNumber: 42,
+	}
+	assert.NilError(t, provider.GetCommitInfo(ctx, event))
+	assert.Equal(t, event.SHA, "headsha")
+	assert.Equal(t, event.SHATitle, "My beautiful pony")
+	assert.Equal(t, event.HeadBranch, "feature")
+	assert.Equal(t, event.BaseBranch, "main")
+	assert.Equal(t, event.EventType, triggertype.Incoming.String())
+}
+
+func TestGithubIsCommitOnBranch(t *testing.T) {
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	mux.HandleFunc("/repos/owner/repository/compare/main...onbranch", func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, `{"status":"behind","ahead_by":0,"behind_by":2}`)
+	})
+	mux.HandleFunc("/repos/owner/repository/compare/main...forkhead", func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, `{"status":"diverged","ahead_by":1,"behind_by":2}`)
+	})
+	ctx, _ := rtesting.SetupFakeContext(t)
+	provider := &Provider{ghClient: fakeclient}
+	event := &info.Event{Organization: "owner", Repository: "repository"}
+
+	onBranch, err := provider.IsCommitOnBranch(ctx, event, "onbranch", "main")
+	assert.NilError(t, err)
+	assert.Assert(t, onBranch)
+	onBranch, err = provider.IsCommitOnBranch(ctx, event, "forkhead", "main")
+	assert.NilError(t, err)
+	assert.Assert(t, !onBranch)
+}
+
 func TestGithubSetClient(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
@@ -12,18 +13,46 @@ import (
 
 // IsAllowedOwnersFile get the owner files (OWNERS, OWNERS_ALIASES) from main branch
 // and check if we have explicitly allowed the user in there.
-func (v *Provider) IsAllowedOwnersFile(_ context.Context, event *info.Event) (bool, error) {
-	ownerContent, _, _ := v.getObject("OWNERS", event.DefaultBranch, v.targetProjectID)
-	if string(ownerContent) == "" {
+func (v *Provider) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (bool, error) {
+	getFile := func(_ context.Context, path string) (string, error) {
+		content, resp, err := v.getObject(path, event.DefaultBranch, v.targetProjectID)
+		// the existence of the OWNERS files is not required, if we get "not found" continue
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return "", err
+		}
+		return string(content), nil
+	}
+	allowed, err := acl.UserInEventOwnersFiles(ctx, getFile, v.GetFiles, event, event.Sender)
+	if err != nil || allowed || v.repo == nil || v.repo.Spec.Settings == nil || !v.repo.Spec.Settings.CodeOwners {
+		return allowed, err
+	}
+	var userID int64
+	return acl.UserInEventCodeOwnersFile(ctx, getFile, v.GetFiles, event, event.Sender, func(_ context.Context, group string) (bool, error) {
+		if userID == 0 {
+			users, _, err := v.Client().Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(event.Sender)})
+			if err != nil {
+				return false, fmt.Errorf("cannot get the user %s: %w", event.Sender, err)
+			}
+			if len(users) == 0 {
+				return false, nil
+			}
+			userID = users[0].ID
+		}
+		return v.isGroupMember(group, userID)
+	})
+}
+
+// isGroupMember checks if the user is a member of a group mentioned in a
+// CODEOWNERS file. A mention which is not a group is not found.
+func (v *Provider) isGroupMember(group string, userID int64) (bool, error) {
+	member, resp, err := v.Client().GroupMembers.GetInheritedGroupMember(group, userID)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
 		return false, nil
 	}
-	// OWNERS_ALIASES file existence is not required, if we get "not found" continue
-	ownerAliasesContent, resp, err := v.getObject("OWNERS_ALIASES", event.DefaultBranch, v.targetProjectID)
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
-		return false, err
+	if err != nil {
+		return false, fmt.Errorf("cannot get the membership of user %d in group %s: %w", userID, group, err)
 	}
-	allowed, _ := acl.UserInOwnerFile(string(ownerContent), string(ownerAliasesContent), event.Sender)
-	return allowed, nil
+	return member.ID == userID, nil
 }
 
 func (v *Provider) checkMembership(ctx context.Context, event *info.Event, userid int64) bool {
@@ -61,11 +90,19 @@ func (v *Provider) checkOkToTestCommentFromApprovedMember(ctx context.Context, e
 			v.Logger.Debug("RememberOKToTest is disabled, skipping MergeRequest notes check as it is not needed")
 			return false, nil
 		}
+		if !acl.RememberOkToTest(v.repo, event, v.pacInfo, time.Now()) {
+			v.Logger.Debug("the /ok-to-test approval has been revoked or has expired, skipping MergeRequest notes check")
+			return false, nil
+		}
 	case *gitlab.MergeCommentEvent:
 		if !v.pacInfo.RememberOKToTest {
 			v.Logger.Debug("Event is a MergeCommentEvent and RememberOKToTest is disabled, checking current comment only")
 			return v.aclAllowedOkToTestCurrentComment(ctx, event, gitEvent.ObjectAttributes.ID)
 		}
+		if !acl.RememberOkToTest(v.repo, event, v.pacInfo, time.Now()) {
+			v.Logger.Debug("the /ok-to-test approval has been revoked or has expired, checking current comment only")
+			return v.aclAllowedOkToTestCurrentComment(ctx, event, gitEvent.ObjectAttributes.ID)
+		}
 	default:
 		v.Logger.Debug("Event is not a MergeEvent or MergeCommentEvent, skipping merge request notes check")
 		return false, nil
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
@@ -473,6 +475,84 @@ func TestIsAllowedOwnersFile(t *testing.T) {
 	}
 }
 
+func TestIsAllowedOwnersFileCodeOwners(t *testing.T) {
+	tests := []struct {
+		name       string
+		codeOwners bool
+		sender     string
+		want       bool
+	}{
+		{
+			name:       "user listed in CODEOWNERS",
+			codeOwners: true,
+			sender:     "maintainer",
+			want:       true,
+		},
+		{
+			name:       "member of a group of CODEOWNERS",
+			codeOwners: true,
+			sender:     "group-member",
+			want:       true,
+		},
+		{
+			name:       "not a member of the group of CODEOWNERS",
+			codeOwners: true,
+			sender:     "contributor",
+			want:       false,
+		},
+		{
+			name:       "owner of another path of CODEOWNERS",
+			codeOwners: true,
+			sender:     "docs-writer",
+			want:       false,
+		},
+		{
+			name:   "CODEOWNERS not enabled",
+			sender: "maintainer",
+			want:   false,
+		},
+	}
+	users := map[string]int{"maintainer": 1, "group-member": 2, "contributor": 3, "docs-writer": 4}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+
+			for _, file := range []string{"OWNERS", "OWNERS_ALIASES", ".github%2FCODEOWNERS"} {
+				mux.HandleFunc(fmt.Sprintf("/projects/5000/repository/files/%s/raw", file), func(rw http.ResponseWriter, _ *http.Request) {
+					rw.WriteHeader(http.StatusNotFound)
+				})
+			}
+			thelp.MuxGetFile(mux, 5000, "CODEOWNERS", "* @maintainer @group/subgroup\n[Docs]\n/docs/ @docs-writer\n", false)
+			mux.HandleFunc("/users", func(rw http.ResponseWriter, r *http.Request) {
+				username := r.URL.Query().Get("username")
+				fmt.Fprintf(rw, `[{"id": %d, "username": %q}]`, users[username], username)
+			})
+			mux.HandleFunc("/groups/group%2Fsubgroup/members/all/2", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"id": 2, "username": "group-member"}`)
+			})
+			mux.HandleFunc("/groups/group%2Fsubgroup/members/all/", func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusNotFound)
+			})
+
+			v := &Provider{
+				gitlabClient:       client,
+				targetProjectID:    5000,
+				repo:               &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{CodeOwners: tt.codeOwners}}},
+				cachedChangedFiles: &changedfiles.ChangedFiles{All: []string{"main.go"}},
+			}
+			got, err := v.IsAllowedOwnersFile(ctx, &info.Event{Sender: tt.sender, DefaultBranch: "main"})
+			if err != nil {
+				t.Fatalf("IsAllowedOwnersFile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsAllowedOwnersFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCheckMembership(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -264,7 +264,8 @@ func (v *Provider) SetClient(_ context.Context, run *params.Run, runevent *info.
 	}
 
 	// check that we have access to the source project if it's a private repo, this should only occur on Merge Requests
-	if runevent.TriggerTarget == triggertype.PullRequest {
+	// the source project of an incoming webhook on a merge request is only known once the merge request is fetched
+	if runevent.TriggerTarget == triggertype.PullRequest && runevent.EventType != triggertype.Incoming.String() {
 		_, resp, err := v.Client().Projects.GetProject(runevent.SourceProjectID, &gitlab.GetProjectOptions{})
 		errmsg := fmt.Sprintf("failed to access GitLab source repository ID %d: please ensure token has 'read_repository' scope on that repository",
 			runevent.SourceProjectID)
@@ -287,6 +288,7 @@ func (v *Provider) SetClient(_ context.Context, run *params.Run, runevent *info.
 		// TODO: we really need to move out the runevent.*ProjecTID to v.*ProjectID,
 		// I just spent half an hour debugging because i didn't realise it was there instead in v.*
 		v.sourceProjectID = projectinfo.ID
+		v.targetProjectID = projectinfo.ID
 		runevent.SourceProjectID = projectinfo.ID
 		runevent.TargetProjectID = projectinfo.ID
 		runevent.DefaultBranch = projectinfo.DefaultBranch
@@ -541,15 +543,49 @@ func (v *Provider) GetFileInsideRepo(_ context.Context, runevent *info.Event, pa
 	return string(getobj), nil
 }
 
+// IsCommitOnBranch checks the commit is reachable from the branch, ie: for an
+// incoming webhook on a given SHA.
+func (v *Provider) IsCommitOnBranch(_ context.Context, _ *info.Event, sha, branch string) (bool, error) {
+	if v.gitlabClient == nil {
+		return false, fmt.Errorf("%s", noClientErrStr)
+	}
+	mergeBase, _, err := v.Client().Repositories.MergeBase(v.sourceProjectID, &gitlab.MergeBaseOptions{Ref: &[]string{sha, branch}})
+	if err != nil {
+		return false, fmt.Errorf("cannot get the merge base of %s and the branch %s: %w", sha, branch, err)
+	}
+	return strings.HasPrefix(mergeBase.ID, strings.ToLower(sha)), nil
+}
+
 func (v *Provider) GetCommitInfo(_ context.Context, runevent *info.Event) error {
 	if v.gitlabClient == nil {
 		return fmt.Errorf("%s", noClientErrStr)
 	}
 
+	// an incoming webhook on a merge request, get the head of the merge
+	// request first.
+	if runevent.SHA == "" && runevent.HeadBranch == "" && runevent.PullRequestNumber != 0 {
+		mr, _, err := v.Client().MergeRequests.GetMergeRequest(v.targetProjectID, int64(runevent.PullRequestNumber), &gitlab.GetMergeRequestsOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot get merge request %d: %w", runevent.PullRequestNumber, err)
+		}
+		runevent.HeadBranch = mr.SourceBranch
+		runevent.BaseBranch = mr.TargetBranch
+		runevent.PullRequestTitle = mr.Title
+		runevent.PullRequestLabel = mr.Labels
+		runevent.SourceProjectID = mr.SourceProjectID
+		runevent.TargetProjectID = mr.TargetProjectID
+		v.sourceProjectID = mr.SourceProjectID
+	}
+
 	// if we don't have a SHA (ie: incoming-webhook) then get it from the branch
-	// and populate in the runevent.
-	if runevent.SHA == "" && runevent.HeadBranch != "" {
-		branchinfo, _, err := v.Client().Commits.GetCommit(v.sourceProjectID, runevent.HeadBranch, &gitlab.GetCommitOptions{})
+	// and populate in the runevent. The commit of an incoming webhook on a
+	// given SHA is fetched the same way.
+	ref := runevent.HeadBranch
+	if runevent.SHA != "" {
+		ref = runevent.SHA
+	}
+	if (runevent.SHA == "" && runevent.HeadBranch != "") || (runevent.SHA != "" && runevent.SHATitle == "" && runevent.EventType == "incoming") {
+		branchinfo, _, err := v.Client().Commits.GetCommit(v.sourceProjectID, ref, &gitlab.GetCommitOptions{})
 		if err != nil {
 			return err
 		}
@@ -26,6 +26,7 @@ type Interface interface {
 	SetClient(context.Context, *params.Run, *info.Event, *v1alpha1.Repository, *events.EventEmitter) error
 	SetPacInfo(*info.PacOpts)
 	GetCommitInfo(context.Context, *info.Event) error
+	IsCommitOnBranch(context.Context, *info.Event, string, string) (bool, error) // ctx, event, sha, branch
 	GetConfig() *info.ProviderConfig
 	GetFiles(context.Context, *info.Event) (changedfiles.ChangedFiles, error)
 	GetTaskURI(ctx context.Context, event *info.Event, uri string) (bool, string, error)
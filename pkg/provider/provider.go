@@ -22,15 +22,21 @@ const ValidationErrorTemplate = `> [!CAUTION]
 var (
 	testRetestAllRegex    = regexp.MustCompile(`(?m)^(/retest|/test)\s*$`)
 	testRetestSingleRegex = regexp.MustCompile(`(?m)^(/test|/retest)[ \t]+\S+`)
+	retestFailedRegex     = regexp.MustCompile(`(?m)^/retest-failed[ \t]+\S+`)
+	holdRegex             = regexp.MustCompile(`(?m)^/(un)?hold\s*$`)
+	unholdRegex           = regexp.MustCompile(`(?m)^/unhold\s*$`)
+	commandRegex          = regexp.MustCompile(`(?m)^/[a-z0-9][a-z0-9_-]*(\s|$)`)
 	oktotestRegex         = regexp.MustCompile(`(?m)^/ok-to-test\s*$`)
+	revokeOkToTestRegex   = regexp.MustCompile(`(?m)^/revoke-ok-to-test\s*$`)
 	cancelAllRegex        = regexp.MustCompile(`(?m)^(/cancel)\s*$`)
 	cancelSingleRegex     = regexp.MustCompile(`(?m)^(/cancel)[ \t]+\S+`)
 )
 
 const (
-	testComment   = "/test"
-	retestComment = "/retest"
-	cancelComment = "/cancel"
+	testComment         = "/test"
+	retestComment       = "/retest"
+	retestFailedComment = "/retest-failed"
+	cancelComment       = "/cancel"
 )
 
 const (
@@ -82,10 +88,39 @@ func IsTestRetestComment(comment string) bool {
 	return testRetestSingleRegex.MatchString(comment) || testRetestAllRegex.MatchString(comment)
 }
 
+// IsRetestFailedComment returns true if the comment asks to rerun only the
+// failed tasks of a PipelineRun.
+func IsRetestFailedComment(comment string) bool {
+	return retestFailedRegex.MatchString(comment)
+}
+
+// IsCommandComment returns true if a line of the comment starts with a slash
+// command, custom GitOps commands can only be matched once the Repository CR
+// is known so any command has to be let through.
+func IsCommandComment(comment string) bool {
+	return commandRegex.MatchString(comment)
+}
+
+// IsHoldComment returns true if the comment is a /hold or /unhold command.
+func IsHoldComment(comment string) bool {
+	return holdRegex.MatchString(comment)
+}
+
+// IsUnholdComment returns true if the comment is a /unhold command.
+func IsUnholdComment(comment string) bool {
+	return unholdRegex.MatchString(comment)
+}
+
 func IsOkToTestComment(comment string) bool {
 	return oktotestRegex.MatchString(comment)
 }
 
+// IsRevokeOkToTestComment returns true if the comment is a /revoke-ok-to-test
+// command.
+func IsRevokeOkToTestComment(comment string) bool {
+	return revokeOkToTestRegex.MatchString(comment)
+}
+
 func IsCancelComment(comment string) bool {
 	return cancelAllRegex.MatchString(comment) || cancelSingleRegex.MatchString(comment)
 }
@@ -97,6 +132,10 @@ func GetPipelineRunFromTestComment(comment string) string {
 	return getNameFromComment(retestComment, comment)
 }
 
+func GetPipelineRunFromRetestFailedComment(comment string) string {
+	return getNameFromComment(retestFailedComment, comment)
+}
+
 func GetPipelineRunFromCancelComment(comment string) string {
 	return getNameFromComment(cancelComment, comment)
 }
@@ -177,6 +177,54 @@ func TestIsTestRetestComment(t *testing.T) {
 	}
 }
 
+func TestIsRetestFailedComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		comment  string
+		want     bool
+		wantName string
+	}{
+		{
+			name:     "retest failed",
+			comment:  "/retest-failed abc",
+			want:     true,
+			wantName: "abc",
+		},
+		{
+			name:     "retest failed with some string before and after",
+			comment:  "the flake is fixed\n/retest-failed abc\nthanks",
+			want:     true,
+			wantName: "abc",
+		},
+		{
+			name:    "retest failed without pipelinerun",
+			comment: "/retest-failed",
+			want:    false,
+		},
+		{
+			name:    "retest is not retest failed",
+			comment: "/retest abc",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetestFailedComment(tt.comment))
+			if tt.want {
+				assert.Equal(t, tt.wantName, GetPipelineRunFromRetestFailedComment(tt.comment))
+			}
+		})
+	}
+}
+
+func TestIsCommandComment(t *testing.T) {
+	assert.Assert(t, IsCommandComment("/deploy staging"))
+	assert.Assert(t, IsCommandComment("please\n/help"))
+	assert.Assert(t, !IsCommandComment("no command here /deploy"))
+	assert.Assert(t, !IsCommandComment("/Deploy"))
+}
+
 func TestGetPipelineRunFromComment(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -664,3 +712,23 @@ func TestSkipCI(t *testing.T) {
 		})
 	}
 }
+
+func TestIsHoldComment(t *testing.T) {
+	assert.Assert(t, IsHoldComment("/hold"))
+	assert.Assert(t, IsHoldComment("waiting for the release\n/unhold\n"))
+	assert.Assert(t, !IsHoldComment("/holdings"))
+	assert.Assert(t, !IsHoldComment("please /hold"))
+}
+
+func TestIsUnholdComment(t *testing.T) {
+	assert.Assert(t, IsUnholdComment("waiting for the release\n/unhold\n"))
+	assert.Assert(t, !IsUnholdComment("/hold"))
+	assert.Assert(t, !IsUnholdComment("don't /unhold yet"))
+}
+
+func TestIsRevokeOkToTestComment(t *testing.T) {
+	assert.Assert(t, IsRevokeOkToTestComment("/revoke-ok-to-test"))
+	assert.Assert(t, IsRevokeOkToTestComment("not ready yet\n/revoke-ok-to-test\n"))
+	assert.Assert(t, !IsRevokeOkToTestComment("/revoke-ok-to-test abc"))
+	assert.Assert(t, !IsOkToTestComment("/revoke-ok-to-test"))
+}
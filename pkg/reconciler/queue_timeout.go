@@ -0,0 +1,107 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	providerstatus "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/status"
+	queuepkg "github.com/openshift-pipelines/pipelines-as-code/pkg/queue"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+// queueTimeout returns how long a queued PipelineRun is allowed to wait for
+// its turn, the PipelineRun annotation takes precedence over the global
+// setting. A zero duration means the PipelineRun can wait forever.
+func (r *Reconciler) queueTimeout(logger *zap.SugaredLogger, pr *tektonv1.PipelineRun) time.Duration {
+	value, ok := pr.GetAnnotations()[keys.QueueTimeout]
+	if !ok || value == "" {
+		value = r.run.Info.GetPacOpts().QueueTimeout
+	}
+	if value == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		logger.Warnf("ignoring invalid queue timeout %q for pipelineRun %s/%s", value, pr.GetNamespace(), pr.GetName())
+		return 0
+	}
+	return timeout
+}
+
+// queueTimeoutRemaining returns how much time a queued PipelineRun has left
+// before it expires and whether a queue timeout applies to it at all.
+func (r *Reconciler) queueTimeoutRemaining(logger *zap.SugaredLogger, pr *tektonv1.PipelineRun) (time.Duration, bool) {
+	timeout := r.queueTimeout(logger, pr)
+	if timeout == 0 {
+		return 0, false
+	}
+
+	clock := r.clock
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+	return timeout - clock.Since(pr.GetCreationTimestamp().Time), true
+}
+
+// cancelQueuedPipelineRun cancels a PipelineRun which has waited too long in
+// the queue, reports it as cancelled on the git provider and releases its
+// place in the repository queue.
+func (r *Reconciler) cancelQueuedPipelineRun(ctx context.Context, logger *zap.SugaredLogger, pr *tektonv1.PipelineRun) error {
+	timeout := r.queueTimeout(logger, pr)
+	repoName := pr.GetAnnotations()[keys.Repository]
+	repo, err := r.repoLister.Repositories(pr.Namespace).Get(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get repository CR: %w", err)
+	}
+
+	logger.Infof("pipelineRun %s/%s has been waiting in queue for more than %s, cancelling it", pr.GetNamespace(), pr.GetName(), timeout)
+
+	// mark the PipelineRun as completed so we don't report a second final
+	// status when the cancelled PipelineRun gets reconciled again.
+	mergePatch := map[string]any{
+		"metadata": map[string]any{
+			"labels": map[string]string{
+				keys.State: kubeinteraction.StateCompleted,
+			},
+			"annotations": map[string]string{
+				keys.State: kubeinteraction.StateCompleted,
+			},
+		},
+		"spec": map[string]any{
+			"status": tektonv1.PipelineRunSpecStatusCancelled,
+		},
+	}
+	pr, err = action.PatchPipelineRun(ctx, logger, "queue timeout", r.run.Clients.Tekton, pr, mergePatch)
+	if err != nil {
+		return fmt.Errorf("cannot cancel queued pipelineRun: %w", err)
+	}
+	_ = r.qm.RemoveFromQueue(queuepkg.RepoKey(repo), queuepkg.PrKey(pr))
+
+	msg := fmt.Sprintf("PipelineRun <b>%s</b> has timed out waiting in queue after %s and has been cancelled.", pr.GetName(), timeout)
+	r.eventEmitter.EmitMessage(repo, zap.InfoLevel, "QueueTimeout", msg)
+
+	detectedProvider, event, err := r.initGitProviderClient(ctx, logger, repo, pr)
+	if err != nil {
+		return fmt.Errorf("cannot initialize git provider client: %w", err)
+	}
+	status := providerstatus.StatusOpts{
+		Status:                  pipelineascode.CompletedStatus,
+		Conclusion:              providerstatus.ConclusionCancelled,
+		Text:                    msg,
+		DetailsURL:              r.run.Clients.ConsoleUI().DetailURL(pr),
+		PipelineRunName:         pr.GetName(),
+		PipelineRun:             pr,
+		OriginalPipelineRunName: pr.GetAnnotations()[keys.OriginalPRName],
+	}
+	if err := createStatusWithRetry(ctx, logger, detectedProvider, event, status); err != nil {
+		logger.Errorf("failed to report queue timeout to provider: %v", err)
+	}
+	return nil
+}
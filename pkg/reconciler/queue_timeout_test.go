@@ -0,0 +1,198 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testconcurrency "github.com/openshift-pipelines/pipelines-as-code/pkg/test/concurrency"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	testkubernetestint "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestQueueTimeoutRemaining(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	tests := []struct {
+		name          string
+		annotation    string
+		setting       string
+		age           time.Duration
+		wantRemaining time.Duration
+		wantTimeout   bool
+	}{
+		{
+			name: "no timeout configured",
+			age:  time.Hour,
+		},
+		{
+			name:          "global setting",
+			setting:       "30m",
+			age:           10 * time.Minute,
+			wantRemaining: 20 * time.Minute,
+			wantTimeout:   true,
+		},
+		{
+			name:          "annotation overrides global setting",
+			annotation:    "1h",
+			setting:       "30m",
+			age:           40 * time.Minute,
+			wantRemaining: 20 * time.Minute,
+			wantTimeout:   true,
+		},
+		{
+			name:          "expired",
+			annotation:    "5m",
+			age:           10 * time.Minute,
+			wantRemaining: -5 * time.Minute,
+			wantTimeout:   true,
+		},
+		{
+			name:       "invalid annotation is ignored",
+			annotation: "forever",
+			age:        time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pr",
+					Namespace:         "ns",
+					CreationTimestamp: metav1.NewTime(clock.Now().Add(-tt.age)),
+					Annotations:       map[string]string{},
+				},
+			}
+			if tt.annotation != "" {
+				pr.Annotations[keys.QueueTimeout] = tt.annotation
+			}
+			r := &Reconciler{
+				clock: clock,
+				run: &params.Run{
+					Info: info.Info{
+						Pac: &info.PacOpts{
+							Settings: settings.Settings{QueueTimeout: tt.setting},
+						},
+					},
+				},
+			}
+			remaining, hasTimeout := r.queueTimeoutRemaining(logger, pr)
+			assert.Equal(t, hasTimeout, tt.wantTimeout)
+			assert.Equal(t, remaining, tt.wantRemaining)
+		})
+	}
+}
+
+func TestReconcileKindQueueTimeout(t *testing.T) {
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+
+	_, mux, ghTestServerURL, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+
+	var reportedState string
+	mux.HandleFunc("/repos/random/app/statuses/123afc", func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		status := map[string]any{}
+		_ = json.Unmarshal(body, &status)
+		reportedState, _ = status["state"].(string)
+		_, _ = rw.Write([]byte(`{}`))
+	})
+
+	clock := clockwork.NewFakeClock()
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "test",
+			Name:              "test-pr",
+			CreationTimestamp: metav1.NewTime(clock.Now().Add(-time.Hour)),
+			Annotations: map[string]string{
+				keys.State:          kubeinteraction.StateQueued,
+				keys.Repository:     "test-repo",
+				keys.GitProvider:    "github",
+				keys.SHA:            "123afc",
+				keys.URLOrg:         "random",
+				keys.URLRepository:  "app",
+				keys.ExecutionOrder: "test/test-pr",
+				keys.QueueTimeout:   "10m",
+			},
+		},
+		Spec: tektonv1.PipelineRunSpec{
+			Status: tektonv1.PipelineRunSpecStatusPending,
+		},
+	}
+	testRepo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-repo",
+			Namespace: pr.GetNamespace(),
+		},
+		Spec: v1alpha1.RepositorySpec{
+			URL: randomURL,
+			GitProvider: &v1alpha1.GitProvider{
+				URL: ghTestServerURL,
+				Secret: &v1alpha1.Secret{
+					Name: "pac-git-basic-auth-owner-repo",
+				},
+			},
+		},
+	}
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	stdata, informers := testclient.SeedTestData(t, ctx, testclient.Data{
+		Repositories: []*v1alpha1.Repository{testRepo},
+		PipelineRuns: []*tektonv1.PipelineRun{pr},
+	})
+
+	cs := &params.Run{
+		Clients: clients.Clients{
+			Tekton: stdata.Pipeline,
+			Log:    logger,
+		},
+		Info: info.Info{
+			Pac: &info.PacOpts{
+				Settings: settings.Settings{},
+			},
+		},
+	}
+	cs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+
+	r := &Reconciler{
+		repoLister:   informers.Repository.Lister(),
+		run:          cs,
+		qm:           testconcurrency.TestQMI{},
+		clock:        clock,
+		eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+		kinteract: &testkubernetestint.KinterfaceTest{
+			GetSecretResult: map[string]string{
+				"pac-git-basic-auth-owner-repo": "https://whateveryousayboss",
+			},
+		},
+	}
+
+	assert.NilError(t, r.ReconcileKind(ctx, pr))
+
+	updatedPR, err := stdata.Pipeline.TektonV1().PipelineRuns(pr.GetNamespace()).Get(ctx, pr.GetName(), metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, updatedPR.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusCancelled))
+	assert.Equal(t, updatedPR.GetAnnotations()[keys.State], kubeinteraction.StateCompleted)
+	assert.Equal(t, reportedState, "cancelled")
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jonboulle/clockwork"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	pipelinerunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1/pipelinerun"
 	tektonv1lister "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
@@ -46,6 +48,7 @@ type Reconciler struct {
 	eventEmitter      *events.EventEmitter
 	globalRepo        *v1alpha1.Repository
 	secretNS          string
+	clock             clockwork.Clock
 }
 
 var (
@@ -77,6 +80,12 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pr *tektonv1.PipelineRun
 		return nil
 	}
 
+	// PipelineRuns started from a local working tree with tkn pac run are not
+	// tied to an event, there is nothing to report on the git provider.
+	if pr.GetAnnotations()[keys.LocalRun] == "true" {
+		return nil
+	}
+
 	reason := ""
 	if len(pr.Status.GetConditions()) > 0 {
 		reason = pr.Status.GetConditions()[0].GetReason()
@@ -111,7 +120,19 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pr *tektonv1.PipelineRun
 	// queue pipelines which are in queued state and pending status
 	// if status is not pending, it could be cancelled so let it be reported, even if state is queued
 	if state == kubeinteraction.StateQueued && pr.Spec.Status == tektonv1.PipelineRunSpecStatusPending {
-		return r.queuePipelineRun(ctx, logger, pr)
+		remaining, hasTimeout := r.queueTimeoutRemaining(logger, pr)
+		if hasTimeout && remaining <= 0 {
+			return r.cancelQueuedPipelineRun(ctx, logger, pr)
+		}
+		if err := r.queuePipelineRun(ctx, logger, pr); err != nil {
+			return err
+		}
+		// get reconciled again when the queue timeout expires, if the
+		// PipelineRun has started by then there is nothing to do.
+		if hasTimeout {
+			return controller.NewRequeueAfter(remaining)
+		}
+		return nil
 	}
 
 	if !pr.IsDone() && !pr.IsCancelled() {
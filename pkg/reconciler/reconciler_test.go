@@ -428,6 +428,38 @@ func TestReconcileKind_SCMReportingLogic(t *testing.T) {
 			shouldCallUpdateToInProgress: false,
 			description:                  "PipelineRun is not in Running state",
 		},
+		{
+			name: "Running local run - should NOT call updatePipelineRunToInProgress",
+			pipelineRun: &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "test-pr",
+					Annotations: map[string]string{
+						keys.State:         kubeinteraction.StateQueued,
+						keys.Repository:    "test-repo",
+						keys.GitProvider:   "github",
+						keys.SHA:           "123afc",
+						keys.URLOrg:        "random",
+						keys.URLRepository: "app",
+						keys.LocalRun:      "true",
+					},
+				},
+				Spec: tektonv1.PipelineRunSpec{},
+				Status: tektonv1.PipelineRunStatus{
+					Status: knativeduckv1.Status{
+						Conditions: knativeduckv1.Conditions{
+							{
+								Type:   knativeapi.ConditionSucceeded,
+								Status: corev1.ConditionUnknown,
+								Reason: string(tektonv1.PipelineRunReasonRunning),
+							},
+						},
+					},
+				},
+			},
+			shouldCallUpdateToInProgress: false,
+			description:                  "PipelineRun has been started with tkn pac run",
+		},
 	}
 
 	for _, tt := range tests {
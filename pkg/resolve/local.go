@@ -0,0 +1,34 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadTektonDir returns the YAML files of a local directory and its
+// subdirectories as a single multi-document YAML, the same way they are
+// fetched from the git provider.
+func ReadTektonDir(dir string) (string, error) {
+	var ret strings.Builder
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		ret.WriteString("---\n")
+		ret.Write(b)
+		ret.WriteString("\n")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return ret.String(), nil
+}
@@ -88,7 +88,9 @@ func getPipelineByName(name string, tasks []*tektonv1.Pipeline) (*tektonv1.Pipel
 	return &tektonv1.Pipeline{}, fmt.Errorf("cannot find referenced pipeline %s. for a remote pipeline make sure to add it in the annotation", name)
 }
 
-func pipelineRunsWithSameName(prs []*tektonv1.PipelineRun) error {
+// PipelineRunsWithSameName returns an error for the first PipelineRun sharing
+// its name or generateName with a previous one.
+func PipelineRunsWithSameName(prs []*tektonv1.PipelineRun) error {
 	prNames := map[string]bool{}
 	for _, pr := range prs {
 		name := pr.GetName()
@@ -241,7 +243,7 @@ func Resolve(ctx context.Context, cs *params.Run, logger *zap.SugaredLogger, pro
 }
 
 func MetadataResolve(prs []*tektonv1.PipelineRun) ([]*tektonv1.PipelineRun, error) {
-	if err := pipelineRunsWithSameName(prs); err != nil {
+	if err := PipelineRunsWithSameName(prs); err != nil {
 		return []*tektonv1.PipelineRun{}, err
 	}
 
@@ -573,7 +573,7 @@ func TestPipelineRunsWithSameName(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := pipelineRunsWithSameName(tt.prs)
+			err := PipelineRunsWithSameName(tt.prs)
 			if tt.err == "" {
 				assert.NilError(t, err)
 				return
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
@@ -33,7 +34,12 @@ type TestProviderImp struct {
 	WantRenamedFiles       []string
 	FailGetCommitInfo      bool
 	CommitInfoErrorMsg     string
-	pacInfo                *info.PacOpts
+	CommitNotOnBranch      bool
+	// CreatedComments records the comments created on the events.
+	CreatedComments []string
+	// PolicyChecks counts the calls to CheckPolicyAllowing and IsAllowedOwnersFile.
+	PolicyChecks int
+	pacInfo      *info.PacOpts
 }
 
 func (v *TestProviderImp) SetPacInfo(pacInfo *info.PacOpts) {
@@ -41,17 +47,32 @@ func (v *TestProviderImp) SetPacInfo(pacInfo *info.PacOpts) {
 }
 
 func (v *TestProviderImp) CheckPolicyAllowing(_ context.Context, _ *info.Event, _ []string) (bool, string) {
+	v.PolicyChecks++
 	if v.PolicyDisallowing {
 		return false, "policy disallowing"
 	}
 	return true, ""
 }
 
-func (v *TestProviderImp) IsAllowedOwnersFile(_ context.Context, _ *info.Event) (bool, error) {
-	return v.AllowedInOwnersFile, nil
+// IsAllowedOwnersFile checks the sender against an OWNERS file listing it
+// when AllowedInOwnersFile is set, through the acl package caching the check on
+// the event like the providers do.
+func (v *TestProviderImp) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (bool, error) {
+	getFile := func(_ context.Context, path string) (string, error) {
+		if path != "OWNERS" || !v.AllowedInOwnersFile {
+			return "", nil
+		}
+		return fmt.Sprintf("approvers:\n  - %s\n", event.Sender), nil
+	}
+	getChangedFiles := func(ctx context.Context, event *info.Event) (changedfiles.ChangedFiles, error) {
+		v.PolicyChecks++
+		return v.GetFiles(ctx, event)
+	}
+	return acl.UserInEventOwnersFiles(ctx, getFile, getChangedFiles, event, event.Sender)
 }
 
-func (v *TestProviderImp) CreateComment(_ context.Context, _ *info.Event, _, _ string) error {
+func (v *TestProviderImp) CreateComment(_ context.Context, _ *info.Event, comment, _ string) error {
+	v.CreatedComments = append(v.CreatedComments, comment)
 	return nil
 }
 
@@ -74,6 +95,10 @@ func (v *TestProviderImp) GetConfig() *info.ProviderConfig {
 	return &info.ProviderConfig{}
 }
 
+func (v *TestProviderImp) IsCommitOnBranch(_ context.Context, _ *info.Event, _, _ string) (bool, error) {
+	return !v.CommitNotOnBranch, nil
+}
+
 func (v *TestProviderImp) GetCommitInfo(_ context.Context, event *info.Event) error {
 	if v.FailGetCommitInfo {
 		if v.CommitInfoErrorMsg != "" {
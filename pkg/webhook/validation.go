@@ -2,8 +2,10 @@ package webhook
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"os"
+	"regexp"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	pac "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/listers/pipelinesascode/v1alpha1"
@@ -77,9 +79,31 @@ func (ac *reconciler) Admit(_ context.Context, request *v1.AdmissionRequest) *v1
 		}
 	}
 
+	if repo.Spec.Incomings != nil {
+		for _, incoming := range *repo.Spec.Incomings {
+			if err := validateIncomingParamsSchema(incoming.ParamsSchema); err != nil {
+				return webhook.MakeErrorStatus("invalid incoming webhook params schema: %v", err)
+			}
+		}
+	}
+
 	return &v1.AdmissionResponse{Allowed: true}
 }
 
+func validateIncomingParamsSchema(schema []v1alpha1.IncomingParam) error {
+	for _, param := range schema {
+		if param.Type == "enum" && len(param.Enum) == 0 {
+			return fmt.Errorf("param %s of type enum has no enum values", param.Name)
+		}
+		if param.Pattern != "" {
+			if _, err := regexp.Compile(param.Pattern); err != nil {
+				return fmt.Errorf("param %s has an invalid pattern: %w", param.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func checkIfRepoExist(pac pac.RepositoryLister, repo *v1alpha1.Repository, ns string) (bool, error) {
 	repositories, err := pac.Repositories(ns).List(labels.NewSelector())
 	if err != nil {
@@ -14,6 +14,16 @@ import (
 	rtesting "knative.dev/pkg/reconciler/testing"
 )
 
+func repoWithIncomingParamsSchema(schema ...v1alpha1.IncomingParam) *v1alpha1.Repository {
+	repo := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+		Name:             "test-run",
+		InstallNamespace: "namespace",
+		URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+	})
+	repo.Spec.Incomings = &[]v1alpha1.Incoming{{Type: "webhook-url", ParamsSchema: schema}}
+	return repo
+}
+
 func TestReconciler_Admit(t *testing.T) {
 	globalNamespace := "globalNamespace"
 	envRemove := env.PatchAll(t, map[string]string{"SYSTEM_NAMESPACE": globalNamespace})
@@ -103,6 +113,26 @@ func TestReconciler_Admit(t *testing.T) {
 			allowed: false,
 			result:  "repository already exists with URL: https://pac.test/already/installed",
 		},
+		{
+			name: "allow incoming params schema",
+			repo: repoWithIncomingParamsSchema(
+				v1alpha1.IncomingParam{Name: "env", Type: "enum", Enum: []string{"staging", "production"}},
+				v1alpha1.IncomingParam{Name: "version", Pattern: `^v[0-9.]+$`},
+			),
+			allowed: true,
+		},
+		{
+			name:    "reject incoming enum param without values",
+			repo:    repoWithIncomingParamsSchema(v1alpha1.IncomingParam{Name: "env", Type: "enum"}),
+			allowed: false,
+			result:  "invalid incoming webhook params schema: param env of type enum has no enum values",
+		},
+		{
+			name:    "reject incoming param with invalid pattern",
+			repo:    repoWithIncomingParamsSchema(v1alpha1.IncomingParam{Name: "version", Pattern: `v[0-9`}),
+			allowed: false,
+			result:  "invalid incoming webhook params schema: param version has an invalid pattern: error parsing regexp: missing closing ]: `[0-9`",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
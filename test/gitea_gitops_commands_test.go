@@ -11,6 +11,7 @@ import (
 	"time"
 
 	forgejo "codeberg.org/mvdkleijn/forgejo-sdk/forgejo/v3"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
@@ -302,3 +303,31 @@ func TestGiteaRetestCommentUpdate(t *testing.T) {
 		})
 	}
 }
+
+// TestGiteaHoldUnhold tests that /hold and /unhold record the hold of the
+// pull request on the Repository CR, which needs the controller to be allowed
+// to patch it.
+func TestGiteaHoldUnhold(t *testing.T) {
+	topts := &tgitea.TestOpts{
+		TargetEvent: triggertype.PullRequest.String(),
+		YAMLFiles: map[string]string{
+			".tekton/pr.yaml": "testdata/pipelinerun.yaml",
+		},
+	}
+	_, f := tgitea.TestPR(t, topts)
+	defer f()
+
+	holdKey := fmt.Sprintf("%s-%d", keys.Hold, topts.PullRequest.Index)
+	waitOpts := twait.Opts{
+		RepoName:    topts.TargetNS,
+		Namespace:   topts.TargetNS,
+		PollTimeout: twait.DefaultTimeout,
+	}
+	tgitea.PostCommentOnPullRequest(t, topts, "/hold")
+	_, err := twait.UntilRepositoryAnnotation(context.Background(), topts.ParamsRun.Clients, waitOpts, holdKey, topts.PullRequest.Poster.UserName)
+	assert.NilError(t, err, "the pull request has not been put on hold")
+
+	tgitea.PostCommentOnPullRequest(t, topts, "/unhold")
+	_, err = twait.UntilRepositoryAnnotation(context.Background(), topts.ParamsRun.Clients, waitOpts, holdKey, "")
+	assert.NilError(t, err, "the pull request has not been released from hold")
+}
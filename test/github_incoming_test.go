@@ -219,6 +219,7 @@ func verifyIncomingWebhook(t *testing.T, randomedString, pipelinerunName string,
 	incoming := &[]v1alpha1.Incoming{
 		{
 			Type: "webhook-url",
+			Auth: &v1alpha1.IncomingAuth{Type: "secret"},
 			Secret: v1alpha1.Secret{
 				Name: incomingSecretName,
 				Key:  "incoming",
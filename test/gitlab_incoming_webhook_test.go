@@ -36,6 +36,7 @@ func testGitlabIncomingWebhook(t *testing.T, useLegacy bool) {
 		Incomings: &[]v1alpha1.Incoming{
 			{
 				Type: "webhook-url",
+				Auth: &v1alpha1.IncomingAuth{Type: "secret"},
 				Secret: v1alpha1.Secret{
 					Name: incomingSecretName,
 					Key:  "incoming",
@@ -123,6 +123,23 @@ func UntilRepositoryHasStatusReason(ctx context.Context, clients clients.Clients
 	})
 }
 
+// UntilRepositoryAnnotation waits until the annotation of the Repository has
+// the value, an empty value waits until the annotation is removed.
+func UntilRepositoryAnnotation(ctx context.Context, clients clients.Clients, opts Opts, key, value string) (*pacv1alpha1.Repository, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.PollTimeout)
+	defer cancel()
+	var repo *pacv1alpha1.Repository
+	return repo, kubeinteraction.PollImmediateWithContext(ctx, opts.PollTimeout, func() (bool, error) {
+		var err error
+		if repo, err = clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(opts.Namespace).Get(ctx, opts.RepoName, metav1.GetOptions{}); err != nil {
+			return true, err
+		}
+		got := repo.GetAnnotations()[key]
+		clients.Log.Infof("Still waiting for repository annotation %s: wanted=%q got=%q", key, value, got)
+		return got == value, nil
+	})
+}
+
 func UntilPipelineRunCreated(ctx context.Context, clients clients.Clients, opts Opts) error {
 	ctx, cancel := context.WithTimeout(ctx, opts.PollTimeout)
 	defer cancel()